@@ -62,7 +62,7 @@ func main() {
 
 	// solve linear problem:
 	//   K11 * U1 = F1
-	U1, err := la.SolveRealLinSys(&K11, F1)
+	U1, err := la.SpSolve(&K11, F1)
 	if err != nil {
 		chk.Panic("solve failed: %v", err)
 	}
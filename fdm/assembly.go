@@ -0,0 +1,210 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fdm
+
+import (
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+)
+
+// Source1d defines the source term s(x) in 1D
+type Source1d func(x float64, args ...interface{}) float64
+
+// Source2d defines the source term s(x,y) in 2D
+type Source2d func(x, y float64, args ...interface{}) float64
+
+// Source3d defines the source term s(x,y,z) in 3D
+type Source3d func(x, y, z float64, args ...interface{}) float64
+
+// Operator holds the coefficients of the linear (diffusion-convection-reaction) operator
+//
+//   L(u) = - Σᵢ kᵢ⋅∂²u/∂xᵢ² + Σᵢ vᵢ⋅∂u/∂xᵢ + r⋅u
+//
+//  The second derivatives are approximated by central differences. The first derivatives are
+//  approximated by central differences or by first-order upwind differences.
+type Operator struct {
+	K      [3]float64 // diffusion coefficients: kx, ky, kz
+	V      [3]float64 // convection velocities: vx, vy, vz
+	R      float64    // reaction coefficient
+	Upwind bool       // use upwind differences for the convective terms
+}
+
+// coefs returns the coefficients multiplying u[i-1], u[i] and u[i+1] along direction dim
+func (o *Operator) coefs(dim int, h float64) (cm, c0, cp float64) {
+	k, v := o.K[dim], o.V[dim]
+	hh := h * h
+	cm, c0, cp = -k/hh, 2.0*k/hh, -k/hh
+	if v == 0 {
+		return
+	}
+	if o.Upwind {
+		if v > 0 {
+			cm -= v / h
+			c0 += v / h
+		} else {
+			c0 -= v / h
+			cp += v / h
+		}
+		return
+	}
+	cm -= v / (2.0 * h)
+	cp += v / (2.0 * h)
+	return
+}
+
+// AssembleOperator assembles the discrete system L(u) = s corresponding to the unknown equations
+//
+//   K11 ⋅ U1 + K12 ⋅ U2 = F1
+//
+//  Notes:
+//   1) K11 and K12 must have been allocated; e.g. with InitK11andK12
+//   2) K11, K12 and F1 are re-started (overwritten)
+//   3) at boundary nodes that are not prescribed, the (ghost) node outside the grid is mirrored;
+//      i.e. homogeneous Neumann conditions (zero flux) are applied by default. Non-zero fluxes
+//      and Robin conditions can be added afterwards with AssembleNeumann and AssembleRobin
+//  Input:
+//   op     -- coefficients of the operator
+//   source -- source term s(x) with len(x) = ndim [may be nil]
+//   g      -- the grid
+//   e      -- the equations
+func AssembleOperator(K11, K12 *la.Triplet, F1 []float64, op *Operator, source func(x []float64) float64, g Grid, e *Equations) {
+	K11.Start()
+	K12.Start()
+	nn, dd := g.shape()
+	ndim := g.Ndim()
+	stride := [3]int{1, nn[0], nn[0] * nn[1]}
+	x := make([]float64, ndim)
+	put := func(I, n int, val float64) {
+		if val == 0 {
+			return
+		}
+		if e.FR1[n] >= 0 {
+			K11.Put(I, e.FR1[n], val)
+		} else {
+			K12.Put(I, e.FR2[n], val)
+		}
+	}
+	for I, n := range e.RF1 {
+		idx := [3]int{n % nn[0], (n / nn[0]) % nn[1], n / stride[2]}
+		diag := op.R
+		for d := 0; d < ndim; d++ {
+			cm, c0, cp := op.coefs(d, dd[d])
+			diag += c0
+			m, p := n-stride[d], n+stride[d]
+			if idx[d] == 0 {
+				m = p // mirror ghost node
+			}
+			if idx[d] == nn[d]-1 {
+				p = m // mirror ghost node
+			}
+			put(I, m, cm)
+			put(I, p, cp)
+		}
+		put(I, n, diag)
+		F1[I] = 0
+		if source != nil {
+			g.Coords(x, n)
+			F1[I] = source(x)
+		}
+	}
+}
+
+// AssembleRobin adds the contribution of the Robin boundary condition
+//
+//   k ⋅ ∂u/∂n + β ⋅ u = q(x)
+//
+//  on side of the grid, where n is the outward normal and k the diffusion coefficient along
+//  the normal direction. The condition is imposed by eliminating the ghost node outside the grid.
+//  Prescribed nodes on side are skipped.
+//  NOTE: this function must be called after AssembleOperator
+func AssembleRobin(K11 *la.Triplet, F1 []float64, op *Operator, beta float64, q func(x []float64) float64, side int, g Grid, e *Equations) {
+	_, dd := g.shape()
+	dim := side / 2
+	if dim >= g.Ndim() {
+		chk.Panic("side %d is not available in %dD grid", side, g.Ndim())
+	}
+	k, h := op.K[dim], dd[dim]
+	if k == 0 {
+		chk.Panic("diffusion coefficient along direction %d must be non-zero for Neumann/Robin conditions", dim)
+	}
+	cm, _, cp := op.coefs(dim, h)
+	cg := cm // coefficient of ghost node
+	if side%2 == 1 {
+		cg = cp
+	}
+	x := make([]float64, g.Ndim())
+	for _, n := range g.Boundary(side) {
+		I := e.FR1[n]
+		if I < 0 {
+			continue
+		}
+		// u_ghost = u_inner + (2h/k)⋅(q - β⋅u)
+		fac := -cg * 2.0 * h / k
+		if beta != 0 {
+			K11.Put(I, I, fac*beta)
+		}
+		if q != nil {
+			g.Coords(x, n)
+			F1[I] += fac * q(x)
+		}
+	}
+}
+
+// AssembleNeumann adds the contribution of the Neumann boundary condition
+//
+//   k ⋅ ∂u/∂n = q(x)
+//
+//  on side of the grid, where n is the outward normal. See AssembleRobin.
+func AssembleNeumann(F1 []float64, op *Operator, q func(x []float64) float64, side int, g Grid, e *Equations) {
+	AssembleRobin(nil, F1, op, 0, q, side, g, e)
+}
+
+// AssemblePoisson1d assembles the discrete Poisson equation
+//
+//       ∂²u
+//  - kx ———  =  s(x)
+//       ∂x²
+//
+//  See AssembleOperator
+func AssemblePoisson1d(K11, K12 *la.Triplet, F1 []float64, kx float64, source Source1d, g *Grid1d, e *Equations, args ...interface{}) {
+	op := Operator{K: [3]float64{kx, 0, 0}}
+	var src func(x []float64) float64
+	if source != nil {
+		src = func(x []float64) float64 { return source(x[0], args...) }
+	}
+	AssembleOperator(K11, K12, F1, &op, src, g, e)
+}
+
+// AssemblePoisson2d assembles the discrete Poisson equation
+//
+//       ∂²u        ∂²u
+//  - kx ———  -  ky ———  =  s(x,y)
+//       ∂x²        ∂y²
+//
+//  See AssembleOperator
+func AssemblePoisson2d(K11, K12 *la.Triplet, F1 []float64, kx, ky float64, source Source2d, g *Grid2d, e *Equations, args ...interface{}) {
+	op := Operator{K: [3]float64{kx, ky, 0}}
+	var src func(x []float64) float64
+	if source != nil {
+		src = func(x []float64) float64 { return source(x[0], x[1], args...) }
+	}
+	AssembleOperator(K11, K12, F1, &op, src, g, e)
+}
+
+// AssemblePoisson3d assembles the discrete Poisson equation
+//
+//       ∂²u        ∂²u        ∂²u
+//  - kx ———  -  ky ———  -  kz ———  =  s(x,y,z)
+//       ∂x²        ∂y²        ∂z²
+//
+//  See AssembleOperator
+func AssemblePoisson3d(K11, K12 *la.Triplet, F1 []float64, kx, ky, kz float64, source Source3d, g *Grid3d, e *Equations, args ...interface{}) {
+	op := Operator{K: [3]float64{kx, ky, kz}}
+	var src func(x []float64) float64
+	if source != nil {
+		src = func(x []float64) float64 { return source(x[0], x[1], x[2], args...) }
+	}
+	AssembleOperator(K11, K12, F1, &op, src, g, e)
+}
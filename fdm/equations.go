@@ -0,0 +1,113 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fdm
+
+import (
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+)
+
+// Equations organises the equation numbers by partitioning the system into unknown (1) and
+// prescribed (2) parts
+//
+//   [K11 K12] [U1]   [F1]
+//   [K21 K22] [U2] = [F2]
+//
+//  where U1 are the unknowns and U2 the prescribed (known, given) values
+type Equations struct {
+	N        int   // total number of equations
+	N1, N2   int   // number of unknown and prescribed equations
+	RF1, FR1 []int // reduced=>full and full=>reduced maps of unknown equations. FR1[eq] = -1 if eq is prescribed
+	RF2, FR2 []int // reduced=>full and full=>reduced maps of prescribed equations. FR2[eq] = -1 if eq is unknown
+}
+
+// Init initialises Equations
+//   Input:
+//    n   -- total number of equations; e.g. number of grid nodes
+//    peq -- ids of equations with prescribed values; e.g. from utl.IntUnique(g.L, g.R)
+func (o *Equations) Init(n int, peq []int) {
+	o.N = n
+	o.FR1 = make([]int, n)
+	o.FR2 = make([]int, n)
+	for eq := 0; eq < n; eq++ {
+		o.FR1[eq] = 0
+		o.FR2[eq] = -1
+	}
+	for _, eq := range peq {
+		if eq < 0 || eq >= n {
+			chk.Panic("prescribed equation %d is out of range [0, %d)", eq, n)
+		}
+		o.FR1[eq] = -1
+	}
+	o.RF1 = make([]int, 0, n-len(peq))
+	o.RF2 = make([]int, 0, len(peq))
+	for eq := 0; eq < n; eq++ {
+		if o.FR1[eq] < 0 {
+			o.FR2[eq] = len(o.RF2)
+			o.RF2 = append(o.RF2, eq)
+		} else {
+			o.FR1[eq] = len(o.RF1)
+			o.RF1 = append(o.RF1, eq)
+		}
+	}
+	o.N1, o.N2 = len(o.RF1), len(o.RF2)
+}
+
+// InitK11andK12 allocates the K11 and K12 triplets corresponding to the unknown equations
+//  NOTE: the number of non-zeros is estimated for stencils with up to 7 points (3D) plus
+//        contributions from boundary conditions
+func InitK11andK12(K11, K12 *la.Triplet, e *Equations) {
+	nnzPerRow := 10
+	K11.Init(e.N1, e.N1, nnzPerRow*e.N1)
+	K12.Init(e.N1, e.N2, nnzPerRow*e.N1)
+}
+
+// JoinVecs assembles the full vector U from the unknown (U1) and prescribed (U2) parts
+func JoinVecs(U, U1, U2 []float64, e *Equations) {
+	for i, eq := range e.RF1 {
+		U[eq] = U1[i]
+	}
+	for i, eq := range e.RF2 {
+		U[eq] = U2[i]
+	}
+}
+
+// SplitVec extracts the unknown (U1) and prescribed (U2) parts from the full vector U
+func SplitVec(U1, U2, U []float64, e *Equations) {
+	for i, eq := range e.RF1 {
+		U1[i] = U[eq]
+	}
+	for i, eq := range e.RF2 {
+		U2[i] = U[eq]
+	}
+}
+
+// SetDirichlet sets the prescribed values U2 at the given nodes
+//   Input:
+//    nodes -- nodes with prescribed values (must have been given to Equations.Init)
+//    value -- function of the coordinates x of each node; len(x) = ndim
+func SetDirichlet(U2 []float64, e *Equations, g Grid, nodes []int, value func(x []float64) float64) {
+	x := make([]float64, g.Ndim())
+	for _, n := range nodes {
+		if e.FR2[n] < 0 {
+			chk.Panic("node %d is not prescribed", n)
+		}
+		g.Coords(x, n)
+		U2[e.FR2[n]] = value(x)
+	}
+}
+
+// ApplyDirichlet modifies the right-hand-side to account for the prescribed values:
+//   F1 -= K12 ⋅ U2
+func ApplyDirichlet(F1 []float64, K12 *la.Triplet, U2 []float64) {
+	if len(U2) == 0 {
+		return
+	}
+	tmp := la.NewVector(len(F1))
+	la.SpTriMatVecMul(tmp, K12, U2)
+	for i := 0; i < len(F1); i++ {
+		F1[i] -= tmp[i]
+	}
+}
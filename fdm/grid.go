@@ -0,0 +1,316 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package fdm implements the finite difference method on structured grids
+package fdm
+
+import "github.com/cpmech/gosl/chk"
+
+// sides of structured grids
+const (
+	Xmin = iota // side with x = xmin; e.g. left
+	Xmax        // side with x = xmax; e.g. right
+	Ymin        // side with y = ymin; e.g. bottom
+	Ymax        // side with y = ymax; e.g. top
+	Zmin        // side with z = zmin; e.g. back
+	Zmax        // side with z = zmax; e.g. front
+)
+
+// Grid defines structured grids in 1D, 2D or 3D
+//  The nodes are numbered with the x-index running fastest; i.e.
+//   n = i + j⋅nx + k⋅nx⋅ny
+type Grid interface {
+	Ndim() int                         // space dimension
+	Size() int                         // number of nodes
+	Coords(x []float64, n int)         // computes the coordinates of node n. len(x) = ndim
+	Boundary(side int) []int           // returns the nodes on side (Xmin, Xmax, Ymin, ...)
+	shape() (nn [3]int, dd [3]float64) // number of nodes and spacing along each direction
+}
+
+// Grid1d implements a uniform grid along x
+type Grid1d struct {
+	Xmin, Xmax float64 // limits
+	Lx         float64 // length
+	Nx         int     // number of nodes
+	N          int     // total number of nodes == Nx
+	Dx         float64 // spacing
+	Dxx        float64 // Dx²
+	L, R       []int   // nodes on the left and right boundaries
+}
+
+// Init initialises Grid1d
+func (o *Grid1d) Init(xmin, xmax float64, nx int) {
+	if nx < 2 {
+		chk.Panic("number of nodes must be at least 2. nx = %d is invalid", nx)
+	}
+	o.Xmin, o.Xmax, o.Nx = xmin, xmax, nx
+	o.Lx = xmax - xmin
+	o.N = nx
+	o.Dx = o.Lx / float64(nx-1)
+	o.Dxx = o.Dx * o.Dx
+	o.L = []int{0}
+	o.R = []int{nx - 1}
+}
+
+// X returns the x-coordinate of node i
+func (o *Grid1d) X(i int) float64 {
+	return o.Xmin + float64(i)*o.Dx
+}
+
+// Generate generates the x coordinates and, if fx != nil or U != nil, the values of the
+// function at the nodes
+//   Input:
+//    fx -- function f(x) [may be nil]
+//    U  -- values of the function at each node; used if fx == nil [may be nil]
+func (o *Grid1d) Generate(fx func(x float64) float64, U []float64) (X, F []float64) {
+	X = make([]float64, o.Nx)
+	if fx != nil || U != nil {
+		F = make([]float64, o.Nx)
+	}
+	for i := 0; i < o.Nx; i++ {
+		X[i] = o.X(i)
+		if fx != nil {
+			F[i] = fx(X[i])
+		} else if U != nil {
+			F[i] = U[i]
+		}
+	}
+	return
+}
+
+// Ndim returns the space dimension
+func (o *Grid1d) Ndim() int { return 1 }
+
+// Size returns the number of nodes
+func (o *Grid1d) Size() int { return o.N }
+
+// Coords computes the coordinates of node n
+func (o *Grid1d) Coords(x []float64, n int) {
+	x[0] = o.X(n)
+}
+
+// Boundary returns the nodes on side
+func (o *Grid1d) Boundary(side int) []int {
+	switch side {
+	case Xmin:
+		return o.L
+	case Xmax:
+		return o.R
+	}
+	chk.Panic("side %d is not available in 1D grid", side)
+	return nil
+}
+
+// shape returns the number of nodes and spacing along each direction
+func (o *Grid1d) shape() (nn [3]int, dd [3]float64) {
+	return [3]int{o.Nx, 1, 1}, [3]float64{o.Dx, 0, 0}
+}
+
+// Grid2d implements a uniform grid in the xy-plane
+type Grid2d struct {
+	Xmin, Xmax float64 // x-limits
+	Ymin, Ymax float64 // y-limits
+	Lx, Ly     float64 // lengths
+	Nx, Ny     int     // number of nodes along each direction
+	N          int     // total number of nodes == Nx⋅Ny
+	Dx, Dy     float64 // spacing
+	Dxx, Dyy   float64 // Dx² and Dy²
+	L, R, B, T []int   // nodes on the left, right, bottom and top boundaries
+}
+
+// Init initialises Grid2d
+func (o *Grid2d) Init(xmin, xmax, ymin, ymax float64, nx, ny int) {
+	if nx < 2 || ny < 2 {
+		chk.Panic("number of nodes along each direction must be at least 2. (nx,ny) = (%d,%d) is invalid", nx, ny)
+	}
+	o.Xmin, o.Xmax, o.Ymin, o.Ymax = xmin, xmax, ymin, ymax
+	o.Nx, o.Ny = nx, ny
+	o.Lx, o.Ly = xmax-xmin, ymax-ymin
+	o.N = nx * ny
+	o.Dx = o.Lx / float64(nx-1)
+	o.Dy = o.Ly / float64(ny-1)
+	o.Dxx, o.Dyy = o.Dx*o.Dx, o.Dy*o.Dy
+	o.L = make([]int, ny)
+	o.R = make([]int, ny)
+	o.B = make([]int, nx)
+	o.T = make([]int, nx)
+	for j := 0; j < ny; j++ {
+		o.L[j] = o.Node(0, j)
+		o.R[j] = o.Node(nx-1, j)
+	}
+	for i := 0; i < nx; i++ {
+		o.B[i] = o.Node(i, 0)
+		o.T[i] = o.Node(i, ny-1)
+	}
+}
+
+// Node returns the node number corresponding to indices i (along x) and j (along y)
+func (o *Grid2d) Node(i, j int) int {
+	return i + j*o.Nx
+}
+
+// IJ returns the indices (i,j) of node n
+func (o *Grid2d) IJ(n int) (i, j int) {
+	return n % o.Nx, n / o.Nx
+}
+
+// X returns the x-coordinate corresponding to index i
+func (o *Grid2d) X(i int) float64 {
+	return o.Xmin + float64(i)*o.Dx
+}
+
+// Y returns the y-coordinate corresponding to index j
+func (o *Grid2d) Y(j int) float64 {
+	return o.Ymin + float64(j)*o.Dy
+}
+
+// Generate generates the meshgrid coordinates and, if fxy != nil or U != nil, the values of the
+// function at the nodes. The output matrices have dimensions (ny,nx); e.g. for contour plots.
+//   Input:
+//    fxy -- function f(x,y) [may be nil]
+//    U   -- values of the function at each node; used if fxy == nil [may be nil]
+func (o *Grid2d) Generate(fxy func(x, y float64) float64, U []float64) (X, Y, F [][]float64) {
+	X = make([][]float64, o.Ny)
+	Y = make([][]float64, o.Ny)
+	hasF := fxy != nil || U != nil
+	if hasF {
+		F = make([][]float64, o.Ny)
+	}
+	for j := 0; j < o.Ny; j++ {
+		X[j] = make([]float64, o.Nx)
+		Y[j] = make([]float64, o.Nx)
+		if hasF {
+			F[j] = make([]float64, o.Nx)
+		}
+		for i := 0; i < o.Nx; i++ {
+			X[j][i] = o.X(i)
+			Y[j][i] = o.Y(j)
+			if fxy != nil {
+				F[j][i] = fxy(X[j][i], Y[j][i])
+			} else if U != nil {
+				F[j][i] = U[o.Node(i, j)]
+			}
+		}
+	}
+	return
+}
+
+// Ndim returns the space dimension
+func (o *Grid2d) Ndim() int { return 2 }
+
+// Size returns the number of nodes
+func (o *Grid2d) Size() int { return o.N }
+
+// Coords computes the coordinates of node n
+func (o *Grid2d) Coords(x []float64, n int) {
+	i, j := o.IJ(n)
+	x[0], x[1] = o.X(i), o.Y(j)
+}
+
+// Boundary returns the nodes on side
+func (o *Grid2d) Boundary(side int) []int {
+	switch side {
+	case Xmin:
+		return o.L
+	case Xmax:
+		return o.R
+	case Ymin:
+		return o.B
+	case Ymax:
+		return o.T
+	}
+	chk.Panic("side %d is not available in 2D grid", side)
+	return nil
+}
+
+// shape returns the number of nodes and spacing along each direction
+func (o *Grid2d) shape() (nn [3]int, dd [3]float64) {
+	return [3]int{o.Nx, o.Ny, 1}, [3]float64{o.Dx, o.Dy, 0}
+}
+
+// Grid3d implements a uniform grid in the xyz-space
+type Grid3d struct {
+	Xmin, Xmax    float64  // x-limits
+	Ymin, Ymax    float64  // y-limits
+	Zmin, Zmax    float64  // z-limits
+	Lx, Ly, Lz    float64  // lengths
+	Nx, Ny, Nz    int      // number of nodes along each direction
+	N             int      // total number of nodes == Nx⋅Ny⋅Nz
+	Dx, Dy, Dz    float64  // spacing
+	Dxx, Dyy, Dzz float64  // Dx², Dy² and Dz²
+	Sides         [6][]int // nodes on each side: Xmin, Xmax, Ymin, Ymax, Zmin, Zmax
+}
+
+// Init initialises Grid3d
+func (o *Grid3d) Init(xmin, xmax, ymin, ymax, zmin, zmax float64, nx, ny, nz int) {
+	if nx < 2 || ny < 2 || nz < 2 {
+		chk.Panic("number of nodes along each direction must be at least 2. (nx,ny,nz) = (%d,%d,%d) is invalid", nx, ny, nz)
+	}
+	o.Xmin, o.Xmax, o.Ymin, o.Ymax, o.Zmin, o.Zmax = xmin, xmax, ymin, ymax, zmin, zmax
+	o.Nx, o.Ny, o.Nz = nx, ny, nz
+	o.Lx, o.Ly, o.Lz = xmax-xmin, ymax-ymin, zmax-zmin
+	o.N = nx * ny * nz
+	o.Dx = o.Lx / float64(nx-1)
+	o.Dy = o.Ly / float64(ny-1)
+	o.Dz = o.Lz / float64(nz-1)
+	o.Dxx, o.Dyy, o.Dzz = o.Dx*o.Dx, o.Dy*o.Dy, o.Dz*o.Dz
+	for s := 0; s < 6; s++ {
+		o.Sides[s] = nil
+	}
+	for k := 0; k < nz; k++ {
+		for j := 0; j < ny; j++ {
+			o.Sides[Xmin] = append(o.Sides[Xmin], o.Node(0, j, k))
+			o.Sides[Xmax] = append(o.Sides[Xmax], o.Node(nx-1, j, k))
+		}
+		for i := 0; i < nx; i++ {
+			o.Sides[Ymin] = append(o.Sides[Ymin], o.Node(i, 0, k))
+			o.Sides[Ymax] = append(o.Sides[Ymax], o.Node(i, ny-1, k))
+		}
+	}
+	for j := 0; j < ny; j++ {
+		for i := 0; i < nx; i++ {
+			o.Sides[Zmin] = append(o.Sides[Zmin], o.Node(i, j, 0))
+			o.Sides[Zmax] = append(o.Sides[Zmax], o.Node(i, j, nz-1))
+		}
+	}
+}
+
+// Node returns the node number corresponding to indices i, j and k
+func (o *Grid3d) Node(i, j, k int) int {
+	return i + j*o.Nx + k*o.Nx*o.Ny
+}
+
+// IJK returns the indices (i,j,k) of node n
+func (o *Grid3d) IJK(n int) (i, j, k int) {
+	k = n / (o.Nx * o.Ny)
+	n -= k * o.Nx * o.Ny
+	return n % o.Nx, n / o.Nx, k
+}
+
+// Ndim returns the space dimension
+func (o *Grid3d) Ndim() int { return 3 }
+
+// Size returns the number of nodes
+func (o *Grid3d) Size() int { return o.N }
+
+// Coords computes the coordinates of node n
+func (o *Grid3d) Coords(x []float64, n int) {
+	i, j, k := o.IJK(n)
+	x[0] = o.Xmin + float64(i)*o.Dx
+	x[1] = o.Ymin + float64(j)*o.Dy
+	x[2] = o.Zmin + float64(k)*o.Dz
+}
+
+// Boundary returns the nodes on side
+func (o *Grid3d) Boundary(side int) []int {
+	if side < 0 || side > 5 {
+		chk.Panic("side %d is not available in 3D grid", side)
+	}
+	return o.Sides[side]
+}
+
+// shape returns the number of nodes and spacing along each direction
+func (o *Grid3d) shape() (nn [3]int, dd [3]float64) {
+	return [3]int{o.Nx, o.Ny, o.Nz}, [3]float64{o.Dx, o.Dy, o.Dz}
+}
@@ -0,0 +1,171 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fdm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+// solve solves K11⋅U1 = F1 - K12⋅U2 and returns the full vector U
+func solve(tst *testing.T, K11, K12 *la.Triplet, F1, U2 []float64, e *Equations) (U []float64) {
+	ApplyDirichlet(F1, K12, U2)
+	U1, err := la.SpSolve(K11, F1)
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	U = make([]float64, e.N)
+	JoinVecs(U, U1, U2, e)
+	return
+}
+
+func Test_assembly01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("assembly01. Poisson 2d (examples/fdm_problem01.go)")
+
+	// closed-form solution
+	π, π3 := math.Pi, math.Pow(math.Pi, 3.0)
+	solution := func(x, y float64) (res float64) {
+		res = (1.0 - x*x) / 2.0
+		for i := 1; i < 50; i += 2 {
+			k := float64(i)
+			a := k * π * (1.0 + x) / 2.0
+			b := k * π * (1.0 + y) / 2.0
+			c := k * π * (1.0 - y) / 2.0
+			d := k * k * k * math.Sinh(k*π)
+			res -= (16.0 / π3) * (math.Sin(a) / d) * (math.Sinh(b) + math.Sinh(c))
+		}
+		return
+	}
+
+	// problem
+	var g Grid2d
+	g.Init(-1.0, 1.0, -1.0, 1.0, 21, 21)
+	var e Equations
+	e.Init(g.N, utl.IntUnique(g.B, g.R, g.T, g.L))
+	var K11, K12 la.Triplet
+	InitK11andK12(&K11, &K12, &e)
+	F1 := make([]float64, e.N1)
+	source := func(x, y float64, args ...interface{}) float64 { return 1.0 }
+	AssemblePoisson2d(&K11, &K12, F1, 1, 1, source, &g, &e)
+
+	// solve
+	U := solve(tst, &K11, &K12, F1, make([]float64, e.N2), &e)
+	for j := 0; j < g.Ny; j += 5 {
+		for i := 0; i < g.Nx; i += 5 {
+			n := g.Node(i, j)
+			chk.Float64(tst, io.Sf("u(%g,%g)", g.X(i), g.Y(j)), 2e-3, U[n], solution(g.X(i), g.Y(j)))
+		}
+	}
+}
+
+func Test_assembly02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("assembly02. Poisson 3d with quadratic solution")
+
+	// u = x² + y² + z²  =>  -∇²u = -6
+	var g Grid3d
+	g.Init(0, 1, -1, 1, 0, 2, 5, 6, 7)
+	var peq []int
+	for s := Xmin; s <= Zmax; s++ {
+		peq = utl.IntUnique(peq, g.Boundary(s))
+	}
+	var e Equations
+	e.Init(g.N, peq)
+	var K11, K12 la.Triplet
+	InitK11andK12(&K11, &K12, &e)
+	F1 := make([]float64, e.N1)
+	AssemblePoisson3d(&K11, &K12, F1, 1, 1, 1, func(x, y, z float64, args ...interface{}) float64 { return -6 }, &g, &e)
+	U2 := make([]float64, e.N2)
+	sol := func(x []float64) float64 { return x[0]*x[0] + x[1]*x[1] + x[2]*x[2] }
+	SetDirichlet(U2, &e, &g, e.RF2, sol)
+	U := solve(tst, &K11, &K12, F1, U2, &e)
+	x := make([]float64, 3)
+	for n := 0; n < g.N; n++ {
+		g.Coords(x, n)
+		chk.Float64(tst, io.Sf("u%d", n), 1e-13, U[n], sol(x))
+	}
+}
+
+func Test_assembly03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("assembly03. Neumann and Robin conditions in 1d")
+
+	var g Grid1d
+	g.Init(0, 1, 11)
+	var e Equations
+	e.Init(g.N, g.L)
+	var K11, K12 la.Triplet
+	InitK11andK12(&K11, &K12, &e)
+	F1 := make([]float64, e.N1)
+	U2 := []float64{0}
+	op := Operator{K: [3]float64{1, 0, 0}}
+
+	// -u'' = 2, u(0) = 0, u'(1) = 0  =>  u = 2x - x²  (zero flux is the default)
+	AssembleOperator(&K11, &K12, F1, &op, func(x []float64) float64 { return 2 }, &g, &e)
+	U := solve(tst, &K11, &K12, F1, U2, &e)
+	for i := 0; i < g.N; i++ {
+		x := g.X(i)
+		chk.Float64(tst, io.Sf("zero flux: u(%g)", x), 1e-14, U[i], 2*x-x*x)
+	}
+
+	// -u'' = 0, u(0) = 0, u'(1) = 3  =>  u = 3x
+	AssembleOperator(&K11, &K12, F1, &op, nil, &g, &e)
+	AssembleNeumann(F1, &op, func(x []float64) float64 { return 3 }, Xmax, &g, &e)
+	U = solve(tst, &K11, &K12, F1, U2, &e)
+	for i := 0; i < g.N; i++ {
+		chk.Float64(tst, io.Sf("Neumann: u(%g)", g.X(i)), 1e-14, U[i], 3*g.X(i))
+	}
+
+	// -u'' = 0, u(0) = 0, u'(1) + 2⋅u(1) = 6  =>  u = 2x
+	AssembleOperator(&K11, &K12, F1, &op, nil, &g, &e)
+	AssembleRobin(&K11, F1, &op, 2, func(x []float64) float64 { return 6 }, Xmax, &g, &e)
+	U = solve(tst, &K11, &K12, F1, U2, &e)
+	for i := 0; i < g.N; i++ {
+		chk.Float64(tst, io.Sf("Robin: u(%g)", g.X(i)), 1e-14, U[i], 2*g.X(i))
+	}
+}
+
+func Test_assembly04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("assembly04. convection-diffusion in 1d")
+
+	// -k u'' + v u' = 0, u(0) = 0, u(1) = 1
+	k, v := 1.0, 5.0
+	sol := func(x float64) float64 { return (math.Exp(v*x/k) - 1.0) / (math.Exp(v/k) - 1.0) }
+
+	var g Grid1d
+	g.Init(0, 1, 41)
+	var e Equations
+	e.Init(g.N, utl.IntUnique(g.L, g.R))
+	var K11, K12 la.Triplet
+	InitK11andK12(&K11, &K12, &e)
+	F1 := make([]float64, e.N1)
+	U2 := []float64{0, 1}
+
+	for _, upwind := range []bool{false, true} {
+		op := Operator{K: [3]float64{k, 0, 0}, V: [3]float64{v, 0, 0}, Upwind: upwind}
+		AssembleOperator(&K11, &K12, F1, &op, nil, &g, &e)
+		U := solve(tst, &K11, &K12, F1, U2, &e)
+		tol := 2e-3
+		if upwind {
+			tol = 3e-2
+		}
+		for i := 0; i < g.N; i += 4 {
+			x := g.X(i)
+			chk.Float64(tst, io.Sf("upwind=%v: u(%g)", upwind, x), tol, U[i], sol(x))
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fdm
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/utl"
+)
+
+func Test_grid01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("grid01. Grid2d and Equations")
+
+	var g Grid2d
+	g.Init(2.0, 14.0, 2.0, 8.0, 4, 3)
+	chk.IntAssert(g.N, 12)
+	chk.Float64(tst, "Dx", 1e-15, g.Dx, 4)
+	chk.Float64(tst, "Dy", 1e-15, g.Dy, 3)
+	chk.Ints(tst, "L", g.L, []int{0, 4, 8})
+	chk.Ints(tst, "R", g.R, []int{3, 7, 11})
+	chk.Ints(tst, "B", g.B, []int{0, 1, 2, 3})
+	chk.Ints(tst, "T", g.T, []int{8, 9, 10, 11})
+
+	X, Y, F := g.Generate(func(x, y float64) float64 { return x + y }, nil)
+	io.Pforan("X = %v\n", X)
+	io.Pforan("Y = %v\n", Y)
+	chk.Deep2(tst, "X", 1e-15, X, [][]float64{{2, 6, 10, 14}, {2, 6, 10, 14}, {2, 6, 10, 14}})
+	chk.Deep2(tst, "Y", 1e-15, Y, [][]float64{{2, 2, 2, 2}, {5, 5, 5, 5}, {8, 8, 8, 8}})
+	chk.Deep2(tst, "F", 1e-15, F, [][]float64{{4, 8, 12, 16}, {7, 11, 15, 19}, {10, 14, 18, 22}})
+
+	x := make([]float64, 2)
+	g.Coords(x, 6)
+	chk.Array(tst, "x(6)", 1e-15, x, []float64{10, 5})
+
+	var e Equations
+	e.Init(g.N, utl.IntUnique(g.L, g.R))
+	chk.IntAssert(e.N1, 6)
+	chk.IntAssert(e.N2, 6)
+	chk.Ints(tst, "RF1", e.RF1, []int{1, 2, 5, 6, 9, 10})
+	chk.Ints(tst, "RF2", e.RF2, []int{0, 3, 4, 7, 8, 11})
+	chk.Ints(tst, "FR1", e.FR1, []int{-1, 0, 1, -1, -1, 2, 3, -1, -1, 4, 5, -1})
+	chk.Ints(tst, "FR2", e.FR2, []int{0, -1, -1, 1, 2, -1, -1, 3, 4, -1, -1, 5})
+
+	U := make([]float64, g.N)
+	U1 := []float64{1, 2, 5, 6, 9, 10}
+	U2 := []float64{0, 3, 4, 7, 8, 11}
+	JoinVecs(U, U1, U2, &e)
+	chk.Array(tst, "U", 1e-17, U, utl.LinSpace(0, 11, 12))
+	V1, V2 := make([]float64, 6), make([]float64, 6)
+	SplitVec(V1, V2, U, &e)
+	chk.Array(tst, "V1", 1e-17, V1, U1)
+	chk.Array(tst, "V2", 1e-17, V2, U2)
+}
+
+func Test_grid02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("grid02. Grid1d and Grid3d")
+
+	var g1 Grid1d
+	g1.Init(-1, 1, 5)
+	X, F := g1.Generate(nil, []float64{1, 2, 3, 4, 5})
+	chk.Array(tst, "X", 1e-15, X, []float64{-1, -0.5, 0, 0.5, 1})
+	chk.Array(tst, "F", 1e-15, F, []float64{1, 2, 3, 4, 5})
+	chk.Ints(tst, "Xmin", g1.Boundary(Xmin), []int{0})
+	chk.Ints(tst, "Xmax", g1.Boundary(Xmax), []int{4})
+
+	var g3 Grid3d
+	g3.Init(0, 1, 0, 2, 0, 3, 2, 3, 4)
+	chk.IntAssert(g3.N, 24)
+	chk.IntAssert(len(g3.Boundary(Xmin)), 12)
+	chk.IntAssert(len(g3.Boundary(Ymax)), 8)
+	chk.IntAssert(len(g3.Boundary(Zmax)), 6)
+	i, j, k := g3.IJK(g3.Node(1, 2, 3))
+	chk.Ints(tst, "ijk", []int{i, j, k}, []int{1, 2, 3})
+	x := make([]float64, 3)
+	g3.Coords(x, g3.Node(1, 1, 2))
+	chk.Array(tst, "x", 1e-15, x, []float64{1, 1, 2})
+	chk.Ints(tst, "Zmin", g3.Boundary(Zmin), []int{0, 1, 2, 3, 4, 5})
+}
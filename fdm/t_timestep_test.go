@@ -0,0 +1,69 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fdm
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+func Test_timestep01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("timestep01. heat equation in 1d")
+
+	// ∂u/∂t = ∂²u/∂x², u(0,t) = u(1,t) = 0, u(x,0) = sin(πx)  =>  u = exp(-π²t)⋅sin(πx)
+	var g Grid1d
+	g.Init(0, 1, 21)
+	var e Equations
+	e.Init(g.N, utl.IntUnique(g.L, g.R))
+	var K11, K12 la.Triplet
+	InitK11andK12(&K11, &K12, &e)
+	F1 := make([]float64, e.N1)
+	op := Operator{K: [3]float64{1, 0, 0}}
+	AssembleOperator(&K11, &K12, F1, &op, nil, &g, &e)
+
+	dtFE := StableDtForwardEuler(&op, &g)
+	chk.Float64(tst, "Δt(FE)", 1e-15, dtFE, 0.5*g.Dxx)
+
+	tf := 0.1
+	for _, θ := range []float64{0, 0.5, 1} {
+		dt, tol := 1e-3, 2e-3
+		if θ == 0 {
+			dt = 0.8 * dtFE
+		}
+		if θ == 1 {
+			tol = 3e-3 // first order in time
+		}
+		nsteps := int(math.Ceil(tf / dt))
+		var stp ThetaMethod
+		err := stp.Init(&K11, &e, θ, tf/float64(nsteps))
+		if err != nil {
+			tst.Errorf("%v\n", err)
+			return
+		}
+		U1 := make([]float64, e.N1)
+		for I, n := range e.RF1 {
+			U1[I] = math.Sin(math.Pi * g.X(n))
+		}
+		for k := 0; k < nsteps; k++ {
+			err = stp.Step(U1, F1)
+			if err != nil {
+				tst.Errorf("%v\n", err)
+				return
+			}
+		}
+		stp.Free()
+		for I, n := range e.RF1 {
+			x := g.X(n)
+			chk.Float64(tst, io.Sf("θ=%g: u(%g)", θ, x), tol, U1[I], math.Exp(-math.Pi*math.Pi*tf)*math.Sin(math.Pi*x))
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fdm
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+)
+
+// ThetaMethod implements the θ-method to solve the semi-discrete system
+//
+//   dU1/dt + K11 ⋅ U1 = F1
+//
+//  where F1 accounts for the prescribed values (F1 -= K12 ⋅ U2); see ApplyDirichlet.
+//  Each time step solves:
+//
+//   (I + θ⋅Δt⋅K11) ⋅ U1ⁿ⁺¹ = (I - (1-θ)⋅Δt⋅K11) ⋅ U1ⁿ + Δt⋅F1
+//
+//  θ = 0: forward Euler (explicit); θ = ½: Crank-Nicolson; θ = 1: backward Euler
+type ThetaMethod struct {
+	Theta float64 // θ coefficient
+	Dt    float64 // time step size
+
+	// internal
+	k11    *la.Triplet     // the K11 matrix
+	a      la.Triplet      // I + θ⋅Δt⋅K11
+	solver la.SparseSolver // linear solver
+	rhs    la.Vector       // right-hand-side
+	tmp    la.Vector       // K11 ⋅ U1ⁿ
+}
+
+// Init initialises the θ-method and factorises the coefficient matrix
+//  NOTE: K11 must not be modified afterwards unless Init is called again
+func (o *ThetaMethod) Init(K11 *la.Triplet, e *Equations, theta, dt float64) (err error) {
+	if theta < 0 || theta > 1 {
+		return chk.Err("θ must be in [0, 1]. θ = %g is invalid", theta)
+	}
+	if dt <= 0 {
+		return chk.Err("Δt must be positive. Δt = %g is invalid", dt)
+	}
+	o.Free()
+	o.Theta, o.Dt, o.k11 = theta, dt, K11
+	n := e.N1
+	var eye la.Triplet
+	la.SpTriSetDiag(&eye, n, 1)
+	o.a.Init(n, n, n+K11.Len())
+	la.SpTriAdd(&o.a, 1, &eye, theta*dt, K11)
+	o.rhs = la.NewVector(n)
+	o.tmp = la.NewVector(n)
+	o.solver = la.NewSparseSolver("umfpack")
+	err = o.solver.Init(&o.a, false, false, "", "", nil)
+	if err != nil {
+		return
+	}
+	return o.solver.Fact()
+}
+
+// Free frees memory allocated by the linear solver
+func (o *ThetaMethod) Free() {
+	if o.solver != nil {
+		o.solver.Free()
+		o.solver = nil
+	}
+}
+
+// Step advances the solution U1 by one time step (U1 is overwritten)
+func (o *ThetaMethod) Step(U1, F1 []float64) (err error) {
+	if o.solver == nil {
+		return chk.Err("Init must be called first")
+	}
+	la.SpTriMatVecMul(o.tmp, o.k11, U1)
+	c := (1.0 - o.Theta) * o.Dt
+	for i := 0; i < len(U1); i++ {
+		o.rhs[i] = U1[i] - c*o.tmp[i] + o.Dt*F1[i]
+	}
+	return o.solver.Solve(U1, o.rhs, false)
+}
+
+// StableDtForwardEuler returns the largest time step for which the forward Euler (θ = 0) scheme
+// is stable when applied to the diffusion-reaction operator (von Neumann analysis)
+//
+//   Δt ≤ 1 / (Σᵢ 2⋅kᵢ/hᵢ² + r/2)
+//
+//  NOTE: convective terms are disregarded
+func StableDtForwardEuler(op *Operator, g Grid) float64 {
+	_, dd := g.shape()
+	den := 0.5 * math.Max(op.R, 0)
+	for d := 0; d < g.Ndim(); d++ {
+		den += 2.0 * op.K[d] / (dd[d] * dd[d])
+	}
+	if den == 0 {
+		return math.Inf(1)
+	}
+	return 1.0 / den
+}
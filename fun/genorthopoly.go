@@ -27,6 +27,9 @@ var (
 
 	// OpolyCheby2Kind specifies the Chebyshev second kind orthogonal polynomial
 	OpolyCheby2Kind = io.NewEnum("Chebyshev2", "fun.opoly", "U", "Chebyshev Second Kind orthogonal polynomial")
+
+	// OpolyLaguerreKind specifies the (generalized) Laguerre orthogonal polynomial
+	OpolyLaguerreKind = io.NewEnum("Laguerre", "fun.opoly", "G", "Generalized Laguerre orthogonal polynomial")
 )
 
 // GeneralOrthoPoly (main) structure ////////////////////////////////////////////////////////////////
@@ -56,7 +59,7 @@ type GeneralOrthoPoly struct {
 //   N     -- is the (max) degree of the polynomial.
 //            Lower order can later be quickly obtained after this
 //            polynomial with max(N) is created
-//   alpha -- Jacobi only: α coefficient
+//   alpha -- Jacobi and Laguerre only: α coefficient
 //   beta  -- Jacobi only: β coefficient
 //
 //   NOTE: all coefficients for the 0...N polynomials will be generated
@@ -277,6 +280,36 @@ func newChebyshev2(alpha, beta float64) oPoly {
 	return new(opChebyshev2)
 }
 
+// Laguerre ////////////////////////////////////////////////////////////////////////////////////////
+
+type opLaguerre struct {
+	alpha float64
+}
+
+func (o *opLaguerre) M(n int) int {
+	return n
+}
+
+func (o *opLaguerre) d(n int) float64 {
+	return 1.0
+}
+
+func (o *opLaguerre) c(n, m int) float64 {
+	r := Rbinomial(float64(n)+o.alpha, float64(n-m))
+	s := Factorial22(m)
+	return math.Pow(-1, float64(m)) * r / s
+}
+
+func (o *opLaguerre) g(n, m int, x float64) float64 {
+	return math.Pow(x, float64(m))
+}
+
+func newLaguerre(alpha, beta float64) oPoly {
+	o := new(opLaguerre)
+	o.alpha = alpha
+	return o
+}
+
 // add polynomials to database /////////////////////////////////////////////////////////////////////
 
 func init() {
@@ -285,4 +318,5 @@ func init() {
 	oPolyDB[OpolyHermiteKind] = newHermite
 	oPolyDB[OpolyCheby1Kind] = newChebyshev1
 	oPolyDB[OpolyCheby2Kind] = newChebyshev2
+	oPolyDB[OpolyLaguerreKind] = newLaguerre
 }
@@ -280,3 +280,24 @@ func TestGenOrthoPoly06(tst *testing.T) {
 		plt.Save("/tmp/gosl/fun", "genorthopoly06")
 	}
 }
+
+func TestGenOrthoPoly07(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("GenOrthoPoly07 generalized Laguerre polynomials")
+
+	N, α := 3, 0.5
+	op := NewGeneralOrthoPoly(OpolyLaguerreKind, N, α, 0)
+
+	xx := utl.LinSpace(0, 5, 6)
+	for _, x := range xx {
+		y := op.P(0, x)
+		chk.Float64(tst, "L0", 1e-15, y, 1)
+		y = op.P(1, x)
+		chk.Float64(tst, "L1", 1e-15, y, -x+α+1)
+		y = op.P(2, x)
+		chk.Float64(tst, "L2", 1e-14, y, x*x/2-(α+2)*x+(α+2)*(α+1)/2)
+		y = op.P(3, x)
+		chk.Float64(tst, "L3", 1e-13, y, -x*x*x/6+(α+3)*x*x/2-(α+2)*(α+3)*x/2+(α+1)*(α+2)*(α+3)/6)
+	}
+}
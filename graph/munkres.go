@@ -5,6 +5,7 @@
 package graph
 
 import (
+	"context"
 	"math"
 
 	"github.com/cpmech/gosl/chk"
@@ -59,6 +60,9 @@ type Munkres struct {
 	ncol       int          // number of column in cost/mask matrix
 	pathRow0   int          // first row in path
 	pathCol0   int          // first col in path
+
+	// cancellation and progress report
+	Monitor utl.Monitor // it = step counter, residual = number of rows not yet assigned
 }
 
 // Init initialises Munkres' structure
@@ -110,7 +114,23 @@ func (o *Munkres) SetCostMatrix(C [][]float64) {
 //              j := o.Links[i] means that i is assigned to j
 //              -1 means no assignment/link
 //   o.Cost -- will have the total cost by following links
+//  Note: Run panics if the Monitor stops the iterations. Use RunCtx to handle cancellation.
 func (o *Munkres) Run() {
+	err := o.run()
+	if err != nil {
+		chk.Panic("%v", err)
+	}
+}
+
+// RunCtx runs the iterative algorithm and stops with an error if ctx is cancelled. See Run.
+func (o *Munkres) RunCtx(ctx context.Context) (err error) {
+	o.Monitor.Ctx = ctx
+	defer func() { o.Monitor.Ctx = nil }()
+	return o.run()
+}
+
+// run runs the iterative algorithm
+func (o *Munkres) run() (err error) {
 
 	// column matrix
 	if o.ncol == 1 {
@@ -145,7 +165,13 @@ func (o *Munkres) Run() {
 	// run Munkres algorithm
 	step := 1
 	done := false
-	for !done {
+	for it := 0; !done; it++ {
+		if step == 3 {
+			err = o.Monitor.Check("Munkres", it, float64(o.nrow-o.nStarredCols()), 0)
+			if err != nil {
+				return
+			}
+		}
 		switch step {
 		case 1:
 			step = o.step1() // returns 2
@@ -176,6 +202,7 @@ func (o *Munkres) Run() {
 			}
 		}
 	}
+	return
 }
 
 // steps //////////////////////////////////////////////////////////////////////////////////////////
@@ -361,6 +388,19 @@ func (o *Munkres) step6() (nextStep int) {
 
 // auxiliary ///////////////////////////////////////////////////////////////////////////////////////
 
+// nStarredCols returns the number of columns containing a starred zero
+func (o *Munkres) nStarredCols() (count int) {
+	for j := 0; j < o.ncol; j++ {
+		for i := 0; i < o.nrow; i++ {
+			if o.M[i][j] == StarType {
+				count++
+				break
+			}
+		}
+	}
+	return
+}
+
 // StrCostMatrix returns a representation of cost matrix with masks and covers
 func (o *Munkres) StrCostMatrix() (l string) {
 	numfmt := "%v"
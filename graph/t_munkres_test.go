@@ -5,10 +5,12 @@
 package graph
 
 import (
+	"context"
 	"testing"
 
 	"github.com/cpmech/gosl/chk"
 	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/utl"
 )
 
 func Test_munkres01(tst *testing.T) {
@@ -478,3 +480,48 @@ func Test_munkres05(tst *testing.T) {
 	chk.Ints(tst, "D: links", mnkD.Links, []int{0, -1, 1})
 	chk.Float64(tst, "D: cost", 1e-17, mnkD.Cost, 35928)
 }
+
+func Test_munkres06(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("munkres06. progress and cancellation")
+
+	C := [][]float64{
+		{1, 2, 3},
+		{2, 4, 6},
+		{3, 6, 9},
+	}
+
+	// progress
+	var mnk Munkres
+	mnk.Init(len(C), len(C[0]))
+	mnk.SetCostMatrix(C)
+	ncalls := 0
+	mnk.Monitor.Callback = func(p utl.Progress) {
+		io.Pforan("it = %d  unassigned = %g\n", p.It, p.Residual)
+		ncalls++
+	}
+	err := mnk.RunCtx(context.Background())
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	chk.Ints(tst, "links", mnk.Links, []int{2, 1, 0})
+	chk.Float64(tst, "cost", 1e-17, mnk.Cost, 10)
+	if ncalls < 1 {
+		tst.Errorf("callback should have been called at least once\n")
+		return
+	}
+
+	// cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	mnk.Init(len(C), len(C[0]))
+	mnk.SetCostMatrix(C)
+	err = mnk.RunCtx(ctx)
+	if !utl.IsCancelled(err) {
+		tst.Errorf("RunCtx should have been cancelled. err = %v\n", err)
+		return
+	}
+	io.Pforan("err = %v\n", err)
+}
@@ -130,6 +130,13 @@ func (o *CCMatrix) Set(m, n int, Ap, Ai []int, Ax []float64) {
 	o.p, o.i, o.x = Ap, Ai, Ax
 }
 
+// Get returns the dimensions and the internal arrays of a column-compressed matrix
+//  NOTE: the slices are not copied; i.e. they must not be modified
+func (o *CCMatrix) Get() (m, n int, Ap, Ai []int, Ax []float64) {
+	nnz := o.p[o.n] // duplicates may have been summed up
+	return o.m, o.n, o.p[:o.n+1], o.i[:nnz], o.x[:nnz]
+}
+
 // complex /////////////////////////////////////////////////////////////////////////////////////////
 
 // TripletC is a simple representation of a sparse matrix, where the indices and values
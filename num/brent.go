@@ -5,22 +5,28 @@
 package num
 
 import (
+	"context"
 	"math"
 
 	"github.com/cpmech/gosl/chk"
 	"github.com/cpmech/gosl/fun"
 	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/utl"
 )
 
 // Brent implements Brent's method for finding the roots of an equation
 type Brent struct {
-	MaxIt  int     // max iterations
-	Tol    float64 // tolerance
-	Ffcn   fun.Ss  // y = f(x) function
-	NFeval int     // number of calls to Ffcn (function evaluations)
-	It     int     // number of iterations from last call to Solve
-	sqeps  float64 // sqrt(EPS)
-	gsr    float64 // gold section ratio
+	MaxIt  int         // max iterations
+	Tol    float64     // tolerance
+	Ffcn   fun.Ss      // y = f(x) function
+	NFeval int         // number of calls to Ffcn (function evaluations)
+	It     int         // number of iterations from last call to Solve
+	Hist   RootHistory // history of iterations from last call to Solve
+	sqeps  float64     // sqrt(EPS)
+	gsr    float64     // gold section ratio
+
+	// cancellation and progress report
+	Monitor utl.Monitor // residual = |f(x)|, step = |Δx|
 }
 
 // Init intialises Brent structure
@@ -32,7 +38,12 @@ func (o *Brent) Init(ffcn fun.Ss) {
 	o.sqeps = math.Sqrt(MACHEPS)
 }
 
-// Solve solves y(x) = 0 for x in [xa, xb] with f(xa) * f(xb) < 0
+// History returns the history of iterations from the last call to Solve
+func (o *Brent) History() *RootHistory {
+	return &o.Hist
+}
+
+// Solve solves y(x) = 0 for x in [xa, xb] with f(xa) * f(xb) ≤ 0
 //
 //  Based on ZEROIN C math library: http://www.netlib.org/c/
 //  By: Oleg Keselyov <oleg@ponder.csci.unt.edu, oleg@unt.edu> May 23, 1991
@@ -75,10 +86,11 @@ func (o *Brent) Solve(xa, xb float64, silent bool) (res float64, err error) {
 		return 0, chk.Err("fb(%g) failed:\n%v", xb, errb.Error())
 	}
 	fc := fa
+	o.Hist.Reset()
 
 	// check input
-	if fa*fb >= -MACHEPS {
-		return 0, chk.Err("root must be bracketed: xa=%g, xb=%g, fa=%g, fb=%b => fa * fb >= 0", xa, xb, fa, fb)
+	if fa*fb > 0 {
+		return 0, chk.Err("root must be bracketed: xa=%g, xb=%g, fa=%g, fb=%g => fa * fb > 0", xa, xb, fa, fb)
 	}
 
 	// message
@@ -111,6 +123,7 @@ func (o *Brent) Solve(xa, xb float64, silent bool) (res float64, err error) {
 		newStep = (c - b) / 2.0
 
 		// converged?
+		o.Hist.Append(b, fb, math.Abs(newStep))
 		if !silent {
 			io.Pf("%4d%23.15e%23.15e%23.15e\n", o.It, b, fb, math.Abs(newStep))
 		}
@@ -118,6 +131,12 @@ func (o *Brent) Solve(xa, xb float64, silent bool) (res float64, err error) {
 			return b, nil
 		}
 
+		// cancellation and progress
+		err = o.Monitor.Check("Brent", o.It, math.Abs(fb), math.Abs(newStep))
+		if err != nil {
+			return b, err
+		}
+
 		// decide if the interpolation can be tried
 		if math.Abs(prevStep) >= tolAct && math.Abs(fa) > math.Abs(fb) {
 			// if prev_step was large enough and was in true direction, interpolatiom may be tried
@@ -182,7 +201,15 @@ func (o *Brent) Solve(xa, xb float64, silent bool) (res float64, err error) {
 	}
 
 	// did not converge
-	return fb, chk.Err("fail to converge after %d iterations", o.It)
+	return fb, utl.NewIterError(utl.StopMaxIt, "Brent", o.It, "fail to converge after %d iterations", o.It)
+}
+
+// SolveCtx solves y(x) = 0 for x in [xa, xb] with f(xa) * f(xb) ≤ 0 and stops with an error if
+// ctx is cancelled. See Solve.
+func (o *Brent) SolveCtx(ctx context.Context, xa, xb float64, silent bool) (res float64, err error) {
+	o.Monitor.Ctx = ctx
+	defer func() { o.Monitor.Ctx = nil }()
+	return o.Solve(xa, xb, silent)
 }
 
 // Min finds the minimum of f(x) in [xa, xb]
@@ -255,6 +282,12 @@ func (o *Brent) Min(xa, xb float64, silent bool) (res float64, err error) {
 			return x, nil
 		}
 
+		// cancellation and progress
+		err = o.Monitor.Check("Brent.Min", o.It, fx, math.Abs(x-midRng)+rng/2.0)
+		if err != nil {
+			return x, err
+		}
+
 		// Obtain the gold section step
 		tmp = xa - x
 		if x < midRng {
@@ -334,5 +367,11 @@ func (o *Brent) Min(xa, xb float64, silent bool) (res float64, err error) {
 	}
 
 	// did not converge
-	return x, chk.Err("fail to converge after %d iterations", o.It)
+	return x, utl.NewIterError(utl.StopMaxIt, "Brent.Min", o.It, "fail to converge after %d iterations", o.It)
+}
+
+// add solver to database //////////////////////////////////////////////////////////////////////////
+
+func init() {
+	rootSolverDB["brent"] = func() RootSolver { return new(Brent) }
 }
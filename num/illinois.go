@@ -0,0 +1,138 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package num
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+// Illinois implements the Illinois and Anderson-Björck variants of the false position (regula
+// falsi) method for finding the roots of an equation
+//
+//  The plain false position method may converge very slowly because one end of the bracket
+//  stays fixed. The Illinois variant halves the function value at the retained end whenever the
+//  same end is retained twice in a row. The Anderson-Björck variant uses the factor
+//  m = 1 - f(c)/f(b) instead of 1/2 (or 1/2 if m ≤ 0). See [1,2].
+//
+//  References:
+//   [1] Dowell M, Jarratt P (1971) A modified regula falsi method for computing the root of an
+//       equation. BIT Numerical Mathematics, 11(2):168-174
+//   [2] Anderson N, Björck Å (1973) A new high order method of regula falsi type for computing
+//       a root of an equation. BIT Numerical Mathematics, 13(3):253-264
+type Illinois struct {
+	MaxIt    int         // max iterations
+	Tol      float64     // tolerance
+	Anderson bool        // use Anderson-Björck scaling factor instead of 1/2
+	Ffcn     fun.Ss      // y = f(x) function
+	NFeval   int         // number of calls to Ffcn (function evaluations)
+	It       int         // number of iterations from last call to Solve
+	Hist     RootHistory // history of iterations from last call to Solve
+}
+
+// Init initialises Illinois structure
+func (o *Illinois) Init(ffcn fun.Ss) {
+	o.MaxIt = 100
+	o.Tol = 1e-14
+	o.Ffcn = ffcn
+}
+
+// History returns the history of iterations from the last call to Solve
+func (o *Illinois) History() *RootHistory {
+	return &o.Hist
+}
+
+// Solve solves y(x) = 0 for x in [xa, xb] with f(xa) * f(xb) < 0
+func (o *Illinois) Solve(xa, xb float64, silent bool) (res float64, err error) {
+
+	// check input
+	o.Hist.Reset()
+	fa, fb, err := rootCheckBracket(o.Ffcn, xa, xb)
+	o.NFeval = 2
+	if err != nil {
+		return
+	}
+	if fa == 0 {
+		return xa, nil
+	}
+	if fb == 0 {
+		return xb, nil
+	}
+
+	// message
+	if !silent {
+		rootMsg(0, 0, 0, 0, o.Tol, true)
+	}
+
+	// solve
+	a, b := xa, xb
+	c, cprev := b, a
+	side := 0 // which end was retained last time: -1 => a, +1 => b
+	var fc, m, tolAct float64
+	for o.It = 0; o.It < o.MaxIt; o.It++ {
+
+		// secant step
+		cprev = c
+		c = (fa*b - fb*a) / (fa - fb)
+		fc, err = o.Ffcn(c)
+		o.NFeval++
+		if err != nil {
+			return 0, chk.Err("f(%g) failed:\n%v", c, err)
+		}
+
+		// history and message
+		o.Hist.Append(c, fc, math.Abs(c-cprev))
+		if !silent {
+			rootMsg(o.It, c, fc, math.Abs(c-cprev), o.Tol, false)
+		}
+
+		// converged?
+		tolAct = 2.0*MACHEPS*math.Abs(c) + o.Tol/2.0
+		if fc == 0.0 || math.Abs(c-cprev) <= tolAct || math.Abs(b-a) <= tolAct {
+			return c, nil
+		}
+
+		// replace the end with the same sign as f(c)
+		if fc*fb > 0 {
+			m = 0.5
+			if o.Anderson {
+				m = 1.0 - fc/fb
+				if m <= 0 {
+					m = 0.5
+				}
+			}
+			b, fb = c, fc
+			if side == -1 {
+				fa *= m
+			}
+			side = -1
+		} else {
+			m = 0.5
+			if o.Anderson {
+				m = 1.0 - fc/fa
+				if m <= 0 {
+					m = 0.5
+				}
+			}
+			a, fa = c, fc
+			if side == +1 {
+				fb *= m
+			}
+			side = +1
+		}
+	}
+
+	// did not converge
+	return c, chk.Err("fail to converge after %d iterations", o.It)
+}
+
+// add solver to database //////////////////////////////////////////////////////////////////////////
+
+func init() {
+	rootSolverDB["illinois"] = func() RootSolver { return new(Illinois) }
+	rootSolverDB["anderson"] = func() RootSolver { return &Illinois{Anderson: true} }
+}
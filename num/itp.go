@@ -0,0 +1,148 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package num
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+// ITP implements the Interpolate-Truncate-Project method for finding the roots of an equation
+//
+//  The ITP method attains the superlinear convergence of the secant method while retaining the
+//  optimal worst case performance of the bisection method; i.e. it never requires more than
+//  n½ + n0 iterations, where n½ = ⌈log2((b-a)/(2ε))⌉ is the number of iterations of bisection.
+//  See [1].
+//
+//  Reference:
+//   [1] Oliveira IFD, Takahashi RHC (2020) An enhancement of the bisection method average
+//       performance preserving minmax optimality. ACM Transactions on Mathematical Software,
+//       47(1):5:1-5:24
+type ITP struct {
+	K1     float64     // truncation parameter κ1 = K1 / (b - a); default = 0.2
+	K2     float64     // truncation parameter κ2 ϵ [1, 1+φ); default = 2
+	N0     int         // slack of the projection step n0 ≥ 0; default = 1
+	Tol    float64     // tolerance ε on the size of the bracket (b - a ≤ 2ε)
+	Ffcn   fun.Ss      // y = f(x) function
+	NFeval int         // number of calls to Ffcn (function evaluations)
+	It     int         // number of iterations from last call to Solve
+	Hist   RootHistory // history of iterations from last call to Solve
+}
+
+// Init initialises ITP structure
+func (o *ITP) Init(ffcn fun.Ss) {
+	o.K1 = 0.2
+	o.K2 = 2.0
+	o.N0 = 1
+	o.Tol = 1e-14
+	o.Ffcn = ffcn
+}
+
+// History returns the history of iterations from the last call to Solve
+func (o *ITP) History() *RootHistory {
+	return &o.Hist
+}
+
+// Solve solves y(x) = 0 for x in [xa, xb] with f(xa) * f(xb) < 0
+func (o *ITP) Solve(xa, xb float64, silent bool) (res float64, err error) {
+
+	// check input
+	o.Hist.Reset()
+	ya, yb, err := rootCheckBracket(o.Ffcn, xa, xb)
+	o.NFeval = 2
+	if err != nil {
+		return
+	}
+	if ya == 0 {
+		return xa, nil
+	}
+	if yb == 0 {
+		return xb, nil
+	}
+	a, b := xa, xb
+	if a > b {
+		a, b, ya, yb = b, a, yb, ya
+	}
+
+	// normalise such that f(a) < 0 < f(b)
+	s := 1.0
+	if ya > 0 {
+		s = -1.0
+		ya, yb = -ya, -yb
+	}
+
+	// message
+	if !silent {
+		rootMsg(0, 0, 0, 0, o.Tol, true)
+	}
+
+	// constants
+	ε := max(o.Tol, 2.0*MACHEPS*max(math.Abs(a), math.Abs(b))) // cannot be smaller than the spacing of floats
+	κ1 := o.K1 / (b - a)
+	nhalf := math.Ceil(math.Log2((b - a) / (2.0 * ε)))
+	if nhalf < 0 {
+		nhalf = 0
+	}
+	nmax := int(nhalf) + o.N0
+
+	// solve
+	var xh, xf, xt, xitp, yitp, r, δ, σ float64
+	for o.It = 0; b-a > 2.0*ε; o.It++ {
+
+		// check
+		if o.It > nmax+1 {
+			return 0.5 * (a + b), chk.Err("fail to converge after %d iterations", o.It)
+		}
+
+		// interpolation
+		xh = 0.5 * (a + b)
+		r = ε*math.Pow(2.0, float64(nmax-o.It)) - 0.5*(b-a)
+		δ = κ1 * math.Pow(b-a, o.K2)
+		xf = (yb*a - ya*b) / (yb - ya)
+
+		// truncation
+		σ = sign(xh - xf)
+		if δ <= math.Abs(xh-xf) {
+			xt = xf + σ*δ
+		} else {
+			xt = xh
+		}
+
+		// projection
+		if math.Abs(xt-xh) <= r {
+			xitp = xt
+		} else {
+			xitp = xh - σ*r
+		}
+
+		// update interval
+		yitp, err = o.Ffcn(xitp)
+		o.NFeval++
+		if err != nil {
+			return 0, chk.Err("f(%g) failed:\n%v", xitp, err)
+		}
+		yitp *= s
+		o.Hist.Append(xitp, s*yitp, 0.5*(b-a))
+		if !silent {
+			rootMsg(o.It, xitp, s*yitp, 0.5*(b-a), o.Tol, false)
+		}
+		if yitp > 0 {
+			b, yb = xitp, yitp
+		} else if yitp < 0 {
+			a, ya = xitp, yitp
+		} else {
+			return xitp, nil
+		}
+	}
+	return 0.5 * (a + b), nil
+}
+
+// add solver to database //////////////////////////////////////////////////////////////////////////
+
+func init() {
+	rootSolverDB["itp"] = func() RootSolver { return new(ITP) }
+}
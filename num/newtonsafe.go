@@ -0,0 +1,250 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package num
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+// NewtonSafe implements the safeguarded Newton-Raphson method for finding the roots of a scalar
+// equation. The root is kept bracketed and a bisection step is taken whenever the Newton step
+// would jump out of the bracket or would not reduce the size of the bracket fast enough.
+// See rtsafe on page 460 of [1].
+//
+//  NOTE: if Dfcn is nil, the derivative is computed numerically with DerivCen5
+//
+//  Reference:
+//   [1] Press WH, Teukolsky SA, Vetterling WT, Fnannery BP (2007) Numerical Recipes: The Art of
+//       Scientific Computing. Third Edition. Cambridge University Press. 1235p.
+type NewtonSafe struct {
+	MaxIt  int         // max iterations
+	Tol    float64     // tolerance
+	H      float64     // stepsize for numerical derivatives
+	Ffcn   fun.Ss      // y = f(x) function
+	Dfcn   fun.Ss      // dy/dx = f'(x) function [optional]
+	NFeval int         // number of calls to Ffcn (function evaluations)
+	NDeval int         // number of calls to Dfcn or numerical derivatives
+	It     int         // number of iterations from last call to Solve
+	Hist   RootHistory // history of iterations from last call to Solve
+}
+
+// Init initialises NewtonSafe structure
+func (o *NewtonSafe) Init(ffcn fun.Ss) {
+	o.MaxIt = 100
+	o.Tol = 1e-14
+	o.H = 1e-3
+	o.Ffcn = ffcn
+}
+
+// History returns the history of iterations from the last call to Solve
+func (o *NewtonSafe) History() *RootHistory {
+	return &o.Hist
+}
+
+// Solve solves y(x) = 0 for x in [xa, xb] with f(xa) * f(xb) < 0
+func (o *NewtonSafe) Solve(xa, xb float64, silent bool) (res float64, err error) {
+	step := func(x, fx float64) (dx float64, err error) {
+		o.NDeval++
+		df, err := rootDeriv1(o.Dfcn, o.Ffcn, x, o.H)
+		if err != nil {
+			return
+		}
+		return fx / df, nil
+	}
+	o.NDeval = 0
+	return rootSafeSolve(o.Ffcn, step, xa, xb, o.Tol, o.MaxIt, &o.NFeval, &o.It, &o.Hist, silent)
+}
+
+// Halley implements the safeguarded Halley's method for finding the roots of a scalar equation.
+// Halley's method uses the first and second derivatives and converges cubically near simple roots.
+// As in NewtonSafe, the root is kept bracketed and bisection is used whenever the Halley step
+// is not acceptable.
+//
+//  NOTE: if Dfcn or D2fcn are nil, the derivatives are computed numerically
+type Halley struct {
+	MaxIt  int         // max iterations
+	Tol    float64     // tolerance
+	H      float64     // stepsize for numerical derivatives
+	Ffcn   fun.Ss      // y = f(x) function
+	Dfcn   fun.Ss      // dy/dx = f'(x) function [optional]
+	D2fcn  fun.Ss      // d²y/dx² = f''(x) function [optional]
+	NFeval int         // number of calls to Ffcn (function evaluations)
+	NDeval int         // number of evaluations of (first and second) derivatives
+	It     int         // number of iterations from last call to Solve
+	Hist   RootHistory // history of iterations from last call to Solve
+}
+
+// Init initialises Halley structure
+func (o *Halley) Init(ffcn fun.Ss) {
+	o.MaxIt = 100
+	o.Tol = 1e-14
+	o.H = 1e-3
+	o.Ffcn = ffcn
+}
+
+// History returns the history of iterations from the last call to Solve
+func (o *Halley) History() *RootHistory {
+	return &o.Hist
+}
+
+// Solve solves y(x) = 0 for x in [xa, xb] with f(xa) * f(xb) < 0
+func (o *Halley) Solve(xa, xb float64, silent bool) (res float64, err error) {
+	step := func(x, fx float64) (dx float64, err error) {
+		o.NDeval++
+		df, err := rootDeriv1(o.Dfcn, o.Ffcn, x, o.H)
+		if err != nil {
+			return
+		}
+		var d2f float64
+		if o.D2fcn != nil {
+			d2f, err = o.D2fcn(x)
+		} else {
+			d2f, err = rootDeriv2(o.Ffcn, x, fx, o.H)
+		}
+		if err != nil {
+			return
+		}
+		den := 2.0*df*df - fx*d2f
+		if den == 0 {
+			return fx / df, nil // fallback to Newton's step
+		}
+		return 2.0 * fx * df / den, nil
+	}
+	o.NDeval = 0
+	return rootSafeSolve(o.Ffcn, step, xa, xb, o.Tol, o.MaxIt, &o.NFeval, &o.It, &o.Hist, silent)
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// rootSafeSolve runs safeguarded Newton-like iterations x ← x - dx keeping the root bracketed
+func rootSafeSolve(ffcn fun.Ss, step func(x, fx float64) (float64, error), xa, xb, tol float64, maxIt int,
+	nfeval, nit *int, hist *RootHistory, silent bool) (res float64, err error) {
+
+	// check input
+	hist.Reset()
+	fa, fb, err := rootCheckBracket(ffcn, xa, xb)
+	*nfeval = 2
+	if err != nil {
+		return
+	}
+	if fa == 0 {
+		return xa, nil
+	}
+	if fb == 0 {
+		return xb, nil
+	}
+
+	// orient the search such that f(xl) < 0
+	xl, xh := xa, xb
+	if fa > 0 {
+		xl, xh = xb, xa
+	}
+
+	// message
+	if !silent {
+		rootMsg(0, 0, 0, 0, tol, true)
+	}
+
+	// initial guess
+	res = 0.5 * (xa + xb)
+	dxold := math.Abs(xb - xa)
+	dx := dxold
+	f, err := ffcn(res)
+	*nfeval++
+	if err != nil {
+		return 0, chk.Err("f(%g) failed:\n%v", res, err)
+	}
+
+	// solve
+	var dxs, tmp float64
+	for *nit = 0; *nit < maxIt; *nit++ {
+
+		// history and message
+		hist.Append(res, f, math.Abs(dx))
+		if !silent {
+			rootMsg(*nit, res, f, math.Abs(dx), tol, false)
+		}
+		if f == 0 {
+			return
+		}
+
+		// trial step
+		dxs, err = step(res, f)
+		if err != nil {
+			return 0, chk.Err("derivative at x=%g failed:\n%v", res, err)
+		}
+
+		// bisect if the step is out of range or not decreasing fast enough
+		tmp = res - dxs
+		if math.IsNaN(dxs) || math.IsInf(dxs, 0) || (tmp-xh)*(tmp-xl) > 0 || math.Abs(2.0*dxs) > math.Abs(dxold) {
+			dxold = dx
+			dx = 0.5 * (xh - xl)
+			res = xl + dx
+			if xl == res {
+				return
+			}
+		} else {
+			dxold = dx
+			dx = dxs
+			tmp = res
+			res -= dx
+			if tmp == res {
+				return
+			}
+		}
+
+		// converged?
+		if math.Abs(dx) < 2.0*MACHEPS*math.Abs(res)+tol/2.0 {
+			return
+		}
+
+		// new function value and bracket
+		f, err = ffcn(res)
+		*nfeval++
+		if err != nil {
+			return 0, chk.Err("f(%g) failed:\n%v", res, err)
+		}
+		if f < 0 {
+			xl = res
+		} else {
+			xh = res
+		}
+	}
+
+	// did not converge
+	return res, chk.Err("fail to converge after %d iterations", *nit)
+}
+
+// rootDeriv1 computes the first derivative analytically (if dfcn != nil) or numerically
+func rootDeriv1(dfcn, ffcn fun.Ss, x, h float64) (res float64, err error) {
+	if dfcn != nil {
+		return dfcn(x)
+	}
+	return DerivCen5(x, h, ffcn)
+}
+
+// rootDeriv2 computes the second derivative numerically using central differences
+func rootDeriv2(ffcn fun.Ss, x, fx, h float64) (res float64, err error) {
+	h = h * max(1.0, math.Abs(x))
+	fp, err := ffcn(x + h)
+	if err != nil {
+		return
+	}
+	fm, err := ffcn(x - h)
+	if err != nil {
+		return
+	}
+	return (fp - 2.0*fx + fm) / (h * h), nil
+}
+
+// add solvers to database /////////////////////////////////////////////////////////////////////////
+
+func init() {
+	rootSolverDB["newton"] = func() RootSolver { return new(NewtonSafe) }
+	rootSolverDB["halley"] = func() RootSolver { return new(Halley) }
+}
@@ -5,12 +5,14 @@
 package num
 
 import (
+	"context"
 	"math"
 
 	"github.com/cpmech/gosl/chk"
 	"github.com/cpmech/gosl/fun"
 	"github.com/cpmech/gosl/io"
 	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
 )
 
 // NlSolver implements a solver to nonlinear systems of equations
@@ -43,6 +45,9 @@ type NlSolver struct {
 	// output callback
 	Out func(x []float64) error // output callback function
 
+	// cancellation and progress report
+	Monitor utl.Monitor // residual = max(|f(x)|), step = RMS norm of δx
+
 	// data for Umfpack (sparse)
 	Jtri la.Triplet // triplet
 	w    la.Vector  // workspace
@@ -182,6 +187,12 @@ func (o *NlSolver) Solve(x []float64, silent bool) (err error) {
 			o.msg("", o.It, Ldx, fxMax, false, false)
 		}
 
+		// cancellation and progress
+		err = o.Monitor.Check("NlSolver", o.It, fxMax, Ldx)
+		if err != nil {
+			return
+		}
+
 		// output
 		if o.Out != nil {
 			o.Out(x)
@@ -309,7 +320,7 @@ func (o *NlSolver) Solve(x []float64, silent bool) (err error) {
 		if o.It > 0 && o.ChkConv {
 			Θ = Ldx / LdxPrev
 			if Θ > 0.99 {
-				return chk.Err("solver is diverging with Θ = %g (Ldx=%g, LdxPrev=%g)", Θ, Ldx, LdxPrev)
+				return utl.NewIterError(utl.StopDiverged, "NlSolver", o.It, "Θ = %g (Ldx=%g, LdxPrev=%g)", Θ, Ldx, LdxPrev)
 			}
 		}
 		LdxPrev = Ldx
@@ -322,11 +333,18 @@ func (o *NlSolver) Solve(x []float64, silent bool) (err error) {
 
 	// check convergence
 	if o.It == o.MaxIt {
-		err = chk.Err("cannot converge after %d iterations", o.It)
+		err = utl.NewIterError(utl.StopMaxIt, "NlSolver", o.It, "cannot converge after %d iterations", o.It)
 	}
 	return
 }
 
+// SolveCtx solves non-linear problem f(x) == 0 and stops with an error if ctx is cancelled
+func (o *NlSolver) SolveCtx(ctx context.Context, x []float64, silent bool) (err error) {
+	o.Monitor.Ctx = ctx
+	defer func() { o.Monitor.Ctx = nil }()
+	return o.Solve(x, silent)
+}
+
 // CheckJ check Jacobian matrix
 //  Ouptut: cnd -- condition number (with Frobenius norm)
 func (o *NlSolver) CheckJ(x []float64, tol float64, chkJnum, silent bool) (cnd float64, err error) {
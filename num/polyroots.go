@@ -0,0 +1,174 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package num
+
+import (
+	"math"
+	"math/cmplx"
+	"sort"
+
+	"github.com/cpmech/gosl/chk"
+)
+
+// PolyRoots computes all (complex) roots of a polynomial with real coefficients using the
+// Aberth-Ehrlich method
+//
+//  The polynomial is specified by:
+//
+//   p(x) = c[0] + c[1]⋅x + c[2]⋅x² + ... + c[n]⋅xⁿ
+//
+//  Notes:
+//   1) all roots are refined simultaneously; the convergence is cubic for simple roots
+//   2) the initial approximations are placed on a circle with radius given by the root bounds
+//   3) the imaginary part of roots that are real within round-off errors is set to zero
+//   4) the roots are sorted by real part and then by imaginary part
+//  Output:
+//   roots -- the n roots of the polynomial (repeated roots appear repeatedly)
+//
+//  Reference:
+//   [1] Aberth O (1973) Iteration methods for finding all zeros of a polynomial simultaneously.
+//       Mathematics of Computation, 27(122):339-344
+func PolyRoots(c []float64) (roots []complex128, err error) {
+
+	// remove leading zeros
+	n := len(c) - 1
+	for n >= 0 && c[n] == 0 {
+		n--
+	}
+	if n < 0 {
+		return nil, chk.Err("polynomial must have at least one non-zero coefficient")
+	}
+	for i := 0; i <= n; i++ {
+		if math.IsNaN(c[i]) || math.IsInf(c[i], 0) {
+			return nil, chk.Err("coefficients must be finite. c[%d] = %g is invalid", i, c[i])
+		}
+	}
+
+	// zero roots
+	nzero := 0
+	for nzero < n && c[nzero] == 0 {
+		nzero++
+	}
+	for i := 0; i < nzero; i++ {
+		roots = append(roots, 0)
+	}
+	a := c[nzero : n+1] // reduced polynomial
+	deg := len(a) - 1
+
+	// trivial cases
+	if deg == 0 {
+		return
+	}
+	if deg == 1 {
+		roots = append(roots, complex(-a[0]/a[1], 0))
+		return
+	}
+
+	// initial approximations: circle with radius between Cauchy's lower and upper bounds
+	upper, lower := 0.0, 0.0
+	for i := 0; i < deg; i++ {
+		upper = math.Max(upper, math.Abs(a[i]/a[deg]))
+		lower = math.Max(lower, math.Abs(a[i+1]/a[0]))
+	}
+	upper = 1.0 + upper
+	lower = 1.0 / (1.0 + lower)
+	radius := 0.5 * (upper + lower)
+	z := make([]complex128, deg)
+	for k := 0; k < deg; k++ {
+		θ := 2.0*math.Pi*float64(k)/float64(deg) + 0.4 // shift avoids symmetric configurations
+		z[k] = cmplx.Rect(radius, θ)
+	}
+
+	// iterations
+	_, err = aberth(a, z, 500)
+	if err != nil {
+		return nil, err
+	}
+
+	// clean imaginary parts
+	for k := 0; k < deg; k++ {
+		if math.Abs(imag(z[k])) <= 1e3*MACHEPS*cmplx.Abs(z[k]) {
+			z[k] = complex(real(z[k]), 0)
+		}
+	}
+
+	// results
+	roots = append(roots, z...)
+	sort.Slice(roots, func(i, j int) bool {
+		if real(roots[i]) == real(roots[j]) {
+			return imag(roots[i]) < imag(roots[j])
+		}
+		return real(roots[i]) < real(roots[j])
+	})
+	return
+}
+
+// aberth refines the approximations z of the roots of p(z) = a[0] + a[1]⋅z + ... + a[n]⋅zⁿ
+// simultaneously by the Aberth-Ehrlich method
+//  Notes:
+//   1) z is updated in place
+//   2) if the correction of an approximation cannot be computed (e.g. p'(z) = 0), the
+//      approximation is perturbed and the iterations continue
+//  Output:
+//   nit -- number of iterations (sweeps)
+func aberth(a []float64, z []complex128, maxIt int) (nit int, err error) {
+	deg := len(z)
+	tol := 4.0 * MACHEPS
+	converged := make([]bool, deg)
+	var p, dp, ratio, sum, w complex128
+	var bound float64
+	for nit = 1; nit <= maxIt; nit++ {
+		nconv := 0
+		for k := 0; k < deg; k++ {
+			if converged[k] {
+				nconv++
+				continue
+			}
+			p, dp, bound = polyEvalC(a, z[k])
+			if cmplx.Abs(p) <= tol*bound { // p(z) is as small as the round-off errors allow
+				converged[k] = true
+				nconv++
+				continue
+			}
+			ratio = p / dp
+			sum = 0
+			for j := 0; j < deg; j++ {
+				if j != k {
+					sum += 1.0 / (z[k] - z[j])
+				}
+			}
+			w = ratio / (1.0 - ratio*sum)
+			if cmplx.IsNaN(w) || cmplx.IsInf(w) {
+				z[k] += cmplx.Rect(1e-3*(1.0+cmplx.Abs(z[k])), 1.0+float64(k))
+				continue
+			}
+			z[k] -= w
+			if cmplx.Abs(w) <= tol*cmplx.Abs(z[k]) {
+				converged[k] = true
+				nconv++
+			}
+		}
+		if nconv == deg {
+			return
+		}
+	}
+	return maxIt, chk.Err("Aberth-Ehrlich method did not converge after %d iterations", maxIt)
+}
+
+// polyEvalC evaluates p(z) and dp/dz(z) using Horner's method
+//   p(z) = a[0] + a[1]⋅z + ... + a[n]⋅zⁿ
+//   bound = Σ |a[i]|⋅|z|ⁱ is used to estimate the round-off error in p(z)
+func polyEvalC(a []float64, z complex128) (p, dp complex128, bound float64) {
+	n := len(a) - 1
+	r := cmplx.Abs(z)
+	p = complex(a[n], 0)
+	bound = math.Abs(a[n])
+	for i := n - 1; i >= 0; i-- {
+		dp = dp*z + p
+		p = p*z + complex(a[i], 0)
+		bound = bound*r + math.Abs(a[i])
+	}
+	return
+}
@@ -19,6 +19,7 @@ import (
 // QuadElementary defines the interface for elementary quadrature algorithms with refinement.
 type QuadElementary interface {
 	Init(f fun.Ss, a, b, eps float64) // The constructor takes as inputs f, the function or functor to be integrated between limits a and b, also input.
+	Next() (float64, error)           // Returns the next stage of refinement
 	Integrate() (float64, error)      // Returns the integral for the specified input data
 }
 
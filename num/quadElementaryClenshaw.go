@@ -0,0 +1,115 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package num
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+// ElementaryClenshawCurtis structure implements the nested Clenshaw-Curtis quadrature rule with
+// refinement
+//
+//  The nodes are the Chebyshev extreme points:
+//
+//   xⱼ = c + d⋅cos(j⋅π/N)   j = 0...N   with   c = (a+b)/2  and  d = (b-a)/2
+//
+//  and the weights are:
+//
+//   wⱼ = d⋅(cⱼ/N)⋅[1 - Σₖ bₖ⋅cos(2⋅k⋅j⋅π/N) / (4⋅k² - 1)]   k = 1...N/2
+//
+//  with cⱼ = 1 for j ∈ {0,N} or 2 otherwise and bₖ = 1 for k = N/2 or 2 otherwise.
+//
+//  The nth stage of refinement uses N = 2ⁿ; the rules are nested, thus only the N/2 new points
+//  are evaluated at each stage.
+//
+//  Reference:
+//   [1] Clenshaw CW and Curtis AR (1960) A method for numerical integration on an automatic
+//       computer. Numerische Mathematik, 2:197-205
+//   [2] Trefethen LN (2008) Is Gauss quadrature better than Clenshaw-Curtis? SIAM Review,
+//       50(1):67-87
+type ElementaryClenshawCurtis struct {
+	n     int       // current level of refinement
+	a, b  float64   // limits
+	s     float64   // current value of the integral
+	eps   float64   // precision
+	fvals []float64 // function values at the nodes of the current stage
+	f     fun.Ss    // the function
+}
+
+// Init initialises ClenshawCurtis structure
+func (o *ElementaryClenshawCurtis) Init(f fun.Ss, a, b, eps float64) {
+	o.n = 0
+	o.f = f
+	o.a = a
+	o.b = b
+	o.s = 0
+	o.eps = eps
+	o.fvals = nil
+}
+
+// Next returns the nth stage of refinement. On the first call (n=1) the routine returns
+// Simpson's rule (N=2); subsequent calls double N and reuse all previous function values.
+func (o *ElementaryClenshawCurtis) Next() (res float64, err error) {
+	o.n++
+	N := 1 << uint(o.n)
+	c := 0.5 * (o.a + o.b)
+	d := 0.5 * (o.b - o.a)
+
+	// function values
+	fvals := make([]float64, N+1)
+	for j := 0; j <= N; j++ {
+		if o.n > 1 && j%2 == 0 {
+			fvals[j] = o.fvals[j/2]
+			continue
+		}
+		fvals[j], err = o.f(c + d*math.Cos(float64(j)*math.Pi/float64(N)))
+		if err != nil {
+			return
+		}
+	}
+	o.fvals = fvals
+
+	// weighted sum
+	var sum, wj, bk float64
+	half := N / 2
+	for j := 0; j <= N; j++ {
+		wj = 1.0
+		for k := 1; k <= half; k++ {
+			bk = 2.0
+			if k == half {
+				bk = 1.0
+			}
+			wj -= bk * math.Cos(float64(2*k*j)*math.Pi/float64(N)) / float64(4*k*k-1)
+		}
+		if j > 0 && j < N {
+			wj *= 2.0
+		}
+		sum += wj * fvals[j]
+	}
+	o.s = d * sum / float64(N)
+	return o.s, nil
+}
+
+// Integrate performs the numerical integration
+func (o *ElementaryClenshawCurtis) Integrate() (res float64, err error) {
+	jmax := 12
+	var olds float64
+	for j := 0; j < jmax; j++ {
+		o.s, err = o.Next()
+		if err != nil {
+			return
+		}
+		if j > 2 {
+			if math.Abs(o.s-olds) < o.eps*math.Abs(olds) || (o.s == 0 && olds == 0) {
+				return o.s, nil
+			}
+		}
+		olds = o.s
+	}
+	return 0, chk.Err("achieved maximum number of iterations (n=%d)", jmax)
+}
@@ -0,0 +1,79 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package num
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+// ElementaryRomberg structure implements Romberg's method: the successive refinements of the
+// trapezoidal rule are extrapolated to h → 0 by means of Richardson's extrapolation
+//
+//  The Romberg table is:
+//
+//   R[k][0] = T(hₖ)  with  hₖ = (b-a)/2ᵏ
+//   R[k][j] = R[k][j-1] + (R[k][j-1] - R[k-1][j-1]) / (4ʲ - 1)
+//
+//  and the diagonal R[k][k] is the current estimate of the integral.
+//
+//  Reference:
+//   [1] Press WH, Teukolsky SA, Vetterling WT, Fnannery BP (2007) Numerical Recipes: The Art of
+//       Scientific Computing. Third Edition. Cambridge University Press. 1235p.
+type ElementaryRomberg struct {
+	trapz ElementaryTrapz // trapezoidal rule with refinement
+	row   []float64       // last row of the Romberg table
+	s     float64         // current value of the integral
+	eps   float64         // precision
+}
+
+// Init initialises Romberg structure
+func (o *ElementaryRomberg) Init(f fun.Ss, a, b, eps float64) {
+	o.trapz.Init(f, a, b, eps)
+	o.row = o.row[:0]
+	o.s = 0
+	o.eps = eps
+}
+
+// Next computes the next refinement of the trapezoidal rule, appends a new row to the Romberg
+// table and returns the extrapolated value R[k][k]
+func (o *ElementaryRomberg) Next() (res float64, err error) {
+	t, err := o.trapz.Next()
+	if err != nil {
+		return
+	}
+	k := len(o.row)
+	row := make([]float64, k+1)
+	row[0] = t
+	fac := 1.0
+	for j := 1; j <= k; j++ {
+		fac *= 4.0
+		row[j] = row[j-1] + (row[j-1]-o.row[j-1])/(fac-1.0)
+	}
+	o.row = row
+	o.s = row[k]
+	return o.s, nil
+}
+
+// Integrate performs the numerical integration
+func (o *ElementaryRomberg) Integrate() (res float64, err error) {
+	jmax := 20
+	var olds float64
+	for j := 0; j < jmax; j++ {
+		o.s, err = o.Next()
+		if err != nil {
+			return
+		}
+		if j > 3 {
+			if math.Abs(o.s-olds) < o.eps*math.Abs(olds) || (o.s == 0 && olds == 0) {
+				return o.s, nil
+			}
+		}
+		olds = o.s
+	}
+	return 0, chk.Err("achieved maximum number of iterations (n=%d)", jmax)
+}
@@ -0,0 +1,129 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package num
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+// ElementaryTanhSinh structure implements the double-exponential (tanh-sinh) quadrature rule
+// with refinement. The rule is well suited for integrands with (integrable) singularities at
+// the end points since f is never evaluated exactly at a or b.
+//
+//  The substitution is:
+//
+//   x(t) = c + d⋅tanh(π/2⋅sinh(t))   with   c = (a+b)/2  and  d = (b-a)/2
+//
+//  and the integral becomes:
+//
+//   ∫ f(x) dx ≈ h Σₖ f(x(tₖ)) x'(tₖ)   with   tₖ = k⋅h  and  |tₖ| ≤ tmax
+//
+//  Each refinement halves h and adds only the new (odd) nodes. The distance from the nodes to
+//  the end points is computed directly, thus avoiding the cancellation in b - x.
+//
+//  Reference:
+//   [1] Takahasi H and Mori M (1974) Double exponential formulas for numerical integration.
+//       Publications of the Research Institute for Mathematical Sciences, 9(3):721-741
+//   [2] Press WH, Teukolsky SA, Vetterling WT, Fnannery BP (2007) Numerical Recipes: The Art of
+//       Scientific Computing. Third Edition. Cambridge University Press. 1235p.
+type ElementaryTanhSinh struct {
+	n    int     // current level of refinement
+	a, b float64 // limits
+	s    float64 // current value of the integral
+	sum  float64 // sum of f(x(tₖ)) x'(tₖ) over all nodes computed so far
+	eps  float64 // precision
+	tmax float64 // truncation of the t-axis
+	f    fun.Ss  // the function
+}
+
+// Init initialises TanhSinh structure
+func (o *ElementaryTanhSinh) Init(f fun.Ss, a, b, eps float64) {
+	o.n = 0
+	o.f = f
+	o.a = a
+	o.b = b
+	o.s = 0
+	o.sum = 0
+	o.eps = eps
+	o.tmax = 4.0 // exp(-π/2⋅sinh(4)) ≈ 2e-19
+}
+
+// Next returns the nth stage of refinement. On the first call (n=1) the step size is h=1;
+// subsequent calls halve h and add the nodes in between the existing ones.
+func (o *ElementaryTanhSinh) Next() (res float64, err error) {
+	o.n++
+	h := math.Pow(2.0, float64(1-o.n))
+	kmax := int(o.tmax / h)
+	kinc := 2 // only odd k are new nodes
+	if o.n == 1 {
+		kinc = 1
+		o.sum, err = o.term(0)
+		if err != nil {
+			return
+		}
+	}
+	var v float64
+	for k := 1; k <= kmax; k += kinc {
+		v, err = o.term(float64(k) * h)
+		if err != nil {
+			return
+		}
+		o.sum += v
+	}
+	o.s = h * o.sum
+	return o.s, nil
+}
+
+// Integrate performs the numerical integration
+func (o *ElementaryTanhSinh) Integrate() (res float64, err error) {
+	jmax := 12
+	var olds float64
+	for j := 0; j < jmax; j++ {
+		o.s, err = o.Next()
+		if err != nil {
+			return
+		}
+		if j > 2 {
+			if math.Abs(o.s-olds) < o.eps*math.Abs(olds) || (o.s == 0 && olds == 0) {
+				return o.s, nil
+			}
+		}
+		olds = o.s
+	}
+	return 0, chk.Err("achieved maximum number of iterations (n=%d)", jmax)
+}
+
+// term computes f(x(t))⋅x'(t) + f(x(-t))⋅x'(-t) for t > 0 or f(x(0))⋅x'(0) for t = 0
+// Nodes that coincide with the end points (within round-off) are skipped.
+func (o *ElementaryTanhSinh) term(t float64) (res float64, err error) {
+	d := 0.5 * (o.b - o.a)
+	u := 0.5 * math.Pi * math.Sinh(t)
+	chu := math.Cosh(u)
+	w := d * 0.5 * math.Pi * math.Cosh(t) / (chu * chu)
+	var fx float64
+	if t == 0 {
+		fx, err = o.f(o.a + d)
+		return w * fx, err
+	}
+	δ := d * math.Exp(-u) / chu // distance from the end points: d⋅(1 - tanh(u))
+	if xa := o.a + δ; xa != o.a {
+		fx, err = o.f(xa)
+		if err != nil {
+			return
+		}
+		res += w * fx
+	}
+	if xb := o.b - δ; xb != o.b {
+		fx, err = o.f(xb)
+		if err != nil {
+			return
+		}
+		res += w * fx
+	}
+	return
+}
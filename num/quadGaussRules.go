@@ -0,0 +1,459 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package num
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/utl"
+)
+
+// The algorithms below are based on [1,2,3]
+// REFERENCES:
+// [1] Golub GH, Welsch JH (1969) Calculation of Gauss quadrature rules. Mathematics of
+//     Computation, 23(106):221-230
+// [2] Golub GH (1973) Some modified matrix eigenvalue problems. SIAM Review, 15(2):318-334
+// [3] Gautschi W (2004) Orthogonal Polynomials: Computation and Approximation. Oxford University
+//     Press. 301p.
+
+// GaussRecurrence returns the coefficients of the three-term recurrence relation of the monic
+// orthogonal polynomials associated with one of the fun.Opoly kinds; i.e.
+//
+//   p₋₁(x) = 0,  p₀(x) = 1,  pₖ₊₁(x) = (x - a[k])⋅pₖ(x) - b[k]⋅pₖ₋₁(x)
+//
+//  Input:
+//   kind  -- fun.OpolyLegendreKind, fun.OpolyJacobiKind, fun.OpolyHermiteKind,
+//            fun.OpolyLaguerreKind, fun.OpolyCheby1Kind or fun.OpolyCheby2Kind
+//   n     -- number of coefficients
+//   alpha -- Jacobi and Laguerre only: α coefficient
+//   beta  -- Jacobi only: β coefficient
+//  Output:
+//   a, b -- recurrence coefficients (len = n). NOTE: b[0] is not used by the recurrence
+//   mu0  -- integral of the weight function: μ₀ = ∫ w(x) dx
+//
+//  The weight functions w(x) and intervals are:
+//   Legendre:   w = 1                      x ϵ [-1, 1]
+//   Jacobi:     w = (1-x)^α ⋅ (1+x)^β      x ϵ [-1, 1]
+//   Hermite:    w = exp(-x²)               x ϵ (-∞, ∞)
+//   Laguerre:   w = x^α ⋅ exp(-x)          x ϵ [0, ∞)
+//   Chebyshev1: w = 1 / √(1-x²)            x ϵ [-1, 1]
+//   Chebyshev2: w = √(1-x²)                x ϵ [-1, 1]
+func GaussRecurrence(kind io.Enum, n int, alpha, beta float64) (a, b []float64, mu0 float64) {
+	a = make([]float64, n)
+	b = make([]float64, n)
+	switch kind {
+
+	case fun.OpolyLegendreKind:
+		mu0 = 2.0
+		for k := 1; k < n; k++ {
+			K := float64(k)
+			b[k] = K * K / (4.0*K*K - 1.0)
+		}
+
+	case fun.OpolyJacobiKind:
+		if alpha <= -1 || beta <= -1 {
+			chk.Panic("Jacobi weight requires α > -1 and β > -1. α=%g, β=%g", alpha, beta)
+		}
+		ab := alpha + beta
+		l1, _ := math.Lgamma(alpha + 1.0)
+		l2, _ := math.Lgamma(beta + 1.0)
+		l3, _ := math.Lgamma(ab + 2.0)
+		mu0 = math.Pow(2.0, ab+1.0) * math.Exp(l1+l2-l3)
+		if n > 0 {
+			a[0] = (beta - alpha) / (ab + 2.0)
+		}
+		if n > 1 {
+			b[1] = 4.0 * (alpha + 1.0) * (beta + 1.0) / ((ab + 2.0) * (ab + 2.0) * (ab + 3.0))
+		}
+		for k := 1; k < n; k++ {
+			K := float64(k)
+			t := 2.0*K + ab
+			a[k] = (beta*beta - alpha*alpha) / (t * (t + 2.0))
+			if k > 1 {
+				b[k] = 4.0 * K * (K + alpha) * (K + beta) * (K + ab) / (t * t * (t + 1.0) * (t - 1.0))
+			}
+		}
+
+	case fun.OpolyHermiteKind:
+		mu0 = math.Sqrt(math.Pi)
+		for k := 1; k < n; k++ {
+			b[k] = float64(k) / 2.0
+		}
+
+	case fun.OpolyLaguerreKind:
+		if alpha <= -1 {
+			chk.Panic("Laguerre weight requires α > -1. α=%g", alpha)
+		}
+		mu0 = math.Gamma(alpha + 1.0)
+		for k := 0; k < n; k++ {
+			K := float64(k)
+			a[k] = 2.0*K + alpha + 1.0
+			b[k] = K * (K + alpha)
+		}
+
+	case fun.OpolyCheby1Kind:
+		mu0 = math.Pi
+		for k := 1; k < n; k++ {
+			b[k] = 0.25
+		}
+		if n > 1 {
+			b[1] = 0.5
+		}
+
+	case fun.OpolyCheby2Kind:
+		mu0 = math.Pi / 2.0
+		for k := 1; k < n; k++ {
+			b[k] = 0.25
+		}
+
+	default:
+		chk.Panic("cannot compute recurrence coefficients for orthogonal polynomial %q", kind)
+	}
+	b[0] = mu0
+	return
+}
+
+// GaussRecurrenceFromMoments computes the three-term recurrence coefficients of the monic
+// polynomials orthogonal with respect to an arbitrary weight function given its moments
+//
+//   mom[k] = ∫ xᵏ ⋅ w(x) dx     k = 0, 1, ..., 2n-1
+//
+//  The (modified) Chebyshev algorithm is employed; see [3]. NOTE: this problem is severely
+//  ill-conditioned for large n; hence, only small rules (say, n ≤ 12) can be computed accurately.
+//
+//  Output:
+//   a, b -- recurrence coefficients (len = n = len(mom)/2); see GaussRecurrence
+//   mu0  -- integral of the weight function (= mom[0])
+func GaussRecurrenceFromMoments(mom []float64) (a, b []float64, mu0 float64, err error) {
+
+	// check
+	n := len(mom) / 2
+	if n < 1 {
+		return nil, nil, 0, chk.Err("at least 2 moments are required")
+	}
+	if mom[0] <= 0 {
+		return nil, nil, 0, chk.Err("the zero-th moment must be positive. mom[0] = %g", mom[0])
+	}
+
+	// auxiliary
+	a = make([]float64, n)
+	b = make([]float64, n)
+	sigOld := make([]float64, 2*n) // σ(k-2, ⋅)
+	sig := make([]float64, 2*n)    // σ(k-1, ⋅)
+	sigNew := make([]float64, 2*n) // σ(k, ⋅)
+	copy(sig, mom[:2*n])
+
+	// first coefficients
+	a[0] = mom[1] / mom[0]
+	b[0] = mom[0]
+	mu0 = mom[0]
+
+	// Chebyshev algorithm
+	for k := 1; k < n; k++ {
+		for l := k; l < 2*n-k; l++ {
+			sigNew[l] = sig[l+1] - a[k-1]*sig[l] - b[k-1]*sigOld[l]
+		}
+		if sigNew[k] <= 0 {
+			return nil, nil, 0, chk.Err("moments do not define a positive weight function (σ[%d] = %g)", k, sigNew[k])
+		}
+		a[k] = sigNew[k+1]/sigNew[k] - sig[k]/sig[k-1]
+		b[k] = sigNew[k] / sig[k-1]
+		sigOld, sig, sigNew = sig, sigNew, sigOld
+	}
+	return
+}
+
+// GaussGolubWelsch computes the nodes (x) and weights (w) of a Gauss quadrature rule with
+// n = len(a) points from the recurrence coefficients of the associated orthogonal polynomials
+//
+//  The nodes are the eigenvalues of the symmetric tridiagonal (Jacobi) matrix with diagonal a[k]
+//  and off-diagonal √b[k]; the weights are given by μ₀ times the square of the first component
+//  of the normalised eigenvectors. See [1].
+//
+//  Output:
+//   x -- nodes sorted in ascending order
+//   w -- weights
+func GaussGolubWelsch(a, b []float64, mu0 float64) (x, w []float64, err error) {
+	n := len(a)
+	if n < 1 || len(b) < n {
+		return nil, nil, chk.Err("len(a) = %d must be positive and len(b) = %d must be ≥ len(a)", n, len(b))
+	}
+	x = make([]float64, n)
+	e := make([]float64, n)
+	z := make([]float64, n) // first components of the eigenvectors
+	copy(x, a)
+	for k := 0; k < n-1; k++ {
+		if b[k+1] < 0 {
+			return nil, nil, chk.Err("recurrence coefficients b must be non-negative. b[%d] = %g", k+1, b[k+1])
+		}
+		e[k] = math.Sqrt(b[k+1])
+	}
+	z[0] = 1.0
+	err = tridiagEigQL(x, e, z)
+	if err != nil {
+		return
+	}
+	w = make([]float64, n)
+	for k := 0; k < n; k++ {
+		w[k] = mu0 * z[k] * z[k]
+	}
+	utl.Qsort2(x, w)
+	return
+}
+
+// GaussRadauGolubWelsch computes the nodes (x) and weights (w) of a Gauss-Radau quadrature rule
+// with n = len(a) points, one of them fixed at x = r (usually one end of the interval).
+// See [2] and [3].
+func GaussRadauGolubWelsch(a, b []float64, mu0, r float64) (x, w []float64, err error) {
+	n := len(a)
+	if n < 2 {
+		return nil, nil, chk.Err("Gauss-Radau rules require at least 2 points")
+	}
+	aa := make([]float64, n)
+	copy(aa, a)
+	pm, p := 0.0, 1.0 // p₋₁(r), p₀(r)
+	for k := 0; k < n-1; k++ {
+		pm, p = p, (r-a[k])*p-b[k]*pm
+	}
+	if p == 0 {
+		return nil, nil, chk.Err("cannot fix node at r=%g because it is a root of the orthogonal polynomial", r)
+	}
+	aa[n-1] = r - b[n-1]*pm/p
+	return GaussGolubWelsch(aa, b, mu0)
+}
+
+// GaussLobattoGolubWelsch computes the nodes (x) and weights (w) of a Gauss-Lobatto quadrature
+// rule with n = len(a) points, two of them fixed at x = l and x = r (usually the ends of the
+// interval). See [2] and [3].
+func GaussLobattoGolubWelsch(a, b []float64, mu0, l, r float64) (x, w []float64, err error) {
+	n := len(a)
+	if n < 3 {
+		return nil, nil, chk.Err("Gauss-Lobatto rules require at least 3 points")
+	}
+	aa := make([]float64, n)
+	bb := make([]float64, n)
+	copy(aa, a)
+	copy(bb, b)
+	pml, pl := 0.0, 1.0 // p₋₁(l), p₀(l)
+	pmr, pr := 0.0, 1.0 // p₋₁(r), p₀(r)
+	for k := 0; k < n-1; k++ {
+		pml, pl = pl, (l-a[k])*pl-b[k]*pml
+		pmr, pr = pr, (r-a[k])*pr-b[k]*pmr
+	}
+	det := pl*pmr - pr*pml
+	if det == 0 {
+		return nil, nil, chk.Err("cannot fix nodes at l=%g and r=%g", l, r)
+	}
+	aa[n-1] = (l*pl*pmr - r*pr*pml) / det
+	bb[n-1] = (r - l) * pl * pr / det
+	return GaussGolubWelsch(aa, bb, mu0)
+}
+
+// GaussRuleXW computes the nodes (x) and weights (w) of the n-point Gauss quadrature rule
+// associated with one of the fun.Opoly kinds; see GaussRecurrence for the weight functions
+func GaussRuleXW(kind io.Enum, n int, alpha, beta float64) (x, w []float64) {
+	a, b, mu0 := GaussRecurrence(kind, n, alpha, beta)
+	x, w, err := GaussGolubWelsch(a, b, mu0)
+	if err != nil {
+		chk.Panic("%v", err)
+	}
+	return
+}
+
+// GaussHermiteXW computes the nodes and weights of the n-point Gauss-Hermite rule
+//   ∫ exp(-x²) f(x) dx ≈ Σ w[i] f(x[i])   with x ϵ (-∞, ∞)
+func GaussHermiteXW(n int) (x, w []float64) {
+	return GaussRuleXW(fun.OpolyHermiteKind, n, 0, 0)
+}
+
+// GaussLaguerreXW computes the nodes and weights of the n-point generalized Gauss-Laguerre rule
+//   ∫ x^α exp(-x) f(x) dx ≈ Σ w[i] f(x[i])   with x ϵ [0, ∞) and α > -1
+func GaussLaguerreXW(n int, alpha float64) (x, w []float64) {
+	return GaussRuleXW(fun.OpolyLaguerreKind, n, alpha, 0)
+}
+
+// GaussChebyshev1XW computes the nodes and weights of the n-point Gauss-Chebyshev rule of the
+// first kind
+//   ∫ f(x) / √(1-x²) dx ≈ Σ w[i] f(x[i])   with x ϵ [-1, 1]
+func GaussChebyshev1XW(n int) (x, w []float64) {
+	return GaussRuleXW(fun.OpolyCheby1Kind, n, 0, 0)
+}
+
+// GaussChebyshev2XW computes the nodes and weights of the n-point Gauss-Chebyshev rule of the
+// second kind
+//   ∫ f(x) ⋅ √(1-x²) dx ≈ Σ w[i] f(x[i])   with x ϵ [-1, 1]
+func GaussChebyshev2XW(n int) (x, w []float64) {
+	return GaussRuleXW(fun.OpolyCheby2Kind, n, 0, 0)
+}
+
+// GaussRadauXW computes the nodes and weights of the n-point Gauss-Radau (Legendre) rule with one
+// node fixed at x = -1 (or at x = +1 if right == true). The rule is exact for polynomials of
+// degree up to 2n-2
+func GaussRadauXW(n int, right bool) (x, w []float64) {
+	a, b, mu0 := GaussRecurrence(fun.OpolyLegendreKind, n, 0, 0)
+	r := -1.0
+	if right {
+		r = 1.0
+	}
+	x, w, err := GaussRadauGolubWelsch(a, b, mu0, r)
+	if err != nil {
+		chk.Panic("%v", err)
+	}
+	if right { // exact fixed node
+		x[n-1] = 1
+	} else {
+		x[0] = -1
+	}
+	return
+}
+
+// GaussLobattoXW computes the nodes and weights of the n-point Gauss-Lobatto (Legendre) rule
+// including both ends x = -1 and x = +1. The rule is exact for polynomials of degree up to 2n-3
+func GaussLobattoXW(n int) (x, w []float64) {
+	a, b, mu0 := GaussRecurrence(fun.OpolyLegendreKind, n, 0, 0)
+	x, w, err := GaussLobattoGolubWelsch(a, b, mu0, -1, 1)
+	if err != nil {
+		chk.Panic("%v", err)
+	}
+	x[0], x[n-1] = -1, 1 // exact ends
+	return
+}
+
+// GaussMomentsXW computes the nodes and weights of the Gauss rule with n = len(mom)/2 points for
+// an arbitrary weight function given by its moments mom[k] = ∫ xᵏ ⋅ w(x) dx, k = 0...2n-1
+func GaussMomentsXW(mom []float64) (x, w []float64, err error) {
+	a, b, mu0, err := GaussRecurrenceFromMoments(mom)
+	if err != nil {
+		return
+	}
+	return GaussGolubWelsch(a, b, mu0)
+}
+
+// QuadGaussHermite approximates ∫ exp(-x²) f(x) dx over (-∞, ∞) using the n-point Gauss-Hermite rule
+func QuadGaussHermite(f fun.Ss, n int) (res float64, err error) {
+	x, w := GaussHermiteXW(n)
+	return quadGaussSum(f, x, w, 1, 0)
+}
+
+// QuadGaussLaguerre approximates ∫ x^α exp(-x) f(x) dx over [0, ∞) using the n-point generalized
+// Gauss-Laguerre rule
+func QuadGaussLaguerre(f fun.Ss, n int, alpha float64) (res float64, err error) {
+	x, w := GaussLaguerreXW(n, alpha)
+	return quadGaussSum(f, x, w, 1, 0)
+}
+
+// QuadGaussChebyshev1 approximates ∫ f(x) / √(1-x²) dx over [-1, 1] using the n-point
+// Gauss-Chebyshev rule of the first kind
+func QuadGaussChebyshev1(f fun.Ss, n int) (res float64, err error) {
+	x, w := GaussChebyshev1XW(n)
+	return quadGaussSum(f, x, w, 1, 0)
+}
+
+// QuadGaussChebyshev2 approximates ∫ f(x) ⋅ √(1-x²) dx over [-1, 1] using the n-point
+// Gauss-Chebyshev rule of the second kind
+func QuadGaussChebyshev2(f fun.Ss, n int) (res float64, err error) {
+	x, w := GaussChebyshev2XW(n)
+	return quadGaussSum(f, x, w, 1, 0)
+}
+
+// QuadGaussLobatto approximates ∫ f(x) dx over [a, b] using the n-point Gauss-Lobatto rule
+func QuadGaussLobatto(a, b float64, f fun.Ss, n int) (res float64, err error) {
+	x, w := GaussLobattoXW(n)
+	return quadGaussSum(f, x, w, 0.5*(b-a), 0.5*(b+a))
+}
+
+// quadGaussSum computes Σ w[i] f(xm + xr⋅x[i]) ⋅ xr
+func quadGaussSum(f fun.Ss, x, w []float64, xr, xm float64) (res float64, err error) {
+	var fx float64
+	for i := 0; i < len(x); i++ {
+		fx, err = f(xm + xr*x[i])
+		if err != nil {
+			return
+		}
+		res += w[i] * fx
+	}
+	res *= xr
+	return
+}
+
+// tridiagEigQL computes the eigenvalues of a symmetric tridiagonal matrix and the first
+// components of the normalised eigenvectors using the QL algorithm with implicit shifts
+// (tqli on page 583 of [4]; only the first row of the eigenvectors matrix is updated)
+//
+//  Input:
+//   d -- diagonal (len = n)
+//   e -- sub-diagonal in e[0...n-2]; e[n-1] is arbitrary
+//   z -- first row of the initial matrix of eigenvectors; e.g. z = [1, 0, 0, ...]
+//  Output:
+//   d -- eigenvalues (not sorted)
+//   e -- destroyed
+//   z -- first components of the eigenvectors
+//
+//  Reference:
+//   [4] Press WH, Teukolsky SA, Vetterling WT, Fnannery BP (2007) Numerical Recipes: The Art of
+//       Scientific Computing. Third Edition. Cambridge University Press. 1235p.
+func tridiagEigQL(d, e, z []float64) (err error) {
+	n := len(d)
+	if n < 2 {
+		return
+	}
+	e[n-1] = 0.0
+	maxIt := 50
+	var m, i, it int
+	var s, r, p, g, f, dd, c, b float64
+	for l := 0; l < n; l++ {
+		it = 0
+		for {
+			for m = l; m < n-1; m++ {
+				dd = math.Abs(d[m]) + math.Abs(d[m+1])
+				if math.Abs(e[m]) <= MACHEPS*dd {
+					break
+				}
+			}
+			if m == l {
+				break
+			}
+			if it == maxIt {
+				return chk.Err("QL algorithm did not converge after %d iterations", maxIt)
+			}
+			it++
+			g = (d[l+1] - d[l]) / (2.0 * e[l])
+			r = math.Hypot(g, 1.0)
+			g = d[m] - d[l] + e[l]/(g+math.Copysign(r, g))
+			s, c, p = 1.0, 1.0, 0.0
+			for i = m - 1; i >= l; i-- {
+				f = s * e[i]
+				b = c * e[i]
+				r = math.Hypot(f, g)
+				e[i+1] = r
+				if r == 0.0 {
+					d[i+1] -= p
+					e[m] = 0.0
+					break
+				}
+				s = f / r
+				c = g / r
+				g = d[i+1] - p
+				r = (d[i]-g)*s + 2.0*c*b
+				p = s * r
+				d[i+1] = g + p
+				g = c*r - b
+				f = z[i+1]
+				z[i+1] = s*z[i] + c*f
+				z[i] = c*z[i] - s*f
+			}
+			if r == 0.0 && i >= l {
+				continue
+			}
+			d[l] -= p
+			e[l] = g
+			e[m] = 0.0
+		}
+	}
+	return
+}
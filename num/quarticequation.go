@@ -0,0 +1,127 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package num
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/utl"
+)
+
+// EqQuarticSolveReal solves a quartic equation, ignoring the complex answers.
+//  The equation is specified by:
+//   x⁴ + a x³ + b x² + c x + d = 0
+//  Notes:
+//   1) Ferrari's method is employed: the depressed quartic is factorised into two quadratics
+//      using one positive root of the resolvent cubic (computed with EqCubicSolveReal)
+//   2) the roots are polished with a few Newton iterations and sorted in ascending order
+//  Output:
+//   x[i] -- roots
+//   nx   -- number of real roots: 0, 1, 2, 3 or 4 (repeated roots are counted once)
+func EqQuarticSolveReal(a, b, c, d float64) (x1, x2, x3, x4 float64, nx int) {
+
+	// tolerance
+	ϵ := 1e-14
+
+	// depressed quartic: y⁴ + p y² + q y + r = 0 with x = y - a/4
+	aa := a * a
+	p := b - 3.0*aa/8.0
+	q := c - a*b/2.0 + aa*a/8.0
+	r := d - a*c/4.0 + aa*b/16.0 - 3.0*aa*aa/256.0
+
+	// candidate roots
+	var y []float64
+	addQuadratic := func(B, C float64) { // roots of y² + B y + C = 0
+		Δ := B*B - 4.0*C
+		if Δ < 0 && Δ > -ϵ*max(1.0, B*B) {
+			Δ = 0
+		}
+		if Δ < 0 {
+			return
+		}
+		sq := math.Sqrt(Δ)
+		y = append(y, (-B-sq)/2.0, (-B+sq)/2.0)
+	}
+
+	// biquadratic equation: z² + p z + r = 0 with z = y²
+	if math.Abs(q) < ϵ {
+		Δ := p*p - 4.0*r
+		if Δ < 0 && Δ > -ϵ*max(1.0, p*p) {
+			Δ = 0
+		}
+		if Δ >= 0 {
+			sq := math.Sqrt(Δ)
+			for _, z := range []float64{(-p - sq) / 2.0, (-p + sq) / 2.0} {
+				if z < 0 && z > -ϵ {
+					z = 0
+				}
+				if z >= 0 {
+					y = append(y, -math.Sqrt(z), math.Sqrt(z))
+				}
+			}
+		}
+
+		// Ferrari's method
+	} else {
+
+		// resolvent cubic: m³ + p m² + (p²/4 - r) m - q²/8 = 0
+		m1, m2, m3, nm := EqCubicSolveReal(p, p*p/4.0-r, -q*q/8.0)
+		m := m1
+		if nm > 1 && m2 > m {
+			m = m2
+		}
+		if nm > 2 && m3 > m {
+			m = m3
+		}
+		if m > 0 {
+			sq2m := math.Sqrt(2.0 * m)
+			// y² ∓ √(2m) y + (p/2 + m ± q/(2√(2m))) = 0
+			addQuadratic(-sq2m, p/2.0+m+q/(2.0*sq2m))
+			addQuadratic(+sq2m, p/2.0+m-q/(2.0*sq2m))
+		}
+	}
+
+	// polish roots with Newton's method and remove duplicates
+	f := func(x float64) (fx, dfx float64) {
+		fx = (((x+a)*x+b)*x+c)*x + d
+		dfx = ((4.0*x+3.0*a)*x+2.0*b)*x + c
+		return
+	}
+	var roots []float64
+	for _, yi := range y {
+		x := yi - a/4.0
+		for it := 0; it < 3; it++ {
+			fx, dfx := f(x)
+			if fx == 0 || dfx == 0 {
+				break
+			}
+			xnew := x - fx/dfx
+			fnew, _ := f(xnew)
+			if math.Abs(fnew) >= math.Abs(fx) {
+				break
+			}
+			x = xnew
+		}
+		duplicate := false
+		for _, xr := range roots {
+			if math.Abs(x-xr) < 1e-10*max(1.0, math.Abs(x)) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			roots = append(roots, x)
+		}
+	}
+	utl.Qsort(roots)
+
+	// results
+	nx = len(roots)
+	xx := []*float64{&x1, &x2, &x3, &x4}
+	for i := 0; i < nx && i < 4; i++ {
+		*xx[i] = roots[i]
+	}
+	return
+}
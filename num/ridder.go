@@ -0,0 +1,131 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package num
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+)
+
+// Ridder implements Ridder's method for finding the roots of an equation
+//
+//  Ridder's method evaluates the function at the midpoint of the bracket and then applies an
+//  exponential factor to turn the function into a straight line, i.e. it is a powerful variant of
+//  the false position method. The convergence is quadratic and the root is always kept bracketed.
+//  See page 452 of [1].
+//
+//  Reference:
+//   [1] Press WH, Teukolsky SA, Vetterling WT, Fnannery BP (2007) Numerical Recipes: The Art of
+//       Scientific Computing. Third Edition. Cambridge University Press. 1235p.
+type Ridder struct {
+	MaxIt  int         // max iterations
+	Tol    float64     // tolerance
+	Ffcn   fun.Ss      // y = f(x) function
+	NFeval int         // number of calls to Ffcn (function evaluations)
+	It     int         // number of iterations from last call to Solve
+	Hist   RootHistory // history of iterations from last call to Solve
+}
+
+// Init initialises Ridder structure
+func (o *Ridder) Init(ffcn fun.Ss) {
+	o.MaxIt = 60
+	o.Tol = 1e-14
+	o.Ffcn = ffcn
+}
+
+// History returns the history of iterations from the last call to Solve
+func (o *Ridder) History() *RootHistory {
+	return &o.Hist
+}
+
+// Solve solves y(x) = 0 for x in [xa, xb] with f(xa) * f(xb) < 0
+func (o *Ridder) Solve(xa, xb float64, silent bool) (res float64, err error) {
+
+	// check input
+	o.Hist.Reset()
+	fl, fh, err := rootCheckBracket(o.Ffcn, xa, xb)
+	o.NFeval = 2
+	if err != nil {
+		return
+	}
+	if fl == 0 {
+		return xa, nil
+	}
+	if fh == 0 {
+		return xb, nil
+	}
+
+	// message
+	if !silent {
+		rootMsg(0, 0, 0, 0, o.Tol, true)
+	}
+
+	// solve
+	xl, xh := xa, xb
+	res = math.Inf(-1) // any highly unlikely value, to simplify logic below
+	var xm, fm, s, xnew, fnew, tolAct float64
+	for o.It = 0; o.It < o.MaxIt; o.It++ {
+
+		// midpoint
+		xm = 0.5 * (xl + xh)
+		fm, err = o.Ffcn(xm)
+		o.NFeval++
+		if err != nil {
+			return 0, chk.Err("f(%g) failed:\n%v", xm, err)
+		}
+		s = math.Sqrt(fm*fm - fl*fh)
+		if s == 0.0 {
+			return xm, nil
+		}
+
+		// updating formula
+		xnew = xm + (xm-xl)*sign(fl-fh)*fm/s
+		tolAct = 2.0*MACHEPS*math.Abs(xnew) + o.Tol/2.0
+		if math.Abs(xnew-res) <= tolAct {
+			return res, nil
+		}
+		res = xnew
+		fnew, err = o.Ffcn(res)
+		o.NFeval++
+		if err != nil {
+			return 0, chk.Err("f(%g) failed:\n%v", res, err)
+		}
+
+		// history and message
+		o.Hist.Append(res, fnew, math.Abs(xh-xl)/2.0)
+		if !silent {
+			rootMsg(o.It, res, fnew, math.Abs(xh-xl)/2.0, o.Tol, false)
+		}
+		if fnew == 0.0 {
+			return res, nil
+		}
+
+		// bookkeeping to keep the root bracketed on next iteration
+		if math.Copysign(fm, fnew) != fm {
+			xl, fl = xm, fm
+			xh, fh = res, fnew
+		} else if math.Copysign(fl, fnew) != fl {
+			xh, fh = res, fnew
+		} else if math.Copysign(fh, fnew) != fh {
+			xl, fl = res, fnew
+		} else {
+			return res, chk.Err("Ridder's method failed: the root is not bracketed anymore")
+		}
+		if math.Abs(xh-xl) <= tolAct {
+			return res, nil
+		}
+	}
+
+	// did not converge
+	return res, chk.Err("fail to converge after %d iterations", o.It)
+}
+
+// add solver to database //////////////////////////////////////////////////////////////////////////
+
+func init() {
+	rootSolverDB["ridder"] = func() RootSolver { return new(Ridder) }
+}
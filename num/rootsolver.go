@@ -0,0 +1,170 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package num
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+)
+
+// RootSolver defines the interface for (bracketing) root finding algorithms
+//
+//   Given y = f(x) with f(xa) * f(xb) < 0, find x in [xa, xb] such that f(x) = 0
+//
+type RootSolver interface {
+	Init(ffcn fun.Ss)                                           // initialises solver with the y = f(x) function
+	Solve(xa, xb float64, silent bool) (res float64, err error) // solves f(x) = 0 for x in [xa, xb]
+	History() *RootHistory                                      // returns the history of iterations from the last call to Solve
+}
+
+// rootSolverMaker defines a function that makes RootSolvers
+type rootSolverMaker func() RootSolver
+
+// rootSolverDB implements a database of RootSolver makers
+var rootSolverDB = make(map[string]rootSolverMaker)
+
+// NewRootSolver finds a RootSolver in database or panic
+//   kind -- "brent", "ridder", "illinois", "anderson", "itp", "newton" or "halley"
+func NewRootSolver(kind string) RootSolver {
+	if maker, ok := rootSolverDB[kind]; ok {
+		return maker()
+	}
+	chk.Panic("cannot find RootSolver named %q in database", kind)
+	return nil
+}
+
+// RootHistory holds the history of iterations of a root solver
+type RootHistory struct {
+	X   []float64 // approximations of the root
+	F   []float64 // f(x) at each approximation
+	Err []float64 // error estimate (e.g. half size of bracket or size of step)
+}
+
+// Reset clears history
+func (o *RootHistory) Reset() {
+	o.X = o.X[:0]
+	o.F = o.F[:0]
+	o.Err = o.Err[:0]
+}
+
+// Append appends new record to history
+func (o *RootHistory) Append(x, fx, err float64) {
+	o.X = append(o.X, x)
+	o.F = append(o.F, fx)
+	o.Err = append(o.Err, err)
+}
+
+// Len returns the number of records in history
+func (o *RootHistory) Len() int {
+	return len(o.X)
+}
+
+// BracketExpand expands the range [xa, xb] geometrically until a root of f(x) is bracketed
+//
+//  Based on zbrac of [1]; the bound with the smallest |f| is moved away by factor times the
+//  size of the current range.
+//
+//  Input:
+//   xa, xb -- initial guess of range; must have xa != xb
+//   factor -- expansion factor; use factor <= 0 for the default value 1.6
+//   maxIt  -- max number of iterations; use maxIt <= 0 for the default value 50
+//  Output:
+//   a, b   -- new range with f(a) * f(b) <= 0
+//   nfeval -- number of function evaluations
+//
+//  Reference:
+//   [1] Press WH, Teukolsky SA, Vetterling WT, Fnannery BP (2007) Numerical Recipes: The Art of
+//       Scientific Computing. Third Edition. Cambridge University Press. 1235p.
+func BracketExpand(ffcn fun.Ss, xa, xb, factor float64, maxIt int) (a, b float64, nfeval int, err error) {
+
+	// check
+	if xa == xb {
+		return xa, xb, 0, chk.Err("range must be non-empty: xa=%g, xb=%g", xa, xb)
+	}
+	if factor <= 0 {
+		factor = 1.6
+	}
+	if maxIt <= 0 {
+		maxIt = 50
+	}
+
+	// initial values
+	a, b = xa, xb
+	fa, err := ffcn(a)
+	if err != nil {
+		return
+	}
+	fb, err := ffcn(b)
+	if err != nil {
+		return
+	}
+	nfeval = 2
+
+	// expand
+	for it := 0; it < maxIt; it++ {
+		if fa*fb <= 0.0 {
+			return
+		}
+		if math.Abs(fa) < math.Abs(fb) {
+			a += factor * (a - b)
+			fa, err = ffcn(a)
+		} else {
+			b += factor * (b - a)
+			fb, err = ffcn(b)
+		}
+		nfeval++
+		if err != nil {
+			return
+		}
+	}
+	if fa*fb <= 0.0 {
+		return
+	}
+	return a, b, nfeval, chk.Err("cannot bracket root after %d iterations. a=%g, b=%g, fa=%g, fb=%g", maxIt, a, b, fa, fb)
+}
+
+// RootFind finds a root of f(x) using the bracketing solver named kind. If [xa, xb] does not
+// enclose a root, the range is expanded automatically by means of BracketExpand
+//   kind -- "brent", "ridder", "illinois", "anderson", "itp", "newton" or "halley"
+func RootFind(kind string, ffcn fun.Ss, xa, xb float64) (res float64, err error) {
+	a, b, _, err := BracketExpand(ffcn, xa, xb, 0, 0)
+	if err != nil {
+		return
+	}
+	solver := NewRootSolver(kind)
+	solver.Init(ffcn)
+	return solver.Solve(a, b, true)
+}
+
+// auxiliary ///////////////////////////////////////////////////////////////////////////////////////
+
+// rootCheckBracket evaluates f at the bounds and checks whether the root is bracketed
+func rootCheckBracket(ffcn fun.Ss, xa, xb float64) (fa, fb float64, err error) {
+	fa, err = ffcn(xa)
+	if err != nil {
+		return 0, 0, chk.Err("fa(%g) failed:\n%v", xa, err)
+	}
+	fb, err = ffcn(xb)
+	if err != nil {
+		return 0, 0, chk.Err("fb(%g) failed:\n%v", xb, err)
+	}
+	if fa*fb > 0 {
+		return 0, 0, chk.Err("root must be bracketed: xa=%g, xb=%g, fa=%g, fb=%g => fa * fb > 0", xa, xb, fa, fb)
+	}
+	return
+}
+
+// rootMsg prints information on iterations of root solvers
+func rootMsg(it int, x, fx, err, tol float64, first bool) {
+	if first {
+		io.Pf("%4s%23s%23s%23s\n", "it", "x", "f(x)", "err")
+		io.Pf("%50s%23.1e\n", "", tol)
+		return
+	}
+	io.Pf("%4d%23.15e%23.15e%23.15e\n", it, x, fx, err)
+}
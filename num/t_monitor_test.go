@@ -0,0 +1,120 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package num
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+func Test_monitor01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("monitor01. Brent: progress and cancellation")
+
+	f := func(x float64) (float64, error) { return x*x*x - 2.0*x - 5.0, nil }
+
+	// progress
+	var brent Brent
+	brent.Init(f)
+	var steps []float64
+	brent.Monitor.Callback = func(p utl.Progress) {
+		steps = append(steps, p.Step)
+	}
+	x, err := brent.SolveCtx(context.Background(), 2, 3, true)
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	io.Pforan("steps = %v\n", steps)
+	chk.Float64(tst, "x", 1e-14, x, 2.09455148154233)
+	chk.IntAssert(len(steps), brent.It)
+	if brent.Monitor.Ctx != nil {
+		tst.Errorf("context must be reset after SolveCtx\n")
+		return
+	}
+
+	// cancellation after 3 iterations
+	ctx, cancel := context.WithCancel(context.Background())
+	brent.Monitor.Callback = func(p utl.Progress) {
+		if p.It == 2 {
+			cancel()
+		}
+	}
+	_, err = brent.SolveCtx(ctx, 2, 3, true)
+	io.Pforan("err = %v\n", err)
+	if !utl.IsCancelled(err) {
+		tst.Errorf("Brent should have been cancelled\n")
+		return
+	}
+	chk.IntAssert(brent.It, 2)
+
+	// max iterations
+	brent.Monitor.Callback = nil
+	brent.MaxIt = 2
+	_, err = brent.Solve(2, 3, true)
+	if !utl.IsMaxIt(err) {
+		tst.Errorf("Brent should have failed with max iterations error\n")
+	}
+}
+
+func Test_monitor02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("monitor02. NlSolver: progress and cancellation")
+
+	ffcn := func(fx, x la.Vector) error {
+		fx[0] = 2.0*x[0] - x[1] - math.Exp(-x[0])
+		fx[1] = -x[0] + 2.0*x[1] - math.Exp(-x[1])
+		return nil
+	}
+	Jfcn := func(dfdx *la.Matrix, x la.Vector) error {
+		dfdx.Set(0, 0, 2.0+math.Exp(-x[0]))
+		dfdx.Set(0, 1, -1.0)
+		dfdx.Set(1, 0, -1.0)
+		dfdx.Set(1, 1, 2.0+math.Exp(-x[1]))
+		return nil
+	}
+
+	var nls NlSolver
+	nls.Init(2, ffcn, nil, Jfcn, true, false, map[string]float64{"atol": 1e-10, "rtol": 1e-10, "ftol": 10 * MACHEPS})
+	defer nls.Free()
+
+	// progress
+	var res []float64
+	nls.Monitor.Callback = func(p utl.Progress) {
+		res = append(res, p.Residual)
+	}
+	x := []float64{5.0, 5.0}
+	err := nls.SolveCtx(context.Background(), x, true)
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	io.Pforan("residuals = %v\n", res)
+	chk.Array(tst, "x", 1e-13, x, []float64{0.5671432904097838, 0.5671432904097838})
+	if len(res) < 2 || res[len(res)-1] > res[0] {
+		tst.Errorf("residuals must decrease\n")
+		return
+	}
+
+	// cancelled context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	x = []float64{5.0, 5.0}
+	err = nls.SolveCtx(ctx, x, true)
+	io.Pforan("err = %v\n", err)
+	if !utl.IsCancelled(err) {
+		tst.Errorf("NlSolver should have been cancelled\n")
+		return
+	}
+	chk.IntAssert(nls.It, 0)
+}
@@ -0,0 +1,178 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package num
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_quarticeq01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("quarticeq01. (x-1)(x-2)(x+3)(x-4)")
+
+	// x⁴ - 4x³ - 7x² + 34x - 24
+	x1, x2, x3, x4, nx := EqQuarticSolveReal(-4, -7, 34, -24)
+	io.Pfcyan("nx=%v\n", nx)
+	io.Pfcyan("x1=%v x2=%v x3=%v x4=%v\n", x1, x2, x3, x4)
+	chk.IntAssert(nx, 4)
+	chk.Float64(tst, "x1", 1e-14, x1, -3)
+	chk.Float64(tst, "x2", 1e-14, x2, 1)
+	chk.Float64(tst, "x3", 1e-14, x3, 2)
+	chk.Float64(tst, "x4", 1e-14, x4, 4)
+}
+
+func Test_quarticeq02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("quarticeq02. biquadratic and complex roots")
+
+	// x⁴ - 5x² + 4 = (x²-1)(x²-4)
+	x1, x2, x3, x4, nx := EqQuarticSolveReal(0, -5, 0, 4)
+	io.Pfcyan("x1=%v x2=%v x3=%v x4=%v\n", x1, x2, x3, x4)
+	chk.IntAssert(nx, 4)
+	chk.Array(tst, "x", 1e-15, []float64{x1, x2, x3, x4}, []float64{-2, -1, 1, 2})
+
+	// (x² + 1)(x - 1)(x - 3) = x⁴ - 4x³ + 4x² - 4x + 3
+	x1, x2, _, _, nx = EqQuarticSolveReal(-4, 4, -4, 3)
+	io.Pfcyan("x1=%v x2=%v\n", x1, x2)
+	chk.IntAssert(nx, 2)
+	chk.Float64(tst, "x1", 1e-14, x1, 1)
+	chk.Float64(tst, "x2", 1e-14, x2, 3)
+
+	// (x² + 1)(x² + 4) => no real roots
+	_, _, _, _, nx = EqQuarticSolveReal(0, 5, 0, 4)
+	chk.IntAssert(nx, 0)
+
+	// (x - 2)²(x + 1)(x - 5) = x⁴ - 8x³ + 15x² + 4x - 20 => repeated root
+	x1, x2, x3, _, nx = EqQuarticSolveReal(-8, 15, 4, -20)
+	io.Pfcyan("x1=%v x2=%v x3=%v\n", x1, x2, x3)
+	chk.IntAssert(nx, 3)
+	chk.Float64(tst, "x1", 1e-14, x1, -1)
+	chk.Float64(tst, "x2", 1e-7, x2, 2)
+	chk.Float64(tst, "x3", 1e-14, x3, 5)
+}
+
+func Test_polyroots01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("polyroots01. Aberth-Ehrlich method")
+
+	// (x-1)(x-2)(x-3)(x-4)(x-5) = x⁵ - 15x⁴ + 85x³ - 225x² + 274x - 120
+	roots, err := PolyRoots([]float64{-120, 274, -225, 85, -15, 1})
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	io.Pforan("roots = %v\n", roots)
+	chk.IntAssert(len(roots), 5)
+	for i := 0; i < 5; i++ {
+		chk.Complex128(tst, io.Sf("root%d", i), 1e-12, roots[i], complex(float64(i+1), 0))
+	}
+
+	// x⁴ + 1 => exp(i⋅π/4⋅(2k+1))
+	roots, err = PolyRoots([]float64{1, 0, 0, 0, 1})
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	io.Pforan("roots = %v\n", roots)
+	chk.IntAssert(len(roots), 4)
+	for _, z := range roots {
+		chk.Float64(tst, "|z⁴+1|", 1e-14, cmplx.Abs(z*z*z*z+1), 0)
+		chk.Float64(tst, "|z|", 1e-15, cmplx.Abs(z), 1)
+	}
+
+	// 2x³ + 0x² + 0x + 0 + leading zeros => triple zero root
+	roots, err = PolyRoots([]float64{0, 0, 0, 2, 0, 0})
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	chk.IntAssert(len(roots), 3)
+	for _, z := range roots {
+		chk.Complex128(tst, "zero", 1e-17, z, 0)
+	}
+
+	// x² + 2x + 5 => -1 ± 2i
+	roots, err = PolyRoots([]float64{5, 2, 1})
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	io.Pforan("roots = %v\n", roots)
+	chk.Complex128(tst, "z0", 1e-15, roots[0], complex(-1, -2))
+	chk.Complex128(tst, "z1", 1e-15, roots[1], complex(-1, +2))
+
+	// compare with cubic solver: x³ - 3x² - 144x + 432
+	x1, x2, x3, _ := EqCubicSolveReal(-3, -144, 432)
+	roots, err = PolyRoots([]float64{432, -144, -3, 1})
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	chk.Array(tst, "cubic", 1e-13, []float64{real(roots[0]), real(roots[1]), real(roots[2])}, []float64{x1, x3, x2})
+	chk.Float64(tst, "max(imag)", 1e-17, math.Max(math.Abs(imag(roots[0])), math.Abs(imag(roots[2]))), 0)
+
+	// must fail
+	_, err = PolyRoots([]float64{0, 0})
+	if err == nil {
+		tst.Errorf("PolyRoots should have failed\n")
+	}
+}
+
+func Test_polyroots02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("polyroots02. Aberth-Ehrlich method: p'(z) = 0 and last iteration")
+
+	// x² - 1 starting at the critical point z = 0 => the correction is NaN and z must be perturbed
+	a := []float64{-1, 0, 1}
+	z := []complex128{0, 0.5i}
+	nit, err := aberth(a, z, 100)
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	io.Pforan("nit = %d  z = %v\n", nit, z)
+	if cmplx.IsNaN(z[0]) || cmplx.IsNaN(z[1]) {
+		tst.Errorf("roots must not be NaN\n")
+		return
+	}
+	chk.Float64(tst, "|z0 z1|", 1e-15, cmplx.Abs(z[0]*z[1]), 1)
+	chk.Float64(tst, "|z0 + z1|", 1e-15, cmplx.Abs(z[0]+z[1]), 0)
+
+	// all roots converge in the last allowed iteration
+	a = []float64{432, -144, -3, 1}
+	z0 := []complex128{1 + 1i, -1 + 1i, -1 - 1i}
+	z = append([]complex128{}, z0...)
+	nit, err = aberth(a, z, 500)
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	z = append([]complex128{}, z0...)
+	_, err = aberth(a, z, nit)
+	if err != nil {
+		tst.Errorf("aberth should have converged with maxIt = nit = %d:\n%v\n", nit, err)
+		return
+	}
+	z = append([]complex128{}, z0...)
+	_, err = aberth(a, z, nit-1)
+	if err == nil {
+		tst.Errorf("aberth should have failed with maxIt = nit - 1 = %d\n", nit-1)
+	}
+
+	// non-finite coefficients
+	_, err = PolyRoots([]float64{1, math.NaN(), 1})
+	if err == nil {
+		tst.Errorf("PolyRoots should have failed with NaN coefficient\n")
+	}
+}
@@ -45,3 +45,99 @@ func Test_QuadElem01(tst *testing.T) {
 	io.Pforan("A  = %v\n", A)
 	chk.Float64(tst, "A", 1e-11, A, Acor)
 }
+
+func Test_QuadElem02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("QuadElem02. Romberg, tanh-sinh and Clenshaw-Curtis")
+
+	y := func(x float64) (res float64, err error) {
+		res = math.Sqrt(1.0 + math.Pow(math.Sin(x), 3.0))
+		return
+	}
+	Acor := 1.08268158558
+
+	// all rules through the interface
+	rules := map[string]QuadElementary{
+		"trapz":    new(ElementaryTrapz),
+		"simpson":  new(ElementarySimpson),
+		"romberg":  new(ElementaryRomberg),
+		"tanhsinh": new(ElementaryTanhSinh),
+		"clenshaw": new(ElementaryClenshawCurtis),
+	}
+	for name, Q := range rules {
+		Q.Init(y, 0, 1, 1e-11)
+		A, err := Q.Integrate()
+		if err != nil {
+			tst.Errorf("%s failed: %v\n", name, err)
+			return
+		}
+		io.Pforan("%-8s: A  = %v\n", name, A)
+		chk.Float64(tst, name, 1e-11, A, Acor)
+	}
+
+	// smooth integrand: exp(x) on [-1, 2]
+	g := func(x float64) (float64, error) { return math.Exp(x), nil }
+	gcor := math.Exp(2) - math.Exp(-1)
+	for _, Q := range []QuadElementary{new(ElementaryRomberg), new(ElementaryTanhSinh), new(ElementaryClenshawCurtis)} {
+		Q.Init(g, -1, 2, 1e-14)
+		A, err := Q.Integrate()
+		if err != nil {
+			tst.Errorf("%v\n", err)
+			return
+		}
+		chk.Float64(tst, "exp(x)", 1e-13, A, gcor)
+	}
+
+	// Romberg and Clenshaw-Curtis are exact for polynomials after a few stages
+	p := func(x float64) (float64, error) { return 3.0*x*x - 2.0*x + 1.0, nil }
+	var R ElementaryRomberg
+	R.Init(p, 0, 2, 1e-15)
+	R.Next()
+	A, _ := R.Next()
+	chk.Float64(tst, "Romberg: 3x²-2x+1", 1e-15, A, 6)
+	var C ElementaryClenshawCurtis
+	C.Init(p, 0, 2, 1e-15)
+	A, _ = C.Next()
+	chk.Float64(tst, "Clenshaw-Curtis: 3x²-2x+1", 1e-15, A, 6)
+}
+
+func Test_QuadElem03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("QuadElem03. tanh-sinh with end-point singularities")
+
+	// ∫ 1/√x dx from 0 to 1 = 2
+	f := func(x float64) (float64, error) { return 1.0 / math.Sqrt(x), nil }
+	var T ElementaryTanhSinh
+	T.Init(f, 0, 1, 1e-12)
+	A, err := T.Integrate()
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	io.Pforan("A  = %v  (n=%d)\n", A, T.n)
+	chk.Float64(tst, "1/√x", 1e-12, A, 2)
+
+	// ∫ ln(x) dx from 0 to 1 = -1
+	g := func(x float64) (float64, error) { return math.Log(x), nil }
+	T.Init(g, 0, 1, 1e-12)
+	A, err = T.Integrate()
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	io.Pforan("A  = %v  (n=%d)\n", A, T.n)
+	chk.Float64(tst, "ln(x)", 1e-12, A, -1)
+
+	// ∫ ln(x)/√x dx from 0 to 2 = 2√2⋅ln(2) - 4√2
+	h := func(x float64) (float64, error) { return math.Log(x) / math.Sqrt(x), nil }
+	T.Init(h, 0, 2, 1e-12)
+	A, err = T.Integrate()
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	io.Pforan("A  = %v  (n=%d)\n", A, T.n)
+	chk.Float64(tst, "ln(x)/√x", 1e-11, A, 2.0*math.Sqrt2*math.Ln2-4.0*math.Sqrt2)
+}
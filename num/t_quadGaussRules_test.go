@@ -0,0 +1,186 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package num
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_gaussRules01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("gaussRules01. Golub-Welsch versus Gauss-Legendre and Gauss-Jacobi")
+
+	for _, n := range []int{1, 2, 5, 10, 20, 40} {
+		xL, wL := GaussLegendreXW(-1, 1, n)
+		x, w := GaussRuleXW(fun.OpolyLegendreKind, n, 0, 0)
+		chk.Array(tst, io.Sf("Legendre: x(n=%d)", n), 1e-14, x, xL)
+		chk.Array(tst, io.Sf("Legendre: w(n=%d)", n), 1e-14, w, wL)
+	}
+
+	for _, ab := range [][]float64{{0.5, 0.5}, {1.5, -0.5}, {2, 3}, {-0.5, -0.5}} {
+		α, β := ab[0], ab[1]
+		xJ, wJ := GaussJacobiXW(α, β, 10)
+		x, w := GaussRuleXW(fun.OpolyJacobiKind, 10, α, β)
+		chk.Array(tst, io.Sf("Jacobi(%g,%g): x", α, β), 1e-14, x, xJ)
+		chk.Array(tst, io.Sf("Jacobi(%g,%g): w", α, β), 1e-13, w, wJ)
+	}
+}
+
+func Test_gaussRules02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("gaussRules02. nodes are the roots of the orthogonal polynomials")
+
+	n := 6
+	for _, kind := range []io.Enum{fun.OpolyLegendreKind, fun.OpolyHermiteKind, fun.OpolyLaguerreKind, fun.OpolyCheby1Kind, fun.OpolyCheby2Kind} {
+		α := 0.0
+		if kind == fun.OpolyLaguerreKind {
+			α = 0.5
+		}
+		op := fun.NewGeneralOrthoPoly(kind, n, α, 0)
+		x, _ := GaussRuleXW(kind, n, α, 0)
+		io.Pforan("%-12s x = %v\n", kind, x)
+		for i := 0; i < n; i++ {
+			chk.Float64(tst, io.Sf("%s: P(x%d)", kind, i), 1e-10, op.P(n, x[i]), 0)
+		}
+	}
+}
+
+func Test_gaussRules03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("gaussRules03. high-order accuracy")
+
+	// Gauss-Hermite: ∫ exp(-x²) x²ᵏ dx = Γ(k+½); exact up to degree 2n-1
+	n := 20
+	for k := 0; k < n; k++ {
+		K := float64(k)
+		res, err := QuadGaussHermite(func(x float64) (float64, error) { return math.Pow(x, 2*K), nil }, n)
+		if err != nil {
+			tst.Errorf("%v\n", err)
+			return
+		}
+		chk.Float64(tst, io.Sf("Hermite: x^%d", 2*k), 1e-13*math.Gamma(K+0.5), res, math.Gamma(K+0.5))
+	}
+	res, _ := QuadGaussHermite(func(x float64) (float64, error) { return math.Cos(x), nil }, n)
+	chk.Float64(tst, "Hermite: cos(x)", 1e-14, res, math.Sqrt(math.Pi)*math.Exp(-0.25))
+
+	// generalized Gauss-Laguerre: ∫ x^α exp(-x) xᵏ dx = Γ(k+α+1)
+	α := -0.5
+	for k := 0; k < 2*n; k++ {
+		K := float64(k)
+		res, err := QuadGaussLaguerre(func(x float64) (float64, error) { return math.Pow(x, K), nil }, n, α)
+		if err != nil {
+			tst.Errorf("%v\n", err)
+			return
+		}
+		cor := math.Gamma(K + α + 1)
+		chk.Float64(tst, io.Sf("Laguerre: x^%d", k), 1e-12*cor, res, cor)
+	}
+
+	// Gauss-Chebyshev: ∫ cos(x) / √(1-x²) dx = π J0(1) and ∫ x² √(1-x²) dx = π/8
+	res, _ = QuadGaussChebyshev1(func(x float64) (float64, error) { return math.Cos(x), nil }, 10)
+	chk.Float64(tst, "Chebyshev1: cos(x)", 1e-15, res, math.Pi*math.J0(1))
+	res, _ = QuadGaussChebyshev2(func(x float64) (float64, error) { return x * x, nil }, 2)
+	chk.Float64(tst, "Chebyshev2: x²", 1e-15, res, math.Pi/8.0)
+	x, w := GaussChebyshev1XW(5)
+	for i := 0; i < 5; i++ {
+		chk.Float64(tst, "Chebyshev1: x", 1e-15, x[i], -math.Cos((2.0*float64(i)+1.0)*math.Pi/10.0))
+		chk.Float64(tst, "Chebyshev1: w", 1e-15, w[i], math.Pi/5.0)
+	}
+}
+
+func Test_gaussRules04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("gaussRules04. Gauss-Radau and Gauss-Lobatto")
+
+	// Lobatto with 5 points: ±1, ±√(3/7), 0
+	x, w := GaussLobattoXW(5)
+	io.Pforan("x = %v\n", x)
+	io.Pforan("w = %v\n", w)
+	chk.Array(tst, "Lobatto: x", 1e-15, x, []float64{-1, -math.Sqrt(3.0 / 7.0), 0, math.Sqrt(3.0 / 7.0), 1})
+	chk.Array(tst, "Lobatto: w", 1e-14, w, []float64{0.1, 49.0 / 90.0, 32.0 / 45.0, 49.0 / 90.0, 0.1})
+
+	// Radau with 3 points: -1, (1 ∓ √6)/5
+	x, w = GaussRadauXW(3, false)
+	io.Pforan("x = %v\n", x)
+	io.Pforan("w = %v\n", w)
+	chk.Array(tst, "Radau: x", 1e-15, x, []float64{-1, (1 - math.Sqrt(6)) / 5, (1 + math.Sqrt(6)) / 5})
+	chk.Array(tst, "Radau: w", 1e-15, w, []float64{2.0 / 9.0, (16 + math.Sqrt(6)) / 18, (16 - math.Sqrt(6)) / 18})
+	x, _ = GaussRadauXW(3, true)
+	chk.Float64(tst, "Radau: right", 1e-17, x[2], 1)
+
+	// degree of exactness
+	n := 8
+	for k := 0; k <= 2*n-3; k++ {
+		K := float64(k)
+		f := func(x float64) (float64, error) { return math.Pow(x, K), nil }
+		cor := 0.0
+		if k%2 == 0 {
+			cor = 2.0 / (K + 1.0)
+		}
+		res, err := QuadGaussLobatto(-1, 1, f, n)
+		if err != nil {
+			tst.Errorf("%v\n", err)
+			return
+		}
+		chk.Float64(tst, io.Sf("Lobatto: x^%d", k), 1e-14, res, cor)
+		x, w = GaussRadauXW(n, false)
+		res, _ = quadGaussSum(f, x, w, 1, 0)
+		chk.Float64(tst, io.Sf("Radau: x^%d", k), 1e-14, res, cor)
+	}
+	res, _ := QuadGaussLobatto(0, math.Pi, func(x float64) (float64, error) { return math.Sin(x), nil }, 12)
+	chk.Float64(tst, "Lobatto: sin(x)", 1e-14, res, 2)
+}
+
+func Test_gaussRules05(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("gaussRules05. rules from moments")
+
+	// Legendre weight on [0, 1]: mom[k] = 1/(k+1)
+	n := 6
+	mom := make([]float64, 2*n)
+	for k := 0; k < 2*n; k++ {
+		mom[k] = 1.0 / float64(k+1)
+	}
+	x, w, err := GaussMomentsXW(mom)
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	xL, wL := GaussLegendreXW(0, 1, n)
+	chk.Array(tst, "x", 1e-10, x, xL)
+	chk.Array(tst, "w", 1e-10, w, wL)
+
+	// w(x) = -ln(x) on [0, 1]: mom[k] = 1/(k+1)²
+	n = 4
+	mom = make([]float64, 2*n)
+	for k := 0; k < 2*n; k++ {
+		mom[k] = 1.0 / float64((k+1)*(k+1))
+	}
+	x, w, err = GaussMomentsXW(mom)
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	io.Pforan("x = %v\n", x)
+	io.Pforan("w = %v\n", w)
+	res, _ := quadGaussSum(func(x float64) (float64, error) { return math.Pow(x, 7), nil }, x, w, 1, 0)
+	chk.Float64(tst, "∫ -ln(x) x⁷ dx", 1e-12, res, 1.0/64.0)
+
+	// invalid moments
+	_, _, err = GaussMomentsXW([]float64{1, 0, -1, 0})
+	if err == nil {
+		tst.Errorf("GaussMomentsXW should have failed\n")
+	}
+}
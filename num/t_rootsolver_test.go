@@ -0,0 +1,209 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package num
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_rootsol01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("rootsol01. all bracketing root solvers")
+
+	problems := []struct {
+		name   string
+		f      func(x float64) (float64, error)
+		df     func(x float64) (float64, error)
+		xa, xb float64
+		xcor   float64
+	}{
+		{"x³ - 2x - 5",
+			func(x float64) (float64, error) { return x*x*x - 2.0*x - 5.0, nil },
+			func(x float64) (float64, error) { return 3.0*x*x - 2.0, nil },
+			2, 3, 2.09455148154233},
+		{"cos(x) - x",
+			func(x float64) (float64, error) { return math.Cos(x) - x, nil },
+			func(x float64) (float64, error) { return -math.Sin(x) - 1.0, nil },
+			0, 1, 0.7390851332151607},
+		{"exp(x) - 10",
+			func(x float64) (float64, error) { return math.Exp(x) - 10.0, nil },
+			func(x float64) (float64, error) { return math.Exp(x), nil },
+			0, 5, math.Log(10.0)},
+		{"x³ - 0.165x² + 3.993e-4",
+			func(x float64) (float64, error) { return math.Pow(x, 3.0) - 0.165*math.Pow(x, 2.0) + 3.993e-4, nil },
+			func(x float64) (float64, error) { return 3.0*x*x - 2.0*0.165*x, nil },
+			0, 0.11, 0.0623775815137495},
+	}
+
+	for _, kind := range []string{"brent", "ridder", "illinois", "anderson", "itp", "newton", "halley"} {
+		io.Pfyel("\n%s\n", kind)
+		for _, p := range problems {
+			solver := NewRootSolver(kind)
+			solver.Init(p.f)
+			x, err := solver.Solve(p.xa, p.xb, !chk.Verbose)
+			if err != nil {
+				tst.Errorf("%s failed on %s:\n%v\n", kind, p.name, err)
+				return
+			}
+			nit := solver.History().Len()
+			io.Pforan("%-25s x = %23.15e  nit = %d\n", p.name, x, nit)
+			chk.Float64(tst, kind+": "+p.name, 1e-13, x, p.xcor)
+			if nit < 1 {
+				tst.Errorf("%s: history must not be empty\n", kind)
+				return
+			}
+		}
+	}
+
+	// analytical derivatives
+	for _, p := range problems {
+		var newton NewtonSafe
+		newton.Init(p.f)
+		newton.Dfcn = p.df
+		x, err := newton.Solve(p.xa, p.xb, true)
+		if err != nil {
+			tst.Errorf("%v\n", err)
+			return
+		}
+		chk.Float64(tst, "newton(analytical): "+p.name, 1e-13, x, p.xcor)
+		var halley Halley
+		halley.Init(p.f)
+		halley.Dfcn = p.df
+		x, err = halley.Solve(p.xa, p.xb, true)
+		if err != nil {
+			tst.Errorf("%v\n", err)
+			return
+		}
+		chk.Float64(tst, "halley(numerical f''): "+p.name, 1e-13, x, p.xcor)
+	}
+}
+
+func Test_rootsol02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("rootsol02. Illinois versus Anderson-Björck and ITP")
+
+	// difficult function for plain regula falsi
+	f := func(x float64) (float64, error) { return math.Pow(x, 10) - 1.0, nil }
+
+	var ill, and Illinois
+	ill.Init(f)
+	and.Init(f)
+	and.Anderson = true
+	x1, err := ill.Solve(0, 1.3, !chk.Verbose)
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	x2, err := and.Solve(0, 1.3, !chk.Verbose)
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	io.Pforan("illinois: nfeval = %d\n", ill.NFeval)
+	io.Pforan("anderson: nfeval = %d\n", and.NFeval)
+	chk.Float64(tst, "illinois", 1e-14, x1, 1)
+	chk.Float64(tst, "anderson", 1e-14, x2, 1)
+
+	// ITP never takes more than n½ + n0 iterations
+	var itp ITP
+	itp.Init(f)
+	itp.Tol = 1e-10
+	x3, err := itp.Solve(0, 1.3, !chk.Verbose)
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	nhalf := int(math.Ceil(math.Log2(1.3 / (2.0 * itp.Tol))))
+	io.Pforan("itp: nit = %d  (n½ + n0 = %d)\n", itp.It, nhalf+itp.N0)
+	chk.Float64(tst, "itp", 1e-10, x3, 1)
+	if itp.It > nhalf+itp.N0 {
+		tst.Errorf("ITP took more iterations than n½ + n0\n")
+		return
+	}
+
+	// bracket must be checked
+	_, err = itp.Solve(2, 3, true)
+	if err == nil {
+		tst.Errorf("ITP should have failed with non-bracketed root\n")
+	}
+}
+
+func Test_rootsol03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("rootsol03. bracket expansion")
+
+	f := func(x float64) (float64, error) { return x*x*x - 2.0*x - 5.0, nil }
+
+	a, b, nfeval, err := BracketExpand(f, 10, 11, 0, 0)
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	fa, _ := f(a)
+	fb, _ := f(b)
+	io.Pforan("a = %v  b = %v  nfeval = %d\n", a, b, nfeval)
+	if fa*fb > 0 {
+		tst.Errorf("root is not bracketed: f(a)=%g f(b)=%g\n", fa, fb)
+		return
+	}
+
+	for _, kind := range []string{"brent", "ridder", "anderson", "itp", "halley"} {
+		x, err := RootFind(kind, f, 10, 11)
+		if err != nil {
+			tst.Errorf("%v\n", err)
+			return
+		}
+		chk.Float64(tst, "RootFind: "+kind, 1e-13, x, 2.09455148154233)
+	}
+
+	// function without roots
+	g := func(x float64) (float64, error) { return x*x + 1.0, nil }
+	_, _, _, err = BracketExpand(g, -1, 1, 0, 10)
+	if err == nil {
+		tst.Errorf("BracketExpand should have failed\n")
+	}
+}
+
+func Test_rootsol04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("rootsol04. roots at the bounds and tiny f(a) * f(b)")
+
+	problems := []struct {
+		name   string
+		f      func(x float64) (float64, error)
+		xa, xb float64
+		xcor   float64
+	}{
+		{"x - 1 with root at xa",
+			func(x float64) (float64, error) { return x - 1.0, nil },
+			1, 2, 1},
+		{"x - 1 with root at xb",
+			func(x float64) (float64, error) { return x - 1.0, nil },
+			0, 1, 1},
+		{"1e-9 (x - 0.5)",
+			func(x float64) (float64, error) { return 1e-9 * (x - 0.5), nil },
+			0, 1, 0.5},
+	}
+
+	for _, kind := range []string{"brent", "ridder", "illinois", "anderson", "itp", "newton", "halley"} {
+		for _, p := range problems {
+			x, err := RootFind(kind, p.f, p.xa, p.xb)
+			if err != nil {
+				tst.Errorf("%s failed on %s:\n%v\n", kind, p.name, err)
+				return
+			}
+			io.Pforan("%-9s %-23s x = %v\n", kind, p.name, x)
+			chk.Float64(tst, kind+": "+p.name, 1e-13, x, p.xcor)
+		}
+	}
+}
@@ -0,0 +1,584 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/num"
+	"github.com/cpmech/gosl/utl"
+)
+
+// bdfMaxIt is the max number of simplified Newton iterations in Bdf
+const bdfMaxIt = 4
+
+// Bdf implements the variable-step, variable-order (1 to MaxOrd) backward differentiation
+// formulae (BDF) or the numerical differentiation formulae (NDF) in quasi-constant step size
+// form, with backward differences, as described in [3].
+//  Notes:
+//   1) the order is reset to 1 at the beginning of the integration and after each event
+//   2) the Jacobian is only recomputed when the simplified Newton iterations converge too slowly;
+//      the iteration matrix is refactorised only when the step size or the order change
+type Bdf struct {
+
+	// coefficients
+	ndf     bool      // use NDF coefficients instead of BDF ones
+	kappa   []float64 // κ[k-1] coefficients of NDF (zero for BDF)
+	gamma   []float64 // γ[k-1] = Σ 1/j, j = 1...k
+	invGa   []float64 // invGa[k-1] = 1 / ((1 - κ) γ)
+	erconst []float64 // erconst[k-1] = κ γ + 1 / (k + 1)
+
+	// state
+	k       int         // current order
+	kdns    int         // order of the last accepted step (for dense output)
+	maxk    int         // max order
+	hcur    float64     // step size of the backward differences
+	hinvGak float64     // h * invGa[k-1] corresponding to the current factorisation
+	nconhk  int         // number of steps taken with the current h and k
+	nfails  int         // number of failures of the current step (error test)
+	err     float64     // error estimate of the last step
+	hopt    float64     // new step size selected after Accept
+	rate    float64     // convergence rate of the Newton iterations
+	havrate bool        // rate is available
+	jcur    bool        // the Jacobian is current (computed at the beginning of this step)
+	newJ    bool        // the Jacobian must be computed
+	newFact bool        // the iteration matrix must be refactorised
+	lsInit  bool        // the linear solver has been initialised
+	dif     []la.Vector // backward differences [maxk+2][ndim]
+	tmp     []la.Vector // workspace to change the step size [maxk][ndim]
+	difkp1  la.Vector   // difference of order k+1 of the new y
+	psi     la.Vector   // constant term in the corrector equation
+	pred    la.Vector   // predicted y
+	ynew    la.Vector   // new y
+	rhs     la.Vector   // right-hand side of the linear system
+	del     la.Vector   // correction
+
+	// sensitivities (see SensStep)
+	sdif  [][]la.Vector // backward differences of the sensitivities [npar][maxk+2][ndim]
+	shcur float64       // step size of the backward differences of the sensitivities
+}
+
+// Init initialises structure
+func (o *Bdf) Init(distr bool) (err error) {
+	o.kappa = make([]float64, 5)
+	if o.ndf {
+		o.kappa = []float64{-0.1850, -1.0 / 9.0, -0.0823, -0.0415, 0}
+	}
+	o.gamma = make([]float64, 6)
+	o.invGa = make([]float64, 5)
+	o.erconst = make([]float64, 5)
+	var sum float64
+	for j := 0; j < 6; j++ {
+		sum += 1.0 / float64(j+1)
+		o.gamma[j] = sum
+	}
+	for j := 0; j < 5; j++ {
+		o.invGa[j] = 1.0 / ((1.0 - o.kappa[j]) * o.gamma[j])
+		o.erconst[j] = o.kappa[j]*o.gamma[j] + 1.0/float64(j+2)
+	}
+	return nil
+}
+
+// Nstages returns the number of stages
+func (o *Bdf) Nstages() int {
+	return 1
+}
+
+// Accept accepts update
+func (o *Bdf) Accept(sol *Solver, y la.Vector) {
+
+	// update differences
+	k := o.k
+	updateDif(o.dif, o.difkp1, k)
+
+	// update y
+	y.Apply(1, o.ynew)
+
+	// flags
+	o.kdns = k
+	o.nfails = 0
+	o.jcur = false
+	o.nconhk = utl.Imin(o.nconhk+1, o.maxk+2)
+
+	// select the next order and step size
+	h := sol.h
+	o.hopt = h
+	if o.nconhk < k+2 {
+		return
+	}
+	hopt := stepFromErr(h, 1.2, o.err, k+1)
+	kopt := k
+	if k > 1 {
+		errkm1 := o.norm(sol, o.dif[k-1]) * o.erconst[k-2]
+		hkm1 := stepFromErr(h, 1.3, errkm1, k)
+		if hkm1 > hopt {
+			hopt = min(h, hkm1) // do not increase the step size when decreasing the order
+			kopt = k - 1
+		}
+	}
+	if k < o.maxk {
+		errkp1 := o.norm(sol, o.dif[k+1]) * o.erconst[k]
+		hkp1 := stepFromErr(h, 1.4, errkp1, k+2)
+		if hkp1 > hopt {
+			hopt = hkp1
+			kopt = k + 1
+		}
+	}
+	if kopt != k {
+		o.k = kopt
+		o.nconhk = 0
+	}
+	o.hopt = max(h, hopt)
+}
+
+// NextH returns the step size selected by Bdf after an accepted or a rejected step
+func (o *Bdf) NextH(sol *Solver, accepted bool) (hnew float64) {
+	if accepted {
+		return o.hopt
+	}
+	o.nfails++
+	if o.nfails == 1 {
+		return sol.h * max(0.1, 0.833*math.Pow(1.0/o.err, 1.0/float64(o.k+1)))
+	}
+	return 0.5 * sol.h
+}
+
+// Step steps update
+func (o *Bdf) Step(sol *Solver, y0 la.Vector, x0 float64) (rerr float64, err error) {
+
+	// allocate and initialise
+	if sol.doinit {
+		if sol.MaxOrd < 1 || sol.MaxOrd > 5 {
+			err = chk.Err("the max order of BDF/NDF must be in [1, 5]. MaxOrd = %d is invalid\n", sol.MaxOrd)
+			return
+		}
+		o.maxk = sol.MaxOrd
+		if len(o.pred) != sol.ndim {
+			o.dif = make([]la.Vector, 7)
+			o.tmp = make([]la.Vector, 5)
+			for j := 0; j < 7; j++ {
+				o.dif[j] = la.NewVector(sol.ndim)
+				if j < 5 {
+					o.tmp[j] = la.NewVector(sol.ndim)
+				}
+			}
+			o.difkp1 = la.NewVector(sol.ndim)
+			o.psi = la.NewVector(sol.ndim)
+			o.pred = la.NewVector(sol.ndim)
+			o.ynew = la.NewVector(sol.ndim)
+			o.rhs = la.NewVector(sol.ndim)
+			o.del = la.NewVector(sol.ndim)
+		}
+		if !sol.hasM && sol.mTri == nil {
+			sol.mTri = new(la.Triplet)
+			la.SpTriSetDiag(sol.mTri, sol.ndim, 1)
+		}
+		o.lsInit = false
+		o.hinvGak = 0
+		o.newJ = true
+		o.havrate = false
+	}
+
+	// f(x0,y0) is not computed by Solver with fixed steps (see also jacobian)
+	if sol.fixstp && sol.first {
+		sol.Nfeval++
+		err = sol.fcn(sol.f0, sol.h, x0, y0)
+		if err != nil {
+			return
+		}
+	}
+
+	// restart with order 1 (first step or after an event) or change step size
+	if sol.first {
+		o.k = 1
+		o.hcur = sol.h
+		o.nconhk = 0
+		o.dif[0].Apply(sol.h, sol.f0) // dif[0] := h * f0
+		for j := 1; j < len(o.dif); j++ {
+			o.dif[j].Fill(0)
+		}
+	} else if sol.h != o.hcur {
+		o.rescale(o.dif, o.k, sol.h/o.hcur)
+		o.hcur = sol.h
+		o.nconhk = 0
+	}
+
+	// check step size
+	h := sol.h
+	if math.Abs(h) <= 16.0*sol.Eps*math.Abs(x0) {
+		err = chk.Err("step size is too small: h = %g at x = %g\n", h, x0)
+		return
+	}
+
+	// iteration matrix must be refactorised if h or k change
+	hinvGak := h * o.invGa[o.k-1]
+	if hinvGak != o.hinvGak {
+		o.hinvGak = hinvGak
+		o.newFact = true
+	}
+
+	// constant term and predictor
+	k := o.k
+	xnew := x0 + h
+	o.psi.Fill(0)
+	o.pred.Apply(1, y0)
+	for j := 0; j < k; j++ {
+		la.VecAdd(o.psi, 1, o.psi, o.gamma[j]*o.invGa[k-1], o.dif[j]) // psi += γ[j] invGa[k-1] dif[j]
+		la.VecAdd(o.pred, 1, o.pred, 1, o.dif[j])                     // pred += dif[j]
+	}
+	minnrm := 100.0 * sol.Eps * o.norm(sol, o.pred)
+
+	// iterations
+	for {
+
+		// Jacobian and factorisation
+		if o.newJ {
+			err = o.jacobian(sol, x0, y0)
+			if err != nil {
+				return
+			}
+		}
+		if o.newFact {
+			err = o.factorise(sol)
+			if err != nil {
+				return
+			}
+		}
+
+		// simplified Newton's method
+		o.ynew.Apply(1, o.pred)
+		o.difkp1.Fill(0)
+		tooslow := false
+		var newnrm, oldnrm, errit float64
+		for it := 0; it < bdfMaxIt; it++ {
+
+			// max iterations ?
+			sol.nit = it + 1
+			if sol.nit > sol.Nitmax {
+				sol.Nitmax = sol.nit
+			}
+
+			// residual: rhs = hinvGak*f(xnew,ynew) - M*(psi+difkp1)
+			sol.Nfeval++
+			err = sol.fcn(sol.f[0], h, xnew, o.ynew)
+			if err != nil {
+				return
+			}
+			la.VecAdd(sol.w[0], 1, o.psi, 1, o.difkp1)
+			if sol.hasM {
+				la.SpMatVecMul(sol.dw[0], 1, sol.mMat, sol.w[0])
+				la.VecAdd(o.rhs, hinvGak, sol.f[0], -1, sol.dw[0])
+			} else {
+				la.VecAdd(o.rhs, hinvGak, sol.f[0], -1, sol.w[0])
+			}
+
+			// solve linear system: (M - hinvGak*J) del = rhs
+			sol.Nlinsol++
+			err = sol.lsolR.Solve(o.del, o.rhs, false)
+			if err != nil {
+				return
+			}
+
+			// update
+			newnrm = o.norm(sol, o.del)
+			la.VecAdd(o.difkp1, 1, o.difkp1, 1, o.del)
+			la.VecAdd(o.ynew, 1, o.pred, 1, o.difkp1)
+
+			// check convergence
+			if newnrm <= minnrm {
+				if it > 0 { // e.g. linear problem: the rate can be reused
+					o.rate = max(0.9*o.rate, newnrm/oldnrm)
+					o.havrate = true
+				}
+				break
+			}
+			if it == 0 {
+				if o.havrate {
+					errit = newnrm * o.rate / (1.0 - o.rate)
+					if errit <= 0.05 { // more stringent when using old rate
+						break
+					}
+				} else {
+					o.rate = 0
+				}
+			} else if newnrm > 0.9*oldnrm {
+				tooslow = true
+				break
+			} else {
+				o.rate = max(0.9*o.rate, newnrm/oldnrm)
+				o.havrate = true
+				errit = newnrm * o.rate / (1.0 - o.rate)
+				if errit <= 0.5 {
+					break
+				} else if it == bdfMaxIt-1 || 0.5 < errit*math.Pow(o.rate, float64(bdfMaxIt-1-it)) {
+					tooslow = true
+					break
+				}
+			}
+			oldnrm = newnrm
+		}
+		if !tooslow {
+			break
+		}
+
+		// too slow: compute a new Jacobian
+		if !o.jcur {
+			o.newJ = true
+			continue
+		}
+
+		// too slow: reduce step size
+		if sol.fixstp {
+			err = chk.Err("Newton iterations failed to converge with fixed steps: h = %g at x = %g\n", h, x0)
+			return
+		}
+		sol.diverg = true
+		sol.dvfac = 0.3
+		rerr = 2.0 // must leave state intact, any rerr is OK
+		return
+	}
+
+	// error estimate
+	o.err = o.norm(sol, o.difkp1) * o.erconst[k-1]
+	rerr = max(o.err, 1.0e-10)
+	return
+}
+
+// DenseOut produces dense output after Accept by interpolating the backward differences.
+// See FwEuler.DenseOut
+func (o *Bdf) DenseOut(sol *Solver, yout la.Vector, h, x float64, y la.Vector, xout float64) {
+	interpDif(yout, y, o.dif, o.kdns, h, x, xout)
+}
+
+// SensStep solves the sensitivity equations M s' = J⋅s + ∂f/∂p with the formula of the last step
+// and a staggered corrector reusing the factorisation of M - hinvGak*J. See sensStepper
+func (o *Bdf) SensStep(sol *Solver, sens *FwdSens, x0 float64, y0, y la.Vector, s []la.Vector) (err error) {
+
+	// allocate
+	if len(o.sdif) != len(s) || len(o.sdif[0][0]) != sol.ndim {
+		o.sdif = make([][]la.Vector, len(s))
+		for j := 0; j < len(s); j++ {
+			o.sdif[j] = make([]la.Vector, len(o.dif))
+			for i := 0; i < len(o.dif); i++ {
+				o.sdif[j][i] = la.NewVector(sol.ndim)
+			}
+		}
+	}
+
+	// restart with order 1 or change step size
+	h, k := sol.h, o.kdns
+	r := sol.f[0] // workspace
+	if sens.first {
+		err = sens.prepare(h, x0, y0, nil)
+		if err != nil {
+			return
+		}
+		for j := 0; j < len(s); j++ {
+			err = sens.rhs(r, j, s[j])
+			if err != nil {
+				return
+			}
+			o.sdif[j][0].Apply(h, r) // sdif[0] := h * s'
+			for i := 1; i < len(o.sdif[j]); i++ {
+				o.sdif[j][i].Fill(0)
+			}
+		}
+		o.shcur = h
+	} else if h != o.shcur {
+		for j := 0; j < len(s); j++ {
+			o.rescale(o.sdif[j], k, h/o.shcur)
+		}
+		o.shcur = h
+	}
+
+	// corrector (psi, pred, difkp1, rhs and del are free after Accept)
+	err = sens.prepare(h, x0+h, y, nil)
+	if err != nil {
+		return
+	}
+	hinvGak := h * o.invGa[k-1]
+	for j := 0; j < len(s); j++ {
+		dif := o.sdif[j]
+		o.psi.Fill(0)
+		o.pred.Apply(1, s[j])
+		for i := 0; i < k; i++ {
+			la.VecAdd(o.psi, 1, o.psi, o.gamma[i]*o.invGa[k-1], dif[i])
+			la.VecAdd(o.pred, 1, o.pred, 1, dif[i])
+		}
+		o.difkp1.Fill(0)
+		s[j].Apply(1, o.pred)
+		for it := 0; ; it++ {
+			if it == sol.NmaxIt {
+				return chk.Err("staggered corrector of sensitivity %d did not converge after %d iterations\n", j, it)
+			}
+
+			// residual: rhs = hinvGak*(J⋅s + ∂f/∂p) - M*(psi+difkp1)
+			err = sens.rhs(r, j, s[j])
+			if err != nil {
+				return
+			}
+			la.VecAdd(sol.w[0], 1, o.psi, 1, o.difkp1)
+			if sol.hasM {
+				la.SpMatVecMul(sol.dw[0], 1, sol.mMat, sol.w[0])
+				la.VecAdd(o.rhs, hinvGak, r, -1, sol.dw[0])
+			} else {
+				la.VecAdd(o.rhs, hinvGak, r, -1, sol.w[0])
+			}
+
+			// solve linear system and update
+			sol.Nlinsol++
+			err = sol.lsolR.Solve(o.del, o.rhs, false)
+			if err != nil {
+				return
+			}
+			la.VecAdd(o.difkp1, 1, o.difkp1, 1, o.del)
+			la.VecAdd(s[j], 1, o.pred, 1, o.difkp1)
+			if sens.norm(o.del, s[j]) < sens.CorrTol {
+				break
+			}
+		}
+		updateDif(dif, o.difkp1, k)
+	}
+	return
+}
+
+// SensDenseOut produces dense output of the sensitivities after SensStep
+func (o *Bdf) SensDenseOut(sol *Solver, sout []la.Vector, h, x float64, s []la.Vector, xout float64) {
+	for j := 0; j < len(s); j++ {
+		interpDif(sout[j], s[j], o.sdif[j], o.kdns, h, x, xout)
+	}
+}
+
+// jacobian computes the Jacobian at (x0,y0)
+func (o *Bdf) jacobian(sol *Solver, x0 float64, y0 la.Vector) (err error) {
+	if sol.jac == nil { // numerical
+		if sol.fixstp && !sol.first { // f(x0,y0) is not computed by Solver with fixed steps
+			sol.Nfeval++
+			err = sol.fcn(sol.f0, sol.h, x0, y0)
+			if err != nil {
+				return
+			}
+		}
+		err = num.Jacobian(&sol.dfdyT, func(fy, yy la.Vector) (e error) {
+			e = sol.fcn(fy, sol.h, x0, yy)
+			return
+		}, y0, sol.f0, sol.dw[0]) // δw works here as workspace variable
+	} else { // analytical
+		err = sol.jac(&sol.dfdyT, sol.h, x0, y0)
+	}
+	if err != nil {
+		return
+	}
+	sol.Njeval++
+	o.newJ = false
+	o.jcur = true
+	o.newFact = true
+	return
+}
+
+// factorise computes and factorises the iteration matrix M - hinvGak*J
+func (o *Bdf) factorise(sol *Solver) (err error) {
+	if !o.lsInit {
+		sol.rctriR = new(la.Triplet)
+		sol.rctriR.Init(sol.ndim, sol.ndim, sol.mTri.Len()+sol.dfdyT.Len())
+	}
+	la.SpTriAdd(sol.rctriR, 1, sol.mTri, -o.hinvGak, &sol.dfdyT) // rctriR := M - hinvGak * dfdy
+	if !o.lsInit {
+		err = sol.lsolR.Init(sol.rctriR, sol.symmetric, sol.lsverbose, sol.ordering, sol.scaling, sol.comm)
+		if err != nil {
+			return
+		}
+		o.lsInit = true
+	}
+	err = sol.lsolR.Fact()
+	if err != nil {
+		return
+	}
+	sol.Ndecomp++
+	o.newFact = false
+	o.havrate = false
+	return
+}
+
+// rescale changes the backward differences of order k to the new step size h = ρ * hcur; see [3]
+func (o *Bdf) rescale(dif []la.Vector, k int, ρ float64) {
+	for j := 0; j < k; j++ {
+		o.tmp[j].Fill(0)
+	}
+	for j := 0; j < k; j++ {
+		for i := 0; i < k; i++ {
+			ru := o.ruCoef(ρ, i, j)
+			if ru != 0 {
+				la.VecAdd(o.tmp[j], 1, o.tmp[j], ru, dif[i])
+			}
+		}
+	}
+	for j := 0; j < k; j++ {
+		dif[j].Apply(1, o.tmp[j])
+	}
+}
+
+// ruCoef computes the (i,j) component of R(ρ)*U, where R(ρ)[i][j] = Π_{m=1}^{i+1} (m-1-(j+1)ρ)/m
+// and U = R(1)
+func (o *Bdf) ruCoef(ρ float64, i, j int) (res float64) {
+	rmat := func(r float64, a, b int) float64 {
+		p := 1.0
+		for m := 1; m <= a+1; m++ {
+			p *= (float64(m-1) - float64(b+1)*r) / float64(m)
+		}
+		return p
+	}
+	for l := 0; l <= j; l++ { // U is upper triangular
+		res += rmat(ρ, i, l) * rmat(1, l, j)
+	}
+	return
+}
+
+// norm computes the RMS norm of v scaled by Atol + Rtol*|y|
+func (o *Bdf) norm(sol *Solver, v la.Vector) (nrm float64) {
+	for m := 0; m < sol.ndim; m++ {
+		nrm += math.Pow(v[m]/sol.scal[m], 2.0)
+	}
+	return math.Sqrt(nrm / float64(sol.ndim))
+}
+
+// updateDif updates the backward differences of order k with the difference of order k+1 of the
+// new value (d)
+func updateDif(dif []la.Vector, d la.Vector, k int) {
+	la.VecAdd(dif[k+1], 1, d, -1, dif[k]) // dif[k+1] := d - dif[k]
+	dif[k].Apply(1, d)
+	for j := k - 1; j >= 0; j-- {
+		la.VecAdd(dif[j], 1, dif[j], 1, dif[j+1])
+	}
+}
+
+// interpDif interpolates the backward differences of order k at xout; y is the value at x
+func interpDif(yout, y la.Vector, dif []la.Vector, k int, h, x, xout float64) {
+	s := (xout - x) / h
+	yout.Apply(1, y)
+	prod := 1.0
+	for j := 0; j < k; j++ {
+		prod *= (s + float64(j)) / float64(j+1)
+		la.VecAdd(yout, 1, yout, prod, dif[j])
+	}
+}
+
+// stepFromErr computes the step size corresponding to the error estimate err of order q-1
+func stepFromErr(h, safety, err float64, q int) float64 {
+	temp := safety * math.Pow(err, 1.0/float64(q))
+	if temp > 0.1 {
+		return h / temp
+	}
+	return 10.0 * h
+}
+
+// add method to database //////////////////////////////////////////////////////////////////////////
+
+func init() {
+	rkmDB[BdfKind] = func() RKmethod { return new(Bdf) }
+	rkmDB[NdfKind] = func() RKmethod { return &Bdf{ndf: true} }
+}
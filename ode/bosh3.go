@@ -0,0 +1,58 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import "github.com/cpmech/gosl/la"
+
+// BoSh3 implements the (explicit) Bogacki-Shampine 3(2) method
+// Bogacki-Shampine 3(2), order=3, error_est_order=2, nstages=4 (first same as last)
+type BoSh3 struct {
+	dat   *erkdata
+	dense erkhermite
+}
+
+// Init initialises structure
+func (o *BoSh3) Init(distr bool) (err error) {
+	o.dat = &erkdata{
+		A: [][]float64{
+			{},
+			{1.0 / 2.0},
+			{0.0, 3.0 / 4.0},
+			{2.0 / 9.0, 1.0 / 3.0, 4.0 / 9.0},
+		},
+		B:  []float64{2.0 / 9.0, 1.0 / 3.0, 4.0 / 9.0, 0.0},
+		Be: []float64{7.0 / 24.0, 1.0 / 4.0, 1.0 / 3.0, 1.0 / 8.0},
+		C:  []float64{0.0, 1.0 / 2.0, 3.0 / 4.0, 1.0},
+	}
+	return nil
+}
+
+// Nstages returns the number of stages
+func (o *BoSh3) Nstages() int {
+	return 4
+}
+
+// Accept accepts update
+func (o *BoSh3) Accept(sol *Solver, y la.Vector) {
+	o.dense.accept(sol, y, 4, true)
+	y.Apply(1, sol.w[0]) // y := w (update y)
+}
+
+// Step steps update
+func (o *BoSh3) Step(sol *Solver, y0 la.Vector, x0 float64) (rerr float64, err error) {
+	return erkstep(o.dat, 4, true, sol, y0, x0)
+}
+
+// DenseOut produces dense output after Accept using Hermite interpolation (order 3).
+// See FwEuler.DenseOut
+func (o *BoSh3) DenseOut(sol *Solver, yout la.Vector, h, x float64, y la.Vector, xout float64) {
+	o.dense.denseOut(sol, yout, h, x, y, xout)
+}
+
+// add method to database //////////////////////////////////////////////////////////////////////////
+
+func init() {
+	rkmDB[BoSh3kind] = func() RKmethod { return new(BoSh3) }
+}
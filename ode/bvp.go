@@ -0,0 +1,186 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/num"
+)
+
+// BvpF defines the function of a boundary value problem (BVP) d{y}/dx = {f}(x, {y}, {p})
+//
+//   Input:
+//     x -- current x
+//     y -- current {y}
+//     p -- unknown parameters {p} [may be empty]
+//   Output:
+//     f -- {f}(x, {y}, {p})
+//
+type BvpF func(f la.Vector, x float64, y, p la.Vector) error
+
+// BcF defines the two-point boundary conditions {r}({ya}, {yb}, {p}) = {0} of a BVP
+//
+//   Input:
+//     ya -- {y} at the left boundary
+//     yb -- {y} at the right boundary
+//     p  -- unknown parameters {p} [may be empty]
+//   Output:
+//     r -- residuals [ndim + npar]
+//
+type BcF func(r la.Vector, ya, yb, p la.Vector) error
+
+// BvpShooting implements the multiple shooting method to solve boundary value problems
+//
+//   d{y}/dx = {f}(x, {y}, {p})   with   {r}({y}(xa), {y}(xb), {p}) = {0}
+//
+//  The interval [xa, xb] is divided by nodes x_0 = xa < x_1 < ... < x_N = xb. The unknowns are
+//  the values {s_k} of {y} at x_k (k < N) and the parameters {p}. Initial value problems are
+//  solved from each node with an ODE solver and the continuity conditions
+//  {y}(x_{k+1}; x_k, {s_k}) = {s_{k+1}} together with the boundary conditions are solved by
+//  Newton's method (num.NlSolver). Single shooting corresponds to N = 1
+type BvpShooting struct {
+
+	// primary variables
+	ndim int       // size of y
+	npar int       // number of unknown parameters
+	fcn  BvpF      // dydx := f(x, y, p)
+	bc   BcF       // boundary conditions
+	p    la.Vector // current parameters (used by the ODE function)
+
+	// solvers
+	Ode *Solver      // ODE solver (tolerances, ...)
+	Nls num.NlSolver // nonlinear solver (tolerances, ...)
+
+	// flags
+	Verbose bool // show messages
+
+	// output
+	X []float64   // nodes [nnodes]
+	Y [][]float64 // solution at nodes [nnodes][ndim]
+	P la.Vector   // parameters [npar]
+
+	// workspace
+	yk la.Vector // y during integration
+	rb la.Vector // boundary residuals
+}
+
+// NewBvpShooting returns a new multiple shooting BVP solver
+//  Input:
+//   method -- the ODE method kind; e.g. DoPri5kind
+//   ndim   -- size of y
+//   npar   -- number of unknown parameters
+//   fcn    -- function f(x, y, p)
+//   bc     -- boundary conditions r(ya, yb, p) [ndim + npar]
+func NewBvpShooting(method io.Enum, ndim, npar int, fcn BvpF, bc BcF) (o *BvpShooting) {
+	o = new(BvpShooting)
+	o.ndim = ndim
+	o.npar = npar
+	o.fcn = fcn
+	o.bc = bc
+	o.p = la.NewVector(npar)
+	o.Ode = NewSolver(method, ndim, func(f la.Vector, h, x float64, y la.Vector) error {
+		return o.fcn(f, x, y, o.p)
+	}, nil, nil, nil)
+	o.Ode.SetTol(1e-10, 1e-10)
+	o.yk = la.NewVector(ndim)
+	o.rb = la.NewVector(ndim + npar)
+	return
+}
+
+// Solve solves the BVP
+//  Input:
+//   X -- nodes (ascending) including xa and xb [nnodes]
+//   Y -- initial guesses of y at the nodes; Y[nnodes-1] is not used [nnodes][ndim]
+//   p -- initial guesses of the parameters [npar]
+//  Output:
+//   X, Y and P are set in o
+func (o *BvpShooting) Solve(X []float64, Y [][]float64, p la.Vector) (err error) {
+
+	// check
+	nnod := len(X)
+	if nnod < 2 || len(Y) != nnod {
+		return chk.Err("at least 2 nodes are required and len(Y) must be equal to len(X). %d, %d are invalid\n", nnod, len(Y))
+	}
+	if len(p) != o.npar {
+		return chk.Err("the number of parameters must be %d. %d is invalid\n", o.npar, len(p))
+	}
+	for k := 1; k < nnod; k++ {
+		if X[k] <= X[k-1] {
+			return chk.Err("nodes must be in ascending order\n")
+		}
+	}
+
+	// unknowns
+	nint := nnod - 1
+	neq := nint*o.ndim + o.npar
+	u := la.NewVector(neq)
+	for k := 0; k < nint; k++ {
+		copy(u[k*o.ndim:], Y[k])
+	}
+	copy(u[nint*o.ndim:], p)
+
+	// solve
+	o.X = append([]float64{}, X...)
+	ffcn := func(r, u la.Vector) error {
+		_, e := o.residual(r, u)
+		return e
+	}
+	o.Nls.Init(neq, ffcn, nil, nil, false, true, nil)
+	defer o.Nls.Free()
+	err = o.Nls.Solve(u, !o.Verbose)
+	if err != nil {
+		return
+	}
+
+	// results
+	o.Y = make([][]float64, nnod)
+	for k := 0; k < nint; k++ {
+		o.Y[k] = append([]float64{}, u[k*o.ndim:(k+1)*o.ndim]...)
+	}
+	yb, err := o.residual(la.NewVector(neq), u)
+	if err != nil {
+		return
+	}
+	o.Y[nint] = yb.GetCopy()
+	o.P = o.p.GetCopy()
+	return
+}
+
+// Eval computes y(x) for xa ≤ x ≤ xb by integrating from the nearest node on the left
+func (o *BvpShooting) Eval(y la.Vector, x float64) (err error) {
+	k := 0
+	for k < len(o.X)-2 && o.X[k+1] <= x {
+		k++
+	}
+	copy(o.p, o.P)
+	y.Apply(1, o.Y[k])
+	if x > o.X[k] {
+		err = o.Ode.Solve(y, o.X[k], x, x-o.X[k], false)
+	}
+	return
+}
+
+// residual computes the continuity and boundary residuals. It returns y(xb)
+func (o *BvpShooting) residual(r, u la.Vector) (yb la.Vector, err error) {
+	nint := len(o.X) - 1
+	copy(o.p, u[nint*o.ndim:])
+	for k := 0; k < nint; k++ {
+		copy(o.yk, u[k*o.ndim:(k+1)*o.ndim])
+		err = o.Ode.Solve(o.yk, o.X[k], o.X[k+1], o.X[k+1]-o.X[k], false)
+		if err != nil {
+			return
+		}
+		if k < nint-1 {
+			for i := 0; i < o.ndim; i++ {
+				r[k*o.ndim+i] = o.yk[i] - u[(k+1)*o.ndim+i]
+			}
+		}
+	}
+	err = o.bc(o.rb, u[:o.ndim], o.yk, o.p)
+	copy(r[(nint-1)*o.ndim:], o.rb)
+	return o.yk, err
+}
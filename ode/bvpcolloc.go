@@ -0,0 +1,435 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+// BvpColloc implements a 4th order collocation method with mesh adaptation to solve boundary
+// value problems (the method of MATLAB's bvp4c; see Kierzenka J, Shampine LF (2001) A BVP solver
+// based on residual control and the MATLAB PSE. ACM TOMS, 27(3):299-316)
+//
+//   d{y}/dx = {f}(x, {y}, {p})   with   {r}({y}(xa), {y}(xb), {p}) = {0}
+//
+//  The solution is approximated by a continuous piecewise cubic polynomial S(x) with continuous
+//  derivative that satisfies the ODEs at the nodes and at the midpoints of the intervals (the
+//  three-stage Lobatto IIIA formula):
+//
+//   y_{i+1} - y_i - h_i (f_i + 4 f_{i+½} + f_{i+1}) / 6 = 0
+//   y_{i+½} = (y_i + y_{i+1}) / 2 - h_i (f_{i+1} - f_i) / 8
+//
+//  The nonlinear system is solved by a damped Newton method with a sparse Jacobian computed by
+//  finite differences. The mesh is refined where the residual S'(x) - f(x, S(x), p) is larger
+//  than the tolerance; i.e. where |r_m| > Atol + Rtol |f_m| at the interior Lobatto points
+type BvpColloc struct {
+
+	// primary variables
+	ndim int  // size of y
+	npar int  // number of unknown parameters
+	fcn  BvpF // dydx := f(x, y, p)
+	bc   BcF  // boundary conditions
+
+	// parameters
+	Atol     float64 // absolute tolerance of the residual
+	Rtol     float64 // relative tolerance of the residual
+	NmaxMesh int     // max number of nodes
+	MaxIt    int     // max number of Newton iterations
+	MaxRef   int     // max number of mesh refinements
+	Verbose  bool    // show messages
+
+	// output
+	X      []float64   // mesh [nnodes]
+	Y      [][]float64 // solution at nodes [nnodes][ndim]
+	F      [][]float64 // f at nodes [nnodes][ndim]
+	P      la.Vector   // parameters [npar]
+	MaxRes float64     // max scaled residual on the final mesh
+	Nfeval int         // number of calls to fcn
+	Nit    int         // total number of Newton iterations
+
+	// workspace
+	ym, fm, fp la.Vector // y and f at midpoint and perturbed f
+	rb         la.Vector // boundary residuals
+}
+
+// NewBvpColloc returns a new collocation BVP solver
+//  Input:
+//   ndim -- size of y
+//   npar -- number of unknown parameters
+//   fcn  -- function f(x, y, p)
+//   bc   -- boundary conditions r(ya, yb, p) [ndim + npar]
+func NewBvpColloc(ndim, npar int, fcn BvpF, bc BcF) (o *BvpColloc) {
+	o = new(BvpColloc)
+	o.ndim = ndim
+	o.npar = npar
+	o.fcn = fcn
+	o.bc = bc
+	o.Atol = 1e-6
+	o.Rtol = 1e-3
+	o.NmaxMesh = 5000
+	o.MaxIt = 40
+	o.MaxRef = 20
+	o.ym = la.NewVector(ndim)
+	o.fm = la.NewVector(ndim)
+	o.fp = la.NewVector(ndim)
+	o.rb = la.NewVector(ndim + npar)
+	return
+}
+
+// Solve solves the BVP
+//  Input:
+//   X -- initial mesh (ascending) including xa and xb [nnodes]
+//   Y -- initial guesses of y at the nodes [nnodes][ndim]
+//   p -- initial guesses of the parameters [npar]
+//  Output:
+//   X, Y, F and P are set in o
+func (o *BvpColloc) Solve(X []float64, Y [][]float64, p la.Vector) (err error) {
+
+	// check
+	if len(X) < 2 || len(Y) != len(X) {
+		return chk.Err("at least 2 nodes are required and len(Y) must be equal to len(X). %d, %d are invalid\n", len(X), len(Y))
+	}
+	if len(p) != o.npar {
+		return chk.Err("the number of parameters must be %d. %d is invalid\n", o.npar, len(p))
+	}
+	for i := 1; i < len(X); i++ {
+		if X[i] <= X[i-1] {
+			return chk.Err("nodes must be in ascending order\n")
+		}
+	}
+
+	// initial data
+	o.X = utl.GetCopy(X)
+	o.Y = utl.Alloc(len(X), o.ndim)
+	o.F = utl.Alloc(len(X), o.ndim)
+	for i := 0; i < len(X); i++ {
+		copy(o.Y[i], Y[i])
+	}
+	o.P = p.GetCopy()
+	o.Nfeval, o.Nit = 0, 0
+
+	// solve and refine
+	for iref := 0; iref <= o.MaxRef; iref++ {
+		err = o.newton()
+		if err != nil {
+			return
+		}
+		var nnew int
+		nnew, err = o.refine()
+		if err != nil {
+			return
+		}
+		if o.Verbose {
+			io.Pf("mesh %3d: nnodes = %5d  max residual = %g\n", iref, len(o.X), o.MaxRes)
+		}
+		if nnew == 0 {
+			return
+		}
+		if len(o.X) > o.NmaxMesh {
+			return chk.Err("the number of nodes %d exceeds the maximum %d\n", len(o.X), o.NmaxMesh)
+		}
+	}
+	return chk.Err("the tolerances could not be satisfied after %d mesh refinements. max residual = %g\n", o.MaxRef, o.MaxRes)
+}
+
+// Eval computes y(x) for xa ≤ x ≤ xb using the cubic polynomial of the interval containing x
+func (o *BvpColloc) Eval(y la.Vector, x float64) {
+	i := 0
+	for i < len(o.X)-2 && o.X[i+1] <= x {
+		i++
+	}
+	o.cubic(y, nil, i, (x-o.X[i])/(o.X[i+1]-o.X[i]))
+}
+
+// cubic computes S(x) and S'(x) [may be nil] in interval i at x = x_i + θ h_i
+func (o *BvpColloc) cubic(s, ds la.Vector, i int, θ float64) {
+	h := o.X[i+1] - o.X[i]
+	θ2, θ3 := θ*θ, θ*θ*θ
+	a0, b0 := 2.0*θ3-3.0*θ2+1.0, (θ3-2.0*θ2+θ)*h
+	a1, b1 := -2.0*θ3+3.0*θ2, (θ3-θ2)*h
+	y0, f0, y1, f1 := o.Y[i], o.F[i], o.Y[i+1], o.F[i+1]
+	for m := 0; m < o.ndim; m++ {
+		s[m] = a0*y0[m] + b0*f0[m] + a1*y1[m] + b1*f1[m]
+	}
+	if ds != nil {
+		da0, db0 := (6.0*θ2-6.0*θ)/h, 3.0*θ2-4.0*θ+1.0
+		da1, db1 := (-6.0*θ2+6.0*θ)/h, 3.0*θ2-2.0*θ
+		for m := 0; m < o.ndim; m++ {
+			ds[m] = da0*y0[m] + db0*f0[m] + da1*y1[m] + db1*f1[m]
+		}
+	}
+}
+
+// calcF computes f at node i
+func (o *BvpColloc) calcF(i int, p la.Vector) error {
+	o.Nfeval++
+	return o.fcn(o.F[i], o.X[i], o.Y[i], p)
+}
+
+// phi computes the collocation residual of interval i (using F[i] and F[i+1]) and adds it to res
+func (o *BvpColloc) phi(res []float64, i int, p la.Vector) (err error) {
+	h := o.X[i+1] - o.X[i]
+	y0, f0, y1, f1 := o.Y[i], o.F[i], o.Y[i+1], o.F[i+1]
+	for m := 0; m < o.ndim; m++ {
+		o.ym[m] = (y0[m]+y1[m])/2.0 - h*(f1[m]-f0[m])/8.0
+	}
+	o.Nfeval++
+	err = o.fcn(o.fm, o.X[i]+h/2.0, o.ym, p)
+	if err != nil {
+		return
+	}
+	for m := 0; m < o.ndim; m++ {
+		res[m] += y1[m] - y0[m] - h*(f0[m]+4.0*o.fm[m]+f1[m])/6.0
+	}
+	return
+}
+
+// residual computes all residuals: collocation [nint][ndim] followed by boundary conditions
+func (o *BvpColloc) residual(r la.Vector) (err error) {
+	nnod, n := len(o.X), o.ndim
+	for i := 0; i < nnod; i++ {
+		if err = o.calcF(i, o.P); err != nil {
+			return
+		}
+	}
+	r.Fill(0)
+	for i := 0; i < nnod-1; i++ {
+		if err = o.phi(r[i*n:(i+1)*n], i, o.P); err != nil {
+			return
+		}
+	}
+	err = o.bc(o.rb, o.Y[0], o.Y[nnod-1], o.P)
+	copy(r[(nnod-1)*n:], o.rb)
+	return
+}
+
+// jacobian computes the sparse Jacobian by finite differences. F must be up-to-date
+func (o *BvpColloc) jacobian(J *la.Triplet, r la.Vector) (err error) {
+	nnod, n := len(o.X), o.ndim
+	nbc := n + o.npar
+	ibc := (nnod - 1) * n // first row of boundary conditions
+	col := make([]float64, n)
+	rbc := la.NewVector(nbc)
+	J.Start()
+
+	// columns of y_j (all entries are put, even if zero, to keep the sparsity pattern)
+	for j := 0; j < nnod; j++ {
+		for m := 0; m < n; m++ {
+			yold, fold := o.Y[j][m], o.fp
+			copy(fold, o.F[j])
+			δ := math.Sqrt(1e-16 * max(1e-5, math.Abs(yold)))
+			o.Y[j][m] = yold + δ
+			if err = o.calcF(j, o.P); err != nil {
+				return
+			}
+
+			// collocation equations of intervals j-1 and j
+			for i := j - 1; i <= j; i++ {
+				if i < 0 || i >= nnod-1 {
+					continue
+				}
+				for k := 0; k < n; k++ {
+					col[k] = -r[i*n+k]
+				}
+				if err = o.phi(col, i, o.P); err != nil {
+					return
+				}
+				for k := 0; k < n; k++ {
+					J.Put(i*n+k, j*n+m, col[k]/δ)
+				}
+			}
+
+			// boundary conditions
+			if j == 0 || j == nnod-1 {
+				if err = o.bc(rbc, o.Y[0], o.Y[nnod-1], o.P); err != nil {
+					return
+				}
+				for k := 0; k < nbc; k++ {
+					J.Put(ibc+k, j*n+m, (rbc[k]-r[ibc+k])/δ)
+				}
+			}
+			o.Y[j][m] = yold
+			copy(o.F[j], fold)
+		}
+	}
+
+	// columns of p
+	if o.npar > 0 {
+		rp := la.NewVector(len(r))
+		for m := 0; m < o.npar; m++ {
+			pold := o.P[m]
+			δ := math.Sqrt(1e-16 * max(1e-5, math.Abs(pold)))
+			o.P[m] = pold + δ
+			if err = o.residual(rp); err != nil {
+				return
+			}
+			for k := 0; k < len(r); k++ {
+				J.Put(k, nnod*n+m, (rp[k]-r[k])/δ)
+			}
+			o.P[m] = pold
+		}
+		err = o.residual(rp) // restore F
+	}
+	return
+}
+
+// newton solves the collocation equations on the current mesh with a damped Newton method
+func (o *BvpColloc) newton() (err error) {
+
+	// workspace
+	nnod, n := len(o.X), o.ndim
+	neq := nnod*n + o.npar
+	r := la.NewVector(neq)
+	rnew := la.NewVector(neq)
+	δu := la.NewVector(neq)
+	u0 := la.NewVector(neq)
+	nnz := 4*n*n*(nnod-1) + 2*n*(n+o.npar) + neq*o.npar
+	J := new(la.Triplet)
+	J.Init(neq, neq, nnz)
+	lsol := la.NewSparseSolver("umfpack")
+	defer lsol.Free()
+
+	// unknowns
+	getU := func(u la.Vector) {
+		for i := 0; i < nnod; i++ {
+			copy(u[i*n:], o.Y[i])
+		}
+		copy(u[nnod*n:], o.P)
+	}
+	setU := func(u la.Vector) {
+		for i := 0; i < nnod; i++ {
+			copy(o.Y[i], u[i*n:(i+1)*n])
+		}
+		copy(o.P, u[nnod*n:])
+	}
+
+	// iterations
+	if err = o.residual(r); err != nil {
+		return
+	}
+	rnorm := r.Norm()
+	for it := 0; it < o.MaxIt; it++ {
+		o.Nit++
+
+		// Jacobian and Newton increment
+		if err = o.jacobian(J, r); err != nil {
+			return
+		}
+		if it == 0 {
+			if err = lsol.Init(J, false, false, "", "", nil); err != nil {
+				return
+			}
+		}
+		if err = lsol.Fact(); err != nil {
+			return
+		}
+		if err = lsol.Solve(δu, r, false); err != nil { // δu = J⁻¹ r
+			return
+		}
+
+		// damping: halve the step until the residual decreases
+		getU(u0)
+		λ := 1.0
+		for {
+			for k := 0; k < neq; k++ {
+				δ := λ * δu[k]
+				if k < nnod*n {
+					o.Y[k/n][k%n] = u0[k] - δ
+				} else {
+					o.P[k-nnod*n] = u0[k] - δ
+				}
+			}
+			if err = o.residual(rnew); err != nil {
+				return
+			}
+			if rnew.Norm() < rnorm || λ < 1e-4 {
+				break
+			}
+			λ /= 2.0
+		}
+		r.Apply(1, rnew)
+		rnorm = r.Norm()
+
+		// convergence
+		var dmax float64
+		for k := 0; k < neq; k++ {
+			dmax = max(dmax, math.Abs(λ*δu[k])/(1.0+math.Abs(u0[k])))
+		}
+		if o.Verbose {
+			io.Pf("  it = %2d  |r| = %12.5e  max(δu) = %12.5e  λ = %g\n", it, rnorm, dmax, λ)
+		}
+		if dmax < 1e-10 || rnorm < 1e-12 {
+			return
+		}
+		if λ < 1e-4 {
+			setU(u0)
+			break
+		}
+	}
+	return chk.Err("Newton's method did not converge after %d iterations. |r| = %g\n", o.MaxIt, rnorm)
+}
+
+// refine computes the residuals of the cubic solution at the interior Lobatto points of each
+// interval and refines the mesh where they are too large. It returns the number of new nodes
+func (o *BvpColloc) refine() (nnew int, err error) {
+
+	// residuals
+	nint := len(o.X) - 1
+	errs := make([]float64, nint)
+	s := la.NewVector(o.ndim)
+	ds := la.NewVector(o.ndim)
+	c := math.Sqrt(3.0/7.0) / 2.0
+	o.MaxRes = 0
+	for i := 0; i < nint; i++ {
+		h := o.X[i+1] - o.X[i]
+		for _, θ := range []float64{0.5 - c, 0.5 + c} {
+			o.cubic(s, ds, i, θ)
+			o.Nfeval++
+			if err = o.fcn(o.fm, o.X[i]+θ*h, s, o.P); err != nil {
+				return
+			}
+			for m := 0; m < o.ndim; m++ {
+				errs[i] = max(errs[i], math.Abs(ds[m]-o.fm[m])/(o.Atol+o.Rtol*math.Abs(o.fm[m])))
+			}
+		}
+		o.MaxRes = max(o.MaxRes, errs[i])
+	}
+
+	// new mesh: one new node if the residual is moderate; two otherwise
+	var X []float64
+	var Y [][]float64
+	for i := 0; i < nint; i++ {
+		X = append(X, o.X[i])
+		Y = append(Y, o.Y[i])
+		nadd := 0
+		if errs[i] > 1 {
+			nadd = 1
+			if errs[i] > 100 {
+				nadd = 2
+			}
+		}
+		for k := 1; k <= nadd; k++ {
+			θ := float64(k) / float64(nadd+1)
+			y := la.NewVector(o.ndim)
+			o.cubic(y, nil, i, θ)
+			X = append(X, o.X[i]+θ*(o.X[i+1]-o.X[i]))
+			Y = append(Y, y)
+			nnew++
+		}
+	}
+	if nnew == 0 {
+		return
+	}
+	X = append(X, o.X[nint])
+	Y = append(Y, o.Y[nint])
+	o.X, o.Y = X, Y
+	o.F = utl.Alloc(len(X), o.ndim)
+	return
+}
@@ -15,6 +15,9 @@ import (
 
 // BwEuler implements the (implicit) Backward Euler method
 type BwEuler struct {
+	yold   la.Vector   // y before the step (for dense output)
+	lsInit bool        // the linear solver has been initialised
+	sold   []la.Vector // sensitivities before the step (for dense output) [npar][ndim]
 }
 
 // Init initialises structure
@@ -36,9 +39,16 @@ func (o *BwEuler) Step(sol *Solver, y0 la.Vector, x0 float64) (rerr float64, err
 
 	// new x
 	x0 += sol.h
+	if sol.doinit {
+		o.lsInit = false
+	}
 
 	// previous y
 	sol.v[0].Apply(1, y0) // v := y_old
+	if len(o.yold) != len(y0) {
+		o.yold = la.NewVector(len(y0))
+	}
+	o.yold.Apply(1, y0)
 
 	// iterations
 	var rmsnr float64 // rms norm of residual
@@ -83,40 +93,11 @@ func (o *BwEuler) Step(sol *Solver, y0 la.Vector, x0 float64) (rerr float64, err
 		}
 
 		// Jacobian matrix
-		if sol.doinit || !sol.CteTg {
-			sol.Njeval++
-
-			// calculate Jacobian
-			if sol.jac == nil { // numerical
-				err = num.Jacobian(&sol.dfdyT, func(fy, yy la.Vector) (e error) {
-					e = sol.fcn(fy, sol.h, x0, yy)
-					return
-				}, y0, sol.f[0], sol.dw[0]) // δw works here as workspace variable
-			} else { // analytical
-				err = sol.jac(&sol.dfdyT, sol.h, x0, y0)
-			}
+		if !o.lsInit || !sol.CteTg {
+			err = o.factorise(sol, x0, y0, sol.f[0])
 			if err != nil {
 				return
 			}
-			if sol.doinit {
-				sol.rctriR = new(la.Triplet)
-				sol.rctriR.Init(sol.ndim, sol.ndim, sol.mTri.Len()+sol.dfdyT.Len())
-			}
-
-			// calculate drdy matrix
-			la.SpTriAdd(sol.rctriR, 1, sol.mTri, -sol.h, &sol.dfdyT) // rctriR := I - h * dfdy
-
-			// initialise linear solver
-			if sol.doinit {
-				err = sol.lsolR.Init(sol.rctriR, sol.symmetric, sol.lsverbose, sol.ordering, sol.scaling, sol.comm)
-				if err != nil {
-					return
-				}
-			}
-
-			// perform factorisation
-			sol.Ndecomp++
-			sol.lsolR.Fact()
 		}
 
 		// solve linear system
@@ -137,6 +118,121 @@ func (o *BwEuler) Step(sol *Solver, y0 la.Vector, x0 float64) (rerr float64, err
 	return 1e+20, err // must not be used with automatic substepping
 }
 
+// DenseOut produces dense output (linear interpolation) after Accept. See FwEuler.DenseOut
+func (o *BwEuler) DenseOut(sol *Solver, yout la.Vector, h, x float64, y la.Vector, xout float64) {
+	θ := 1.0 + (xout-x)/h
+	for i := 0; i < len(y); i++ {
+		yout[i] = o.yold[i] + θ*(y[i]-o.yold[i])
+	}
+}
+
+// SensStep solves the sensitivity equations s = s0 + h (J⋅s + ∂f/∂p) with a staggered corrector
+// reusing the factorisation of the last step. See sensStepper
+func (o *BwEuler) SensStep(sol *Solver, sens *FwdSens, x0 float64, y0, y la.Vector, s []la.Vector) (err error) {
+
+	// allocate
+	if len(o.sold) != len(s) {
+		o.sold = make([]la.Vector, len(s))
+		for j := 0; j < len(s); j++ {
+			o.sold[j] = la.NewVector(sol.ndim)
+		}
+	}
+
+	// the state iterations converged without factorisation
+	x := x0 + sol.h
+	if !o.lsInit {
+		sol.Nfeval++
+		err = sol.fcn(sol.f[0], sol.h, x, y)
+		if err != nil {
+			return
+		}
+		err = o.factorise(sol, x, y, sol.f[0])
+		if err != nil {
+			return
+		}
+	}
+
+	// corrector: s := s - (I - h J)⁻¹ r  with  r = s - s0 - h (J⋅s + ∂f/∂p)
+	err = sens.prepare(sol.h, x, y, nil)
+	if err != nil {
+		return
+	}
+	r, δs := sol.w[0], sol.dw[0] // workspace
+	for j := 0; j < len(s); j++ {
+		o.sold[j].Apply(1, s[j])
+		for it := 0; ; it++ {
+			if it == sol.NmaxIt {
+				return chk.Err("staggered corrector of sensitivity %d did not converge after %d iterations\n", j, it)
+			}
+			err = sens.rhs(r, j, s[j])
+			if err != nil {
+				return
+			}
+			for i := 0; i < sol.ndim; i++ {
+				r[i] = s[j][i] - o.sold[j][i] - sol.h*r[i]
+			}
+			sol.Nlinsol++
+			err = sol.lsolR.Solve(δs, r, false)
+			if err != nil {
+				return
+			}
+			la.VecAdd(s[j], 1, s[j], -1, δs)
+			if sens.norm(δs, s[j]) < sens.CorrTol {
+				break
+			}
+		}
+	}
+	return
+}
+
+// SensDenseOut produces dense output of the sensitivities (linear interpolation) after SensStep
+func (o *BwEuler) SensDenseOut(sol *Solver, sout []la.Vector, h, x float64, s []la.Vector, xout float64) {
+	θ := 1.0 + (xout-x)/h
+	for j := 0; j < len(s); j++ {
+		la.VecAdd(sout[j], 1-θ, o.sold[j], θ, s[j])
+	}
+}
+
+// factorise computes the Jacobian at (x,y) and factorises the iteration matrix M - h*J
+//  Input:
+//   fy -- f(x,y) (used by the numerical Jacobian)
+func (o *BwEuler) factorise(sol *Solver, x float64, y, fy la.Vector) (err error) {
+
+	// calculate Jacobian
+	sol.Njeval++
+	if sol.jac == nil { // numerical
+		err = num.Jacobian(&sol.dfdyT, func(ff, yy la.Vector) (e error) {
+			e = sol.fcn(ff, sol.h, x, yy)
+			return
+		}, y, fy, sol.dw[0]) // δw works here as workspace variable
+	} else { // analytical
+		err = sol.jac(&sol.dfdyT, sol.h, x, y)
+	}
+	if err != nil {
+		return
+	}
+	if !o.lsInit {
+		sol.rctriR = new(la.Triplet)
+		sol.rctriR.Init(sol.ndim, sol.ndim, sol.mTri.Len()+sol.dfdyT.Len())
+	}
+
+	// calculate drdy matrix
+	la.SpTriAdd(sol.rctriR, 1, sol.mTri, -sol.h, &sol.dfdyT) // rctriR := I - h * dfdy
+
+	// initialise linear solver
+	if !o.lsInit {
+		err = sol.lsolR.Init(sol.rctriR, sol.symmetric, sol.lsverbose, sol.ordering, sol.scaling, sol.comm)
+		if err != nil {
+			return
+		}
+		o.lsInit = true
+	}
+
+	// perform factorisation
+	sol.Ndecomp++
+	return sol.lsolR.Fact()
+}
+
 // add method to database //////////////////////////////////////////////////////////////////////////
 
 func init() {
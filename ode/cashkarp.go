@@ -0,0 +1,60 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import "github.com/cpmech/gosl/la"
+
+// CashKarp implements the (explicit) Cash-Karp 5(4) method
+// Cash-Karp 5(4), order=5, error_est_order=4, nstages=6
+type CashKarp struct {
+	dat   *erkdata
+	dense erkhermite
+}
+
+// Init initialises structure
+func (o *CashKarp) Init(distr bool) (err error) {
+	o.dat = &erkdata{
+		A: [][]float64{
+			{},
+			{1.0 / 5.0},
+			{3.0 / 40.0, 9.0 / 40.0},
+			{3.0 / 10.0, -9.0 / 10.0, 6.0 / 5.0},
+			{-11.0 / 54.0, 5.0 / 2.0, -70.0 / 27.0, 35.0 / 27.0},
+			{1631.0 / 55296.0, 175.0 / 512.0, 575.0 / 13824.0, 44275.0 / 110592.0, 253.0 / 4096.0},
+		},
+		B:  []float64{37.0 / 378.0, 0.0, 250.0 / 621.0, 125.0 / 594.0, 0.0, 512.0 / 1771.0},
+		Be: []float64{2825.0 / 27648.0, 0.0, 18575.0 / 48384.0, 13525.0 / 55296.0, 277.0 / 14336.0, 1.0 / 4.0},
+		C:  []float64{0.0, 1.0 / 5.0, 3.0 / 10.0, 3.0 / 5.0, 1.0, 7.0 / 8.0},
+	}
+	return nil
+}
+
+// Nstages returns the number of stages
+func (o *CashKarp) Nstages() int {
+	return 6
+}
+
+// Accept accepts update
+func (o *CashKarp) Accept(sol *Solver, y la.Vector) {
+	o.dense.accept(sol, y, 6, false)
+	y.Apply(1, sol.w[0]) // y := w (update y)
+}
+
+// Step steps update
+func (o *CashKarp) Step(sol *Solver, y0 la.Vector, x0 float64) (rerr float64, err error) {
+	return erkstep(o.dat, 6, false, sol, y0, x0)
+}
+
+// DenseOut produces dense output after Accept using Hermite interpolation (order 3). This requires
+// one extra function evaluation per step. See FwEuler.DenseOut
+func (o *CashKarp) DenseOut(sol *Solver, yout la.Vector, h, x float64, y la.Vector, xout float64) {
+	o.dense.denseOut(sol, yout, h, x, y, xout)
+}
+
+// add method to database //////////////////////////////////////////////////////////////////////////
+
+func init() {
+	rkmDB[CashKarpKind] = func() RKmethod { return new(CashKarp) }
+}
@@ -0,0 +1,461 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+	"sort"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+// DdeF defines the main function of a delay differential equation (DDE)
+//
+//   d{y}/dx := {f}(h=dx, x, {y}, {z_0}, {z_1}, ...)   with   {z_k} = {y}(x - τ_k)
+//
+//   Input:
+//     h -- current stepsize = dx
+//     x -- current x
+//     y -- current {y}
+//     z -- delayed values {y}(x - τ_k) [nlags][ndim]
+//   Output:
+//     f -- {f}(h, x, {y}, {z})
+//
+type DdeF func(f la.Vector, h, x float64, y la.Vector, z []la.Vector) error
+
+// LagF defines state-dependent delays τ_k(x, {y}) ≥ 0
+//   Input:
+//     x -- current x
+//     y -- current {y}
+//   Output:
+//     tau -- delays [nlags]
+type LagF func(tau la.Vector, x float64, y la.Vector) error
+
+// HistF defines the initial history {y}(x) for x < xa
+type HistF func(y la.Vector, x float64)
+
+// DdeSolver implements a solver for delay differential equations with constant or
+// state-dependent delays by means of the method of steps; i.e. an ODE solver is used and the
+// delayed values are computed from the history of the solution
+//
+//  The history is given by the dense output of the method (continuous extension) of every
+//  accepted step, which is stored as its values at the Chebyshev-Lobatto points of the step;
+//  these reproduce exactly the dense output polynomials of degree up to 7 (e.g. Dop853). If the
+//  method does not implement dense output, y and f = dy/dx are stored at the end of each step
+//  instead and interpolated with cubic Hermite polynomials. Delays smaller than the current step
+//  size are handled by extrapolating the last piece of the history.
+//
+//  Jumps in the derivatives of the solution propagate from the initial point xa (where the
+//  history function meets the solution) to xa + τ, xa + 2τ, ... The integration is restarted at
+//  these points up to MaxLevel levels of propagation. With constant delays, the points are
+//  computed in advance; with state-dependent delays, they are located with the events of the
+//  ODE solver; i.e. x - τ_k(x, y) = ξ where ξ is a point of the previous level. Thus, events
+//  cannot be added to Ode with state-dependent delays
+type DdeSolver struct {
+
+	// primary variables
+	ndim  int       // size of y
+	nlags int       // number of delays
+	fcn   DdeF      // dydx := f(x, y, z)
+	lags  []float64 // constant delays [nil if lagf is given]
+	lagf  LagF      // state-dependent delays [may be nil]
+	hist  HistF     // initial history
+	out   OutF      // output function
+
+	// underlying ODE solver (tolerances, output stations, statistics of the last segment, ...)
+	Ode *Solver
+
+	// flags
+	MaxLevel int  // max level of propagation of discontinuities
+	SaveXY   bool // save X values in an array (e.g. for plotting)
+
+	// output
+	Disc    []float64   // points where the integration was restarted due to discontinuities
+	Xvalues []float64   // X values if SaveXY is true
+	Yvalues [][]float64 // Y values if SaveXY is true [ndim][nsaved]
+
+	// stat variables (total)
+	Nfeval    int // number of calls to fcn
+	Nsteps    int // total number of substeps
+	Naccepted int // number of accepted substeps
+	Nrejected int // number of rejected substeps
+
+	// history
+	xa     float64     // initial x
+	hx     []float64   // x values (repeated at restarts) [npoints]
+	hy, hf []la.Vector // y and dy/dx values [npoints][ndim]; hf is nil at the end of steps with dense output
+	hd     []*ddePiece // dense output of the steps ending at hx; nil at restarts or without dense output [npoints]
+	dense  bool        // the method implements dense output
+
+	// workspace
+	tau  la.Vector   // delays
+	taue la.Vector   // delays for events
+	z    []la.Vector // delayed values
+	left bool        // compute the history from the left at discontinuities
+	next bool        // not the first segment (skip output of initial state)
+}
+
+// ddeNcheb is the number of Chebyshev-Lobatto points used to store the dense output of a step
+const ddeNcheb = 8
+
+// ddeCheb holds the Chebyshev-Lobatto points θ_k = (1 - cos(kπ/7))/2 in [0,1] and the
+// corresponding weights of the barycentric interpolation formula
+var ddeCheb, ddeChebW = func() (θ, w []float64) {
+	n := ddeNcheb - 1
+	θ, w = make([]float64, n+1), make([]float64, n+1)
+	for k := 0; k <= n; k++ {
+		θ[k] = (1.0 - math.Cos(float64(k)*math.Pi/float64(n))) / 2.0
+		w[k] = 1.0 - 2.0*float64(k%2)
+	}
+	w[0], w[n] = w[0]/2.0, w[n]/2.0
+	return
+}()
+
+// ddePiece holds the dense output of an accepted step from x0 to x0+h
+type ddePiece struct {
+	x0, h float64     // beginning of step and step size
+	y     []la.Vector // y at the Chebyshev-Lobatto points [ddeNcheb][ndim]
+}
+
+// eval evaluates the polynomial at x (x may be outside the step: extrapolation)
+func (o *ddePiece) eval(y la.Vector, x float64) {
+	θ := (x - o.x0) / o.h
+	var den float64
+	y.Fill(0)
+	for k, θk := range ddeCheb {
+		if θ == θk {
+			y.Apply(1, o.y[k])
+			return
+		}
+		c := ddeChebW[k] / (θ - θk)
+		den += c
+		la.VecAdd(y, 1, y, c, o.y[k])
+	}
+	y.Apply(1.0/den, y)
+}
+
+// NewDdeSolver returns a new DDE solver
+//  Input:
+//   method -- the ODE method kind; e.g. DoPri5kind. Explicit methods are recommended
+//   ndim   -- size of y
+//   fcn    -- function f(x, y, z) with z_k = y(x - τ_k)
+//   hist   -- history function: y(x) for x < xa
+//   lags   -- constant delays τ_k > 0 [may be nil if lagf is given]
+//   nlags  -- number of state-dependent delays; ignored if lags is given
+//   lagf   -- state-dependent delays τ_k(x, y) [may be nil if lags is given]
+//   out    -- output function [may be nil]
+func NewDdeSolver(method io.Enum, ndim int, fcn DdeF, hist HistF, lags []float64, nlags int, lagf LagF, out OutF) (o *DdeSolver) {
+
+	// check
+	if (lags == nil) == (lagf == nil) {
+		chk.Panic("either lags (constant) or lagf (state-dependent) must be given\n")
+	}
+	if hist == nil {
+		chk.Panic("the history function must be given\n")
+	}
+
+	// data
+	o = new(DdeSolver)
+	o.ndim = ndim
+	o.fcn = fcn
+	o.lagf = lagf
+	o.hist = hist
+	o.out = out
+	o.MaxLevel = 5
+	if lags != nil {
+		o.lags = utl.GetCopy(lags)
+		nlags = len(lags)
+		for k, τ := range lags {
+			if τ <= 0 {
+				chk.Panic("constant delays must be positive. τ[%d] = %g is invalid\n", k, τ)
+			}
+		}
+	}
+	o.nlags = nlags
+
+	// workspace
+	o.tau = la.NewVector(nlags)
+	o.taue = la.NewVector(nlags)
+	o.z = make([]la.Vector, nlags)
+	for k := 0; k < nlags; k++ {
+		o.z[k] = la.NewVector(ndim)
+	}
+
+	// ODE solver
+	o.Ode = NewSolver(method, ndim, func(f la.Vector, h, x float64, y la.Vector) error {
+		return o.rhs(f, h, x, y)
+	}, nil, nil, func(first bool, h, x float64, y la.Vector) (err error) {
+		if first && o.next {
+			return // initial state of the next segments
+		}
+		if o.SaveXY {
+			o.Xvalues = append(o.Xvalues, x)
+			for i := 0; i < o.ndim; i++ {
+				o.Yvalues[i] = append(o.Yvalues[i], y[i])
+			}
+		}
+		if o.out != nil {
+			err = o.out(first, h, x, y)
+		}
+		return
+	})
+	o.dense = o.Ode.dns != nil
+	o.Ode.stepHook = func(x float64, y la.Vector) error {
+		if o.dense {
+			o.recordStep(x, y)
+			return nil
+		}
+		o.left = true // f(x-, y) at the end of the step
+		defer func() { o.left = false }()
+		return o.record(x, y)
+	}
+	return
+}
+
+// Solve solves from (xa,ya) to (xb,yb) => find yb (stored in y)
+//  Note: Δx (the fixed step size or the output interval with adaptive steps) is slightly reduced
+//        (if necessary) to reach the points of discontinuity exactly
+func (o *DdeSolver) Solve(y la.Vector, x, xb, Δx float64, fixstp bool) (err error) {
+
+	// check
+	if xb < x {
+		err = chk.Err("xb == %v must be greater than x == %v\n", xb, x)
+		return
+	}
+
+	// initialise
+	o.xa = x
+	o.hx = o.hx[:0]
+	o.hy = o.hy[:0]
+	o.hf = o.hf[:0]
+	o.hd = o.hd[:0]
+	o.Disc = []float64{x}
+	o.next = false
+	o.Nfeval, o.Nsteps, o.Naccepted, o.Nrejected = 0, 0, 0, 0
+	if o.SaveXY {
+		o.Xvalues = make([]float64, 0)
+		o.Yvalues = make([][]float64, o.ndim)
+	}
+	eps := 1e-10 * max(1.0, math.Abs(xb))
+
+	// discontinuities with constant delays
+	var xdisc []float64
+	if o.lags != nil {
+		xdisc = o.propagate(x, xb, eps)
+	}
+
+	// discontinuities with state-dependent delays
+	type discPoint struct {
+		x     float64 // location
+		level int     // level of propagation
+	}
+	type discPair struct {
+		lag, idisc int // index of delay and discontinuity point
+	}
+	points := []discPoint{{x, 0}}
+	done := make(map[discPair]bool)
+
+	// segments
+	for x < xb-eps {
+
+		// history at the beginning of the segment (right-hand side)
+		err = o.record(x, y)
+		if err != nil {
+			return
+		}
+
+		// end of segment
+		xend := xb
+		if o.lags != nil {
+			for _, xd := range xdisc {
+				if xd > x+eps {
+					xend = xd
+					break
+				}
+			}
+		}
+
+		// events: x - τ_k(x,y) = ξ_j
+		var pairs []discPair
+		if o.lagf != nil {
+			o.Ode.ClearEvents()
+			for j, pt := range points {
+				if pt.level >= o.MaxLevel {
+					continue
+				}
+				for k := 0; k < o.nlags; k++ {
+					pair := discPair{k, j}
+					if done[pair] {
+						continue
+					}
+					ξ, kk := pt.x, k
+					o.Ode.AddEvent(func(xx float64, yy la.Vector) float64 {
+						o.lagf(o.taue, xx, yy)
+						return xx - o.taue[kk] - ξ
+					}, +1, true, nil)
+					pairs = append(pairs, pair)
+				}
+			}
+		}
+
+		// solve segment; Δx must divide the segment because Solver does not stop at xb otherwise
+		n := math.Ceil((xend-x)/Δx - 1e-10)
+		h := (xend - x) / max(1, n)
+		err = o.Ode.Solve(y, x, xend, h, fixstp)
+		o.Nfeval += o.Ode.Nfeval
+		o.Nsteps += o.Ode.Nsteps
+		o.Naccepted += o.Ode.Naccepted
+		o.Nrejected += o.Ode.Nrejected
+		if err != nil {
+			return
+		}
+		o.next = true
+		x = o.Ode.Xfinal
+		if xend < xb && math.Abs(x-xend) <= eps {
+			o.Disc = append(o.Disc, xend)
+		}
+
+		// terminal event added by the user (constant delays only)
+		if o.Ode.Stopped && o.lagf == nil {
+			return
+		}
+
+		// new discontinuity located by an event
+		if o.Ode.Stopped && x < xb-eps {
+			pair := pairs[o.Ode.EvtIdx[len(o.Ode.EvtIdx)-1]]
+			done[pair] = true
+			points = append(points, discPoint{x, points[pair.idisc].level + 1})
+			o.Disc = append(o.Disc, x)
+		}
+	}
+	return
+}
+
+// History computes y(x) for any x that has been already reached by Solve (or x < xa)
+func (o *DdeSolver) History(y la.Vector, x float64) {
+	n := len(o.hx)
+	if n == 0 || x < o.xa || (o.left && x <= o.xa) {
+		o.hist(y, x)
+		return
+	}
+
+	// find interval [x_i, x_{i+1}]
+	var i int
+	if o.left {
+		i = sort.SearchFloat64s(o.hx, x) - 1 // x_i < x ≤ x_{i+1}
+	} else {
+		i = sort.Search(n, func(k int) bool { return o.hx[k] > x }) - 1 // x_i ≤ x < x_{i+1}
+	}
+	if i >= n-1 {
+		i = n - 2 // extrapolation
+	}
+
+	// linear extrapolation from the last point (a restart)
+	if i < 0 || o.hx[i+1] == o.hx[i] {
+		la.VecAdd(y, 1, o.hy[n-1], x-o.hx[n-1], o.hf[n-1])
+		return
+	}
+
+	// dense output
+	if o.hd[i+1] != nil {
+		o.hd[i+1].eval(y, x)
+		return
+	}
+
+	// cubic Hermite interpolation
+	h := o.hx[i+1] - o.hx[i]
+	θ := (x - o.hx[i]) / h
+	θ2, θ3 := θ*θ, θ*θ*θ
+	a0, b0 := 2.0*θ3-3.0*θ2+1.0, (θ3-2.0*θ2+θ)*h
+	a1, b1 := -2.0*θ3+3.0*θ2, (θ3-θ2)*h
+	y0, f0, y1, f1 := o.hy[i], o.hf[i], o.hy[i+1], o.hf[i+1]
+	for m := 0; m < o.ndim; m++ {
+		y[m] = a0*y0[m] + b0*f0[m] + a1*y1[m] + b1*f1[m]
+	}
+}
+
+// rhs computes the delayed values and calls the DDE function
+func (o *DdeSolver) rhs(f la.Vector, h, x float64, y la.Vector) (err error) {
+	if o.lagf != nil {
+		err = o.lagf(o.tau, x, y)
+		if err != nil {
+			return
+		}
+	} else {
+		copy(o.tau, o.lags)
+	}
+	for k := 0; k < o.nlags; k++ {
+		if o.tau[k] <= 0 {
+			o.z[k].Apply(1, y)
+		} else {
+			o.History(o.z[k], x-o.tau[k])
+		}
+	}
+	return o.fcn(f, h, x, y, o.z)
+}
+
+// record stores x, y and f(x,y) in the history
+func (o *DdeSolver) record(x float64, y la.Vector) (err error) {
+	f := la.NewVector(o.ndim)
+	o.Nfeval++
+	err = o.rhs(f, o.Ode.h, x, y)
+	if err != nil {
+		return
+	}
+	o.hx = append(o.hx, x)
+	o.hy = append(o.hy, y.GetCopy())
+	o.hf = append(o.hf, f)
+	o.hd = append(o.hd, nil)
+	return
+}
+
+// recordStep stores the dense output of the last accepted step in the history
+//  Note: x may be smaller than the end of the step if an event occurred
+func (o *DdeSolver) recordStep(x float64, y la.Vector) {
+	sol := o.Ode
+	p := &ddePiece{x0: sol.xend - sol.hprev, h: sol.hprev, y: make([]la.Vector, ddeNcheb)}
+	for k, θ := range ddeCheb {
+		p.y[k] = la.NewVector(o.ndim)
+		sol.denseOut(p.y[k], p.h, sol.xend, sol.yend, p.x0+θ*p.h)
+	}
+	o.hx = append(o.hx, x)
+	o.hy = append(o.hy, y.GetCopy())
+	o.hf = append(o.hf, nil)
+	o.hd = append(o.hd, p)
+}
+
+// propagate computes the points of discontinuity xa + Σ n_k τ_k (with Σ n_k ≤ MaxLevel) in
+// (xa, xb) for constant delays
+func (o *DdeSolver) propagate(xa, xb, eps float64) (xdisc []float64) {
+	level := []float64{xa}
+	for l := 0; l < o.MaxLevel; l++ {
+		var next []float64
+		for _, ξ := range level {
+			for _, τ := range o.lags {
+				xd := ξ + τ
+				if xd >= xb-eps {
+					continue
+				}
+				dup := false
+				for _, xo := range xdisc {
+					if math.Abs(xo-xd) <= eps {
+						dup = true
+						break
+					}
+				}
+				if !dup {
+					xdisc = append(xdisc, xd)
+					next = append(next, xd)
+				}
+			}
+		}
+		level = next
+	}
+	sort.Float64s(xdisc)
+	return
+}
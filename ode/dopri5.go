@@ -10,6 +10,10 @@ import "github.com/cpmech/gosl/la"
 // Dormand-Prince 5(4), order=5, error_est_order=4, nstages=7
 type DoPri5 struct {
 	dat *erkdata
+
+	// dense output
+	d     []float64   // coefficients of the continuous extension
+	rcont []la.Vector // coefficients of the interpolating polynomial [5][ndim]
 }
 
 // Init initialises structure
@@ -26,6 +30,8 @@ func (o *DoPri5) Init(distr bool) (err error) {
 		Be: []float64{5179.0 / 57600.0, 0.0, 7571.0 / 16695.0, 393.0 / 640.0, -92097.0 / 339200.0, 187.0 / 2100.0, 1.0 / 40.0},
 		C:  []float64{0.0, 1.0 / 5.0, 3.0 / 10.0, 4.0 / 5.0, 8.0 / 9.0, 1.0, 1.0},
 	}
+	o.d = []float64{-12715105075.0 / 11282082432.0, 0.0, 87487479700.0 / 32700410799.0, -10690763975.0 / 1880347072.0,
+		701980252875.0 / 199316789632.0, -1453857185.0 / 822651844.0, 69997945.0 / 29380423.0}
 	return nil
 }
 
@@ -36,7 +42,30 @@ func (o *DoPri5) Nstages() int {
 
 // Accept accepts update
 func (o *DoPri5) Accept(sol *Solver, y la.Vector) {
-	y.Apply(1, sol.w[0]) // y := w (update y)
+
+	// coefficients for dense output
+	if len(o.rcont) == 0 {
+		o.rcont = make([]la.Vector, 5)
+		for k := 0; k < 5; k++ {
+			o.rcont[k] = la.NewVector(sol.ndim)
+		}
+	}
+	h := sol.h
+	for m := 0; m < sol.ndim; m++ {
+		ydiff := sol.w[0][m] - y[m]
+		bspl := h*sol.f[0][m] - ydiff
+		o.rcont[0][m] = y[m]
+		o.rcont[1][m] = ydiff
+		o.rcont[2][m] = bspl
+		o.rcont[3][m] = ydiff - h*sol.f[6][m] - bspl
+		o.rcont[4][m] = 0
+		for i := 0; i < 7; i++ {
+			o.rcont[4][m] += h * o.d[i] * sol.f[i][m]
+		}
+	}
+
+	// update y
+	y.Apply(1, sol.w[0]) // y := w
 }
 
 // Step steps update
@@ -44,6 +73,16 @@ func (o *DoPri5) Step(sol *Solver, y0 la.Vector, x0 float64) (rerr float64, err
 	return erkstep(o.dat, 7, true, sol, y0, x0)
 }
 
+// DenseOut produces dense output after Accept using the continuous extension of order 4
+// by Shampine (see [1]). See FwEuler.DenseOut
+func (o *DoPri5) DenseOut(sol *Solver, yout la.Vector, h, x float64, y la.Vector, xout float64) {
+	θ := 1.0 + (xout-x)/h
+	θ1 := 1.0 - θ
+	for m := 0; m < len(y); m++ {
+		yout[m] = o.rcont[0][m] + θ*(o.rcont[1][m]+θ1*(o.rcont[2][m]+θ*(o.rcont[3][m]+θ1*o.rcont[4][m])))
+	}
+}
+
 // add method to database //////////////////////////////////////////////////////////////////////////
 
 func init() {
@@ -11,10 +11,11 @@ import (
 )
 
 // erkdata holds data for an explicit Runge-Kutta method
+//  Note: only the lower triangular part of A is accessed; thus the rows of A may have length i
 type erkdata struct {
 	A  [][]float64 // a coefficients
 	B  []float64   // b coefficients
-	Be []float64   // be coefficients
+	Be []float64   // be coefficients [may be nil => no error estimate; fixed steps only]
 	C  []float64   // c coefficients
 }
 
@@ -22,14 +23,44 @@ type erkdata struct {
 func erkstep(o *erkdata, nStages int, useFprev bool, sol *Solver, y0 la.Vector, x0 float64) (rerr float64, err error) {
 
 	// update
+	err = erkstages(o, nStages, useFprev, sol, y0, x0)
+	if err != nil {
+		return
+	}
+	if o.Be == nil {
+		return 1e+20, nil // must not be used with automatic substepping
+	}
+
+	// error estimation
+	var lerrm float64 // m component of local error estimate
+	for m := 0; m < sol.ndim; m++ {
+		lerrm = 0.0
+		for i := 0; i < nStages; i++ {
+			lerrm += (o.Be[i] - o.B[i]) * sol.f[i][m] * sol.h
+		}
+		rerr += math.Pow(lerrm/sol.scal[m], 2.0)
+	}
+	rerr = max(math.Sqrt(rerr/float64(sol.ndim)), 1.0e-10)
+	return
+}
+
+// erkstages computes the stages of an explicit Runge-Kutta method and the updated y
+// (stored in w[0])
+func erkstages(o *erkdata, nStages int, useFprev bool, sol *Solver, y0 la.Vector, x0 float64) (err error) {
+
+	// stages
 	for i := 0; i < nStages; i++ {
 		sol.u[i] = x0 + sol.h*o.C[i]
 		sol.v[i].Apply(1, y0) // v[i] := y
 		for j := 0; j < i; j++ {
-			la.VecAdd(sol.v[i], 1, sol.v[i], sol.h*o.A[i][j], sol.f[j]) // v[i] += h*a[i][j]*f[j]
+			if o.A[i][j] != 0 {
+				la.VecAdd(sol.v[i], 1, sol.v[i], sol.h*o.A[i][j], sol.f[j]) // v[i] += h*a[i][j]*f[j]
+			}
 		}
 		if i == 0 && useFprev && !sol.first {
-			sol.f[i].Apply(1, sol.f[nStages-1]) // f[i] := f[nstg-1]
+			if !sol.reject { // otherwise, f[0] = f(x0,y0) is still available from the rejected step
+				sol.f[i].Apply(1, sol.f[nStages-1]) // f[i] := f[nstg-1]
+			}
 		} else {
 			sol.Nfeval++
 			err = sol.fcn(sol.f[i], sol.h, sol.u[i], sol.v[i])
@@ -39,16 +70,50 @@ func erkstep(o *erkdata, nStages int, useFprev bool, sol *Solver, y0 la.Vector,
 		}
 	}
 
-	// error estimation
-	var lerrm float64 // m component of local error estimate
+	// updated y
 	for m := 0; m < sol.ndim; m++ {
-		lerrm = 0.0
+		sol.w[0][m] = y0[m]
 		for i := 0; i < nStages; i++ {
 			sol.w[0][m] += o.B[i] * sol.f[i][m] * sol.h
-			lerrm += (o.Be[i] - o.B[i]) * sol.f[i][m] * sol.h
 		}
-		rerr += math.Pow(lerrm/sol.scal[m], 2.0)
 	}
-	rerr = max(math.Sqrt(rerr/float64(sol.ndim)), 1.0e-10)
 	return
 }
+
+// erkhermite implements dense output for explicit Runge-Kutta methods using the cubic Hermite
+// polynomial that matches y and f = dy/dx at both ends of the step (see [1])
+type erkhermite struct {
+	y0, f0, f1 la.Vector // y and f at the beginning of the step and f at the end of the step
+	f1ok       bool      // f1 is available
+}
+
+// accept saves y and f at the beginning of the step. It must be called before updating y
+//  Input:
+//   fsal -- the last stage (nStages-1) is f(x1,y1); i.e. first same as last
+func (o *erkhermite) accept(sol *Solver, y0 la.Vector, nStages int, fsal bool) {
+	if len(o.y0) != sol.ndim {
+		o.y0 = la.NewVector(sol.ndim)
+		o.f0 = la.NewVector(sol.ndim)
+		o.f1 = la.NewVector(sol.ndim)
+	}
+	o.y0.Apply(1, y0)
+	o.f0.Apply(1, sol.f[0])
+	o.f1ok = fsal
+	if fsal {
+		o.f1.Apply(1, sol.f[nStages-1])
+	}
+}
+
+// denseOut computes y(xout) with x-h ≤ xout ≤ x. f(x,y) is computed if not available yet
+func (o *erkhermite) denseOut(sol *Solver, yout la.Vector, h, x float64, y la.Vector, xout float64) {
+	if !o.f1ok {
+		sol.Nfeval++
+		sol.fcn(o.f1, h, x, y)
+		o.f1ok = true
+	}
+	θ := 1.0 + (xout-x)/h
+	for m := 0; m < len(y); m++ {
+		dy := y[m] - o.y0[m]
+		yout[m] = o.y0[m] + θ*dy + θ*(θ-1.0)*((1.0-2.0*θ)*dy+(θ-1.0)*h*o.f0[m]+θ*h*o.f1[m])
+	}
+}
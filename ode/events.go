@@ -0,0 +1,190 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+)
+
+// EventF defines an event function g(x, {y}). An event occurs when g crosses zero
+type EventF func(x float64, y la.Vector) float64
+
+// EventA defines a function to be called when an event occurs
+//   Input:
+//     idx -- index of the event (as returned by AddEvent)
+//     x   -- location of the event
+//     y   -- state at the event. It can be modified before the integration continues
+//   Output:
+//     stop  -- stop the integration
+//     error -- this function can return an error to force stopping the simulation
+type EventA func(idx int, x float64, y la.Vector) (stop bool, err error)
+
+// event holds the data of a registered event
+type event struct {
+	g         EventF  // event function
+	direction int     // 0: all crossings; +1: g increasing only; -1: g decreasing only
+	terminal  bool    // stop integration when the event occurs
+	action    EventA  // action [may be nil]
+	gprev     float64 // value of g at the beginning of the current step
+}
+
+// AddEvent registers an event function whose zero crossings are located during Solve
+//  Input:
+//   g         -- event function g(x,y)
+//   direction -- 0: all crossings; +1: only when g increases (- to +); -1: only when g decreases (+ to -)
+//   terminal  -- stop the integration when the event occurs
+//   action    -- function called when the event occurs; it may modify y [may be nil]
+//  Output:
+//   idx -- index of the event; e.g. to be compared with EvtIdx
+//  Note: only the first crossing of g within a step is detected; thus, the maximum step size
+//        must be small enough to resolve events that occur in pairs
+func (o *Solver) AddEvent(g EventF, direction int, terminal bool, action EventA) (idx int) {
+	if g == nil {
+		chk.Panic("event function must not be nil")
+	}
+	if direction < -1 || direction > 1 {
+		chk.Panic("direction must be -1, 0 or +1. direction = %d is invalid", direction)
+	}
+	o.events = append(o.events, &event{g: g, direction: direction, terminal: terminal, action: action})
+	return len(o.events) - 1
+}
+
+// ClearEvents removes all registered events
+func (o *Solver) ClearEvents() {
+	o.events = nil
+}
+
+// initEvents initialises the event variables at the beginning of Solve
+func (o *Solver) initEvents(x float64, y la.Vector) {
+	o.EvtIdx = make([]int, 0)
+	o.EvtX = make([]float64, 0)
+	o.EvtY = make([][]float64, 0)
+	for _, e := range o.events {
+		e.gprev = e.g(x, y)
+	}
+}
+
+// crossed tells whether g crossed zero from gprev to gnew considering the direction of the event
+func (o *event) crossed(gnew float64) bool {
+	if o.gprev == 0 || gnew*o.gprev > 0 {
+		return false
+	}
+	switch o.direction {
+	case +1:
+		return o.gprev < 0
+	case -1:
+		return o.gprev > 0
+	}
+	return true
+}
+
+// locateEvent finds the first event occurring in the accepted step [x0, x1] using dense output
+//  Input:
+//   y -- state at x1
+//  Output:
+//   idx -- index of the event that occurred or -1 if no event was found
+//   xe  -- location of the event or x1 if no event was found
+func (o *Solver) locateEvent(x0, x1 float64, y la.Vector) (idx int, xe float64) {
+
+	// find candidates
+	idx, xe = -1, x1
+	found := false
+	for _, e := range o.events {
+		if e.crossed(e.g(x1, y)) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		for _, e := range o.events {
+			e.gprev = e.g(x1, y)
+		}
+		return
+	}
+
+	// function along the step
+	h := x1 - x0
+	gfcn := func(e *event, xi float64) float64 {
+		o.denseOut(o.ydns, h, x1, y, xi)
+		return e.g(xi, o.ydns)
+	}
+
+	// locate the earliest crossing
+	for k, e := range o.events {
+		g1 := e.g(x1, y)
+		if !e.crossed(g1) {
+			continue
+		}
+		xk := x1
+		if g1 != 0 {
+			xk = o.findRoot(func(xi float64) float64 { return gfcn(e, xi) }, x0, e.gprev, x1, g1)
+		}
+		if idx < 0 || xk < xe {
+			idx, xe = k, xk
+		}
+	}
+	return
+}
+
+// triggerEvent records the event idx at (x,y) and calls its action function
+//  Output:
+//   stop -- the integration must be stopped
+func (o *Solver) triggerEvent(idx int, x float64, y la.Vector) (stop bool, err error) {
+	o.EvtIdx = append(o.EvtIdx, idx)
+	o.EvtX = append(o.EvtX, x)
+	o.EvtY = append(o.EvtY, y.GetCopy())
+	e := o.events[idx]
+	stop = e.terminal
+	if e.action != nil {
+		var astop bool
+		astop, err = e.action(idx, x, y)
+		if err != nil {
+			return
+		}
+		stop = stop || astop
+	}
+	for k, ev := range o.events {
+		if k == idx {
+			ev.gprev = 0 // do not detect the same crossing again
+		} else {
+			ev.gprev = ev.g(x, y)
+		}
+	}
+	return
+}
+
+// findRoot finds the root of g in [xa, xb] with g(xa)⋅g(xb) < 0 using the Illinois variant of
+// the regula falsi method
+func (o *Solver) findRoot(g func(x float64) float64, xa, ga, xb, gb float64) float64 {
+	tol := o.EvtTol * max(1.0, math.Abs(xb))
+	side := 0
+	for it := 0; it < o.EvtMaxIt; it++ {
+		if xb-xa <= tol {
+			break
+		}
+		xc := (xa*gb - xb*ga) / (gb - ga)
+		gc := g(xc)
+		if gc == 0 {
+			return xc
+		}
+		if gc*gb > 0 { // root in [xa, xc]
+			xb, gb = xc, gc
+			if side == -1 {
+				ga /= 2.0
+			}
+			side = -1
+		} else { // root in [xc, xb]
+			xa, ga = xc, gc
+			if side == +1 {
+				gb /= 2.0
+			}
+			side = +1
+		}
+	}
+	return xb // the returned value is always on or after the crossing
+}
@@ -8,6 +8,7 @@ import "github.com/cpmech/gosl/la"
 
 // FwEuler implements the (explicit) Forward Euler method
 type FwEuler struct {
+	yold la.Vector // y before the step (for dense output)
 }
 
 // Init initialises structure
@@ -31,12 +32,31 @@ func (o *FwEuler) Step(sol *Solver, y0 la.Vector, x0 float64) (rerr float64, err
 	if err != nil {
 		return
 	}
+	if len(o.yold) != len(y0) {
+		o.yold = la.NewVector(len(y0))
+	}
+	o.yold.Apply(1, y0)
 	for i := 0; i < sol.ndim; i++ {
 		y0[i] += sol.h * sol.f[0][i]
 	}
 	return 1e+20, err // must not be used with automatic substepping
 }
 
+// DenseOut produces dense output (linear interpolation) after Accept
+//   Input:
+//     h    -- step size of the last accepted step
+//     x    -- x at the end of the last accepted step
+//     y    -- y at the end of the last accepted step
+//     xout -- x where output is required; x-h ≤ xout ≤ x
+//   Output:
+//     yout -- y at xout
+func (o *FwEuler) DenseOut(sol *Solver, yout la.Vector, h, x float64, y la.Vector, xout float64) {
+	θ := 1.0 + (xout-x)/h
+	for i := 0; i < len(y); i++ {
+		yout[i] = o.yold[i] + θ*(y[i]-o.yold[i])
+	}
+}
+
 // add method to database //////////////////////////////////////////////////////////////////////////
 
 func init() {
@@ -26,6 +26,36 @@ var (
 
 	// Radau5kind specifies the Radau5 method (implicit)
 	Radau5kind = io.NewEnum("Radau5", "ode", "R", "Radau5 (implicit)")
+
+	// Rk4kind specifies the classical Runge-Kutta method of order 4 (explicit)
+	Rk4kind = io.NewEnum("Rk4", "ode", "RK4", "classical Runge-Kutta 4 (explicit)")
+
+	// BoSh3kind specifies the Bogacki-Shampine 3(2) method (explicit)
+	BoSh3kind = io.NewEnum("BoSh3", "ode", "BS", "Bogacki-Shampine 3(2) (explicit)")
+
+	// CashKarpKind specifies the Cash-Karp 5(4) method (explicit)
+	CashKarpKind = io.NewEnum("CashKarp", "ode", "CK", "Cash-Karp 5(4) (explicit)")
+
+	// Verner65kind specifies the Verner 6(5) method (explicit)
+	Verner65kind = io.NewEnum("Verner65", "ode", "V6", "Verner 6(5) (explicit)")
+
+	// Dop853kind specifies the Dormand-Prince 8(5,3) method (explicit)
+	Dop853kind = io.NewEnum("Dop853", "ode", "D8", "Dormand-Prince 8(5,3) (explicit)")
+
+	// BdfKind specifies the variable-order backward differentiation formulae (implicit, multistep)
+	BdfKind = io.NewEnum("Bdf", "ode", "BDF", "variable-order BDF (implicit, multistep)")
+
+	// NdfKind specifies the variable-order numerical differentiation formulae (implicit, multistep)
+	NdfKind = io.NewEnum("Ndf", "ode", "NDF", "variable-order NDF (implicit, multistep)")
+
+	// Ros2kind specifies the Rosenbrock method ROS2 (linearly implicit)
+	Ros2kind = io.NewEnum("Ros2", "ode", "ROS2", "Rosenbrock ROS2 (linearly implicit)")
+
+	// Ros3pKind specifies the Rosenbrock method ROS3P (linearly implicit)
+	Ros3pKind = io.NewEnum("Ros3p", "ode", "ROS3P", "Rosenbrock ROS3P (linearly implicit)")
+
+	// Rodas4kind specifies the Rosenbrock method RODAS (linearly implicit)
+	Rodas4kind = io.NewEnum("Rodas4", "ode", "RODAS4", "Rosenbrock RODAS4 (linearly implicit)")
 )
 
 // RKmethod defines the required functions of Runge-Kutta method
@@ -36,6 +66,30 @@ type RKmethod interface {
 	Step(o *Solver, y0 la.Vector, x0 float64) (rerr float64, err error) // step update
 }
 
+// denseOutputter defines an optional interface for methods that provide dense output (continuous
+// extensions). DenseOut is called after Accept with the step size h and the state y at the end of
+// the last accepted step; yout is computed at x-h ≤ xout ≤ x. Methods that do not implement this
+// interface are interpolated linearly between the beginning and the end of the step
+type denseOutputter interface {
+	DenseOut(o *Solver, yout la.Vector, h, x float64, y la.Vector, xout float64)
+}
+
+// stepSizer defines an optional interface for methods that select the step size (and order) by
+// themselves; e.g. variable-order multistep methods. NextH is called after Accept or after a
+// rejected step
+type stepSizer interface {
+	NextH(o *Solver, accepted bool) (hnew float64)
+}
+
+// sensStepper defines an optional interface for implicit methods that solve the forward
+// sensitivity equations with a staggered corrector, i.e. after the state has been updated,
+// reusing the factorisation of the iteration matrix of the last step (see FwdSens). SensStep is
+// called after Accept with the state y0 at x0 and the new state y; s is updated in place
+type sensStepper interface {
+	SensStep(o *Solver, sens *FwdSens, x0 float64, y0, y la.Vector, s []la.Vector) (err error)
+	SensDenseOut(o *Solver, sout []la.Vector, h, x float64, s []la.Vector, xout float64)
+}
+
 // rkmMaker defines a function that makes RKmethods
 type rkmMaker func() RKmethod
 
@@ -9,7 +9,8 @@ import "github.com/cpmech/gosl/la"
 // MoEuler implements the (explicit) Modified Euler method
 // Modified-Euler 2(1), order=2, error_est_order=2, nstages=2
 type MoEuler struct {
-	dat *erkdata
+	dat  *erkdata
+	yold la.Vector // y before the step (for dense output)
 }
 
 // Init initialises structure
@@ -33,6 +34,10 @@ func (o *MoEuler) Nstages() int {
 
 // Accept accepts update
 func (o *MoEuler) Accept(sol *Solver, y la.Vector) {
+	if len(o.yold) != len(y) {
+		o.yold = la.NewVector(len(y))
+	}
+	o.yold.Apply(1, y)
 	y.Apply(1, sol.w[0]) // y := w (update y)
 }
 
@@ -41,6 +46,14 @@ func (o *MoEuler) Step(sol *Solver, y0 la.Vector, x0 float64) (rerr float64, err
 	return erkstep(o.dat, 2, true, sol, y0, x0)
 }
 
+// DenseOut produces dense output (linear interpolation) after Accept. See FwEuler.DenseOut
+func (o *MoEuler) DenseOut(sol *Solver, yout la.Vector, h, x float64, y la.Vector, xout float64) {
+	θ := 1.0 + (xout-x)/h
+	for i := 0; i < len(y); i++ {
+		yout[i] = o.yold[i] + θ*(y[i]-o.yold[i])
+	}
+}
+
 // add method to database //////////////////////////////////////////////////////////////////////////
 
 func init() {
@@ -12,9 +12,12 @@
 //     [2] Hairer E, Wanner G (1996). Solving Ordinary Differential Equations II: Stiff and
 //         Differential-Algebraic Problems. Springer Series in Computational Mathematics,
 //         Vol. 14, Berlin, Germany, 614 p.
+//     [3] Shampine LF, Reichelt MW (1997). The MATLAB ODE Suite. SIAM Journal on Scientific
+//         Computing, 18(1):1-22.
 package ode
 
 import (
+	"context"
 	"math"
 
 	"github.com/cpmech/gosl/chk"
@@ -63,12 +66,27 @@ type Solver struct {
 	UseRmsNorm bool    // use RMS norm instead of Euclidian in BwEuler
 	Verbose    bool    // be more verbose, e.g. during iterations
 	SaveXY     bool    // save X values in an array (e.g. for plotting)
+	EvtTol     float64 // tolerance to locate events (relative to max(1,|x|))
+	EvtMaxIt   int     // max num iterations to locate events
+	MaxOrd     int     // max order of variable-order multistep methods (BDF/NDF) in [1, 5]
+	Autonom    bool    // f does not depend on x; thus ∂f/∂x is not computed by Rosenbrock methods
+
+	// output stations
+	Stations []float64 // if not empty, OutF is called and X/Y are saved only at these x values (ascending); uses dense output
+
+	// cancellation and progress report
+	Monitor utl.Monitor // it = number of substeps, residual = local error, step = h
 
 	// output
 	IdxSave int         // current index in Xvalues and Yvalues == last output
 	Hvalues []float64   // h values if SaveXY is true [IdxSave]
 	Xvalues []float64   // X values if SaveXY is true [IdxSave]
 	Yvalues [][]float64 // Y values if SaveXY is true [ndim][IdxSave]
+	EvtIdx  []int       // indices of the events that occurred (see AddEvent) [nevents]
+	EvtX    []float64   // X values at the events [nevents]
+	EvtY    [][]float64 // Y values at the events, before calling the actions [nevents][ndim]
+	Stopped bool        // the integration was stopped by an event before reaching xb
+	Xfinal  float64     // x at the end of Solve; e.g. location of the terminal event if Stopped
 
 	// derived variables
 	Distr bool    // MPI distributed execution. automatically set ON in Init if mpi is on and there are more then one processor.
@@ -87,6 +105,7 @@ type Solver struct {
 
 	// control variables
 	doinit    bool    // flag indicating 'do initialisation' within step function
+	fixstp    bool    // fixed steps
 	first     bool    // first substep
 	last      bool    // last substep
 	reject    bool    // reject step
@@ -123,9 +142,24 @@ type Solver struct {
 	rhs   la.Vector   // Radau5
 	dfdyT la.Triplet  // Jacobian (triplet)
 
+	// rosenbrock variables
+	dfdx la.Vector // ∂f/∂x (numerical)
+
 	// interpolation (radau5)
 	ycol []la.Vector // colocation values
 
+	// dense output and events
+	events []*event       // registered events
+	istat  int            // index of the next station
+	dns    denseOutputter // dense output of the method; nil if linear interpolation is used
+	ybeg   la.Vector      // y at the beginning of the last accepted step (linear interpolation)
+	xend   float64        // x at the end of the last accepted step
+	yend   la.Vector      // y at the end of the last accepted step
+	ydns   la.Vector      // y computed with dense output
+
+	// hook called after each accepted step with the final x and y (e.g. to store the history of DdeSolver)
+	stepHook func(x float64, y la.Vector) error
+
 	// linear systems solver
 	symmetric bool              // symmetric
 	lsverbose bool              // verbose
@@ -166,6 +200,9 @@ func NewSolver(method io.Enum, ndim int, fcn Func, jac JacF, M *la.Triplet, out
 	o.LerrStrat = 3
 	o.Pll = true
 	o.UseRmsNorm = true
+	o.EvtTol = 1e-12
+	o.EvtMaxIt = 100
+	o.MaxOrd = 5
 	o.SetTol(o.Atol, o.Rtol)
 
 	// derived variables
@@ -198,11 +235,17 @@ func NewSolver(method io.Enum, ndim int, fcn Func, jac JacF, M *la.Triplet, out
 	o.method = method
 	o.rkm = NewRKmethod(method)
 	o.rkm.Init(o.Distr)
+	o.dns, _ = o.rkm.(denseOutputter)
 	nstg := o.rkm.Nstages()
 
 	// allocate step variables
 	o.f0 = la.NewVector(o.ndim)
 	o.scal = la.NewVector(o.ndim)
+	o.yend = la.NewVector(o.ndim)
+	o.ydns = la.NewVector(o.ndim)
+	if o.dns == nil {
+		o.ybeg = la.NewVector(o.ndim)
+	}
 
 	// allocate rk variables
 	o.u = la.NewVector(nstg)
@@ -248,6 +291,8 @@ func (o *Solver) SetTol(atol, rtol float64) {
 }
 
 // Solve solves from (xa,ya) to (xb,yb) => find yb (stored in y)
+//  Note: if a terminal event occurs (see AddEvent), Solve returns with Stopped = true and with
+//        the state at the event stored in y. The location of the event is given by Xfinal
 func (o *Solver) Solve(y la.Vector, x, xb, Δx float64, fixstp bool) (err error) {
 
 	// check
@@ -255,6 +300,12 @@ func (o *Solver) Solve(y la.Vector, x, xb, Δx float64, fixstp bool) (err error)
 		err = chk.Err("xb == %v must be greater than x == %v\n", xb, x)
 		return
 	}
+	for i := 1; i < len(o.Stations); i++ {
+		if o.Stations[i] < o.Stations[i-1] {
+			err = chk.Err("stations must be in ascending order\n")
+			return
+		}
+	}
 
 	// derived variables
 	o.fnewt = max(10.0*o.Eps/o.Rtol, min(0.03, math.Sqrt(o.Rtol)))
@@ -273,12 +324,19 @@ func (o *Solver) Solve(y la.Vector, x, xb, Δx float64, fixstp bool) (err error)
 		o.out(true, o.h, x, y)
 	}
 
+	// stations
+	o.istat = 0
+	for o.istat < len(o.Stations) && o.Stations[o.istat] <= x {
+		o.istat++ // the initial state is always output
+	}
+
 	// save X
 	o.IdxSave = 0
 	if o.SaveXY {
-		o.Hvalues = make([]float64, o.NmaxSS+1)
-		o.Xvalues = make([]float64, o.NmaxSS+1)
-		o.Yvalues = utl.Alloc(o.ndim, o.NmaxSS+1)
+		nmax := utl.Imax(o.NmaxSS, len(o.Stations)) + 1
+		o.Hvalues = make([]float64, nmax)
+		o.Xvalues = make([]float64, nmax)
+		o.Yvalues = utl.Alloc(o.ndim, nmax)
 		o.Xvalues[o.IdxSave] = x
 		for i := 0; i < o.ndim; i++ {
 			o.Yvalues[i][o.IdxSave] = y[i]
@@ -298,6 +356,7 @@ func (o *Solver) Solve(y la.Vector, x, xb, Δx float64, fixstp bool) (err error)
 
 	// control variables
 	o.doinit = true
+	o.fixstp = fixstp
 	o.first = true
 	o.last = false
 	o.reject = false
@@ -311,6 +370,12 @@ func (o *Solver) Solve(y la.Vector, x, xb, Δx float64, fixstp bool) (err error)
 	o.hopt = o.h
 	o.theta = o.ThetaMax
 
+	// events
+	o.Stopped = false
+	o.initEvents(x, y)
+	ievt := -1     // index of the event that occurred in the last step
+	var x0 float64 // x at the beginning of the step
+
 	// local error indicator
 	var rerr float64
 
@@ -326,6 +391,7 @@ func (o *Solver) Solve(y la.Vector, x, xb, Δx float64, fixstp bool) (err error)
 	defer func() {
 		o.lsolR.Free()
 		o.lsolC.Free()
+		o.Xfinal = x
 	}()
 
 	// first scaling variable
@@ -337,8 +403,12 @@ func (o *Solver) Solve(y la.Vector, x, xb, Δx float64, fixstp bool) (err error)
 		if o.Verbose {
 			io.Pfgreen("x = %v\n", x)
 		}
-		for x < xb {
-			//if x + o.h > xb { o.h = xb - x }
+		xend := xb - 1e-10*max(1.0, math.Abs(xb)) // avoid an extra step due to round-off errors
+		for x < xend {
+
+			// events may shift the grid; the last step must not overshoot xb
+			o.h = min(o.h, xb-x)
+
 			if o.jac == nil { // numerical Jacobian
 				if o.method == Radau5kind {
 					o.Nfeval++
@@ -348,29 +418,41 @@ func (o *Solver) Solve(y la.Vector, x, xb, Δx float64, fixstp bool) (err error)
 			o.reuseJdec = false
 			o.reuseJ = false
 			o.jacIsOK = false
-			o.rkm.Step(o, y, x)
+			if o.dns == nil {
+				o.ybeg.Apply(1, y)
+			}
+			_, err = o.rkm.Step(o, y, x)
+			if err != nil {
+				return
+			}
 			o.Nsteps++
 			o.doinit = false
 			o.first = false
 			o.hprev = o.h
+			x0 = x
 			x += o.h
 			o.rkm.Accept(o, y)
-			if o.out != nil {
-				o.out(false, o.h, x, y)
+			x, ievt, err = o.endOfStep(x0, x, y)
+			if err != nil {
+				return
 			}
-			if o.SaveXY {
-				if o.IdxSave < o.NmaxSS {
-					o.Hvalues[o.IdxSave] = o.h
-					o.Xvalues[o.IdxSave] = x
-					for i := 0; i < o.ndim; i++ {
-						o.Yvalues[i][o.IdxSave] = y[i]
-					}
-					o.IdxSave++
-				}
+			err = o.Monitor.Check("ode.Solver", o.Nsteps, 0, o.h)
+			if err != nil {
+				return
 			}
 			if o.Verbose {
 				io.Pfgreen("x = %v\n", x)
 			}
+			if ievt >= 0 {
+				o.Stopped, err = o.triggerEvent(ievt, x, y)
+				if err != nil || o.Stopped {
+					return
+				}
+				la.VecScaleAbs(o.scal, o.Atol, o.Rtol, y)
+				o.Nfeval++
+				o.fcn(o.f0, o.h, x, y)
+				o.first = true // restart from the event; e.g. f[0] must not be reused by FSAL methods
+			}
 		}
 		return
 	}
@@ -384,7 +466,15 @@ func (o *Solver) Solve(y la.Vector, x, xb, Δx float64, fixstp bool) (err error)
 	var dxratio float64
 	var failed bool
 	for x < xb {
-		dxmax, xstep = Δx, x+Δx
+		dxmax, xstep = Δx, min(x+Δx, xb)
+		if o.last { // continue with the step size selected before the end of the previous interval
+			o.h = min(dxnew, dxmax)
+			o.last = false
+		}
+		if x+o.h-xstep >= 0.0 {
+			o.last = true
+			o.h = xstep - x
+		}
 		failed = false
 		for iss := 0; iss < o.NmaxSS+1; iss++ {
 
@@ -402,7 +492,16 @@ func (o *Solver) Solve(y la.Vector, x, xb, Δx float64, fixstp bool) (err error)
 				break
 			}
 
+			// cancellation and progress
+			err = o.Monitor.Check("ode.Solver", o.Nsteps, rerr, o.h)
+			if err != nil {
+				return
+			}
+
 			// step update
+			if o.dns == nil {
+				o.ybeg.Apply(1, y)
+			}
 			rerr, err = o.rkm.Step(o, y, x)
 
 			// initialise only once
@@ -432,27 +531,46 @@ func (o *Solver) Solve(y la.Vector, x, xb, Δx float64, fixstp bool) (err error)
 
 				// update x and y
 				o.hprev = o.h
+				x0 = x
 				x += o.h
 				o.rkm.Accept(o, y)
 
-				// output
-				if o.out != nil {
-					o.out(false, o.h, x, y)
+				// events and output: x and y are moved to the event, if any
+				x, ievt, err = o.endOfStep(x0, x, y)
+				if err != nil {
+					return
 				}
 
-				// save X value
-				if o.SaveXY {
-					o.Hvalues[o.IdxSave] = o.h
-					o.Xvalues[o.IdxSave] = x
-					for i := 0; i < o.ndim; i++ {
-						o.Yvalues[i][o.IdxSave] = y[i]
+				// event: record, call action and restart integration from the event
+				if ievt >= 0 {
+					o.Stopped, err = o.triggerEvent(ievt, x, y)
+					if err != nil || o.Stopped {
+						return
+					}
+					la.VecScaleAbs(o.scal, o.Atol, o.Rtol, y)
+					o.Nfeval++
+					o.fcn(o.f0, o.h, x, y)
+					o.first, o.reject, o.last = true, false, false
+					o.reuseJdec, o.reuseJ, o.jacIsOK = false, false, false
+					if x+o.h-xstep >= 0.0 {
+						o.last = true
+						o.h = xstep - x
 					}
-					o.IdxSave++
+					continue
 				}
 
 				// converged ?
 				if o.last {
 					o.hopt = o.h // optimal h
+					x = xstep    // avoid round-off errors
+					if xstep < xb {
+						if ss, ok := o.rkm.(stepSizer); ok {
+							dxnew = ss.NextH(o, true)
+						}
+						la.VecScaleAbs(o.scal, o.Atol, o.Rtol, y)
+						o.Nfeval++
+						o.fcn(o.f0, o.h, x, y) // f0 for the next interval
+					}
 					break
 				}
 
@@ -468,6 +586,11 @@ func (o *Solver) Solve(y la.Vector, x, xb, Δx float64, fixstp bool) (err error)
 					oldRerr = max(1.0e-2, rerr)
 				}
 
+				// step size selected by the method; e.g. variable-order multistep
+				if ss, ok := o.rkm.(stepSizer); ok {
+					dxnew = ss.NextH(o, true)
+				}
+
 				// calc new scal and f0
 				la.VecScaleAbs(o.scal, o.Atol, o.Rtol, y) // o.scal := o.Atol + o.Rtol * abs(y)
 				o.Nfeval++
@@ -510,7 +633,9 @@ func (o *Solver) Solve(y la.Vector, x, xb, Δx float64, fixstp bool) (err error)
 				o.last = false
 
 				// new step size
-				if o.first {
+				if ss, ok := o.rkm.(stepSizer); ok {
+					o.h = ss.NextH(o, false)
+				} else if o.first {
 					o.h = 0.1 * o.h
 				} else {
 					o.h = dxnew
@@ -525,13 +650,108 @@ func (o *Solver) Solve(y la.Vector, x, xb, Δx float64, fixstp bool) (err error)
 
 		// sub-stepping failed
 		if failed {
-			err = chk.Err("substepping did not converge after %d steps\n", o.NmaxSS)
+			err = utl.NewIterError(utl.StopMaxIt, "ode.Solver", o.Nsteps, "substepping did not converge after %d steps", o.NmaxSS)
 			break
 		}
 	}
 	return
 }
 
+// SolveCtx solves from (xa,ya) to (xb,yb) and stops with an error if ctx is cancelled
+func (o *Solver) SolveCtx(ctx context.Context, y la.Vector, x, xb, Δx float64, fixstp bool) (err error) {
+	o.Monitor.Ctx = ctx
+	defer func() { o.Monitor.Ctx = nil }()
+	return o.Solve(y, x, xb, Δx, fixstp)
+}
+
+// denseOut computes y at xout within the last accepted step using the dense output of the method
+// or, if the method does not implement denseOutputter, linear interpolation
+//  Input:
+//   h    -- step size of the last accepted step
+//   x    -- x at the end of the last accepted step
+//   y    -- y at the end of the last accepted step
+//   xout -- x where output is required; x-h ≤ xout ≤ x
+//  Output:
+//   yout -- y at xout
+func (o *Solver) denseOut(yout la.Vector, h, x float64, y la.Vector, xout float64) {
+	if o.dns != nil {
+		o.dns.DenseOut(o, yout, h, x, y, xout)
+		return
+	}
+	θ := 1.0 + (xout-x)/h
+	for i := 0; i < len(y); i++ {
+		yout[i] = o.ybeg[i] + θ*(y[i]-o.ybeg[i])
+	}
+}
+
+// endOfStep locates events and produces output after an accepted step from x0 to x1
+//  Input:
+//   y -- state at x1
+//  Output:
+//   x    -- x1 or the location of the event
+//   ievt -- index of the event that occurred or -1
+//  Note: if an event occurs, y is replaced by the state at the event
+func (o *Solver) endOfStep(x0, x1 float64, y la.Vector) (x float64, ievt int, err error) {
+
+	// state at the end of the step; e.g. for the dense output after y is moved to an event
+	o.xend = x1
+	o.yend.Apply(1, y)
+
+	// events
+	x, ievt = x1, -1
+	h := x1 - x0
+	if len(o.events) > 0 {
+		ievt, x = o.locateEvent(x0, x1, y)
+	}
+
+	// output at stations
+	if len(o.Stations) > 0 {
+		for o.istat < len(o.Stations) && o.Stations[o.istat] <= x {
+			o.denseOut(o.ydns, h, x1, o.yend, o.Stations[o.istat])
+			err = o.output(o.Stations[o.istat], o.ydns)
+			if err != nil {
+				return
+			}
+			o.istat++
+		}
+	}
+
+	// state at event
+	if x < x1 {
+		o.denseOut(y, h, x1, o.yend, x)
+	}
+
+	// hook
+	if o.stepHook != nil {
+		err = o.stepHook(x, y)
+		if err != nil {
+			return
+		}
+	}
+
+	// output at the end of step
+	if len(o.Stations) == 0 {
+		err = o.output(x, y)
+	}
+	return
+}
+
+// output calls the output function and saves x and y if SaveXY is true
+func (o *Solver) output(x float64, y la.Vector) (err error) {
+	if o.SaveXY && o.IdxSave < len(o.Xvalues) {
+		o.Hvalues[o.IdxSave] = o.h
+		o.Xvalues[o.IdxSave] = x
+		for i := 0; i < o.ndim; i++ {
+			o.Yvalues[i][o.IdxSave] = y[i]
+		}
+		o.IdxSave++
+	}
+	if o.out != nil {
+		err = o.out(false, o.h, x, y)
+	}
+	return
+}
+
 // Stat prints "statistical" information about the solution process
 func (o *Solver) Stat() {
 	io.Pf("number of F evaluations   =%6d\n", o.Nfeval)
@@ -82,6 +82,15 @@ func (o *Radau5) Accept(sol *Solver, y la.Vector) {
 	}
 }
 
+// DenseOut produces dense output after Accept using the collocation polynomial.
+// See FwEuler.DenseOut
+func (o *Radau5) DenseOut(sol *Solver, yout la.Vector, h, x float64, y la.Vector, xout float64) {
+	s := (xout - x) / h
+	for m := 0; m < len(y); m++ {
+		yout[m] = y[m] + s*(sol.ycol[0][m]+(s-o.Mu4)*(sol.ycol[1][m]+(s-o.Mu3)*sol.ycol[2][m]))
+	}
+}
+
 // Step steps update
 func (o *Radau5) Step(sol *Solver, y0 la.Vector, x0 float64) (rerr float64, err error) {
 
@@ -0,0 +1,67 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import "github.com/cpmech/gosl/la"
+
+// Rk4 implements the classical (explicit) Runge-Kutta method
+// Runge-Kutta 4, order=4, nstages=4 (no error estimate => fixed steps only)
+type Rk4 struct {
+	dat  *erkdata
+	yold la.Vector // y before the step (for dense output)
+}
+
+// Init initialises structure
+func (o *Rk4) Init(distr bool) (err error) {
+	o.dat = &erkdata{
+		A: [][]float64{
+			{},
+			{0.5},
+			{0.0, 0.5},
+			{0.0, 0.0, 1.0},
+		},
+		B: []float64{1.0 / 6.0, 1.0 / 3.0, 1.0 / 3.0, 1.0 / 6.0},
+		C: []float64{0.0, 0.5, 0.5, 1.0},
+	}
+	return nil
+}
+
+// Nstages returns the number of stages
+func (o *Rk4) Nstages() int {
+	return 4
+}
+
+// Accept accepts update
+func (o *Rk4) Accept(sol *Solver, y la.Vector) {
+	if len(o.yold) != len(y) {
+		o.yold = la.NewVector(len(y))
+	}
+	o.yold.Apply(1, y)
+	y.Apply(1, sol.w[0]) // y := w (update y)
+}
+
+// Step steps update
+func (o *Rk4) Step(sol *Solver, y0 la.Vector, x0 float64) (rerr float64, err error) {
+	return erkstep(o.dat, 4, false, sol, y0, x0)
+}
+
+// DenseOut produces dense output after Accept using the continuous extension of order 3
+// (see [1]). See FwEuler.DenseOut
+func (o *Rk4) DenseOut(sol *Solver, yout la.Vector, h, x float64, y la.Vector, xout float64) {
+	θ := 1.0 + (xout-x)/h
+	θ2, θ3 := θ*θ, θ*θ*θ
+	b0 := θ - 1.5*θ2 + 2.0*θ3/3.0
+	b1 := θ2 - 2.0*θ3/3.0
+	b3 := -0.5*θ2 + 2.0*θ3/3.0
+	for m := 0; m < len(y); m++ {
+		yout[m] = o.yold[m] + h*(b0*sol.f[0][m]+b1*(sol.f[1][m]+sol.f[2][m])+b3*sol.f[3][m])
+	}
+}
+
+// add method to database //////////////////////////////////////////////////////////////////////////
+
+func init() {
+	rkmDB[Rk4kind] = func() RKmethod { return new(Rk4) }
+}
@@ -0,0 +1,100 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import "github.com/cpmech/gosl/la"
+
+// Rodas4 implements the (linearly implicit) Rosenbrock method RODAS of Hairer and Wanner [2],
+// which is stiffly accurate and suitable for differential-algebraic problems (singular M). This
+// is a rewrite of Hairer's RODAS Fortran code with the coefficients of METH=1.
+// RODAS4, order=4, error_est_order=3, nstages=6
+type Rodas4 struct {
+	dat  *rosdata
+	ynew la.Vector // new y
+
+	// dense output
+	d     [][]float64  // coefficients of the continuous extension of order 3 [2][5]
+	rcont [4]la.Vector // y0, y1 and two coefficients of the interpolating polynomial
+}
+
+// Init initialises structure
+func (o *Rodas4) Init(distr bool) (err error) {
+	a51, a52, a53, a54 := 0.1221224509226641e+01, 0.6019134481288629e+01, 0.1253708332932087e+02, -0.6878860361058950e+00
+	o.dat = &rosdata{
+		Gam: 0.25,
+		A: [][]float64{
+			{},
+			{0.1544000000000000e+01},
+			{0.9466785280815826e+00, 0.2557011698983284e+00},
+			{0.3314825187068521e+01, 0.2896124015972201e+01, 0.9986419139977817e+00},
+			{a51, a52, a53, a54},
+			{a51, a52, a53, a54, 1.0},
+		},
+		C: [][]float64{
+			{},
+			{-0.5668800000000000e+01},
+			{-0.2430093356833875e+01, -0.2063599157091915e+00},
+			{-0.1073529058151375e+00, -0.9594562251023355e+01, -0.2047028614809616e+02},
+			{0.7496443313967647e+01, -0.1024680431464352e+02, -0.3399990352819905e+02, 0.1170890893206160e+02},
+			{0.8083246795921522e+01, -0.7981132988064893e+01, -0.3152159432874371e+02, 0.1631930543123136e+02, -0.6058818238834054e+01},
+		},
+		Alp: []float64{0.0, 0.386, 0.21, 0.63, 1.0, 1.0},
+		Gi:  []float64{0.25, -0.1043, 0.1035, -0.3620000000000023e-01, 0.0, 0.0},
+		M:   []float64{a51, a52, a53, a54, 1.0, 1.0},
+		Me:  []float64{a51, a52, a53, a54, 1.0, 0.0},
+	}
+	o.d = [][]float64{
+		{0.1012623508344586e+02, -0.7487995877610167e+01, -0.3480091861555747e+02, -0.7992771707568823e+01, 0.1025137723295662e+01},
+		{-0.6762803392801253e+00, 0.6087714651680015e+01, 0.1643084320892478e+02, 0.2476722511418386e+02, -0.6594389125716872e+01},
+	}
+	return nil
+}
+
+// Nstages returns the number of stages
+func (o *Rodas4) Nstages() int {
+	return 6
+}
+
+// Accept accepts update
+func (o *Rodas4) Accept(sol *Solver, y la.Vector) {
+	if len(o.rcont[0]) != sol.ndim {
+		for k := 0; k < 4; k++ {
+			o.rcont[k] = la.NewVector(sol.ndim)
+		}
+	}
+	o.rcont[0].Apply(1, y)
+	o.rcont[1].Apply(1, o.ynew)
+	o.rcont[2].Fill(0)
+	o.rcont[3].Fill(0)
+	for i := 0; i < 5; i++ {
+		la.VecAdd(o.rcont[2], 1, o.rcont[2], o.d[0][i], sol.w[i])
+		la.VecAdd(o.rcont[3], 1, o.rcont[3], o.d[1][i], sol.w[i])
+	}
+	y.Apply(1, o.ynew)
+}
+
+// Step steps update
+func (o *Rodas4) Step(sol *Solver, y0 la.Vector, x0 float64) (rerr float64, err error) {
+	if len(o.ynew) != sol.ndim {
+		o.ynew = la.NewVector(sol.ndim)
+	}
+	return rosstep(o.dat, 6, sol, y0, x0, o.ynew)
+}
+
+// DenseOut produces dense output after Accept using the continuous extension of order 3 (see [2]).
+// See FwEuler.DenseOut
+func (o *Rodas4) DenseOut(sol *Solver, yout la.Vector, h, x float64, y la.Vector, xout float64) {
+	s := 1.0 + (xout-x)/h
+	s1 := 1.0 - s
+	for m := 0; m < len(y); m++ {
+		yout[m] = o.rcont[0][m]*s1 + s*(o.rcont[1][m]+s1*(o.rcont[2][m]+s*o.rcont[3][m]))
+	}
+}
+
+// add method to database //////////////////////////////////////////////////////////////////////////
+
+func init() {
+	rkmDB[Rodas4kind] = func() RKmethod { return new(Rodas4) }
+}
@@ -0,0 +1,171 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/num"
+)
+
+// rosdata holds data for a Rosenbrock (linearly implicit) method written in the form of [2]
+// (page 119, Eq. 7.25) that avoids matrix-vector multiplications by the Jacobian:
+//
+//   (M/(h γ) - J) u_i = f(x0 + α_i h, y0 + Σ a_ij u_j) + M Σ (c_ij / h) u_j + γ_i h ∂f/∂x
+//
+//   y1 = y0 + Σ m_i u_i   and   err = Σ (m_i - me_i) u_i
+//
+//  Note: only the lower triangular parts of A and C are accessed
+type rosdata struct {
+	Gam float64     // γ: diagonal coefficient
+	A   [][]float64 // a coefficients
+	C   [][]float64 // c coefficients
+	Alp []float64   // α coefficients (x increments)
+	Gi  []float64   // γ_i coefficients (multiplying ∂f/∂x)
+	M   []float64   // m coefficients
+	Me  []float64   // embedded m coefficients
+}
+
+// rosstep performs the step update of a Rosenbrock method. The new y is stored in ynew
+//  Note: the stages u_i are stored in sol.w[i] and f_i in sol.f[i]
+func rosstep(o *rosdata, nStages int, sol *Solver, y0 la.Vector, x0 float64, ynew la.Vector) (rerr float64, err error) {
+
+	// allocate
+	if len(sol.dfdx) != sol.ndim {
+		sol.dfdx = la.NewVector(sol.ndim)
+		sol.rhs = la.NewVector(sol.ndim)
+	}
+
+	// f(x0,y0) is not computed by Solver with fixed steps
+	h := sol.h
+	if sol.fixstp {
+		sol.Nfeval++
+		err = sol.fcn(sol.f0, h, x0, y0)
+		if err != nil {
+			return
+		}
+	}
+
+	// Jacobian (reused if the step is rejected)
+	if !sol.jacIsOK {
+		if sol.jac == nil { // numerical
+			err = num.Jacobian(&sol.dfdyT, func(fy, yy la.Vector) (e error) {
+				e = sol.fcn(fy, h, x0, yy)
+				return
+			}, y0, sol.f0, sol.dw[0]) // δw works here as workspace variable
+		} else { // analytical
+			err = sol.jac(&sol.dfdyT, h, x0, y0)
+		}
+		if err != nil {
+			return
+		}
+		sol.Njeval++
+		sol.jacIsOK = true
+
+		// ∂f/∂x (numerical)
+		if !sol.Autonom {
+			δ := math.Sqrt(sol.Eps * max(1.0e-5, math.Abs(x0)))
+			sol.Nfeval++
+			err = sol.fcn(sol.dw[0], h, x0+δ, y0)
+			if err != nil {
+				return
+			}
+			la.VecAdd(sol.dfdx, 1.0/δ, sol.dw[0], -1.0/δ, sol.f0) // dfdx := (f(x0+δ,y0) - f0) / δ
+		}
+	}
+
+	// iteration matrix
+	if sol.doinit {
+		if !sol.hasM && sol.mTri == nil {
+			sol.mTri = new(la.Triplet)
+			la.SpTriSetDiag(sol.mTri, sol.ndim, 1)
+		}
+		sol.rctriR = new(la.Triplet)
+		sol.rctriR.Init(sol.ndim, sol.ndim, sol.mTri.Len()+sol.dfdyT.Len())
+	}
+	la.SpTriAdd(sol.rctriR, 1.0/(h*o.Gam), sol.mTri, -1, &sol.dfdyT) // rctriR := M/(h γ) - dfdy
+	if sol.doinit {
+		err = sol.lsolR.Init(sol.rctriR, sol.symmetric, sol.lsverbose, sol.ordering, sol.scaling, sol.comm)
+		if err != nil {
+			return
+		}
+	}
+	err = sol.lsolR.Fact()
+	if err != nil {
+		return
+	}
+	sol.Ndecomp++
+
+	// stages
+	for i := 0; i < nStages; i++ {
+
+		// f_i
+		if i == 0 {
+			sol.f[0].Apply(1, sol.f0)
+		} else {
+			sol.u[i] = x0 + o.Alp[i]*h
+			sol.v[i].Apply(1, y0)
+			for j := 0; j < i; j++ {
+				if o.A[i][j] != 0 {
+					la.VecAdd(sol.v[i], 1, sol.v[i], o.A[i][j], sol.w[j]) // v[i] += a[i][j]*u[j]
+				}
+			}
+			sol.Nfeval++
+			err = sol.fcn(sol.f[i], h, sol.u[i], sol.v[i])
+			if err != nil {
+				return
+			}
+		}
+
+		// right-hand side: rhs = f_i + M Σ (c_ij / h) u_j + γ_i h ∂f/∂x
+		sol.dw[0].Fill(0)
+		for j := 0; j < i; j++ {
+			if o.C[i][j] != 0 {
+				la.VecAdd(sol.dw[0], 1, sol.dw[0], o.C[i][j]/h, sol.w[j])
+			}
+		}
+		if sol.hasM {
+			la.SpMatVecMul(sol.rhs, 1, sol.mMat, sol.dw[0])
+			la.VecAdd(sol.rhs, 1, sol.rhs, 1, sol.f[i])
+		} else {
+			la.VecAdd(sol.rhs, 1, sol.dw[0], 1, sol.f[i])
+		}
+		if !sol.Autonom && o.Gi[i] != 0 {
+			la.VecAdd(sol.rhs, 1, sol.rhs, o.Gi[i]*h, sol.dfdx)
+		}
+
+		// solve linear system
+		sol.Nlinsol++
+		err = sol.lsolR.Solve(sol.w[i], sol.rhs, false)
+		if err != nil {
+			return
+		}
+	}
+
+	// update and error estimate
+	ynew.Apply(1, y0)
+	sol.dw[0].Fill(0)
+	for i := 0; i < nStages; i++ {
+		la.VecAdd(ynew, 1, ynew, o.M[i], sol.w[i])
+		la.VecAdd(sol.dw[0], 1, sol.dw[0], o.M[i]-o.Me[i], sol.w[i])
+	}
+	rerr = sol.rmsNorm(sol.dw[0])
+	return
+}
+
+// roshermite produces dense output for Rosenbrock methods without continuous extension using
+// cubic Hermite interpolation or linear interpolation if M is given, since dy/dx = f is not
+// available in this case
+func roshermite(dense *erkhermite, sol *Solver, yout la.Vector, h, x float64, y la.Vector, xout float64) {
+	if !sol.hasM {
+		dense.denseOut(sol, yout, h, x, y, xout)
+		return
+	}
+	θ := 1.0 + (xout-x)/h
+	for m := 0; m < len(y); m++ {
+		yout[m] = dense.y0[m] + θ*(y[m]-dense.y0[m])
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/la"
+)
+
+// Ros2 implements the (linearly implicit) Rosenbrock method ROS2 of Verwer et al. with
+// γ = 1 + 1/√2, which is L-stable. The embedded solution is the linearly implicit Euler one.
+// ROS2, order=2, error_est_order=1, nstages=2
+type Ros2 struct {
+	dat   *rosdata
+	ynew  la.Vector // new y
+	dense erkhermite
+}
+
+// Init initialises structure
+func (o *Ros2) Init(distr bool) (err error) {
+	γ := 1.0 + 1.0/math.Sqrt(2.0)
+	o.dat = &rosdata{
+		Gam: γ,
+		A:   [][]float64{{}, {1.0 / γ}},
+		C:   [][]float64{{}, {-2.0 / γ}},
+		Alp: []float64{0.0, 1.0},
+		Gi:  []float64{γ, -γ},
+		M:   []float64{3.0 / (2.0 * γ), 1.0 / (2.0 * γ)},
+		Me:  []float64{1.0 / γ, 0.0},
+	}
+	return nil
+}
+
+// Nstages returns the number of stages
+func (o *Ros2) Nstages() int {
+	return 2
+}
+
+// Accept accepts update
+func (o *Ros2) Accept(sol *Solver, y la.Vector) {
+	o.dense.accept(sol, y, 2, false)
+	y.Apply(1, o.ynew)
+}
+
+// Step steps update
+func (o *Ros2) Step(sol *Solver, y0 la.Vector, x0 float64) (rerr float64, err error) {
+	if len(o.ynew) != sol.ndim {
+		o.ynew = la.NewVector(sol.ndim)
+	}
+	return rosstep(o.dat, 2, sol, y0, x0, o.ynew)
+}
+
+// DenseOut produces dense output after Accept using Hermite interpolation (order 3) or linear
+// interpolation if M is given. See FwEuler.DenseOut
+func (o *Ros2) DenseOut(sol *Solver, yout la.Vector, h, x float64, y la.Vector, xout float64) {
+	roshermite(&o.dense, sol, yout, h, x, y, xout)
+}
+
+// add method to database //////////////////////////////////////////////////////////////////////////
+
+func init() {
+	rkmDB[Ros2kind] = func() RKmethod { return new(Ros2) }
+}
@@ -0,0 +1,73 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import "github.com/cpmech/gosl/la"
+
+// Ros3p implements the (linearly implicit) Rosenbrock method ROS3P of Lang and Verwer (2001),
+// which is A-stable and does not suffer from order reduction on problems with time-dependent
+// boundary conditions (e.g. method of lines).
+//  Note: the second stage equals the first one for linear problems with constant coefficients;
+//        thus, the embedded error estimate vanishes in this case and automatic step size control
+//        should not be used for such problems
+// ROS3P, order=3, error_est_order=2, nstages=3
+type Ros3p struct {
+	dat   *rosdata
+	ynew  la.Vector // new y
+	dense erkhermite
+}
+
+// Init initialises structure
+func (o *Ros3p) Init(distr bool) (err error) {
+	o.dat = &rosdata{
+		Gam: 7.886751345948129e-01,
+		A: [][]float64{
+			{},
+			{1.267949192431123e+00},
+			{1.267949192431123e+00, 0.0},
+		},
+		C: [][]float64{
+			{},
+			{-1.607695154586736e+00},
+			{-3.464101615137755e+00, -1.732050807568877e+00},
+		},
+		Alp: []float64{0.0, 1.0, 1.0},
+		Gi:  []float64{7.886751345948129e-01, -2.113248654051871e-01, -1.077350269189626e+00},
+		M:   []float64{2.0, 5.773502691896258e-01, 4.226497308103742e-01},
+		Me:  []float64{2.113248654051871e+00, 1.0, 4.226497308103742e-01},
+	}
+	return nil
+}
+
+// Nstages returns the number of stages
+func (o *Ros3p) Nstages() int {
+	return 3
+}
+
+// Accept accepts update
+func (o *Ros3p) Accept(sol *Solver, y la.Vector) {
+	o.dense.accept(sol, y, 3, false)
+	y.Apply(1, o.ynew)
+}
+
+// Step steps update
+func (o *Ros3p) Step(sol *Solver, y0 la.Vector, x0 float64) (rerr float64, err error) {
+	if len(o.ynew) != sol.ndim {
+		o.ynew = la.NewVector(sol.ndim)
+	}
+	return rosstep(o.dat, 3, sol, y0, x0, o.ynew)
+}
+
+// DenseOut produces dense output after Accept using Hermite interpolation (order 3) or linear
+// interpolation if M is given. See FwEuler.DenseOut
+func (o *Ros3p) DenseOut(sol *Solver, yout la.Vector, h, x float64, y la.Vector, xout float64) {
+	roshermite(&o.dense, sol, yout, h, x, y, xout)
+}
+
+// add method to database //////////////////////////////////////////////////////////////////////////
+
+func init() {
+	rkmDB[Ros3pKind] = func() RKmethod { return new(Ros3p) }
+}
@@ -0,0 +1,633 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"context"
+	"math"
+	"math/rand"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/rnd"
+	"github.com/cpmech/gosl/utl"
+)
+
+// methods for stochastic differential equations
+var (
+
+	// EulerMaruyamaKind specifies the Euler-Maruyama method (strong order 0.5); Euler-Heun if
+	// the SDE is of Stratonovich type
+	EulerMaruyamaKind = io.NewEnum("EulerMaruyama", "ode", "EM", "Euler-Maruyama (strong order 0.5)")
+
+	// MilsteinKind specifies the derivative-free Milstein method (strong order 1; diagonal noise)
+	MilsteinKind = io.NewEnum("Milstein", "ode", "MIL", "Milstein (strong order 1)")
+
+	// SriW1kind specifies Rößler's stochastic Runge-Kutta method SRIW1 (strong order 1.5;
+	// diagonal noise; Itô)
+	SriW1kind = io.NewEnum("SriW1", "ode", "SRI", "Rößler SRIW1 (strong order 1.5)")
+)
+
+// NoiseF defines the diffusion of an SDE with diagonal noise; i.e. the system
+//
+//   dy_i = f_i(x, {y}) dx + g_i(x, {y}) dW_i
+//
+//   Input:
+//     h -- current stepsize = dx
+//     x -- current x
+//     y -- current {y}
+//   Output:
+//     g -- {g}(h, x, {y}) [ndim]
+//
+type NoiseF func(g la.Vector, h, x float64, y la.Vector) error
+
+// NoiseMatF defines the diffusion of an SDE with general noise; i.e. the system
+//
+//   d{y} = {f}(x, {y}) dx + [G](x, {y}) d{W}
+//
+//   Input:
+//     h -- current stepsize = dx
+//     x -- current x
+//     y -- current {y}
+//   Output:
+//     G -- [G](h, x, {y}) [ndim][m] where m is the number of Wiener processes
+//
+type NoiseMatF func(G *la.Matrix, h, x float64, y la.Vector) error
+
+// SdeSolver implements solvers for stochastic differential equations (SDEs) of Itô or
+// Stratonovich type with diagonal or general noise
+//
+//   Itô:          d{y} = {f}(x, {y}) dx + [G](x, {y}) d{W}
+//   Stratonovich: d{y} = {f}(x, {y}) dx + [G](x, {y}) ∘ d{W}
+//
+//  The Wiener increments are generated by a generator of the solver initialised with Seed (if
+//  Seed > 0; the global generator is not affected) or by the global generator (see rnd.Init).
+//  Thus, the paths are reproducible by means of Seed or rnd.Init. With adaptive steps (SRIW1
+//  only), the increments of rejected steps are not discarded; instead, they are split using
+//  Brownian bridges and saved for the next steps ("rejection sampling with memory"). This keeps
+//  the samples from the correct distribution.
+//
+//  References:
+//    [1] Kloeden PE, Platen E (1992) Numerical Solution of Stochastic Differential Equations.
+//        Springer, Berlin, 636 p.
+//    [2] Rößler A (2010) Runge-Kutta methods for the strong approximation of solutions of
+//        stochastic differential equations. SIAM J. Numer. Anal., 48(3):922-952.
+//    [3] Rackauckas C, Nie Q (2017) Adaptive methods for stochastic differential equations via
+//        natural embeddings and rejection sampling with memory. Discrete Contin. Dyn. Syst.
+//        Ser. B, 22(7):2731-2761.
+type SdeSolver struct {
+
+	// method data
+	method io.Enum // method kind
+
+	// primary variables
+	ndim int       // size of y
+	nw   int       // number of Wiener processes (== ndim if the noise is diagonal)
+	fcn  Func      // drift: f(x,y)
+	gfcn NoiseF    // diagonal noise: g(x,y) [may be nil]
+	gmat NoiseMatF // general noise: G(x,y) [may be nil]
+	out  OutF      // output function
+
+	// flags
+	Strat  bool    // Stratonovich SDE (instead of Itô)
+	Seed   int     // if > 0, seed of the generator of the Wiener increments (≤ 0 ⇒ global generator)
+	Atol   float64 // absolute tolerance
+	Rtol   float64 // relative tolerance
+	IniH   float64 // initial H
+	NmaxSS int     // max num substeps
+	Mmin   float64 // min step multiplier
+	Mmax   float64 // max step multiplier
+	Mfac   float64 // step multiplier factor
+	SaveXY bool    // save X values in an array (e.g. for plotting)
+
+	// output stations
+	Stations []float64 // if not empty, OutF is called and X/Y are saved only at these x values (ascending); steps are adjusted to hit them
+
+	// cancellation and progress report
+	Monitor utl.Monitor // it = number of substeps, residual = local error, step = h
+
+	// output
+	IdxSave int         // current index in Xvalues and Yvalues == last output
+	Xvalues []float64   // X values if SaveXY is true [IdxSave]
+	Yvalues [][]float64 // Y values if SaveXY is true [ndim][IdxSave]
+
+	// stat variables
+	Nfeval    int // number of calls to fcn
+	Ngeval    int // number of calls to the noise function
+	Nsteps    int // total number of substeps
+	Naccepted int // number of accepted substeps
+	Nrejected int // number of rejected substeps
+
+	// Wiener increments
+	dW, dZ la.Vector  // increments of W and of the auxiliary process Z (for I_(1,0))
+	stack  []*sdeIncr // increments of rejected steps not used yet (last = next)
+	rng    *rand.Rand // random numbers generator
+
+	// workspace
+	h     float64      // step size
+	fs    []la.Vector  // drift evaluations
+	gs    []la.Vector  // diagonal noise evaluations
+	gm    []*la.Matrix // general noise evaluations
+	ys    []la.Vector  // stage values
+	ynew  la.Vector    // updated y
+	err   la.Vector    // error estimate
+	istat int          // index of next station
+}
+
+// sdeIncr holds Wiener increments over an interval of length dx
+type sdeIncr struct {
+	dx     float64   // length of interval
+	dW, dZ la.Vector // increments
+}
+
+// NewSdeSolver returns a new SDE solver
+//  Input:
+//   method -- the method kind; e.g. EulerMaruyamaKind
+//   ndim   -- size of y
+//   m      -- number of Wiener processes with general noise; ignored if the noise is diagonal
+//   fcn    -- drift function f(x,y)
+//   g      -- diagonal noise function g(x,y) [may be nil if G is given]
+//   G      -- general noise function G(x,y) [may be nil if g is given]
+//   out    -- output function [may be nil]
+//  Note: Milstein and SRIW1 require diagonal noise
+func NewSdeSolver(method io.Enum, ndim, m int, fcn Func, g NoiseF, G NoiseMatF, out OutF) (o *SdeSolver) {
+
+	// check
+	if (g == nil) == (G == nil) {
+		chk.Panic("either g (diagonal noise) or G (general noise) must be given\n")
+	}
+	switch method {
+	case EulerMaruyamaKind:
+	case MilsteinKind, SriW1kind:
+		if g == nil {
+			chk.Panic("method %q requires diagonal noise\n", method)
+		}
+	default:
+		chk.Panic("cannot find SDE method named %q\n", method)
+	}
+
+	// data
+	o = new(SdeSolver)
+	o.method = method
+	o.ndim = ndim
+	o.nw = ndim
+	o.fcn = fcn
+	o.gfcn = g
+	o.gmat = G
+	o.out = out
+
+	// parameters
+	o.Atol = 1e-3
+	o.Rtol = 1e-3
+	o.IniH = 1e-4
+	o.NmaxSS = 1000
+	o.Mmin = 0.2
+	o.Mmax = 10.0
+	o.Mfac = 0.9
+
+	// workspace
+	nstg := 2
+	if method == SriW1kind {
+		nstg = 4
+	}
+	if G != nil {
+		o.nw = m
+		o.gm = make([]*la.Matrix, nstg)
+		for i := 0; i < nstg; i++ {
+			o.gm[i] = la.NewMatrix(ndim, m)
+		}
+	} else {
+		o.gs = make([]la.Vector, nstg)
+		for i := 0; i < nstg; i++ {
+			o.gs[i] = la.NewVector(ndim)
+		}
+	}
+	o.fs = make([]la.Vector, 2)
+	o.ys = make([]la.Vector, 4)
+	for i := 0; i < 2; i++ {
+		o.fs[i] = la.NewVector(ndim)
+	}
+	for i := 0; i < 4; i++ {
+		o.ys[i] = la.NewVector(ndim)
+	}
+	o.ynew = la.NewVector(ndim)
+	o.err = la.NewVector(ndim)
+	o.dW = la.NewVector(o.nw)
+	o.dZ = la.NewVector(o.nw)
+	return
+}
+
+// SetTol sets the absolute and relative tolerances used with adaptive steps
+func (o *SdeSolver) SetTol(atol, rtol float64) {
+	o.Atol, o.Rtol = atol, rtol
+}
+
+// Solve solves from (xa,ya) to (xb,yb) => find yb (stored in y)
+//  Note: adaptive steps (fixstp == false) are only available with SRIW1
+func (o *SdeSolver) Solve(y la.Vector, x, xb, Δx float64, fixstp bool) (err error) {
+
+	// check
+	if xb < x {
+		err = chk.Err("xb == %v must be greater than x == %v\n", xb, x)
+		return
+	}
+	if !fixstp && o.method != SriW1kind {
+		err = chk.Err("method %q requires fixed steps\n", o.method)
+		return
+	}
+	if o.Strat && o.method == SriW1kind {
+		err = chk.Err("method %q requires an SDE of Itô type\n", o.method)
+		return
+	}
+	for i := 1; i < len(o.Stations); i++ {
+		if o.Stations[i] < o.Stations[i-1] {
+			err = chk.Err("stations must be in ascending order\n")
+			return
+		}
+	}
+
+	// stat variables
+	o.Nfeval = 0
+	o.Ngeval = 0
+	o.Nsteps = 0
+	o.Naccepted = 0
+	o.Nrejected = 0
+
+	// random numbers
+	o.rng = rnd.NewRand(o.Seed)
+	o.stack = o.stack[:0]
+
+	// initial step size
+	Δx = min(Δx, xb-x)
+	if fixstp {
+		o.h = Δx
+	} else {
+		o.h = min(Δx, o.IniH)
+	}
+
+	// stations
+	o.istat = 0
+	for o.istat < len(o.Stations) && o.Stations[o.istat] <= x {
+		o.istat++ // the initial state is always output
+	}
+
+	// output initial state
+	o.IdxSave = 0
+	if o.SaveXY {
+		nmax := o.NmaxSS + 1
+		if fixstp {
+			nmax = int(math.Ceil((xb-x)/Δx)) + len(o.Stations) + 1
+		}
+		if len(o.Stations) > 0 {
+			nmax = len(o.Stations) + 1
+		}
+		o.Xvalues = make([]float64, nmax)
+		o.Yvalues = utl.Alloc(o.ndim, nmax)
+	}
+	err = o.output(true, x, y)
+	if err != nil {
+		return
+	}
+
+	// scaling factors
+	scal := la.NewVector(o.ndim)
+	rmsNorm := func() (rms float64) {
+		for m := 0; m < o.ndim; m++ {
+			scal[m] = o.Atol + o.Rtol*max(math.Abs(y[m]), math.Abs(o.ynew[m]))
+			rms += math.Pow(o.err[m]/scal[m], 2.0)
+		}
+		return max(math.Sqrt(rms/float64(o.ndim)), 1.0e-10)
+	}
+
+	// first step and stations
+	hmax := Δx
+	eps := 1e-12 * max(1, math.Abs(xb))
+	clip := func(h float64) float64 {
+		if o.istat < len(o.Stations) && o.Stations[o.istat] < xb && x+h > o.Stations[o.istat]-eps {
+			return o.Stations[o.istat] - x
+		}
+		if x+h > xb-eps {
+			return xb - x
+		}
+		return h
+	}
+
+	// sub-stepping
+	var rerr float64
+	for x < xb-eps {
+
+		// step size
+		if !fixstp && len(o.stack) > 0 {
+			o.h = min(o.h, o.stack[len(o.stack)-1].dx) // the next increment must be split
+		}
+		o.h = clip(o.h)
+		o.increments(o.h)
+
+		// step
+		o.Nsteps++
+		rerr, err = o.step(y, x)
+		if err != nil {
+			return
+		}
+		if !fixstp {
+			rerr = rmsNorm()
+		}
+		err = o.Monitor.Check("ode.SdeSolver", o.Nsteps, rerr, o.h)
+		if err != nil {
+			return
+		}
+
+		// accept or reject
+		if fixstp || rerr <= 1.0 {
+			o.Naccepted++
+			x += o.h
+			y.Apply(1, o.ynew)
+			if len(o.Stations) == 0 || (o.istat < len(o.Stations) && math.Abs(x-o.Stations[o.istat]) <= eps) {
+				if len(o.Stations) > 0 {
+					x = o.Stations[o.istat]
+					o.istat++
+				}
+				err = o.output(false, x, y)
+				if err != nil {
+					return
+				}
+			}
+		} else {
+			o.Nrejected++
+			o.stack = append(o.stack, &sdeIncr{o.h, o.dW.GetCopy(), o.dZ.GetCopy()}) // reuse later
+		}
+
+		// next step size
+		if !fixstp {
+			fac := min(o.Mmax, max(o.Mmin, o.Mfac*math.Pow(rerr, -0.5)))
+			o.h = min(hmax, o.h*fac)
+			if o.Nsteps >= o.NmaxSS {
+				err = utl.NewIterError(utl.StopMaxIt, "ode.SdeSolver", o.Nsteps, "substepping did not converge after %d steps", o.NmaxSS)
+				return
+			}
+		} else {
+			o.h = Δx
+		}
+	}
+	return
+}
+
+// SolveCtx solves from (xa,ya) to (xb,yb) and stops with an error if ctx is cancelled
+func (o *SdeSolver) SolveCtx(ctx context.Context, y la.Vector, x, xb, Δx float64, fixstp bool) (err error) {
+	o.Monitor.Ctx = ctx
+	defer func() { o.Monitor.Ctx = nil }()
+	return o.Solve(y, x, xb, Δx, fixstp)
+}
+
+// Ensemble solves npaths realisations of the SDE and computes the mean and the standard
+// deviation of y at the stations
+//  Input:
+//   npaths   -- number of paths
+//   ya       -- initial values
+//   xa, xb   -- initial and final x
+//   Δx       -- step size (fixed) or maximum step size (adaptive)
+//   fixstp   -- fixed steps
+//   stations -- x values where the statistics are computed
+//  Output:
+//   ave -- mean values [ndim][nstations]
+//   dev -- standard deviations [ndim][nstations]
+//  Note: path k uses the seed Seed+k (if Seed > 0); thus, the statistics are reproducible.
+//        Stations, SaveXY and the output function are replaced during this call
+func (o *SdeSolver) Ensemble(npaths int, ya la.Vector, xa, xb, Δx float64, fixstp bool, stations []float64) (ave, dev [][]float64, err error) {
+
+	// save settings
+	seed, stat, save, out := o.Seed, o.Stations, o.SaveXY, o.out
+	defer func() { o.Seed, o.Stations, o.SaveXY, o.out = seed, stat, save, out }()
+
+	// collect values at stations
+	nstat := len(stations)
+	vals := utl.Deep3alloc(o.ndim, nstat, npaths) // [ndim][nstations][npaths]
+	var k, j int
+	o.Stations = stations
+	o.SaveXY = false
+	o.out = func(first bool, h, x float64, y la.Vector) error {
+		if first {
+			j = 0
+			if nstat > 0 && stations[0] > x {
+				return nil
+			}
+		}
+		if j < nstat {
+			for i := 0; i < o.ndim; i++ {
+				vals[i][j][k] = y[i]
+			}
+			j++
+		}
+		return nil
+	}
+	y := la.NewVector(o.ndim)
+	for k = 0; k < npaths; k++ {
+		if seed > 0 {
+			o.Seed = seed + k
+		}
+		y.Apply(1, ya)
+		err = o.Solve(y, xa, xb, Δx, fixstp)
+		if err != nil {
+			return
+		}
+	}
+
+	// statistics
+	ave = utl.Alloc(o.ndim, nstat)
+	dev = utl.Alloc(o.ndim, nstat)
+	for i := 0; i < o.ndim; i++ {
+		for j := 0; j < nstat; j++ {
+			ave[i][j], dev[i][j] = rnd.StatAveDev(vals[i][j], true)
+		}
+	}
+	return
+}
+
+// Stat prints "statistical" information about the solution process
+func (o *SdeSolver) Stat() {
+	io.Pf("number of F evaluations   =%6d\n", o.Nfeval)
+	io.Pf("number of G evaluations   =%6d\n", o.Ngeval)
+	io.Pf("total number of steps     =%6d\n", o.Nsteps)
+	io.Pf("number of accepted steps  =%6d\n", o.Naccepted)
+	io.Pf("number of rejected steps  =%6d\n", o.Nrejected)
+}
+
+// increments sets dW and dZ for a step of size h. The increments of rejected steps are used
+// first: if h is smaller than the saved interval, the increments are split by means of the
+// Brownian bridge; the remainder is kept for the next steps
+func (o *SdeSolver) increments(h float64) {
+
+	// new increments
+	if len(o.stack) == 0 {
+		sq := math.Sqrt(h)
+		for k := 0; k < o.nw; k++ {
+			o.dW[k] = sq * o.rng.NormFloat64()
+			o.dZ[k] = sq * o.rng.NormFloat64()
+		}
+		return
+	}
+
+	// whole saved interval
+	top := o.stack[len(o.stack)-1]
+	if h >= top.dx*(1.0-1e-12) {
+		o.dW.Apply(1, top.dW)
+		o.dZ.Apply(1, top.dZ)
+		o.stack = o.stack[:len(o.stack)-1]
+		return
+	}
+
+	// Brownian bridge: W(h) given W(dx)
+	q := h / top.dx
+	σ := math.Sqrt(q * (1.0 - q) * top.dx)
+	for k := 0; k < o.nw; k++ {
+		o.dW[k] = q*top.dW[k] + σ*o.rng.NormFloat64()
+		o.dZ[k] = q*top.dZ[k] + σ*o.rng.NormFloat64()
+		top.dW[k] -= o.dW[k]
+		top.dZ[k] -= o.dZ[k]
+	}
+	top.dx -= h
+}
+
+// calcF computes the drift f(x,y) and stores the result in fs[i]
+func (o *SdeSolver) calcF(i int, x float64, y la.Vector) (err error) {
+	o.Nfeval++
+	return o.fcn(o.fs[i], o.h, x, y)
+}
+
+// calcG computes the noise g(x,y) or G(x,y) and stores the result in gs[i] or gm[i]
+func (o *SdeSolver) calcG(i int, x float64, y la.Vector) (err error) {
+	o.Ngeval++
+	if o.gfcn != nil {
+		return o.gfcn(o.gs[i], o.h, x, y)
+	}
+	return o.gmat(o.gm[i], o.h, x, y)
+}
+
+// addNoise computes res += α G_i dW
+func (o *SdeSolver) addNoise(res la.Vector, α float64, i int) {
+	if o.gfcn != nil {
+		for m := 0; m < o.ndim; m++ {
+			res[m] += α * o.gs[i][m] * o.dW[m]
+		}
+		return
+	}
+	la.MatVecMulAdd(res, α, o.gm[i], o.dW)
+}
+
+// step performs one step from (x,y) and stores the result in ynew
+//  Note: err holds the error estimate (SRIW1 only)
+func (o *SdeSolver) step(y la.Vector, x float64) (rerr float64, err error) {
+	h := o.h
+	switch o.method {
+
+	// Euler-Maruyama (Itô) or Euler-Heun (Stratonovich)
+	case EulerMaruyamaKind:
+		if err = o.calcF(0, x, y); err != nil {
+			return
+		}
+		if err = o.calcG(0, x, y); err != nil {
+			return
+		}
+		la.VecAdd(o.ynew, 1, y, h, o.fs[0]) // ynew := y + h f
+		if o.Strat {
+			o.ys[0].Apply(1, y)
+			o.addNoise(o.ys[0], 1, 0) // ȳ := y + G dW
+			if err = o.calcG(1, x, o.ys[0]); err != nil {
+				return
+			}
+			o.addNoise(o.ynew, 0.5, 0)
+			o.addNoise(o.ynew, 0.5, 1)
+		} else {
+			o.addNoise(o.ynew, 1, 0)
+		}
+
+	// derivative-free Milstein method [1] (page 374)
+	case MilsteinKind:
+		if err = o.calcF(0, x, y); err != nil {
+			return
+		}
+		if err = o.calcG(0, x, y); err != nil {
+			return
+		}
+		sq := math.Sqrt(h)
+		for m := 0; m < o.ndim; m++ {
+			o.ys[0][m] = y[m] + h*o.fs[0][m] + sq*o.gs[0][m] // ȳ := y + h f + √h g
+		}
+		if err = o.calcG(1, x, o.ys[0]); err != nil {
+			return
+		}
+		c := h // Itô: I_(1,1) = (ΔW² - h) / 2
+		if o.Strat {
+			c = 0 // Stratonovich: J_(1,1) = ΔW² / 2
+		}
+		for m := 0; m < o.ndim; m++ {
+			dW := o.dW[m]
+			o.ynew[m] = y[m] + h*o.fs[0][m] + o.gs[0][m]*dW + (o.gs[1][m]-o.gs[0][m])*(dW*dW-c)/(2.0*sq)
+		}
+
+	// SRIW1 [2, 3]
+	case SriW1kind:
+		sq := math.Sqrt(h)
+		f, g := o.fs, o.gs
+		if err = o.calcF(0, x, y); err != nil {
+			return
+		}
+		if err = o.calcG(0, x, y); err != nil {
+			return
+		}
+		var dW, chi2 float64
+		for m := 0; m < o.ndim; m++ {
+			chi2 = 0.5 * (o.dW[m] + o.dZ[m]/math.Sqrt(3)) // I_(1,0) / h
+			o.ys[0][m] = y[m] + 0.75*h*f[0][m] + 1.5*g[0][m]*chi2
+			o.ys[1][m] = y[m] + 0.25*h*f[0][m] + 0.5*sq*g[0][m]
+			o.ys[2][m] = y[m] + h*f[0][m] - sq*g[0][m]
+		}
+		if err = o.calcF(1, x+0.75*h, o.ys[0]); err != nil {
+			return
+		}
+		if err = o.calcG(1, x+0.25*h, o.ys[1]); err != nil {
+			return
+		}
+		if err = o.calcG(2, x+h, o.ys[2]); err != nil {
+			return
+		}
+		for m := 0; m < o.ndim; m++ {
+			o.ys[3][m] = y[m] + 0.25*h*f[0][m] + sq*(-5.0*g[0][m]+3.0*g[1][m]+0.5*g[2][m])
+		}
+		if err = o.calcG(3, x+0.25*h, o.ys[3]); err != nil {
+			return
+		}
+		for m := 0; m < o.ndim; m++ {
+			dW = o.dW[m]
+			chi1 := 0.5 * (dW*dW - h) / sq            // I_(1,1) / √h
+			chi2 = 0.5 * (dW + o.dZ[m]/math.Sqrt(3))  // I_(1,0) / h
+			chi3 := (dW*dW*dW - 3.0*h*dW) / (6.0 * h) // I_(1,1,1) / h
+			e1 := 2.0 * h * (f[1][m] - f[0][m]) / 3.0 // difference to the explicit Euler drift
+			e2 := chi2*(2.0*g[0][m]-4.0*g[1][m]/3.0-2.0*g[2][m]/3.0) +
+				chi3*(-2.0*g[0][m]+5.0*g[1][m]/3.0-2.0*g[2][m]/3.0+g[3][m]) // strong order 1.5 terms
+			o.ynew[m] = y[m] + h*(f[0][m]+2.0*f[1][m])/3.0 +
+				dW*(-g[0][m]+4.0*g[1][m]/3.0+2.0*g[2][m]/3.0) +
+				chi1*(-g[0][m]+4.0*g[1][m]/3.0-g[2][m]/3.0) + e2
+			o.err[m] = math.Abs(e1)/6.0 + math.Abs(e2)
+		}
+	}
+	return
+}
+
+// output calls the output function and saves x and y if SaveXY is true
+func (o *SdeSolver) output(first bool, x float64, y la.Vector) (err error) {
+	if o.SaveXY && o.IdxSave < len(o.Xvalues) {
+		o.Xvalues[o.IdxSave] = x
+		for i := 0; i < o.ndim; i++ {
+			o.Yvalues[i][o.IdxSave] = y[i]
+		}
+		o.IdxSave++
+	}
+	if o.out != nil {
+		err = o.out(first, o.h, x, y)
+	}
+	return
+}
@@ -0,0 +1,343 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+	"sort"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/num"
+	"github.com/cpmech/gosl/utl"
+)
+
+// ObjF defines a term φ(x, {y}) of the scalar objective G = Σ_k φ(x_k, {y}(x_k)) evaluated at
+// the stations x_k; e.g. the misfit between the solution and measurements
+//
+//   Input:
+//     x -- station
+//     y -- {y}(x)
+//   Output:
+//     dφdy -- ∂φ/∂{y}
+//     φ    -- value of the term
+//
+type ObjF func(dφdy la.Vector, x float64, y la.Vector) (φ float64, err error)
+
+// AdjSens computes the gradient dG/d{p} of the scalar objective G = Σ_k φ(x_k, {y}(x_k)), where
+// {y} solves d{y}/dx = {f}(x, {y}, {p}), with the adjoint method. The adjoint equations
+//
+//   d{λ}/dx = -[J]ᵀ {λ}    with    {λ}(xb⁺) = 0  and  {λ}(x_k⁻) = {λ}(x_k⁺) + ∂φ/∂{y}(x_k)
+//
+//  are integrated backwards together with the quadrature of [∂f/∂p]ᵀ{λ}, giving
+//
+//   dG/d{p} = [S0]ᵀ {λ}(xa) + ∫ [∂f/∂p]ᵀ {λ} dx    with    [S0] = d{y}(xa)/d{p}
+//
+//  The state is stored at checkpoints during the forward pass (Ncheck uniformly spaced intervals
+//  and the stations); during the backward pass, the state is recomputed between consecutive
+//  checkpoints and interpolated with cubic Hermite polynomials. Thus, the memory is proportional
+//  to the number of checkpoints plus the number of steps between two checkpoints.
+//  Note: the backward problem is solved by Adj in the variable τ = -x
+type AdjSens struct {
+
+	// primary variables
+	ndim int   // size of y
+	npar int   // number of parameters
+	fcn  Func  // dydx := f(x, y)
+	jac  JacF  // Jacobian: dfdy [may be nil]
+	dfdp DfdpF // derivatives with respect to the parameters
+	obj  ObjF  // objective terms
+
+	// solvers
+	Ode    *Solver // solver of the state (forward)
+	Adj    *Solver // solver of the adjoint equations and quadratures (backward); size = ndim + npar
+	Ncheck int     // number of uniformly spaced intervals between checkpoints (stations are added)
+
+	// output
+	G      float64     // objective
+	Grad   la.Vector   // gradient dG/d{p} [npar]
+	X      []float64   // stations [nstations]
+	Y      [][]float64 // y at stations [nstations][ndim]
+	Lambda [][]float64 // ∂G/∂{y}(x_k) = {λ}(x_k⁻) at stations [nstations][ndim]
+	Xcheck []float64   // checkpoints
+
+	// stat variables
+	Nfwd int // total number of substeps of the state, including the recomputations
+	Nadj int // total number of substeps of the adjoint equations
+
+	// history of the state in the current interval
+	hx []float64   // x values
+	hy []la.Vector // y values
+	hf []la.Vector // f values
+
+	// workspace
+	yi   la.Vector  // interpolated y
+	fi   la.Vector  // f(x, yi) for the numerical Jacobian
+	wi   la.Vector  // workspace for the numerical Jacobian
+	fp   *la.Matrix // ∂f/∂p
+	dfdy la.Triplet // Jacobian
+}
+
+// NewAdjSens returns a new adjoint sensitivity solver
+//  Input:
+//   method -- the ODE method kind of Ode and Adj; e.g. DoPri5kind
+//   ndim   -- size of y
+//   npar   -- number of parameters
+//   fcn    -- function f(x, y)
+//   jac    -- Jacobian ∂f/∂y [may be nil]
+//   dfdp   -- derivatives ∂f/∂p
+//   obj    -- terms φ(x, y) of the objective
+func NewAdjSens(method io.Enum, ndim, npar int, fcn Func, jac JacF, dfdp DfdpF, obj ObjF) (o *AdjSens) {
+	o = new(AdjSens)
+	o.ndim = ndim
+	o.npar = npar
+	o.fcn = fcn
+	o.jac = jac
+	o.dfdp = dfdp
+	o.obj = obj
+	o.Ode = NewSolver(method, ndim, fcn, jac, nil, nil)
+	o.Adj = NewSolver(method, ndim+npar, o.adjoint, o.adjointJac, nil, nil)
+	o.Ncheck = 10
+	o.yi = la.NewVector(ndim)
+	o.fi = la.NewVector(ndim)
+	o.wi = la.NewVector(ndim)
+	o.fp = la.NewMatrix(ndim, npar)
+	return
+}
+
+// Solve computes G and dG/d{p}
+//  Input:
+//   y        -- initial values
+//   S0       -- initial sensitivities d{y}(xa)/d{p} [may be nil => zero]
+//   x        -- initial x = xa
+//   xb       -- final x
+//   Δx       -- step size if fixstp (adjusted to fit the checkpoints); otherwise max step size
+//   fixstp   -- fixed steps
+//   stations -- x values (ascending) where the objective terms are evaluated; xa ≤ x_k ≤ xb
+//  Output:
+//   y (= y(xb)), G, Grad, X, Y and Lambda
+func (o *AdjSens) Solve(y la.Vector, S0 *la.Matrix, x, xb, Δx float64, fixstp bool, stations []float64) (err error) {
+
+	// check
+	if len(o.Ode.events) > 0 {
+		return chk.Err("events are not supported by AdjSens\n")
+	}
+	if xb <= x {
+		return chk.Err("xb == %v must be greater than x == %v\n", xb, x)
+	}
+	if len(stations) < 1 {
+		return chk.Err("at least one station is required\n")
+	}
+	for k := 0; k < len(stations); k++ {
+		if stations[k] < x || stations[k] > xb || (k > 0 && stations[k] <= stations[k-1]) {
+			return chk.Err("stations must be strictly ascending and within [%g, %g]\n", x, xb)
+		}
+	}
+
+	// checkpoints: stations and uniformly spaced points that are not too close to the stations
+	tol := 1e-8 * (xb - x)
+	o.Xcheck = utl.GetCopy(stations)
+	for _, xu := range utl.LinSpace(x, xb, utl.Imax(o.Ncheck, 1)+1) {
+		k := sort.SearchFloat64s(stations, xu)
+		if (k == len(stations) || stations[k]-xu > tol) && (k == 0 || xu-stations[k-1] > tol) {
+			o.Xcheck = append(o.Xcheck, xu)
+		}
+	}
+	sort.Float64s(o.Xcheck)
+	nc := len(o.Xcheck)
+
+	// forward pass
+	ns := len(stations)
+	o.G, o.Nfwd, o.Nadj = 0, 0, 0
+	o.X = stations
+	o.Y = utl.Alloc(ns, o.ndim)
+	o.Lambda = utl.Alloc(ns, o.ndim)
+	dφdy := utl.Alloc(ns, o.ndim)
+	ycheck := make([]la.Vector, nc)
+	o.Ode.stepHook = nil
+	k := 0 // index of the next station
+	for i := 0; i < nc; i++ {
+		ycheck[i] = y.GetCopy()
+		if k < ns && o.Xcheck[i] == stations[k] {
+			var φ float64
+			φ, err = o.obj(dφdy[k], stations[k], y)
+			if err != nil {
+				return
+			}
+			o.G += φ
+			copy(o.Y[k], y)
+			k++
+		}
+		if i < nc-1 {
+			err = o.segment(o.Ode, y, o.Xcheck[i], o.Xcheck[i+1], Δx, fixstp)
+			if err != nil {
+				return
+			}
+			o.Nfwd += o.Ode.Nsteps
+		}
+	}
+
+	// backward pass
+	z := la.NewVector(o.ndim + o.npar)
+	λ := z[:o.ndim]
+	yrec := la.NewVector(o.ndim)
+	k = ns - 1
+	defer func() { o.Ode.stepHook = nil }()
+	for i := nc - 1; i >= 0; i-- {
+
+		// jump at station
+		if k >= 0 && o.Xcheck[i] == stations[k] {
+			la.VecAdd(λ, 1, λ, 1, dφdy[k])
+			copy(o.Lambda[k], λ)
+			k--
+		}
+		if i == 0 {
+			break
+		}
+
+		// recompute the state in [x_{i-1}, x_i]
+		o.hx, o.hy, o.hf = nil, nil, nil
+		yrec.Apply(1, ycheck[i-1])
+		err = o.record(o.Xcheck[i-1], yrec)
+		if err != nil {
+			return
+		}
+		o.Ode.stepHook = o.record
+		err = o.segment(o.Ode, yrec, o.Xcheck[i-1], o.Xcheck[i], Δx, fixstp)
+		if err != nil {
+			return
+		}
+		o.Ode.stepHook = nil
+		o.Nfwd += o.Ode.Nsteps
+
+		// adjoint equations from x_i to x_{i-1}
+		err = o.segment(o.Adj, z, -o.Xcheck[i], -o.Xcheck[i-1], Δx, fixstp)
+		if err != nil {
+			return
+		}
+		o.Nadj += o.Adj.Nsteps
+	}
+
+	// gradient
+	o.Grad = la.NewVector(o.npar)
+	for j := 0; j < o.npar; j++ {
+		o.Grad[j] = z[o.ndim+j]
+		if S0 != nil {
+			for m := 0; m < o.ndim; m++ {
+				o.Grad[j] += S0.Get(m, j) * λ[m]
+			}
+		}
+	}
+	return
+}
+
+// segment solves from a to b with sol; with fixed steps, Δx is adjusted to fit the interval
+func (o *AdjSens) segment(sol *Solver, y la.Vector, a, b, Δx float64, fixstp bool) (err error) {
+	dx := min(Δx, b-a)
+	if fixstp {
+		n := int(math.Ceil((b-a)/Δx - 1e-10))
+		dx = (b - a) / float64(n)
+	}
+	return sol.Solve(y, a, b, dx, fixstp)
+}
+
+// record stores x, y and f(x,y) in the history of the current interval
+func (o *AdjSens) record(x float64, y la.Vector) (err error) {
+	f := la.NewVector(o.ndim)
+	err = o.fcn(f, o.Ode.h, x, y)
+	if err != nil {
+		return
+	}
+	o.hx = append(o.hx, x)
+	o.hy = append(o.hy, y.GetCopy())
+	o.hf = append(o.hf, f)
+	return
+}
+
+// interp computes y(x) within the current interval with cubic Hermite interpolation
+func (o *AdjSens) interp(y la.Vector, x float64) {
+	n := len(o.hx)
+	if n == 1 {
+		y.Apply(1, o.hy[0])
+		return
+	}
+	i := sort.SearchFloat64s(o.hx, x) - 1 // x_i < x ≤ x_{i+1}
+	if i < 0 {
+		i = 0
+	}
+	if i > n-2 {
+		i = n - 2
+	}
+	h := o.hx[i+1] - o.hx[i]
+	θ := (x - o.hx[i]) / h
+	θ2, θ3 := θ*θ, θ*θ*θ
+	a0, b0 := 2.0*θ3-3.0*θ2+1.0, (θ3-2.0*θ2+θ)*h
+	a1, b1 := -2.0*θ3+3.0*θ2, (θ3-θ2)*h
+	y0, f0, y1, f1 := o.hy[i], o.hf[i], o.hy[i+1], o.hf[i+1]
+	for m := 0; m < o.ndim; m++ {
+		y[m] = a0*y0[m] + b0*f0[m] + a1*y1[m] + b1*f1[m]
+	}
+}
+
+// point computes y, the Jacobian and ∂f/∂p at x
+func (o *AdjSens) point(h, x float64) (err error) {
+	o.interp(o.yi, x)
+	err = o.dfdp(o.fp, h, x, o.yi)
+	if err != nil {
+		return
+	}
+	if o.jac != nil {
+		return o.jac(&o.dfdy, h, x, o.yi)
+	}
+	err = o.fcn(o.fi, h, x, o.yi)
+	if err != nil {
+		return
+	}
+	return num.Jacobian(&o.dfdy, func(ff, yy la.Vector) error {
+		return o.fcn(ff, h, x, yy)
+	}, o.yi, o.fi, o.wi)
+}
+
+// adjoint computes the right-hand side of the adjoint equations and quadratures in τ = -x:
+//   dλ/dτ = Jᵀ λ  and  dq/dτ = [∂f/∂p]ᵀ λ
+func (o *AdjSens) adjoint(f la.Vector, h, τ float64, z la.Vector) (err error) {
+	err = o.point(h, -τ)
+	if err != nil {
+		return
+	}
+	λ := z[:o.ndim]
+	la.SpTriMatTrVecMul(f[:o.ndim], &o.dfdy, λ)
+	for j := 0; j < o.npar; j++ {
+		f[o.ndim+j] = 0
+		for m := 0; m < o.ndim; m++ {
+			f[o.ndim+j] += o.fp.Get(m, j) * λ[m]
+		}
+	}
+	return
+}
+
+// adjointJac computes the Jacobian of the adjoint equations and quadratures (for implicit methods)
+func (o *AdjSens) adjointJac(dfdz *la.Triplet, h, τ float64, z la.Vector) (err error) {
+	err = o.point(h, -τ)
+	if err != nil {
+		return
+	}
+	nz := o.ndim + o.npar
+	if dfdz.Max() == 0 {
+		dfdz.Init(nz, nz, o.ndim*nz)
+	}
+	dfdz.Start()
+	J := o.dfdy.GetDenseMatrix()
+	for m := 0; m < o.ndim; m++ {
+		for i := 0; i < o.ndim; i++ {
+			dfdz.Put(i, m, J.Get(m, i)) // ∂(Jᵀλ)_i/∂λ_m = J_mi
+		}
+		for j := 0; j < o.npar; j++ {
+			dfdz.Put(o.ndim+j, m, o.fp.Get(m, j))
+		}
+	}
+	return
+}
@@ -0,0 +1,331 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+// DfdpF defines the derivatives of Func with respect to the parameters {p}
+//
+//   Input:
+//     h -- current stepsize = dx
+//     x -- current x
+//     y -- current {y}
+//   Output:
+//     dfdp -- ∂{f}/∂{p} [ndim][npar]
+//
+type DfdpF func(dfdp *la.Matrix, h, x float64, y la.Vector) error
+
+// FwdSens computes the sensitivities [S] = d{y}/d{p} of the solution of d{y}/dx = {f}(x, {y}, {p})
+// with respect to the parameters {p} by integrating the forward sensitivity equations
+//
+//   d{s_j}/dx = [J] {s_j} + ∂{f}/∂p_j    with    [J] = ∂{f}/∂{y}  and  {s_j} = column j of [S]
+//
+//  alongside the state. With the implicit methods BwEuler, Bdf and Ndf, the sensitivities are
+//  computed after each step with a staggered corrector that reuses the factorisation of the
+//  iteration matrix of the state. With the other methods, the augmented system with the state and
+//  the sensitivities is integrated simultaneously (with a numerical Jacobian if implicit).
+//  The products [J]{s_j} are computed with the Jacobian function if given or by finite differences
+//  Notes:
+//   1) the local error is only controlled on the sensitivities with the simultaneous approach
+//   2) events are not supported
+type FwdSens struct {
+
+	// primary variables
+	ndim int   // size of y
+	npar int   // number of parameters
+	fcn  Func  // dydx := f(x, y)
+	jac  JacF  // Jacobian: dfdy [may be nil]
+	dfdp DfdpF // derivatives with respect to the parameters
+
+	// solver
+	Ode       *Solver // solver of the state (staggered) or of the augmented system (simultaneous)
+	Staggered bool    // the staggered corrector is used [read only]
+	CorrTol   float64 // tolerance of the staggered corrector: RMS of the corrections scaled by Atol + Rtol*|s|
+
+	// output
+	X      []float64    // stations [nstations]
+	Y      [][]float64  // y at stations [nstations][ndim]
+	S      []*la.Matrix // sensitivities at stations [nstations] (ndim × npar)
+	Sfinal *la.Matrix   // sensitivities at the end of Solve (ndim × npar)
+
+	// stat variables
+	Nfeval int // number of calls to fcn to compute the products [J]{s} by finite differences
+	Njeval int // number of calls to jac
+	Npeval int // number of calls to dfdp
+
+	// control variables
+	first    bool        // first step
+	stations []float64   // output stations
+	istat    int         // index of the next station
+	xprev    float64     // x at the beginning of the step
+	yprev    la.Vector   // y at the beginning of the step
+	s        []la.Vector // sensitivities {s_j} [npar][ndim]
+	ydns     la.Vector   // dense output of y (or of the augmented vector)
+	sdns     []la.Vector // dense output of s [npar][ndim]
+
+	// data of the current point (see prepare)
+	hc, xc float64    // h and x
+	yc     la.Vector  // y
+	fc     la.Vector  // f(xc, yc)
+	fp     *la.Matrix // ∂f/∂p
+	dfdy   la.Triplet // Jacobian
+	ypert  la.Vector  // perturbed y
+}
+
+// NewFwdSens returns a new forward sensitivity solver
+//  Input:
+//   method -- the ODE method kind; e.g. DoPri5kind or BdfKind
+//   ndim   -- size of y
+//   npar   -- number of parameters
+//   fcn    -- function f(x, y)
+//   jac    -- Jacobian ∂f/∂y [may be nil]
+//   dfdp   -- derivatives ∂f/∂p
+func NewFwdSens(method io.Enum, ndim, npar int, fcn Func, jac JacF, dfdp DfdpF) (o *FwdSens) {
+	if npar < 1 {
+		chk.Panic("the number of parameters must be at least 1. npar = %d is invalid\n", npar)
+	}
+	o = new(FwdSens)
+	o.ndim = ndim
+	o.npar = npar
+	o.fcn = fcn
+	o.jac = jac
+	o.dfdp = dfdp
+	o.CorrTol = 0.01
+	if _, ok := NewRKmethod(method).(sensStepper); ok {
+		o.Staggered = true
+		o.Ode = NewSolver(method, ndim, fcn, jac, nil, nil)
+		o.ydns = la.NewVector(ndim)
+	} else {
+		o.Ode = NewSolver(method, ndim*(1+npar), o.augmented, nil, nil, nil)
+		o.ydns = la.NewVector(ndim * (1 + npar))
+	}
+	o.yprev = la.NewVector(ndim)
+	o.s = make([]la.Vector, npar)
+	o.sdns = make([]la.Vector, npar)
+	for j := 0; j < npar; j++ {
+		o.s[j] = la.NewVector(ndim)
+		o.sdns[j] = la.NewVector(ndim)
+	}
+	o.fc = la.NewVector(ndim)
+	o.fp = la.NewMatrix(ndim, npar)
+	o.ypert = la.NewVector(ndim)
+	return
+}
+
+// Solve solves from (xa,ya) to (xb,yb) => find yb (stored in y) and the sensitivities
+//  Input:
+//   y        -- initial values
+//   S0       -- initial sensitivities d{y}(xa)/d{p} [may be nil => zero]
+//   x        -- initial x = xa
+//   xb       -- final x
+//   Δx       -- step size if fixstp; otherwise output interval (see Solver.Solve)
+//   fixstp   -- fixed steps
+//   stations -- x values (ascending) where the sensitivities are output [may be nil]
+//  Output:
+//   y, X, Y, S and Sfinal
+func (o *FwdSens) Solve(y la.Vector, S0 *la.Matrix, x, xb, Δx float64, fixstp bool, stations []float64) (err error) {
+
+	// check
+	if len(o.Ode.events) > 0 {
+		return chk.Err("events are not supported by FwdSens\n")
+	}
+	for i := 1; i < len(stations); i++ {
+		if stations[i] < stations[i-1] {
+			return chk.Err("stations must be in ascending order\n")
+		}
+	}
+
+	// initial sensitivities
+	for j := 0; j < o.npar; j++ {
+		o.s[j].Fill(0)
+		if S0 != nil {
+			for i := 0; i < o.ndim; i++ {
+				o.s[j][i] = S0.Get(i, j)
+			}
+		}
+	}
+
+	// stations
+	o.X, o.Y, o.S = nil, nil, nil
+	o.stations = stations
+	o.istat = 0
+	for o.istat < len(stations) && stations[o.istat] <= x {
+		if stations[o.istat] == x {
+			o.save(x, y, o.s)
+		}
+		o.istat++
+	}
+
+	// solve
+	o.Nfeval, o.Njeval, o.Npeval = 0, 0, 0
+	o.first = true
+	o.xprev = x
+	o.yprev.Apply(1, y)
+	if o.Staggered {
+		o.Ode.stepHook = o.staggered
+		err = o.Ode.Solve(y, x, xb, Δx, fixstp)
+	} else {
+		z := la.NewVector(o.ndim * (1 + o.npar))
+		copy(z, y)
+		for j := 0; j < o.npar; j++ {
+			copy(z[(j+1)*o.ndim:], o.s[j])
+		}
+		o.Ode.stepHook = o.simultaneous
+		err = o.Ode.Solve(z, x, xb, Δx, fixstp)
+		copy(y, z)
+		for j := 0; j < o.npar; j++ {
+			copy(o.s[j], z[(j+1)*o.ndim:(j+2)*o.ndim])
+		}
+	}
+	if err != nil {
+		return
+	}
+
+	// final sensitivities
+	o.Sfinal = la.NewMatrix(o.ndim, o.npar)
+	for j := 0; j < o.npar; j++ {
+		for i := 0; i < o.ndim; i++ {
+			o.Sfinal.Set(i, j, o.s[j][i])
+		}
+	}
+	return
+}
+
+// staggered updates the sensitivities after each accepted step of the state
+func (o *FwdSens) staggered(x float64, y la.Vector) (err error) {
+	sol := o.Ode
+	ss := sol.rkm.(sensStepper)
+	err = ss.SensStep(sol, o, o.xprev, o.yprev, y, o.s)
+	if err != nil {
+		return
+	}
+	o.first = false
+	h := x - o.xprev
+	for o.istat < len(o.stations) && o.stations[o.istat] <= x {
+		xs := o.stations[o.istat]
+		sol.denseOut(o.ydns, h, x, y, xs)
+		ss.SensDenseOut(sol, o.sdns, h, x, o.s, xs)
+		o.save(xs, o.ydns, o.sdns)
+		o.istat++
+	}
+	o.xprev = x
+	o.yprev.Apply(1, y)
+	return
+}
+
+// simultaneous produces the output at stations after each accepted step of the augmented system
+func (o *FwdSens) simultaneous(x float64, z la.Vector) (err error) {
+	sol := o.Ode
+	h := x - o.xprev
+	for o.istat < len(o.stations) && o.stations[o.istat] <= x {
+		xs := o.stations[o.istat]
+		sol.denseOut(o.ydns, h, x, z, xs)
+		for j := 0; j < o.npar; j++ {
+			copy(o.sdns[j], o.ydns[(j+1)*o.ndim:(j+2)*o.ndim])
+		}
+		o.save(xs, o.ydns[:o.ndim], o.sdns)
+		o.istat++
+	}
+	o.xprev = x
+	return
+}
+
+// augmented computes the right-hand side of the state and sensitivity equations
+func (o *FwdSens) augmented(f la.Vector, h, x float64, z la.Vector) (err error) {
+	n := o.ndim
+	err = o.fcn(f[:n], h, x, z[:n])
+	if err != nil {
+		return
+	}
+	err = o.prepare(h, x, z[:n], f[:n])
+	if err != nil {
+		return
+	}
+	for j := 0; j < o.npar; j++ {
+		err = o.rhs(f[(j+1)*n:(j+2)*n], j, z[(j+1)*n:(j+2)*n])
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// prepare computes ∂f/∂p and the Jacobian (or f for the finite differences) at (x,y)
+//  Input:
+//   f -- f(x,y) if available [may be nil]
+func (o *FwdSens) prepare(h, x float64, y, f la.Vector) (err error) {
+	o.hc, o.xc, o.yc = h, x, y
+	o.Npeval++
+	err = o.dfdp(o.fp, h, x, y)
+	if err != nil {
+		return
+	}
+	if o.jac != nil {
+		o.Njeval++
+		return o.jac(&o.dfdy, h, x, y)
+	}
+	if f != nil {
+		o.fc.Apply(1, f)
+		return
+	}
+	o.Nfeval++
+	return o.fcn(o.fc, h, x, y)
+}
+
+// rhs computes r = [J]{s} + ∂{f}/∂p_j at the point given to prepare
+func (o *FwdSens) rhs(r la.Vector, j int, s la.Vector) (err error) {
+	if o.jac != nil {
+		la.SpTriMatVecMul(r, &o.dfdy, s)
+	} else {
+		var ynrm, snrm float64
+		for i := 0; i < o.ndim; i++ {
+			ynrm += o.yc[i] * o.yc[i]
+			snrm += s[i] * s[i]
+		}
+		if snrm == 0 {
+			r.Fill(0)
+		} else {
+			ε := math.Sqrt(o.Ode.Eps) * max(1.0, math.Sqrt(ynrm)) / math.Sqrt(snrm)
+			la.VecAdd(o.ypert, 1, o.yc, ε, s)
+			o.Nfeval++
+			err = o.fcn(r, o.hc, o.xc, o.ypert)
+			if err != nil {
+				return
+			}
+			la.VecAdd(r, 1/ε, r, -1/ε, o.fc) // r := (f(y + ε s) - f(y)) / ε
+		}
+	}
+	for i := 0; i < o.ndim; i++ {
+		r[i] += o.fp.Get(i, j)
+	}
+	return
+}
+
+// norm computes the RMS norm of the correction δs scaled by Atol + Rtol*|s|
+func (o *FwdSens) norm(δs, s la.Vector) (nrm float64) {
+	for m := 0; m < o.ndim; m++ {
+		nrm += math.Pow(δs[m]/(o.Ode.Atol+o.Ode.Rtol*math.Abs(s[m])), 2.0)
+	}
+	return math.Sqrt(nrm / float64(o.ndim))
+}
+
+// save saves x, y and s at a station
+func (o *FwdSens) save(x float64, y la.Vector, s []la.Vector) {
+	S := la.NewMatrix(o.ndim, o.npar)
+	for j := 0; j < o.npar; j++ {
+		for i := 0; i < o.ndim; i++ {
+			S.Set(i, j, s[j][i])
+		}
+	}
+	o.X = append(o.X, x)
+	o.Y = append(o.Y, y.GetCopy())
+	o.S = append(o.S, S)
+}
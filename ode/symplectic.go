@@ -0,0 +1,345 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"context"
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+// symplectic methods for separable Hamiltonian systems
+var (
+
+	// SympEulerKind specifies the symplectic Euler method (order 1)
+	SympEulerKind = io.NewEnum("SympEuler", "ode", "SE", "symplectic Euler (order 1)")
+
+	// StormerVerletKind specifies the Störmer-Verlet (leapfrog) method (order 2)
+	StormerVerletKind = io.NewEnum("StormerVerlet", "ode", "SV", "Störmer-Verlet (order 2)")
+
+	// Ruth3kind specifies Ruth's symplectic partitioned Runge-Kutta method of order 3
+	Ruth3kind = io.NewEnum("Ruth3", "ode", "RU3", "Ruth's partitioned Runge-Kutta (order 3)")
+
+	// Yoshida4kind specifies Yoshida's composition of Störmer-Verlet steps of order 4
+	Yoshida4kind = io.NewEnum("Yoshida4", "ode", "Y4", "Yoshida composition (order 4)")
+
+	// Yoshida6kind specifies Yoshida's composition of Störmer-Verlet steps of order 6
+	Yoshida6kind = io.NewEnum("Yoshida6", "ode", "Y6", "Yoshida composition (order 6)")
+)
+
+// PosF defines the derivative of the positions of a separable Hamiltonian system
+// H(q, p) = T(p) + V(q); i.e. d{q}/dx = ∂H/∂{p} = {fq}(h, x, {p})
+//
+//   Input:
+//     h -- current stepsize = dx
+//     x -- current x
+//     p -- current momenta {p}
+//   Output:
+//     fq -- d{q}/dx
+//
+type PosF func(fq la.Vector, h, x float64, p la.Vector) error
+
+// MomF defines the derivative of the momenta of a separable Hamiltonian system
+// H(q, p) = T(p) + V(q); i.e. d{p}/dx = -∂H/∂{q} = {fp}(h, x, {q})
+//
+//   Input:
+//     h -- current stepsize = dx
+//     x -- current x
+//     q -- current positions {q}
+//   Output:
+//     fp -- d{p}/dx
+//
+type MomF func(fp la.Vector, h, x float64, q la.Vector) error
+
+// HamF defines the Hamiltonian (energy) H(x, {q}, {p}) used to monitor the energy drift
+type HamF func(x float64, q, p la.Vector) float64
+
+// Symplectic implements symplectic (fixed-step) integrators for separable Hamiltonian systems
+//
+//   d{q}/dx =  ∂H/∂{p} = {fq}(x, {p})
+//   d{p}/dx = -∂H/∂{q} = {fp}(x, {q})
+//
+//  The methods are explicit symplectic partitioned Runge-Kutta methods written as a sequence of
+//  "kicks" and "drifts" (see [1], page 190 and Hairer, Lubich & Wanner (2006) Geometric Numerical
+//  Integration, Springer):
+//
+//   for i = 0 ... s-1:
+//     p := p + b_i h fp(x + c_i h, q)     (kick)
+//     q := q + a_i h fq(x + d_i h, p)     (drift)
+//
+//  where c_i = Σ_{j<i} a_j and d_i = Σ_{j≤i} b_j
+//
+//  Note: these methods do not conserve H exactly but keep the energy error bounded for very
+//        long integrations, in contrast to non-symplectic methods such as DoPri5
+type Symplectic struct {
+
+	// method data
+	method io.Enum   // method kind
+	a, b   []float64 // drift and kick coefficients
+
+	// primary variables
+	ndim int  // size of q and p
+	fq   PosF // dq/dx
+	fp   MomF // dp/dx
+	ham  HamF // Hamiltonian [may be nil]
+	out  OutF // output function; y = {q, p} [may be nil]
+
+	// flags
+	SaveXY bool // save X, Q and P values in arrays (e.g. for plotting)
+
+	// cancellation and progress report
+	Monitor utl.Monitor // it = number of steps, residual = energy drift, step = h
+
+	// output
+	IdxSave  int         // current index in Xvalues, Qvalues and Pvalues == last output
+	Xvalues  []float64   // X values if SaveXY is true [IdxSave]
+	Qvalues  [][]float64 // Q values if SaveXY is true [ndim][IdxSave]
+	Pvalues  [][]float64 // P values if SaveXY is true [ndim][IdxSave]
+	Evalues  []float64   // energy drift values if SaveXY is true and H is given [IdxSave]
+	H0       float64     // initial energy (if H is given)
+	Hfinal   float64     // final energy (if H is given)
+	MaxDrift float64     // max relative energy drift |H - H0| / max(1, |H0|) (if H is given)
+
+	// stat variables
+	Nfqeval int // number of calls to fq
+	Nfpeval int // number of calls to fp
+	Nsteps  int // number of steps
+
+	// workspace
+	dq, dp la.Vector // derivatives
+	y      la.Vector // y = {q, p} for the output function
+	fpOk   bool      // dp holds fp(x, q) at the current q
+}
+
+// NewSymplectic returns a new symplectic integrator for separable Hamiltonian systems
+//  Input:
+//   method -- the method kind; e.g. StormerVerletKind
+//   ndim   -- number of positions (equal to the number of momenta)
+//   fq     -- dq/dx = ∂H/∂p
+//   fp     -- dp/dx = -∂H/∂q
+//   H      -- Hamiltonian to monitor the energy drift [may be nil]
+//   out    -- output function called with y = {q, p} [may be nil]
+func NewSymplectic(method io.Enum, ndim int, fq PosF, fp MomF, H HamF, out OutF) (o *Symplectic) {
+	o = new(Symplectic)
+	o.method = method
+	o.ndim = ndim
+	o.fq = fq
+	o.fp = fp
+	o.ham = H
+	o.out = out
+	switch method {
+	case SympEulerKind:
+		o.a, o.b = []float64{1}, []float64{1}
+	case StormerVerletKind:
+		o.a, o.b = sympCompose([]float64{1})
+	case Ruth3kind:
+		o.a = []float64{2.0 / 3.0, -2.0 / 3.0, 1}
+		o.b = []float64{7.0 / 24.0, 3.0 / 4.0, -1.0 / 24.0}
+	case Yoshida4kind:
+		c := math.Cbrt(2)
+		w1 := 1.0 / (2.0 - c)
+		o.a, o.b = sympCompose([]float64{w1, -c * w1, w1})
+	case Yoshida6kind: // solution A of Yoshida (1990) Physics Letters A, 150:262-268
+		w1, w2, w3 := -1.17767998417887, 0.235573213359357, 0.784513610477560
+		w0 := 1.0 - 2.0*(w1+w2+w3)
+		o.a, o.b = sympCompose([]float64{w3, w2, w1, w0, w1, w2, w3})
+	default:
+		chk.Panic("cannot find symplectic method named %q", method)
+	}
+	o.dq = la.NewVector(ndim)
+	o.dp = la.NewVector(ndim)
+	o.y = la.NewVector(2 * ndim)
+	return
+}
+
+// SetCoefficients sets custom drift (a) and kick (b) coefficients of an explicit symplectic
+// partitioned Runge-Kutta method. Consistency requires Σa = Σb = 1
+func (o *Symplectic) SetCoefficients(a, b []float64) {
+	if len(a) != len(b) || len(a) < 1 {
+		chk.Panic("a and b must have the same (non-zero) length. %d != %d\n", len(a), len(b))
+	}
+	o.a = utl.GetCopy(a)
+	o.b = utl.GetCopy(b)
+}
+
+// Solve solves from (xa,qa,pa) to (xb,qb,pb) with fixed steps => find qb and pb (stored in q and p)
+//  Note: the number of steps is the smallest integer n such that (xb-xa)/n ≤ Δx; i.e. Δx is
+//        slightly reduced (if necessary) to reach xb exactly
+func (o *Symplectic) Solve(q, p la.Vector, x, xb, Δx float64) (err error) {
+
+	// check
+	if xb < x {
+		err = chk.Err("xb == %v must be greater than x == %v\n", xb, x)
+		return
+	}
+	if Δx <= 0 {
+		err = chk.Err("Δx == %v must be positive\n", Δx)
+		return
+	}
+	if len(q) != o.ndim || len(p) != o.ndim {
+		err = chk.Err("q and p must have size %d. %d and %d are invalid\n", o.ndim, len(q), len(p))
+		return
+	}
+
+	// step size
+	nsteps := int(math.Ceil((xb-x)/Δx - 1e-10))
+	if nsteps < 1 {
+		nsteps = 1
+	}
+	h := (xb - x) / float64(nsteps)
+	xa := x
+
+	// stat variables
+	o.Nfqeval = 0
+	o.Nfpeval = 0
+	o.Nsteps = 0
+
+	// energy
+	if o.ham != nil {
+		o.H0 = o.ham(x, q, p)
+		o.Hfinal = o.H0
+		o.MaxDrift = 0
+	}
+
+	// output initial state
+	o.IdxSave = 0
+	if o.SaveXY {
+		o.Xvalues = make([]float64, nsteps+1)
+		o.Qvalues = utl.Alloc(o.ndim, nsteps+1)
+		o.Pvalues = utl.Alloc(o.ndim, nsteps+1)
+		if o.ham != nil {
+			o.Evalues = make([]float64, nsteps+1)
+		}
+	}
+	err = o.output(true, h, x, q, p, 0)
+	if err != nil {
+		return
+	}
+
+	// steps
+	o.fpOk = false
+	for n := 1; n <= nsteps; n++ {
+		err = o.step(q, p, x, h)
+		if err != nil {
+			return
+		}
+		o.Nsteps++
+		x = xa + float64(n)*h
+
+		// energy drift
+		var drift float64
+		if o.ham != nil {
+			o.Hfinal = o.ham(x, q, p)
+			drift = math.Abs(o.Hfinal-o.H0) / max(1, math.Abs(o.H0))
+			o.MaxDrift = max(o.MaxDrift, drift)
+		}
+
+		// output
+		err = o.output(false, h, x, q, p, drift)
+		if err != nil {
+			return
+		}
+		err = o.Monitor.Check("ode.Symplectic", n, drift, h)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// SolveCtx solves from (xa,qa,pa) to (xb,qb,pb) and stops with an error if ctx is cancelled
+func (o *Symplectic) SolveCtx(ctx context.Context, q, p la.Vector, x, xb, Δx float64) (err error) {
+	o.Monitor.Ctx = ctx
+	defer func() { o.Monitor.Ctx = nil }()
+	return o.Solve(q, p, x, xb, Δx)
+}
+
+// Stat prints "statistical" information about the solution process
+func (o *Symplectic) Stat() {
+	io.Pf("number of Fq evaluations  =%6d\n", o.Nfqeval)
+	io.Pf("number of Fp evaluations  =%6d\n", o.Nfpeval)
+	io.Pf("total number of steps     =%6d\n", o.Nsteps)
+	if o.ham != nil {
+		io.Pf("max energy drift          = %g\n", o.MaxDrift)
+	}
+}
+
+// step performs one step of the partitioned Runge-Kutta method
+//  Note: fp(q) is not re-computed if q has not changed since the last kick; e.g. with the last
+//        (zero) drift of Störmer-Verlet, which thus requires one evaluation of fp per step
+func (o *Symplectic) step(q, p la.Vector, x, h float64) (err error) {
+	c, d := 0.0, 0.0 // x increments
+	for i := 0; i < len(o.a); i++ {
+
+		// kick
+		d += o.b[i]
+		if o.b[i] != 0 {
+			if !o.fpOk {
+				o.Nfpeval++
+				err = o.fp(o.dp, h, x+c*h, q)
+				if err != nil {
+					return
+				}
+				o.fpOk = true
+			}
+			la.VecAdd(p, 1, p, o.b[i]*h, o.dp) // p += b h fp
+		}
+
+		// drift
+		c += o.a[i]
+		if o.a[i] != 0 {
+			o.Nfqeval++
+			err = o.fq(o.dq, h, x+d*h, p)
+			if err != nil {
+				return
+			}
+			la.VecAdd(q, 1, q, o.a[i]*h, o.dq) // q += a h fq
+			o.fpOk = false
+		}
+	}
+	return
+}
+
+// output calls the output function and saves x, q and p if SaveXY is true
+func (o *Symplectic) output(first bool, h, x float64, q, p la.Vector, drift float64) (err error) {
+	if o.SaveXY && o.IdxSave < len(o.Xvalues) {
+		o.Xvalues[o.IdxSave] = x
+		for i := 0; i < o.ndim; i++ {
+			o.Qvalues[i][o.IdxSave] = q[i]
+			o.Pvalues[i][o.IdxSave] = p[i]
+		}
+		if o.ham != nil {
+			o.Evalues[o.IdxSave] = drift
+		}
+		o.IdxSave++
+	}
+	if o.out != nil {
+		copy(o.y, q)
+		copy(o.y[o.ndim:], p)
+		err = o.out(first, h, x, o.y)
+	}
+	return
+}
+
+// sympCompose returns the kick and drift coefficients of the symmetric composition of
+// Störmer-Verlet steps (kick-drift-kick) with weights w. Consecutive half-kicks are merged
+func sympCompose(w []float64) (a, b []float64) {
+	m := len(w)
+	a = make([]float64, m+1)
+	b = make([]float64, m+1)
+	b[0] = w[0] / 2.0
+	for k := 0; k < m; k++ {
+		a[k] = w[k]
+		if k > 0 {
+			b[k] = (w[k-1] + w[k]) / 2.0
+		}
+	}
+	b[m] = w[m-1] / 2.0
+	return
+}
@@ -0,0 +1,198 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+func Test_solver01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("solver01. first-same-as-last stage after a rejected step")
+
+	// y' = λ (y - cos(x)) - sin(x)
+	lam := -50.0
+	fcn := func(f la.Vector, dx, x float64, y la.Vector) error {
+		f[0] = lam*(y[0]-math.Cos(x)) - math.Sin(x)
+		return nil
+	}
+	x0, y0 := 0.3, la.Vector([]float64{2.0})
+	h1, h2 := 0.2, 0.02 // rejected and retried step sizes
+
+	for _, kind := range []io.Enum{DoPri5kind, MoEulerKind} {
+
+		// reference: step with h2 computing f(x0,y0)
+		sol := NewSolver(kind, 1, fcn, nil, nil, nil)
+		la.VecScaleAbs(sol.scal, sol.Atol, sol.Rtol, y0)
+		sol.first, sol.h = true, h2
+		rerrRef, err := sol.rkm.Step(sol, y0, x0)
+		if err != nil {
+			tst.Errorf("%v\n", err)
+			return
+		}
+		yref := sol.w[0].GetCopy()
+
+		// step with h1 and then, after rejection, with h2: the last stage of the rejected step
+		// must not be taken as f(x0,y0)
+		sol.first, sol.h = true, h1
+		_, err = sol.rkm.Step(sol, y0, x0)
+		if err != nil {
+			tst.Errorf("%v\n", err)
+			return
+		}
+		sol.first, sol.reject, sol.h = false, true, h2
+		rerr, err := sol.rkm.Step(sol, y0, x0)
+		if err != nil {
+			tst.Errorf("%v\n", err)
+			return
+		}
+		io.Pforan("%s: y = %v  yref = %v\n", kind, sol.w[0], yref)
+		chk.Array(tst, io.Sf("%s: y", kind), 1e-15, sol.w[0], yref)
+		chk.Float64(tst, io.Sf("%s: rerr", kind), 1e-15, rerr, rerrRef)
+	}
+}
+
+func Test_solver02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("solver02. several output intervals with fixed and variable steps")
+
+	// y0' = y1, y1' = -y0 with solution y = {cos(x), -sin(x)}
+	fcn := func(f la.Vector, dx, x float64, y la.Vector) error {
+		f[0] = y[1]
+		f[1] = -y[0]
+		return nil
+	}
+	ana := func(x float64) []float64 { return []float64{math.Cos(x), -math.Sin(x)} }
+
+	// fixed steps: Δx is the step size
+	xb, dx, nsteps := 1.0, 0.1, 10
+	tols := []float64{0.06, 1e-6, 1e-6}
+	for k, kind := range []io.Enum{FwEulerKind, DoPri5kind, Radau5kind} {
+		y := la.Vector([]float64{1, 0})
+		sol := NewSolver(kind, 2, fcn, nil, nil, nil)
+		sol.SaveXY = true
+		err := sol.Solve(y, 0, xb, dx, true)
+		if err != nil {
+			tst.Errorf("%v\n", err)
+			return
+		}
+		io.Pforan("%-7s fixed: nsteps = %d  Xfinal = %v  y = %v\n", kind, sol.Nsteps, sol.Xfinal, y)
+		chk.Int(tst, io.Sf("%s: nsteps", kind), sol.Nsteps, nsteps)
+		chk.Int(tst, io.Sf("%s: IdxSave", kind), sol.IdxSave, nsteps+1)
+		chk.Float64(tst, io.Sf("%s: Xfinal", kind), 1e-15, sol.Xfinal, xb)
+		chk.Array(tst, io.Sf("%s: y(xb)", kind), tols[k], y, ana(xb))
+	}
+
+	// variable steps: Δx is the output interval
+	xb, dx = 5.0, 0.5
+	nint := 10
+	for _, kind := range []io.Enum{DoPri5kind, Radau5kind} {
+		y := la.Vector([]float64{1, 0})
+		sol := NewSolver(kind, 2, fcn, nil, nil, nil)
+		sol.SaveXY = true
+		sol.SetTol(1e-8, 1e-8)
+		err := sol.Solve(y, 0, xb, dx, false)
+		if err != nil {
+			tst.Errorf("%v\n", err)
+			return
+		}
+		io.Pforan("%-7s variable: nsteps = %d  naccepted = %d  Xfinal = %v  y = %v\n", kind, sol.Nsteps, sol.Naccepted, sol.Xfinal, y)
+		chk.Float64(tst, io.Sf("%s: Xfinal", kind), 1e-15, sol.Xfinal, xb)
+		chk.Array(tst, io.Sf("%s: y(xb)", kind), 1e-6, y, ana(xb))
+
+		// each interval ends exactly at its end and has more than one step
+		X := sol.Xvalues[:sol.IdxSave]
+		nsteps := make([]int, nint)
+		for i := 1; i < len(X); i++ {
+			if X[i] <= X[i-1] || X[i] > xb {
+				tst.Errorf("%s: x = %v is out of order or beyond xb\n", kind, X[i])
+				return
+			}
+			nsteps[int(math.Ceil(X[i]/dx-1e-8))-1]++
+		}
+		for j := 1; j <= nint; j++ {
+			found := false
+			for _, x := range X {
+				if x == float64(j)*dx {
+					found = true
+				}
+			}
+			if !found {
+				tst.Errorf("%s: end of interval x = %v has not been output\n", kind, float64(j)*dx)
+			}
+			if nsteps[j-1] < 2 {
+				tst.Errorf("%s: interval %d has only %d step(s)\n", kind, j, nsteps[j-1])
+			}
+		}
+	}
+}
+
+// noDenseEuler is a user-defined RKmethod without dense output (Forward Euler)
+type noDenseEuler struct {
+	fw FwEuler
+}
+
+var noDenseEulerKind = io.NewEnum("NoDenseEuler", "ode", "NoDenseEuler", "Forward Euler without dense output")
+
+func init() {
+	rkmDB[noDenseEulerKind] = func() RKmethod { return new(noDenseEuler) }
+}
+
+func (o *noDenseEuler) Init(distr bool) (err error)     { return o.fw.Init(distr) }
+func (o *noDenseEuler) Nstages() int                    { return o.fw.Nstages() }
+func (o *noDenseEuler) Accept(sol *Solver, y la.Vector) { o.fw.Accept(sol, y) }
+func (o *noDenseEuler) Step(sol *Solver, y0 la.Vector, x0 float64) (rerr float64, err error) {
+	return o.fw.Step(sol, y0, x0)
+}
+
+func Test_solver03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("solver03. linear interpolation for methods without dense output")
+
+	fcn := func(f la.Vector, dx, x float64, y la.Vector) error {
+		f[0] = -y[0]
+		f[1] = 2.0 * x
+		return nil
+	}
+
+	// stations
+	y := la.Vector([]float64{1, 0})
+	sol := NewSolver(noDenseEulerKind, 2, fcn, nil, nil, nil)
+	if sol.dns != nil {
+		tst.Errorf("noDenseEuler must not provide dense output\n")
+		return
+	}
+	sol.SaveXY = true
+	sol.Stations = []float64{0.05, 0.1, 0.15}
+	err := sol.Solve(y, 0, 0.2, 0.1, true)
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	chk.Int(tst, "IdxSave", sol.IdxSave, 4)
+	chk.Array(tst, "Xvalues", 1e-15, sol.Xvalues[:4], []float64{0, 0.05, 0.1, 0.15})
+	chk.Array(tst, "Y0values", 1e-15, sol.Yvalues[0][:4], []float64{1, 0.95, 0.9, 0.855})
+	chk.Array(tst, "Y1values", 1e-15, sol.Yvalues[1][:4], []float64{0, 0, 0, 0.01})
+
+	// event: y0 = 0.92 is crossed at x = 0.08 by the linear interpolant of the first step
+	y = la.Vector([]float64{1, 0})
+	sol = NewSolver(noDenseEulerKind, 2, fcn, nil, nil, nil)
+	sol.AddEvent(func(x float64, y la.Vector) float64 { return y[0] - 0.92 }, -1, true, nil)
+	err = sol.Solve(y, 0, 0.2, 0.1, true)
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	chk.Float64(tst, "Xfinal", 1e-12, sol.Xfinal, 0.08)
+	chk.Float64(tst, "y0", 1e-12, y[0], 0.92)
+}
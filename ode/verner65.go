@@ -0,0 +1,62 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ode
+
+import "github.com/cpmech/gosl/la"
+
+// Verner65 implements the (explicit) Verner 6(5) method as used in DVERK by Hull, Enright and
+// Jackson (1976). Verner 6(5), order=6, error_est_order=5, nstages=8
+type Verner65 struct {
+	dat   *erkdata
+	dense erkhermite
+}
+
+// Init initialises structure
+func (o *Verner65) Init(distr bool) (err error) {
+	o.dat = &erkdata{
+		A: [][]float64{
+			{},
+			{1.0 / 6.0},
+			{4.0 / 75.0, 16.0 / 75.0},
+			{5.0 / 6.0, -8.0 / 3.0, 5.0 / 2.0},
+			{-165.0 / 64.0, 55.0 / 6.0, -425.0 / 64.0, 85.0 / 96.0},
+			{12.0 / 5.0, -8.0, 4015.0 / 612.0, -11.0 / 36.0, 88.0 / 255.0},
+			{-8263.0 / 15000.0, 124.0 / 75.0, -643.0 / 680.0, -81.0 / 250.0, 2484.0 / 10625.0, 0.0},
+			{3501.0 / 1720.0, -300.0 / 43.0, 297275.0 / 52632.0, -319.0 / 2322.0, 24068.0 / 84065.0, 0.0, 3850.0 / 26703.0},
+		},
+		B:  []float64{3.0 / 40.0, 0.0, 875.0 / 2244.0, 23.0 / 72.0, 264.0 / 1955.0, 0.0, 125.0 / 11592.0, 43.0 / 616.0},
+		Be: []float64{13.0 / 160.0, 0.0, 2375.0 / 5984.0, 5.0 / 16.0, 12.0 / 85.0, 3.0 / 44.0, 0.0, 0.0},
+		C:  []float64{0.0, 1.0 / 6.0, 4.0 / 15.0, 2.0 / 3.0, 5.0 / 6.0, 1.0, 1.0 / 15.0, 1.0},
+	}
+	return nil
+}
+
+// Nstages returns the number of stages
+func (o *Verner65) Nstages() int {
+	return 8
+}
+
+// Accept accepts update
+func (o *Verner65) Accept(sol *Solver, y la.Vector) {
+	o.dense.accept(sol, y, 8, false)
+	y.Apply(1, sol.w[0]) // y := w (update y)
+}
+
+// Step steps update
+func (o *Verner65) Step(sol *Solver, y0 la.Vector, x0 float64) (rerr float64, err error) {
+	return erkstep(o.dat, 8, false, sol, y0, x0)
+}
+
+// DenseOut produces dense output after Accept using Hermite interpolation (order 3). This requires
+// one extra function evaluation per step. See FwEuler.DenseOut
+func (o *Verner65) DenseOut(sol *Solver, yout la.Vector, h, x float64, y la.Vector, xout float64) {
+	o.dense.denseOut(sol, yout, h, x, y, xout)
+}
+
+// add method to database //////////////////////////////////////////////////////////////////////////
+
+func init() {
+	rkmDB[Verner65kind] = func() RKmethod { return new(Verner65) }
+}
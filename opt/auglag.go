@@ -0,0 +1,204 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+// AugLag implements the augmented Lagrangian method for nonlinear constrained problems
+//  The subproblems are solved by a Minimizer (e.g. L-BFGS) and consist of minimising
+//
+//   L_A(x) = f + Σ (μⱼ hⱼ + ½ ρ hⱼ²) + 1/(2ρ) Σ (max(0, λᵢ + ρ gᵢ)² - λᵢ²)
+//
+//  followed by the update of the multipliers μ ← μ + ρ h and λ ← max(0, λ + ρ g). The penalty
+//  parameter ρ is increased if the infeasibility is greater than Tol and is not reduced by a
+//  factor of 4 in one iteration.
+//  Reference:
+//   Nocedal J and Wright SJ (2006) Numerical Optimization. 2nd Edition. Springer. Chapter 17
+type AugLag struct {
+
+	// constants
+	Prob      *NlProb // problem
+	Method    int     // method to solve the subproblems; e.g. MinLBFGS (gradient-based only)
+	MaxIt     int     // max number of (outer) iterations
+	SubMaxIt  int     // max number of iterations of each subproblem
+	Tol       float64 // tolerance on the largest KKT residual for convergence
+	Rho0      float64 // initial penalty parameter
+	RhoFactor float64 // factor to increase the penalty parameter
+	RhoMax    float64 // max penalty parameter
+	Verbose   bool    // show messages
+
+	// cancellation and progress report
+	Monitor utl.Monitor // residual = largest KKT residual, step = penalty parameter ρ
+}
+
+// NewAugLag returns a new augmented Lagrangian solver
+//  Input:
+//   prob   -- problem
+//   method -- method to solve the subproblems; e.g. MinLBFGS
+func NewAugLag(prob *NlProb, method int) (o *AugLag) {
+	o = new(AugLag)
+	o.Prob = prob
+	o.Method = method
+	o.MaxIt = 50
+	o.SubMaxIt = 1000
+	o.Tol = 1e-6
+	o.Rho0 = 10
+	o.RhoFactor = 10
+	o.RhoMax = 1e10
+	return
+}
+
+// Solve solves the problem starting from x0 (which is not modified)
+func (o *AugLag) Solve(x0 la.Vector) (res *NlResult, err error) {
+
+	// check
+	if o.Method > MinNewtonCG {
+		return nil, chk.Err("method of subproblems must be gradient-based")
+	}
+
+	// initial point
+	ev, err := newNlEval(o.Prob, x0)
+	if err != nil {
+		return
+	}
+	res = ev.res
+	n, mi, me := o.Prob.Ndim, o.Prob.Nineq, o.Prob.Neq
+	x := res.X
+	gf, gl := la.NewVector(n), la.NewVector(n)
+	eval := func() (Ji, Je *la.CCMatrix, e error) {
+		if res.F, e = ev.fcn(x); e != nil {
+			return
+		}
+		if e = ev.cons(res.G, res.H, x); e != nil {
+			return
+		}
+		if e = ev.grad(gf, x); e != nil {
+			return
+		}
+		return ev.jacs(x)
+	}
+	Ji, Je, err := eval()
+	if err != nil {
+		return
+	}
+
+	// augmented Lagrangian and its gradient
+	λ, μ := res.Lam, res.Mu
+	ρ := o.Rho0
+	gx, hx := la.NewVector(mi), la.NewVector(me)
+	v, w := la.NewVector(mi), la.NewVector(me)
+	ffcn := func(z la.Vector) (fa float64, e error) {
+		if fa, e = ev.fcn(z); e != nil {
+			return
+		}
+		if e = ev.cons(gx, hx, z); e != nil {
+			return
+		}
+		for j := 0; j < me; j++ {
+			fa += μ[j]*hx[j] + 0.5*ρ*hx[j]*hx[j]
+		}
+		for i := 0; i < mi; i++ {
+			t := max(0, λ[i]+ρ*gx[i])
+			fa += (t*t - λ[i]*λ[i]) / (2 * ρ)
+		}
+		return
+	}
+	gfcn := func(ga, z la.Vector) (e error) {
+		if e = ev.grad(ga, z); e != nil {
+			return
+		}
+		if e = ev.cons(gx, hx, z); e != nil {
+			return
+		}
+		Jzi, Jze, e := ev.jacs(z)
+		if e != nil {
+			return
+		}
+		for i := 0; i < mi; i++ {
+			v[i] = max(0, λ[i]+ρ*gx[i])
+		}
+		for j := 0; j < me; j++ {
+			w[j] = μ[j] + ρ*hx[j]
+		}
+		lagGrad(ga, ga, Jzi, Jze, v, w)
+		return
+	}
+	sub := NewMinimizer(o.Method, n, ffcn, gfcn)
+	sub.MaxIt = o.SubMaxIt
+	sub.Ftol = 0 // subproblems stop by the tolerance on the gradient only
+
+	// message
+	if o.Verbose {
+		io.Pf("%6s%23s%14s%14s%14s%8s\n", "it", "f(x)", "kkt", "viol", "ρ", "subit")
+	}
+
+	// iterations
+	ω := 1e-2 // tolerance of subproblems
+	vprev := math.Inf(1)
+	nsub := 0
+	for {
+
+		// check convergence
+		ev.kkt(gf, Ji, Je, gl)
+		ev.record()
+		kkt := res.Kkt.Max()
+		if o.Verbose {
+			io.Pf("%6d%23.15e%14.6e%14.6e%14.6e%8d\n", res.Nit, res.F, kkt, violation(res.G, res.H), ρ, nsub)
+		}
+		if kkt <= o.Tol {
+			res.Converged = true
+			return
+		}
+		if res.Nit >= o.MaxIt {
+			return res, utl.NewIterError(utl.StopMaxIt, "AugLag", res.Nit, "iterations did not converge")
+		}
+		err = o.Monitor.Check("AugLag", res.Nit, kkt, ρ)
+		if err != nil {
+			return
+		}
+
+		// subproblem
+		sub.Gtol = max(ω, 0.1*o.Tol) * max(1, gf.Largest(1))
+		r, e := sub.Min(x) // the best point is taken if the subproblem fails after some progress
+		if r == nil || math.IsNaN(r.F) || (e != nil && r.Nit == 0) {
+			return res, chk.Err("subproblem failed at iteration %d (Tol may be too small):\n%v", res.Nit, e)
+		}
+		nsub = r.Nit
+		copy(x, r.X)
+		Ji, Je, err = eval()
+		if err != nil {
+			return
+		}
+
+		// infeasibility with current multipliers
+		viol := normInf(res.H)
+		for i := 0; i < mi; i++ {
+			viol = max(viol, math.Abs(max(res.G[i], -λ[i]/ρ)))
+		}
+
+		// update multipliers
+		for i := 0; i < mi; i++ {
+			λ[i] = max(0, λ[i]+ρ*res.G[i])
+		}
+		for j := 0; j < me; j++ {
+			μ[j] += ρ * res.H[j]
+		}
+		res.Nit++
+
+		// update penalty parameter and tolerance of subproblems
+		if viol > 0.25*vprev && viol > o.Tol {
+			ρ = min(ρ*o.RhoFactor, o.RhoMax)
+		}
+		vprev = viol
+		ω *= 0.1
+	}
+}
@@ -4,6 +4,14 @@
 
 package opt
 
+import (
+	"math"
+	"sync"
+)
+
+// machEps is the smallest number satisfying 1 + machEps > 1
+var machEps = math.Nextafter(1, 2) - 1.0
+
 func min(a, b float64) float64 {
 	if a < b {
 		return a
@@ -17,3 +25,43 @@ func max(a, b float64) float64 {
 	}
 	return b
 }
+
+// normInf returns the infinity norm |v|∞ (zero if v is empty)
+func normInf(v []float64) (res float64) {
+	for _, vi := range v {
+		res = max(res, math.Abs(vi))
+	}
+	return
+}
+
+// runConcurrently calls fcn(i) for i = 0...n-1 by means of nworkers goroutines (sequentially if
+// nworkers ≤ 1) and returns the error with the smallest index i
+func runConcurrently(nworkers, n int, fcn func(i int) error) (err error) {
+	errs := make([]error, n)
+	if nworkers > n {
+		nworkers = n
+	}
+	if nworkers <= 1 {
+		for i := 0; i < n; i++ {
+			errs[i] = fcn(i)
+		}
+	} else {
+		wg := new(sync.WaitGroup)
+		for w := 0; w < nworkers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				for i := w; i < n; i += nworkers {
+					errs[i] = fcn(i)
+				}
+				wg.Done()
+			}(w)
+		}
+		wg.Wait()
+	}
+	for _, e := range errs {
+		if e != nil {
+			return e
+		}
+	}
+	return
+}
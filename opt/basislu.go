@@ -0,0 +1,267 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+)
+
+// basisLU implements the sparse LU factorisation of a basis matrix B and product-form updates of
+// the factorisation after each change of basis:
+//
+//   P B Q = L U    and    B_new = B E   where E = I with column p replaced by α = B⁻¹ a_q
+//
+//  The pivots are selected by the Markowitz criterion; i.e. the minimum of (rᵢ-1)(cⱼ-1), where
+//  rᵢ and cⱼ are the numbers of non-zeros in row i and column j of the active submatrix, among
+//  the entries that satisfy |aᵢⱼ| ≥ u max_k |aₖⱼ| (threshold partial pivoting)
+type basisLU struct {
+	m    int         // dimension
+	prow []int       // [m] pivot row of each elimination step
+	pcol []int       // [m] pivot column (position in basis) of each elimination step
+	piv  []float64   // [m] pivots
+	lidx [][]int     // [m] rows of the multipliers of each step (columns of L)
+	lval [][]float64 // [m] multipliers of each step
+	uidx [][]int     // [m] positions of the other non-zero entries of each pivot row (rows of U)
+	uval [][]float64 // [m] values of the other non-zero entries of each pivot row
+	etas []basisEta  // product-form updates
+	rank int         // number of elimination steps performed by factor (m if B is not singular)
+	sing int         // position of (numerically) dependent column if factor failed
+}
+
+// basisEta holds the column α of one product-form update
+type basisEta struct {
+	p   int       // position in basis
+	ap  float64   // pivot α[p]
+	idx []int     // indices of other non-zero entries of α
+	val []float64 // values of other non-zero entries of α
+}
+
+// constants of basisLU
+const (
+	basisThresh  = 0.1   // threshold u of partial pivoting
+	basisZeroPiv = 1e-11 // columns of the active submatrix with all |aᵢⱼ| below this are dependent
+)
+
+// factor computes the LU factorisation
+//  Input:
+//   m -- dimension
+//   B -- basis matrix [m][m]; column p corresponds to position p in basis
+//  Note: if B is singular, the returned error is not nil and o.sing is the position of a
+//        dependent column; then, rows o.prow[o.rank:] have not been used as pivots
+func (o *basisLU) factor(m int, B *la.Triplet) (err error) {
+
+	// active submatrix: columns with values and rows with the pattern
+	colIdx, colVal, rowCols := make([][]int, m), make([][]float64, m), make([][]int, m)
+	if B.Len() > 0 {
+		_, _, ap, ai, ax := B.ToMatrix(nil).Get()
+		for j := 0; j < m; j++ {
+			for l := ap[j]; l < ap[j+1]; l++ {
+				colIdx[j] = append(colIdx[j], ai[l])
+				colVal[j] = append(colVal[j], ax[l])
+				rowCols[ai[l]] = append(rowCols[ai[l]], j)
+			}
+		}
+	}
+
+	// allocate
+	if o.m != m || o.prow == nil {
+		o.m = m
+		o.prow, o.pcol, o.piv = make([]int, m), make([]int, m), make([]float64, m)
+		o.lidx, o.lval = make([][]int, m), make([][]float64, m)
+		o.uidx, o.uval = make([][]int, m), make([][]float64, m)
+	}
+	o.etas = o.etas[:0]
+	o.rank = 0
+
+	// elimination
+	rowDone, colDone := make([]bool, m), make([]bool, m)
+	where := make([]int, m) // index of row in the column being updated or -1
+	for i := 0; i < m; i++ {
+		where[i] = -1
+	}
+	for k := 0; k < m; k++ {
+
+		// select pivot (Markowitz with threshold). Dependent columns are skipped
+		r, c, best, dep := -1, -1, math.MaxInt64, -1
+		for j := 0; j < m && best > 0; j++ {
+			if colDone[j] {
+				continue
+			}
+			big := 0.0
+			for _, v := range colVal[j] {
+				big = max(big, math.Abs(v))
+			}
+			if big < basisZeroPiv {
+				if dep < 0 {
+					dep = j
+				}
+				continue
+			}
+			cj := len(colIdx[j]) - 1
+			for l, i := range colIdx[j] {
+				if math.Abs(colVal[j][l]) < basisThresh*big {
+					continue
+				}
+				if cost := (len(rowCols[i]) - 1) * cj; cost < best {
+					r, c, best = i, j, cost
+				}
+			}
+		}
+
+		// singular: all columns of the active submatrix are (numerically) zero
+		if r < 0 {
+			o.sing = dep
+			l := k
+			for i := 0; i < m; i++ {
+				if !rowDone[i] {
+					o.prow[l] = i
+					l++
+				}
+			}
+			return chk.Err("basis matrix is singular (column %d)", dep)
+		}
+		o.prow[k], o.pcol[k] = r, c
+		o.rank = k + 1
+
+		// multipliers (column of L)
+		o.lidx[k], o.lval[k] = o.lidx[k][:0], o.lval[k][:0]
+		for l, i := range colIdx[c] {
+			if i == r {
+				o.piv[k] = colVal[c][l]
+			}
+		}
+		for l, i := range colIdx[c] {
+			if i != r {
+				o.lidx[k] = append(o.lidx[k], i)
+				o.lval[k] = append(o.lval[k], colVal[c][l]/o.piv[k])
+				rowCols[i] = basisRemove(rowCols[i], c)
+			}
+		}
+		rowDone[r], colDone[c] = true, true
+
+		// pivot row (row of U) and update of the active submatrix
+		o.uidx[k], o.uval[k] = o.uidx[k][:0], o.uval[k][:0]
+		for _, j := range rowCols[r] {
+			if j == c {
+				continue
+			}
+			for l, i := range colIdx[j] {
+				where[i] = l
+			}
+			lr := where[r]
+			urj := colVal[j][lr]
+			o.uidx[k] = append(o.uidx[k], j)
+			o.uval[k] = append(o.uval[k], urj)
+			for l, i := range o.lidx[k] {
+				if where[i] < 0 { // fill-in
+					where[i] = len(colIdx[j])
+					colIdx[j] = append(colIdx[j], i)
+					colVal[j] = append(colVal[j], 0)
+					rowCols[i] = append(rowCols[i], j)
+				}
+				colVal[j][where[i]] -= o.lval[k][l] * urj
+			}
+			for _, i := range colIdx[j] {
+				where[i] = -1
+			}
+			last := len(colIdx[j]) - 1
+			colIdx[j][lr], colVal[j][lr] = colIdx[j][last], colVal[j][last]
+			colIdx[j], colVal[j] = colIdx[j][:last], colVal[j][:last]
+		}
+		colIdx[c], colVal[c], rowCols[r] = nil, nil, nil
+	}
+	return
+}
+
+// basisRemove removes item from list (the order is not kept)
+func basisRemove(list []int, item int) []int {
+	for l, v := range list {
+		if v == item {
+			list[l] = list[len(list)-1]
+			return list[:len(list)-1]
+		}
+	}
+	return list
+}
+
+// ftran solves B x = b; i.e. x := B⁻¹ b (b is replaced by x)
+func (o *basisLU) ftran(b []float64) {
+	m := o.m
+	for k := 0; k < m; k++ {
+		br := b[o.prow[k]]
+		if br == 0 {
+			continue
+		}
+		for l, i := range o.lidx[k] {
+			b[i] -= o.lval[k][l] * br
+		}
+	}
+	x := make([]float64, m)
+	for k := m - 1; k >= 0; k-- {
+		s := b[o.prow[k]]
+		for l, j := range o.uidx[k] {
+			s -= o.uval[k][l] * x[j]
+		}
+		x[o.pcol[k]] = s / o.piv[k]
+	}
+	copy(b, x)
+	for _, e := range o.etas {
+		xp := b[e.p] / e.ap
+		b[e.p] = xp
+		for k, i := range e.idx {
+			b[i] -= e.val[k] * xp
+		}
+	}
+}
+
+// btran solves Bᵀ y = c; i.e. y := B⁻ᵀ c (c is replaced by y)
+func (o *basisLU) btran(c []float64) {
+	for k := len(o.etas) - 1; k >= 0; k-- {
+		e := o.etas[k]
+		s := c[e.p]
+		for l, i := range e.idx {
+			s -= e.val[l] * c[i]
+		}
+		c[e.p] = s / e.ap
+	}
+	m := o.m
+	w := make([]float64, m)
+	for k := 0; k < m; k++ {
+		wr := c[o.pcol[k]] / o.piv[k]
+		w[o.prow[k]] = wr
+		if wr == 0 {
+			continue
+		}
+		for l, j := range o.uidx[k] {
+			c[j] -= o.uval[k][l] * wr
+		}
+	}
+	for k := m - 1; k >= 0; k-- {
+		s := 0.0
+		for l, i := range o.lidx[k] {
+			s += o.lval[k][l] * w[i]
+		}
+		w[o.prow[k]] -= s
+	}
+	copy(c, w)
+}
+
+// update adds a product-form update after column p of B has been replaced by a_q
+//  Input:
+//   p     -- position in basis
+//   alpha -- B⁻¹ a_q computed with the basis before the update
+func (o *basisLU) update(p int, alpha []float64) {
+	e := basisEta{p: p, ap: alpha[p]}
+	for i, v := range alpha {
+		if i != p && v != 0 {
+			e.idx = append(e.idx, i)
+			e.val = append(e.val, v)
+		}
+	}
+	o.etas = append(o.etas, e)
+}
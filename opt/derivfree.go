@@ -0,0 +1,906 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/num"
+	"github.com/cpmech/gosl/utl"
+)
+
+// nelderMead implements the Nelder–Mead simplex method with adaptive parameters
+//  Reference:
+//   Gao F and Han L (2012) Implementing the Nelder-Mead simplex algorithm with adaptive
+//   parameters. Computational Optimization and Applications, 51:259-277
+func (o *Minimizer) nelderMead() (err error) {
+
+	// parameters: reflection, expansion, contraction and shrink. The standard values are used for
+	// n < 2 since the adaptive ones would give σ = 0 (see Gao and Han (2012))
+	n, res := o.ndim, o.res
+	nn := float64(n)
+	ρ, χ, γ, σ := 1.0, 2.0, 0.5, 0.5
+	if n >= 2 {
+		χ, γ, σ = 1+2/nn, 0.75-1/(2*nn), 1-1/nn
+	}
+
+	// initial simplex
+	xs := make([]la.Vector, n+1)
+	fs := make([]float64, n+1)
+	o.clip(res.X)
+	h := o.step0(res.X)
+	for k := 0; k <= n; k++ {
+		xs[k] = res.X.GetCopy()
+		if k > 0 {
+			i := k - 1
+			if o.Upper != nil && xs[k][i]+h > o.Upper[i] {
+				xs[k][i] -= h
+			} else {
+				xs[k][i] += h
+			}
+			o.clip(xs[k])
+		}
+		fs[k], err = o.fcn(xs[k])
+		if err != nil {
+			return
+		}
+	}
+
+	// auxiliary
+	xc := la.NewVector(n) // centroid
+	xr := la.NewVector(n) // reflected point
+	xe := la.NewVector(n) // expanded or contracted point
+	order := make([]int, n+1)
+	for k := range order {
+		order[k] = k
+	}
+
+	// iterations
+	for {
+
+		// sort vertices (stable: reproducible)
+		for k := 1; k <= n; k++ {
+			for l := k; l > 0 && fs[order[l]] < fs[order[l-1]]; l-- {
+				order[l], order[l-1] = order[l-1], order[l]
+			}
+		}
+		b, w := order[0], order[n]
+		copy(res.X, xs[b])
+		res.F = fs[b]
+		o.record()
+
+		// check convergence
+		size := 0.0
+		for k := 0; k <= n; k++ {
+			for i := 0; i < n; i++ {
+				size = max(size, math.Abs(xs[k][i]-xs[b][i]))
+			}
+		}
+		if size <= o.Xtol*max(1, res.X.Largest(1)) {
+			res.Converged = true
+			return
+		}
+		err = o.checkBudget(size)
+		if err != nil {
+			return
+		}
+
+		// centroid of all vertices but the worst
+		xc.Fill(0)
+		for _, k := range order[:n] {
+			la.VecAdd(xc, 1, xc, 1/nn, xs[k])
+		}
+
+		// reflection
+		la.VecAdd(xr, 1+ρ, xc, -ρ, xs[w])
+		o.clip(xr)
+		fr, e := o.fcn(xr)
+		if e != nil {
+			return e
+		}
+		shrink := false
+		switch {
+
+		// expansion
+		case fr < fs[b]:
+			la.VecAdd(xe, 1+ρ*χ, xc, -ρ*χ, xs[w])
+			o.clip(xe)
+			fe, e := o.fcn(xe)
+			if e != nil {
+				return e
+			}
+			if fe < fr {
+				copy(xs[w], xe)
+				fs[w] = fe
+			} else {
+				copy(xs[w], xr)
+				fs[w] = fr
+			}
+
+		// accept reflection
+		case fr < fs[order[n-1]]:
+			copy(xs[w], xr)
+			fs[w] = fr
+
+		// outside contraction
+		case fr < fs[w]:
+			la.VecAdd(xe, 1+ρ*γ, xc, -ρ*γ, xs[w])
+			o.clip(xe)
+			fe, e := o.fcn(xe)
+			if e != nil {
+				return e
+			}
+			if fe <= fr {
+				copy(xs[w], xe)
+				fs[w] = fe
+			} else {
+				shrink = true
+			}
+
+		// inside contraction
+		default:
+			la.VecAdd(xe, 1-γ, xc, γ, xs[w])
+			fe, e := o.fcn(xe)
+			if e != nil {
+				return e
+			}
+			if fe < fs[w] {
+				copy(xs[w], xe)
+				fs[w] = fe
+			} else {
+				shrink = true
+			}
+		}
+
+		// shrink towards best vertex
+		if shrink {
+			for _, k := range order[1:] {
+				la.VecAdd(xs[k], σ, xs[k], 1-σ, xs[b])
+				fs[k], err = o.fcn(xs[k])
+				if err != nil {
+					return
+				}
+			}
+		}
+		res.Nit++
+	}
+}
+
+// powell implements Powell's method of conjugate directions with line minimisations by Brent's
+// method restricted to the box bounds
+//  Reference:
+//   Press WH, Teukolsky SA, Vetterling WT and Flannery BP (2007) Numerical Recipes: The Art of
+//   Scientific Computing. 3rd Edition. Cambridge University Press. Section 10.7
+func (o *Minimizer) powell() (err error) {
+
+	// initial point and directions
+	n, res := o.ndim, o.res
+	x := res.X
+	o.clip(x)
+	res.F, err = o.fcn(x)
+	if err != nil {
+		return
+	}
+	o.record()
+	dirs := make([]la.Vector, n)
+	for i := 0; i < n; i++ {
+		dirs[i] = la.NewVector(n)
+		dirs[i][i] = 1
+	}
+	h := o.step0(x)
+	xold, xe, dnew := la.NewVector(n), la.NewVector(n), la.NewVector(n)
+
+	// iterations
+	for {
+
+		// minimise along each direction
+		fold := res.F
+		copy(xold, x)
+		ibig, big := 0, 0.0
+		for i, u := range dirs {
+			fprev := res.F
+			res.F, err = o.lineMin(x, u, res.F, h)
+			if err != nil {
+				return
+			}
+			if fprev-res.F > big {
+				ibig, big = i, fprev-res.F
+			}
+		}
+		res.Nit++
+		o.record()
+
+		// check convergence
+		la.VecAdd(dnew, 1, x, -1, xold)
+		size := dnew.Largest(1)
+		if size <= o.Xtol*max(1, x.Largest(1)) {
+			res.Converged = true
+			return
+		}
+		err = o.checkBudget(size)
+		if err != nil {
+			return
+		}
+		h = max(dnew.Norm(), o.Xtol)
+
+		// replace direction of largest decrease by the average direction
+		la.VecAdd(xe, 2, x, -1, xold)
+		o.clip(xe)
+		fe, e := o.fcn(xe)
+		if e != nil {
+			return e
+		}
+		if fe < fold {
+			a, b := fold-res.F-big, fold-fe
+			if 2*(fold-2*res.F+fe)*a*a < big*b*b {
+				dnew.Apply(1/dnew.Norm(), dnew)
+				res.F, err = o.lineMin(x, dnew, res.F, h)
+				if err != nil {
+					return
+				}
+				dirs[ibig] = dirs[n-1]
+				dirs[n-1] = dnew.GetCopy()
+			}
+		}
+	}
+}
+
+// lineMin minimises f(x + t u) for t within the box bounds; x is updated and f(x) is returned
+//  Input:
+//   f -- f(x) at the initial x
+//   h -- initial step for bracketing
+func (o *Minimizer) lineMin(x, u la.Vector, f, h float64) (fmin float64, err error) {
+
+	// limits of t
+	tlo, thi := math.Inf(-1), math.Inf(1)
+	for i := 0; i < o.ndim; i++ {
+		if u[i] == 0 {
+			continue
+		}
+		for _, bnd := range o.bounds(i) {
+			if !math.IsInf(bnd, 0) {
+				t := (bnd - x[i]) / u[i]
+				if t > 0 {
+					thi = min(thi, t)
+				} else {
+					tlo = max(tlo, t)
+				}
+			}
+		}
+	}
+
+	// φ(t) = f(x + t u) keeping the best point
+	x0 := x.GetCopy()
+	xt := la.NewVector(o.ndim)
+	tbest, fmin := 0.0, f
+	var ferr error // error from f (not wrapped by Brent)
+	φ := func(t float64) (ft float64, e error) {
+		la.VecAdd(xt, 1, x0, t, u)
+		o.clip(xt)
+		ft, e = o.fcn(xt)
+		if e != nil {
+			ferr = e
+			return
+		}
+		if ft < fmin {
+			tbest, fmin = t, ft
+		}
+		return
+	}
+
+	// bracket minimum
+	a, c := max(-h, tlo), min(h, thi)
+	if c > 0 {
+		fc, e := φ(c)
+		if e != nil {
+			return o.lineSet(x, x0, u, tbest, fmin, e)
+		}
+		if fc < f { // downhill towards +t: expand
+			a = 0
+			for c < thi {
+				cnew := min(c+1.618034*(c-a), thi)
+				fnew, e := φ(cnew)
+				if e != nil {
+					return o.lineSet(x, x0, u, tbest, fmin, e)
+				}
+				a, c = c, cnew
+				if fnew > fc {
+					break
+				}
+				fc = fnew
+			}
+			a = max(a-(c-a), 0)
+		}
+	}
+	if a < 0 && tbest == 0 {
+		fa, e := φ(a)
+		if e != nil {
+			return o.lineSet(x, x0, u, tbest, fmin, e)
+		}
+		if fa < f { // downhill towards -t: expand
+			c = 0
+			for a > tlo {
+				anew := max(a-1.618034*(c-a), tlo)
+				fnew, e := φ(anew)
+				if e != nil {
+					return o.lineSet(x, x0, u, tbest, fmin, e)
+				}
+				c, a = a, anew
+				if fnew > fa {
+					break
+				}
+				fa = fnew
+			}
+			c = min(c+(c-a), 0)
+		}
+	}
+
+	// Brent's method
+	if c > a {
+		var brent num.Brent
+		brent.Init(φ)
+		brent.MaxIt = 100
+		brent.Tol = o.Xtol * 1e-2
+		_, err = brent.Min(a, c, true)
+		if ferr != nil {
+			err = ferr
+		}
+		if err != nil && (ferr != nil || !utl.IsMaxIt(err)) {
+			return o.lineSet(x, x0, u, tbest, fmin, err)
+		}
+	}
+	return o.lineSet(x, x0, u, tbest, fmin, nil)
+}
+
+// lineSet sets x = x0 + t u within the box bounds and returns f(x)
+func (o *Minimizer) lineSet(x, x0, u la.Vector, t, f float64, err error) (float64, error) {
+	la.VecAdd(x, 1, x0, t, u)
+	o.clip(x)
+	return f, err
+}
+
+// bobyqa implements a trust-region method with quadratic models of f defined by interpolation
+// points and the minimum Frobenius norm of the change of the Hessian (as in BOBYQA)
+//  Reference:
+//   Powell MJD (2009) The BOBYQA algorithm for bound constrained optimization without
+//   derivatives. Technical Report DAMTP 2009/NA06, University of Cambridge
+//  NOTE: this is a simplified version of BOBYQA: the model is computed by solving the KKT
+//        system of the minimum Frobenius norm problem directly, the trust-region subproblem
+//        is solved by truncated conjugate gradients with active bounds and geometry steps
+//        maximise the Lagrange function of the replaced point over a few directions
+func (o *Minimizer) bobyqa() (err error) {
+
+	// radii
+	n, res := o.ndim, o.res
+	o.clip(res.X)
+	ρ := o.step0(res.X)
+	if o.Lower != nil && o.Upper != nil {
+		for i := 0; i < n; i++ {
+			if o.Upper[i]-o.Lower[i] < 2*ρ {
+				ρ = 0.5 * (o.Upper[i] - o.Lower[i])
+			}
+		}
+		if ρ <= 0 {
+			return chk.Err("upper bounds must be greater than lower bounds")
+		}
+	}
+	ρend := min(o.Xtol*max(1, res.X.Largest(1)), ρ)
+	Δ := ρ
+
+	// interpolation points
+	m := o.Npts
+	if m == 0 {
+		m = 2*n + 1
+	}
+	if m < n+2 || m > (n+1)*(n+2)/2 {
+		return chk.Err("number of interpolation points must be in [%d, %d]. %d is invalid", n+2, (n+1)*(n+2)/2, m)
+	}
+	md := &bobyqaModel{n: n, m: m, g: la.NewVector(n), H: la.NewMatrix(n, n)}
+	md.y = make([]la.Vector, m)
+	md.fy = make([]float64, m)
+	signs := make([]float64, n)
+	for k := 0; k < m; k++ {
+		y := res.X.GetCopy()
+		switch {
+		case k == 0:
+		case k <= n: // x0 ± ρ eᵢ
+			i := k - 1
+			signs[i] = 1
+			if o.Upper != nil && y[i]+ρ > o.Upper[i] {
+				signs[i] = -1
+			}
+			y[i] += signs[i] * ρ
+		case k <= 2*n: // opposite side or twice the step
+			i := k - n - 1
+			s := -signs[i]
+			if o.Lower != nil && y[i]+s*ρ < o.Lower[i] || o.Upper != nil && y[i]+s*ρ > o.Upper[i] {
+				s = 2 * signs[i]
+			}
+			y[i] += s * ρ
+		default: // x0 + ρ (±eᵢ ± eⱼ)
+			p := k - 2*n - 1
+			i, j := 0, 1
+			for l := 0; l < p; l++ {
+				if j++; j == n {
+					i++
+					j = i + 1
+				}
+			}
+			y[i] += signs[i] * ρ
+			y[j] += signs[j] * ρ
+		}
+		o.clip(y)
+		md.y[k] = y
+		md.fy[k], err = o.fcn(y)
+		if err != nil {
+			return
+		}
+		if md.fy[k] < md.fy[md.kb] {
+			md.kb = k
+		}
+	}
+	md.c = md.fy[md.kb]
+	err = md.update(ρ)
+	if err != nil {
+		return
+	}
+
+	// auxiliary
+	lo, hi := la.NewVector(n), la.NewVector(n)
+	xnew := la.NewVector(n)
+
+	// iterations
+	for {
+
+		// results
+		xb := md.y[md.kb]
+		copy(res.X, xb)
+		res.F = md.fy[md.kb]
+		o.record()
+
+		// check convergence
+		if ρ <= ρend {
+			res.Converged = true
+			return
+		}
+		err = o.checkBudget(ρ)
+		if err != nil {
+			return
+		}
+		res.Nit++
+
+		// trust-region step
+		for i := 0; i < n; i++ {
+			lo[i], hi[i] = math.Inf(-1), math.Inf(1)
+			if o.Lower != nil {
+				lo[i] = o.Lower[i] - xb[i]
+			}
+			if o.Upper != nil {
+				hi[i] = o.Upper[i] - xb[i]
+			}
+		}
+		s := md.trsbox(Δ, lo, hi)
+		snorm := s.Norm()
+
+		// short step: improve geometry or reduce ρ
+		if snorm < 0.5*ρ {
+			improved, e := o.bobyqaGeometry(md, 2*ρ, ρ, lo, hi)
+			if e != nil {
+				return e
+			}
+			if !improved {
+				ρ, Δ = o.bobyqaReduce(ρ, ρend)
+				err = md.update(ρ)
+				if err != nil {
+					return
+				}
+			}
+			continue
+		}
+
+		// evaluate trial point
+		la.VecAdd(xnew, 1, xb, 1, s)
+		o.clip(xnew)
+		fnew, e := o.fcn(xnew)
+		if e != nil {
+			return e
+		}
+		pred := -md.eval(s) + md.c
+		ratio := -1.0
+		if pred > 0 {
+			ratio = (md.fy[md.kb] - fnew) / pred
+		}
+
+		// update trust region radius
+		switch {
+		case ratio <= 0.1:
+			Δ = min(0.5*Δ, snorm)
+		case ratio <= 0.7:
+			Δ = max(0.5*Δ, snorm)
+		default:
+			Δ = max(0.5*Δ, 2*snorm)
+		}
+		if Δ <= 1.5*ρ {
+			Δ = ρ
+		}
+
+		// replace point with largest weighted Lagrange function
+		t := md.replacement(s, Δ, fnew < md.fy[md.kb])
+		if t >= 0 {
+			md.set(t, xnew, fnew)
+			err = md.update(ρ)
+			if err != nil {
+				return
+			}
+		}
+
+		// poor step: improve geometry or reduce ρ
+		if ratio < 0.1 {
+			improved, e := o.bobyqaGeometry(md, 2*Δ, Δ, lo, hi)
+			if e != nil {
+				return e
+			}
+			if !improved && Δ <= ρ && ratio <= 0 {
+				ρ, Δ = o.bobyqaReduce(ρ, ρend)
+				err = md.update(ρ)
+				if err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// bobyqaReduce reduces the lower bound ρ of the trust-region radius
+func (o *Minimizer) bobyqaReduce(ρ, ρend float64) (ρnew, Δ float64) {
+	ρnew = ρend
+	switch r := ρ / ρend; {
+	case r > 250:
+		ρnew = 0.1 * ρ
+	case r > 16:
+		ρnew = math.Sqrt(ρ * ρend)
+	}
+	return ρnew, max(0.5*ρ, ρnew)
+}
+
+// bobyqaGeometry replaces the interpolation point that is farthest from the best point, if its
+// distance is greater than dist, by a point within radius Δ that maximises its Lagrange function
+func (o *Minimizer) bobyqaGeometry(md *bobyqaModel, dist, Δ float64, lo, hi la.Vector) (improved bool, err error) {
+
+	// farthest point
+	xb := md.y[md.kb].GetCopy()
+	t, dmax := -1, dist
+	for k, y := range md.y {
+		if d := y.NormDiff(xb); d > dmax {
+			t, dmax = k, d
+		}
+	}
+	if t < 0 {
+		return
+	}
+
+	// candidate steps: ±Δ eᵢ and ±Δ (y_t - xb) / |y_t - xb|
+	n := o.ndim
+	lag := md.lagrange(t)
+	s, best := la.NewVector(n), la.NewVector(n)
+	lbest := -1.0
+	try := func() {
+		for i := 0; i < n; i++ {
+			s[i] = math.Max(lo[i], math.Min(hi[i], s[i]))
+		}
+		if s.Norm() < 1e-3*Δ {
+			return
+		}
+		if l := math.Abs(lag(s)); l > lbest {
+			lbest = l
+			copy(best, s)
+		}
+	}
+	for _, sgn := range []float64{1, -1} {
+		for i := 0; i < n; i++ {
+			s.Fill(0)
+			s[i] = sgn * Δ
+			try()
+		}
+		la.VecAdd(s, sgn*Δ/dmax, md.y[t], -sgn*Δ/dmax, xb)
+		try()
+	}
+	if lbest < 0 {
+		return
+	}
+
+	// evaluate and replace
+	la.VecAdd(s, 1, xb, 1, best)
+	o.clip(s)
+	f, err := o.fcn(s)
+	if err != nil {
+		return
+	}
+	md.set(t, s, f)
+	return true, md.update(Δ)
+}
+
+// bobyqaModel holds the quadratic model q(xb + s) = c + gᵀs + ½ sᵀH s and interpolation points
+type bobyqaModel struct {
+	n, m int         // dimension and number of points
+	y    []la.Vector // [m] interpolation points
+	fy   []float64   // [m] f(y)
+	kb   int         // index of best point (xb)
+	c    float64     // model value at xb
+	g    la.Vector   // model gradient at xb
+	H    *la.Matrix  // model Hessian
+	ρ    float64     // scale used in KKT system
+	lu   basisLU     // factorisation of KKT system
+}
+
+// eval computes q(xb + s)
+func (o *bobyqaModel) eval(s la.Vector) (q float64) {
+	q = o.c + la.VecDot(o.g, s)
+	for i := 0; i < o.n; i++ {
+		for j := 0; j < o.n; j++ {
+			q += 0.5 * s[i] * o.H.Get(i, j) * s[j]
+		}
+	}
+	return
+}
+
+// set replaces point t and shifts the model if it is the new best point
+func (o *bobyqaModel) set(t int, x la.Vector, f float64) {
+	if f < o.fy[o.kb] {
+		s := la.NewVector(o.n)
+		la.VecAdd(s, 1, x, -1, o.y[o.kb])
+		o.c = o.eval(s)
+		for i := 0; i < o.n; i++ {
+			for j := 0; j < o.n; j++ {
+				o.g[i] += o.H.Get(i, j) * s[j]
+			}
+		}
+		o.kb = t
+	}
+	o.y[t] = x.GetCopy()
+	o.fy[t] = f
+}
+
+// update updates the model such that it interpolates all points with the least change of H
+// (Frobenius norm); i.e. solves the KKT system (with scaled steps ŝ = (y - xb)/ρ):
+//
+//   [ A  Xᵀ ] [ λ  ]   [ r ]     A_jk = ½ (ŝⱼᵀŝₖ)²,   X = [1; ŝ₁ ... ŝₘ],   rⱼ = f(yⱼ) - q(yⱼ)
+//   [ X  0  ] [ δc ] = [ 0 ]
+//             [ δĝ ]
+//
+//  then: c += δc, g += δĝ/ρ and H += Σ λⱼ ŝⱼŝⱼᵀ / ρ²
+func (o *bobyqaModel) update(ρ float64) (err error) {
+	n, m := o.n, o.m
+	o.ρ = ρ
+	ŝ := o.scaled()
+	var K la.Triplet
+	K.Init(m+n+1, m+n+1, m*m+2*m*(n+1))
+	for p := 0; p < m; p++ {
+		for k := 0; k < m; k++ {
+			d := la.VecDot(ŝ[k], ŝ[p])
+			K.Put(k, p, 0.5*d*d)
+		}
+		K.Put(m, p, 1)
+		K.Put(p, m, 1)
+		for i := 0; i < n; i++ {
+			K.Put(m+1+i, p, ŝ[p][i])
+			K.Put(p, m+1+i, ŝ[p][i])
+		}
+	}
+	err = o.lu.factor(m+n+1, &K)
+	if err != nil {
+		return chk.Err("interpolation points are degenerate:\n%v", err)
+	}
+	z := make([]float64, m+n+1)
+	s := la.NewVector(n)
+	for k := 0; k < m; k++ {
+		la.VecAdd(s, 1, o.y[k], -1, o.y[o.kb])
+		z[k] = o.fy[k] - o.eval(s)
+	}
+	o.lu.ftran(z)
+	o.c += z[m]
+	for i := 0; i < n; i++ {
+		o.g[i] += z[m+1+i] / ρ
+	}
+	for k := 0; k < m; k++ {
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				o.H.Add(i, j, z[k]*ŝ[k][i]*ŝ[k][j]/(ρ*ρ))
+			}
+		}
+	}
+	return
+}
+
+// scaled returns the scaled steps ŝ = (y - xb)/ρ
+func (o *bobyqaModel) scaled() (ŝ []la.Vector) {
+	ŝ = make([]la.Vector, o.m)
+	for k := 0; k < o.m; k++ {
+		ŝ[k] = la.NewVector(o.n)
+		la.VecAdd(ŝ[k], 1/o.ρ, o.y[k], -1/o.ρ, o.y[o.kb])
+	}
+	return
+}
+
+// lagrange returns the Lagrange function of point t; i.e. ℓₜ(xb + s) with ℓₜ(yₖ) = δₜₖ
+func (o *bobyqaModel) lagrange(t int) func(s la.Vector) float64 {
+	n, m := o.n, o.m
+	z := make([]float64, m+n+1)
+	z[t] = 1
+	o.lu.ftran(z)
+	ŝ := o.scaled()
+	return func(s la.Vector) (l float64) {
+		l = z[m]
+		for i := 0; i < n; i++ {
+			l += z[m+1+i] * s[i] / o.ρ
+		}
+		for k := 0; k < m; k++ {
+			d := la.VecDot(ŝ[k], s) / o.ρ
+			l += 0.5 * z[k] * d * d
+		}
+		return
+	}
+}
+
+// replacement selects the point to be replaced by xb + s: the one with the largest value of
+// |ℓₜ(xb + s)| weighted by its distance to xb; returns -1 if no point should be replaced
+func (o *bobyqaModel) replacement(s la.Vector, Δ float64, better bool) (t int) {
+	t = -1
+	best := 0.0
+	for k := 0; k < o.m; k++ {
+		if k == o.kb && !better {
+			continue
+		}
+		d := o.y[k].NormDiff(o.y[o.kb]) / Δ
+		w := math.Abs(o.lagrange(k)(s)) * max(1, d*d*d*d)
+		if w > best {
+			t, best = k, w
+		}
+	}
+	return
+}
+
+// trsbox computes a step s that approximately minimises the model subject to |s| ≤ Δ and
+// lo ≤ s ≤ hi by means of truncated conjugate gradients, where the variables that reach their
+// bounds are fixed and the iterations restart
+func (o *bobyqaModel) trsbox(Δ float64, lo, hi la.Vector) (s la.Vector) {
+	n := o.n
+	s = la.NewVector(n)
+	fixed := make([]bool, n)
+	for i := 0; i < n; i++ {
+		fixed[i] = (lo[i] >= 0 && o.g[i] > 0) || (hi[i] <= 0 && o.g[i] < 0)
+	}
+	r, d, Hd := la.NewVector(n), la.NewVector(n), la.NewVector(n)
+	tol := 1e-10 * max(o.g.Norm(), 1e-300)
+	for outer := 0; outer <= n; outer++ {
+
+		// gradient of model at s
+		for i := 0; i < n; i++ {
+			r[i] = 0
+			if !fixed[i] {
+				r[i] = o.g[i]
+				for j := 0; j < n; j++ {
+					r[i] += o.H.Get(i, j) * s[j]
+				}
+			}
+		}
+		rr := la.VecDot(r, r)
+		if math.Sqrt(rr) <= tol {
+			return
+		}
+		d.Apply(-1, r)
+
+		// conjugate gradients
+		hitBound := false
+		for it := 0; it < n; it++ {
+			for i := 0; i < n; i++ {
+				Hd[i] = 0
+				if !fixed[i] {
+					for j := 0; j < n; j++ {
+						Hd[i] += o.H.Get(i, j) * d[j]
+					}
+				}
+			}
+			dHd := la.VecDot(d, Hd)
+
+			// step to trust-region boundary, to bounds and to minimum along d
+			ss, sd, dd := la.VecDot(s, s), la.VecDot(s, d), la.VecDot(d, d)
+			if dd == 0 {
+				return
+			}
+			τ := (-sd + math.Sqrt(max(sd*sd+dd*(Δ*Δ-ss), 0))) / dd
+			kind, ib := 0, -1
+			if dHd > 0 && rr/dHd < τ {
+				τ, kind = rr/dHd, 1
+			}
+			for i := 0; i < n; i++ {
+				if fixed[i] || d[i] == 0 {
+					continue
+				}
+				tb := (hi[i] - s[i]) / d[i]
+				if d[i] < 0 {
+					tb = (lo[i] - s[i]) / d[i]
+				}
+				if tb < τ {
+					τ, kind, ib = max(tb, 0), 2, i
+				}
+			}
+
+			// update
+			la.VecAdd(s, 1, s, τ, d)
+			la.VecAdd(r, 1, r, τ, Hd)
+			if kind == 0 {
+				return
+			}
+			if kind == 2 {
+				fixed[ib] = true
+				if d[ib] > 0 {
+					s[ib] = hi[ib]
+				} else {
+					s[ib] = lo[ib]
+				}
+				hitBound = true
+				break
+			}
+			rrNew := la.VecDot(r, r)
+			if math.Sqrt(rrNew) <= tol {
+				return
+			}
+			la.VecAdd(d, -1, r, rrNew/rr, d)
+			rr = rrNew
+		}
+		if !hitBound {
+			return
+		}
+	}
+	return
+}
+
+// step0 returns the initial step (simplex size or trust-region radius) for derivative-free methods
+func (o *Minimizer) step0(x la.Vector) float64 {
+	if o.Step0 > 0 {
+		return o.Step0
+	}
+	return 0.1 * max(1, x.Largest(1))
+}
+
+// bounds returns the lower and upper bounds of variable i (±∞ if not given)
+func (o *Minimizer) bounds(i int) []float64 {
+	b := []float64{math.Inf(-1), math.Inf(1)}
+	if o.Lower != nil {
+		b[0] = o.Lower[i]
+	}
+	if o.Upper != nil {
+		b[1] = o.Upper[i]
+	}
+	return b
+}
+
+// clip moves x into the box bounds
+func (o *Minimizer) clip(x la.Vector) {
+	for i := 0; i < o.ndim; i++ {
+		if o.Lower != nil && x[i] < o.Lower[i] {
+			x[i] = o.Lower[i]
+		}
+		if o.Upper != nil && x[i] > o.Upper[i] {
+			x[i] = o.Upper[i]
+		}
+	}
+}
+
+// checkBudget checks the limit of iterations and the cancellation
+func (o *Minimizer) checkBudget(size float64) (err error) {
+	res := o.res
+	if o.Verbose {
+		io.Pf("%6d%8d%23.15e%14.6e\n", res.Nit, res.Nfeval, res.F, size)
+	}
+	if res.Nit >= o.MaxIt {
+		return utl.NewIterError(utl.StopMaxIt, "Minimizer", res.Nit, "iterations did not converge")
+	}
+	return o.Monitor.Check("Minimizer", res.Nit, res.F, size)
+}
@@ -0,0 +1,362 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"math"
+	"sort"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+// diffEvol implements the differential evolution method
+//  NOTE: components of mutants outside the box are placed halfway between the parent and the bound
+//  Reference:
+//   Storn R and Price K (1997) Differential evolution – a simple and efficient heuristic for
+//   global optimization over continuous spaces. Journal of Global Optimization, 11:341-359
+func (o *GlobalMinimizer) diffEvol() (err error) {
+
+	// number of random individuals
+	var nr int
+	switch o.DeStrategy {
+	case DeRand1Bin:
+		nr = 3
+	case DeBest1Bin, DeCurrentToBest1Bin:
+		nr = 2
+	case DeRand2Bin:
+		nr = 5
+	case DeBest2Bin:
+		nr = 4
+	default:
+		return chk.Err("strategy of differential evolution %d is not available", o.DeStrategy)
+	}
+
+	// initial population
+	n := o.ndim
+	npop, err := o.npop(utl.Imax(10*n, 6), nr+1)
+	if err != nil {
+		return
+	}
+	X := latinRand(o.rng, npop, o.Lower, o.Upper)
+	f := make([]float64, npop)
+	err = o.evaluate(f, X)
+	if err != nil {
+		return
+	}
+
+	// trial population
+	U := make([]la.Vector, npop)
+	for i := 0; i < npop; i++ {
+		U[i] = la.NewVector(n)
+	}
+	fu := make([]float64, npop)
+	r := make([]int, nr)
+
+	// generations
+	F := o.DeF
+	for {
+
+		// check convergence
+		done, e := o.next(o.spread(X))
+		if done || e != nil {
+			return e
+		}
+
+		// best individual
+		ibest := 0
+		for i := 1; i < npop; i++ {
+			if f[i] < f[ibest] {
+				ibest = i
+			}
+		}
+		b := X[ibest]
+
+		// mutation and crossover
+		for i := 0; i < npop; i++ {
+			randOthers(o.rng, r, i, npop)
+			x, u := X[i], U[i]
+			jrand := randInt(o.rng, 0, n-1)
+			for j := 0; j < n; j++ {
+				if j != jrand && randFloat64(o.rng, 0, 1) >= o.DeCr {
+					u[j] = x[j]
+					continue
+				}
+				switch o.DeStrategy {
+				case DeRand1Bin:
+					u[j] = X[r[0]][j] + F*(X[r[1]][j]-X[r[2]][j])
+				case DeBest1Bin:
+					u[j] = b[j] + F*(X[r[0]][j]-X[r[1]][j])
+				case DeCurrentToBest1Bin:
+					u[j] = x[j] + F*(b[j]-x[j]) + F*(X[r[0]][j]-X[r[1]][j])
+				case DeRand2Bin:
+					u[j] = X[r[0]][j] + F*(X[r[1]][j]-X[r[2]][j]) + F*(X[r[3]][j]-X[r[4]][j])
+				case DeBest2Bin:
+					u[j] = b[j] + F*(X[r[0]][j]-X[r[1]][j]) + F*(X[r[2]][j]-X[r[3]][j])
+				}
+				if u[j] < o.Lower[j] {
+					u[j] = (x[j] + o.Lower[j]) / 2
+				}
+				if u[j] > o.Upper[j] {
+					u[j] = (x[j] + o.Upper[j]) / 2
+				}
+			}
+		}
+
+		// selection
+		err = o.evaluate(fu, U)
+		if err != nil {
+			return
+		}
+		for i := 0; i < npop; i++ {
+			if fu[i] <= f[i] {
+				X[i], U[i] = U[i], X[i]
+				f[i] = fu[i]
+			}
+		}
+	}
+}
+
+// pso implements the particle swarm optimisation method with inertia weight (global best topology)
+//  NOTE: particles leaving the box are placed on the bound and the corresponding velocity is zeroed
+//  Reference:
+//   Clerc M and Kennedy J (2002) The particle swarm - explosion, stability, and convergence in a
+//   multidimensional complex space. IEEE Transactions on Evolutionary Computation, 6(1):58-73
+func (o *GlobalMinimizer) pso() (err error) {
+
+	// initial swarm
+	n := o.ndim
+	npop, err := o.npop(40, 2)
+	if err != nil {
+		return
+	}
+	X := latinRand(o.rng, npop, o.Lower, o.Upper)
+	f := make([]float64, npop)
+	err = o.evaluate(f, X)
+	if err != nil {
+		return
+	}
+
+	// velocities and best positions of particles
+	vmax := la.NewVector(n)
+	for j := 0; j < n; j++ {
+		vmax[j] = o.PsoVmax * (o.Upper[j] - o.Lower[j])
+	}
+	V := make([]la.Vector, npop)
+	P := make([]la.Vector, npop)
+	fp := make([]float64, npop)
+	for i := 0; i < npop; i++ {
+		V[i] = la.NewVector(n)
+		for j := 0; j < n; j++ {
+			V[i][j] = randFloat64(o.rng, -vmax[j], vmax[j])
+		}
+		P[i] = X[i].GetCopy()
+		fp[i] = f[i]
+	}
+	g := o.res.X // best position of swarm
+
+	// generations
+	for {
+
+		// check convergence
+		done, e := o.next(o.spread(X))
+		if done || e != nil {
+			return e
+		}
+
+		// move particles
+		for i := 0; i < npop; i++ {
+			x, v, p := X[i], V[i], P[i]
+			for j := 0; j < n; j++ {
+				r1, r2 := randFloat64(o.rng, 0, 1), randFloat64(o.rng, 0, 1)
+				v[j] = o.PsoW*v[j] + o.PsoC1*r1*(p[j]-x[j]) + o.PsoC2*r2*(g[j]-x[j])
+				v[j] = max(-vmax[j], min(v[j], vmax[j]))
+				x[j] += v[j]
+				if x[j] < o.Lower[j] {
+					x[j], v[j] = o.Lower[j], 0
+				}
+				if x[j] > o.Upper[j] {
+					x[j], v[j] = o.Upper[j], 0
+				}
+			}
+		}
+
+		// update best positions
+		err = o.evaluate(f, X)
+		if err != nil {
+			return
+		}
+		for i := 0; i < npop; i++ {
+			if f[i] < fp[i] {
+				copy(P[i], X[i])
+				fp[i] = f[i]
+			}
+		}
+	}
+}
+
+// cmaes implements the (μ/μw, λ) covariance matrix adaptation evolution strategy
+//  NOTE: (1) the variables are normalised by the range Upper - Lower
+//        (2) the initial mean is the best point of a Latin hypercube sample with λ points
+//        (3) samples outside the box are projected onto the bounds and the projected steps are
+//            used to update the distribution
+//  Reference:
+//   Hansen N (2016) The CMA evolution strategy: a tutorial. arXiv:1604.00772
+func (o *GlobalMinimizer) cmaes() (err error) {
+
+	// population size and weights
+	n := o.ndim
+	nf := float64(n)
+	λ, err := o.npop(4+int(3*math.Log(nf)), 2)
+	if err != nil {
+		return
+	}
+	μ := λ / 2
+	w := la.NewVector(μ)
+	for i := 0; i < μ; i++ {
+		w[i] = math.Log(float64(λ+1)/2) - math.Log(float64(i+1))
+	}
+	w.Apply(1/w.Accum(), w)
+	μeff := 1 / la.VecDot(w, w)
+
+	// adaptation constants
+	cσ := (μeff + 2) / (nf + μeff + 5)
+	dσ := 1 + 2*max(0, math.Sqrt((μeff-1)/(nf+1))-1) + cσ
+	cc := (4 + μeff/nf) / (nf + 4 + 2*μeff/nf)
+	c1 := 2 / ((nf+1.3)*(nf+1.3) + μeff)
+	cμ := min(1-c1, 2*(μeff-2+1/μeff)/((nf+2)*(nf+2)+μeff))
+	χn := math.Sqrt(nf) * (1 - 1/(4*nf) + 1/(21*nf*nf)) // E|N(0,I)|
+	neig := max(1, math.Floor(1/(10*nf*(c1+cμ))))       // generations between eigendecompositions
+
+	// initial mean: best point of Latin hypercube sample
+	X := latinRand(o.rng, λ, o.Lower, o.Upper)
+	f := make([]float64, λ)
+	err = o.evaluate(f, X)
+	if err != nil {
+		return
+	}
+	m := la.NewVector(n) // mean (normalised)
+	for j := 0; j < n; j++ {
+		m[j] = (o.res.X[j] - o.Lower[j]) / (o.Upper[j] - o.Lower[j])
+	}
+
+	// distribution: C = B D² Bᵀ
+	σ := o.Sigma0
+	C, B, A := la.NewMatrix(n, n), la.NewMatrix(n, n), la.NewMatrix(n, n)
+	C.SetDiag(1)
+	B.SetDiag(1)
+	D := la.NewVector(n)
+	D.Fill(1)
+	pσ, pc := la.NewVector(n), la.NewVector(n)
+
+	// samples and steps
+	Y := make([]la.Vector, λ) // steps (x - m) / σ (normalised)
+	for k := 0; k < λ; k++ {
+		Y[k] = la.NewVector(n)
+	}
+	z, t, yw, mold := la.NewVector(n), la.NewVector(n), la.NewVector(n), la.NewVector(n)
+	idx := make([]int, λ)
+
+	// generations
+	size := σ
+	for gen := 0; ; gen++ {
+
+		// check convergence
+		done, e := o.next(size)
+		if done || e != nil {
+			return e
+		}
+
+		// sample: x = m + σ B D z
+		for k := 0; k < λ; k++ {
+			for j := 0; j < n; j++ {
+				z[j] = D[j] * o.rng.NormFloat64()
+			}
+			for j := 0; j < n; j++ {
+				y := m[j]
+				for l := 0; l < n; l++ {
+					y += σ * B.Get(j, l) * z[l]
+				}
+				y = max(0, min(y, 1))
+				Y[k][j] = (y - m[j]) / σ
+				X[k][j] = o.Lower[j] + y*(o.Upper[j]-o.Lower[j])
+			}
+		}
+		err = o.evaluate(f, X)
+		if err != nil {
+			return
+		}
+
+		// sort and recombine
+		for k := 0; k < λ; k++ {
+			idx[k] = k
+		}
+		sort.SliceStable(idx, func(a, b int) bool { return f[idx[a]] < f[idx[b]] })
+		copy(mold, m)
+		yw.Fill(0)
+		for i := 0; i < μ; i++ {
+			la.VecAdd(yw, 1, yw, w[i], Y[idx[i]])
+		}
+		la.VecAdd(m, 1, mold, σ, yw)
+
+		// evolution paths: pσ uses C^(-½) yw = B D⁻¹ Bᵀ yw
+		for j := 0; j < n; j++ {
+			t[j] = 0
+			for l := 0; l < n; l++ {
+				t[j] += B.Get(l, j) * yw[l]
+			}
+			t[j] /= D[j]
+		}
+		aσ := math.Sqrt(cσ * (2 - cσ) * μeff)
+		for i := 0; i < n; i++ {
+			pσ[i] *= 1 - cσ
+			for l := 0; l < n; l++ {
+				pσ[i] += aσ * B.Get(i, l) * t[l]
+			}
+		}
+		nσ := pσ.Norm()
+		hσ := 0.0
+		if nσ/math.Sqrt(1-math.Pow(1-cσ, 2*float64(gen+1)))/χn < 1.4+2/(nf+1) {
+			hσ = 1
+		}
+		la.VecAdd(pc, 1-cc, pc, hσ*math.Sqrt(cc*(2-cc)*μeff), yw)
+
+		// covariance matrix: rank-one and rank-μ updates
+		δh := (1 - hσ) * cc * (2 - cc)
+		for i := 0; i < n; i++ {
+			for j := 0; j <= i; j++ {
+				cij := (1-c1-cμ+c1*δh)*C.Get(i, j) + c1*pc[i]*pc[j]
+				for k := 0; k < μ; k++ {
+					y := Y[idx[k]]
+					cij += cμ * w[k] * y[i] * y[j]
+				}
+				C.Set(i, j, cij)
+				C.Set(j, i, cij)
+			}
+		}
+
+		// step size
+		σ *= math.Exp((cσ / dσ) * (nσ/χn - 1))
+
+		// eigendecomposition
+		if math.Mod(float64(gen+1), neig) == 0 {
+			C.CopyInto(A, 1)
+			err = la.Jacobi(B, D, A)
+			if err != nil {
+				return
+			}
+			for j := 0; j < n; j++ {
+				D[j] = math.Sqrt(max(D[j], 1e-300))
+			}
+		}
+
+		// size of distribution
+		size = 0
+		for j := 0; j < n; j++ {
+			size = max(size, σ*math.Sqrt(C.Get(j, j)))
+		}
+	}
+}
@@ -0,0 +1,290 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/rnd"
+	"github.com/cpmech/gosl/utl"
+)
+
+// population-based methods for global minimisation
+const (
+	GlobalDE    = iota // differential evolution
+	GlobalPSO          // particle swarm optimisation with inertia weight
+	GlobalCMAES        // covariance matrix adaptation evolution strategy
+)
+
+// strategies of differential evolution (with binomial crossover)
+const (
+	DeRand1Bin          = iota // v = x_r1 + F (x_r2 - x_r3)
+	DeBest1Bin                 // v = x_best + F (x_r1 - x_r2)
+	DeCurrentToBest1Bin        // v = x_i + F (x_best - x_i) + F (x_r1 - x_r2)
+	DeRand2Bin                 // v = x_r1 + F (x_r2 - x_r3) + F (x_r4 - x_r5)
+	DeBest2Bin                 // v = x_best + F (x_r1 - x_r2) + F (x_r3 - x_r4)
+)
+
+// GlobalMinimizer implements population-based (stochastic) methods to find the global minimum
+// of nonlinear (e.g. multimodal) functions within a box
+//  Solve:
+//          min f(x)    subject to    Lower ≤ x ≤ Upper
+//           x
+//
+//  NOTE: (1) the initial population is generated by Latin hypercube sampling (rnd.LatinIHS)
+//        (2) if Seed > 0, the random numbers are generated by a generator of the minimizer that is
+//            initialised with Seed, such that the results are reproducible (also with
+//            Nworkers > 1) and the global generator (rnd.Init) is not affected. Otherwise, the
+//            global generator is used
+//        (3) f(x) is evaluated by Nworkers goroutines concurrently; thus Ffcn must be safe for
+//            concurrent use if Nworkers > 1
+//        (4) the iterations stop successfully (Converged) if the best f(x) is not reduced by
+//            more than Ftol (1 + |f|) within Nstag generations (stagnation)
+type GlobalMinimizer struct {
+
+	// constants
+	Method   int     // method; e.g. GlobalDE
+	Npop     int     // population size [0 ⇒ DE: max(10 n, 6); PSO: 40; CMA-ES: 4 + 3 ln(n)]
+	MaxIt    int     // max number of generations
+	MaxFeval int     // max number of function evaluations [0 ⇒ unlimited]
+	Nstag    int     // number of generations without improvement for stopping (stagnation)
+	Ftol     float64 // tolerance on the relative improvement of the best f(x) for stagnation
+	Seed     int     // seed for random numbers [≤ 0 ⇒ the global generator is used]
+	Nworkers int     // number of goroutines to evaluate f(x) [≤ 1 ⇒ sequential]
+	Hist     bool    // record history of best x in results
+	Verbose  bool    // show messages
+
+	// differential evolution
+	DeStrategy int     // strategy; e.g. DeRand1Bin
+	DeF        float64 // differential weight F
+	DeCr       float64 // crossover probability CR
+
+	// particle swarm optimisation
+	PsoW    float64 // inertia weight
+	PsoC1   float64 // cognitive coefficient (attraction to the best position of each particle)
+	PsoC2   float64 // social coefficient (attraction to the best position of the swarm)
+	PsoVmax float64 // max velocity as a fraction of the range Upper - Lower
+
+	// covariance matrix adaptation evolution strategy
+	Sigma0 float64 // initial step size σ as a fraction of the range Upper - Lower
+
+	// callbacks and bounds
+	Ffcn  fun.Sv    // f(x) function
+	Lower la.Vector // lower bounds (finite)
+	Upper la.Vector // upper bounds (finite)
+
+	// cancellation and progress report
+	Monitor utl.Monitor // residual = best f(x), step = size of population relative to the range
+
+	// internal
+	ndim  int        // dimension
+	res   *MinResult // current results
+	fprev float64    // best f(x) at the last improvement
+	nstag int        // number of generations without improvement
+	rng   *rand.Rand // random numbers generator
+}
+
+// NewGlobalMinimizer returns a new population-based minimizer
+//  Input:
+//   method -- method; e.g. GlobalDE
+//   ffcn   -- f(x) function
+//   lower  -- lower bounds (finite)
+//   upper  -- upper bounds (finite)
+func NewGlobalMinimizer(method int, ffcn fun.Sv, lower, upper la.Vector) (o *GlobalMinimizer) {
+	o = new(GlobalMinimizer)
+	o.Method = method
+	o.MaxIt = 1000
+	o.Nstag = 100
+	o.Ftol = 1e-12
+	o.DeStrategy = DeRand1Bin
+	o.DeF = 0.8
+	o.DeCr = 0.9
+	o.PsoW = 0.7298
+	o.PsoC1 = 1.49618
+	o.PsoC2 = 1.49618
+	o.PsoVmax = 0.2
+	o.Sigma0 = 0.3
+	o.Ffcn = ffcn
+	o.Lower = lower
+	o.Upper = upper
+	o.ndim = len(lower)
+	return
+}
+
+// Min finds the global minimum of f(x)
+func (o *GlobalMinimizer) Min() (res *MinResult, err error) {
+
+	// check
+	n := o.ndim
+	if o.Ffcn == nil || n < 1 || len(o.Upper) != n {
+		return nil, chk.Err("objective function and bounds (with the same size ≥ 1) must be given")
+	}
+	for j := 0; j < n; j++ {
+		if !(o.Lower[j] < o.Upper[j]) || math.IsInf(o.Lower[j], 0) || math.IsInf(o.Upper[j], 0) {
+			return nil, chk.Err("bounds must be finite with Lower < Upper. [%g, %g] of x[%d] is invalid", o.Lower[j], o.Upper[j], j)
+		}
+	}
+
+	// initialise
+	o.rng = rnd.NewRand(o.Seed)
+	o.res = &MinResult{X: la.NewVector(n), F: math.Inf(1)}
+	res = o.res
+	o.fprev, o.nstag = math.Inf(1), 0
+	if o.Verbose {
+		io.Pf("%6s%8s%23s%14s\n", "it", "nfeval", "f(x)", "size")
+	}
+
+	// solve
+	switch o.Method {
+	case GlobalDE:
+		err = o.diffEvol()
+	case GlobalPSO:
+		err = o.pso()
+	case GlobalCMAES:
+		err = o.cmaes()
+	default:
+		err = chk.Err("global minimisation method %d is not available", o.Method)
+	}
+	return
+}
+
+// npop returns the population size
+func (o *GlobalMinimizer) npop(def, least int) (npop int, err error) {
+	npop = o.Npop
+	if npop == 0 {
+		npop = def
+	}
+	if npop < least {
+		return 0, chk.Err("population size must be at least %d. %d is incorrect", least, npop)
+	}
+	return
+}
+
+// latinRand generates npop points within the bounds by Latin hypercube sampling with rng
+func latinRand(rng *rand.Rand, npop int, lower, upper la.Vector) (X []la.Vector) {
+	ndim := len(lower)
+	coords := rnd.HypercubeCoords(rnd.LatinIHSrand(rng, ndim, npop, 5), lower, upper) // [ndim][npop]
+	X = make([]la.Vector, npop)
+	for i := 0; i < npop; i++ {
+		X[i] = la.NewVector(ndim)
+		for j := 0; j < ndim; j++ {
+			X[i][j] = coords[j][i]
+		}
+	}
+	return
+}
+
+// evaluate computes f(x) for a set of points (concurrently if Nworkers > 1) and updates the best
+// point. NaN values are replaced by +∞
+func (o *GlobalMinimizer) evaluate(f []float64, X []la.Vector) (err error) {
+
+	// check budget
+	res := o.res
+	if o.MaxFeval > 0 && res.Nfeval+len(X) > o.MaxFeval {
+		return utl.NewIterError(utl.StopMaxIt, "GlobalMinimizer", res.Nit, "max number of function evaluations (%d) reached", o.MaxFeval)
+	}
+
+	// evaluate
+	err = runConcurrently(o.Nworkers, len(X), func(i int) (e error) {
+		f[i], e = o.Ffcn(X[i])
+		return
+	})
+	res.Nfeval += len(X)
+	if err != nil {
+		return
+	}
+
+	// best point
+	for i := range X {
+		if math.IsNaN(f[i]) {
+			f[i] = math.Inf(1)
+		}
+		if f[i] < res.F {
+			res.F = f[i]
+			copy(res.X, X[i])
+		}
+	}
+	return
+}
+
+// next records the results of a generation and checks the stopping criteria
+//  Input:
+//   size -- size of population relative to the range
+//  Output:
+//   done -- stagnation has been reached
+func (o *GlobalMinimizer) next(size float64) (done bool, err error) {
+
+	// record
+	res := o.res
+	res.HistF = append(res.HistF, res.F)
+	if o.Hist {
+		res.HistX = append(res.HistX, res.X.GetCopy())
+	}
+	if o.Verbose {
+		io.Pf("%6d%8d%23.15e%14.6e\n", res.Nit, res.Nfeval, res.F, size)
+	}
+
+	// check stagnation
+	if res.F < o.fprev-o.Ftol*(1+math.Abs(o.fprev)) || math.IsInf(o.fprev, 1) {
+		o.fprev, o.nstag = res.F, 0
+	} else {
+		o.nstag++
+	}
+	if o.nstag >= o.Nstag {
+		res.Converged = true
+		return true, nil
+	}
+
+	// check iterations
+	if res.Nit >= o.MaxIt {
+		return false, utl.NewIterError(utl.StopMaxIt, "GlobalMinimizer", res.Nit, "iterations did not converge")
+	}
+	err = o.Monitor.Check("GlobalMinimizer", res.Nit, res.F, size)
+	res.Nit++
+	return
+}
+
+// spread returns the size of the population relative to the range: max_j (max_i xᵢⱼ - min_i xᵢⱼ) / (uⱼ - lⱼ)
+func (o *GlobalMinimizer) spread(X []la.Vector) (size float64) {
+	for j := 0; j < o.ndim; j++ {
+		xmin, xmax := X[0][j], X[0][j]
+		for i := 1; i < len(X); i++ {
+			xmin = min(xmin, X[i][j])
+			xmax = max(xmax, X[i][j])
+		}
+		size = max(size, (xmax-xmin)/(o.Upper[j]-o.Lower[j]))
+	}
+	return
+}
+
+// randOthers fills idx with distinct random indices in [0, npop) that are different from i
+func randOthers(rng *rand.Rand, idx []int, i, npop int) {
+	for k := 0; k < len(idx); {
+		r := randInt(rng, 0, npop-1)
+		repeated := r == i
+		for l := 0; l < k && !repeated; l++ {
+			repeated = idx[l] == r
+		}
+		if !repeated {
+			idx[k] = r
+			k++
+		}
+	}
+}
+
+// randInt generates a pseudo random integer between low and high with rng (see rnd.Int)
+func randInt(rng *rand.Rand, low, high int) int {
+	return rng.Int()%(high-low+1) + low
+}
+
+// randFloat64 generates a pseudo random real number in [low, high) with rng (see rnd.Float64)
+func randFloat64(rng *rand.Rand, low, high float64) float64 {
+	return low + (high-low)*rng.Float64()
+}
@@ -0,0 +1,103 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"math"
+	"sort"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/utl"
+)
+
+// Hypervolume computes the hypervolume indicator of a set of objective values (minimisation);
+// i.e. the measure of the region dominated by the set and bounded by a reference point
+//  Input:
+//   ovs -- [npoints][nobj] objective values; e.g. Nsga2.Ovs
+//   ref -- [nobj] reference point. Points that are not better than ref in all objectives are ignored
+//  Output:
+//   hv -- hypervolume (the larger the better)
+//  NOTE: the computation is exact and performed by slicing along the objectives; the cost grows
+//        with npoints^nobj, thus this function is slow for many objectives
+func Hypervolume(ovs [][]float64, ref []float64) (hv float64) {
+	var pts [][]float64
+	for i, f := range ovs {
+		if len(f) != len(ref) {
+			chk.Panic("objective values and reference point must have the same size. %d != %d (point %d)", len(f), len(ref), i)
+		}
+		inside := true
+		for m := range f {
+			if !(f[m] < ref[m]) {
+				inside = false
+				break
+			}
+		}
+		if inside {
+			pts = append(pts, f)
+		}
+	}
+	front := utl.ParetoFront(pts)
+	nondom := make([][]float64, len(front))
+	for k, i := range front {
+		nondom[k] = pts[i]
+	}
+	return hvSlice(nondom, ref, len(ref))
+}
+
+// hvSlice computes the hypervolume of pts considering only the first nobj objectives
+func hvSlice(pts [][]float64, ref []float64, nobj int) (hv float64) {
+	if len(pts) == 0 {
+		return 0
+	}
+	m := nobj - 1
+	if m == 0 {
+		fmin := pts[0][0]
+		for _, f := range pts {
+			fmin = min(fmin, f[0])
+		}
+		return ref[0] - fmin
+	}
+	s := make([][]float64, len(pts))
+	copy(s, pts)
+	sort.SliceStable(s, func(a, b int) bool { return s[a][m] < s[b][m] })
+	for i := range s {
+		next := ref[m]
+		if i+1 < len(s) {
+			next = s[i+1][m]
+		}
+		if next > s[i][m] {
+			hv += (next - s[i][m]) * hvSlice(s[:i+1], ref, m)
+		}
+	}
+	return
+}
+
+// Igd computes the inverted generational distance; i.e. the average Euclidean distance from each
+// point of a reference front to the nearest point of an approximated front
+//  Input:
+//   ovs      -- [npoints][nobj] objective values of the approximated front; e.g. Nsga2.Ovs
+//   refFront -- [nref][nobj] points of the reference front; e.g. sampled from the true Pareto front
+//  Output:
+//   igd -- inverted generational distance (the smaller the better). +∞ if ovs is empty
+func Igd(ovs, refFront [][]float64) (igd float64) {
+	if len(refFront) == 0 {
+		chk.Panic("reference front must have at least one point\n")
+	}
+	for _, r := range refFront {
+		dmin := math.Inf(1)
+		for _, f := range ovs {
+			if len(f) != len(r) {
+				chk.Panic("objective values and points of reference front must have the same size. %d != %d", len(f), len(r))
+			}
+			d := 0.0
+			for m := range r {
+				d += (f[m] - r[m]) * (f[m] - r[m])
+			}
+			dmin = min(dmin, d)
+		}
+		igd += math.Sqrt(dmin)
+	}
+	return igd / float64(len(refFront))
+}
@@ -0,0 +1,123 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
+)
+
+// lineSearch finds a step length α along the direction o.d, from o.x0, that satisfies the
+// strong Wolfe conditions:
+//
+//   φ(α) ≤ φ(0) + C1 α φ'(0)    and    |φ'(α)| ≤ C2 |φ'(0)|    where φ(α) = f(x0 + α d)
+//
+//  Input:
+//   f0  -- f(x0)
+//   dφ0 -- φ'(0) = g(x0)ᵀd < 0
+//   α0  -- initial trial step length
+//  Output:
+//   x, g -- point x = x0 + α d and gradient at x
+//   α    -- step length
+//   f    -- f(x)
+//  Reference:
+//   Nocedal J and Wright SJ (2006) Numerical Optimization. 2nd Edition. Springer.
+//   Algorithms 3.5 and 3.6
+func (o *Minimizer) lineSearch(x, g la.Vector, f0, dφ0, α0 float64) (α, f float64, err error) {
+
+	// function and derivative along d
+	φ := func(a float64) (fa, dφa float64, e error) {
+		la.VecAdd(x, 1, o.x0, a, o.d)
+		fa, e = o.fcn(x)
+		if e != nil {
+			return
+		}
+		e = o.grad(g, x)
+		if e != nil {
+			return
+		}
+		return fa, la.VecDot(g, o.d), nil
+	}
+
+	// bracketing phase
+	αprev, φprev, dφprev := 0.0, f0, dφ0
+	α = α0
+	for it := 0; it < o.LsMaxIt; it++ {
+		var dφ float64
+		f, dφ, err = φ(α)
+		if err != nil {
+			return
+		}
+		if math.IsNaN(f) || math.IsInf(f, 0) { // step is too large
+			α = αprev + 0.1*(α-αprev)
+			continue
+		}
+		if f > f0+o.C1*α*dφ0 || (it > 0 && f >= φprev) {
+			return o.zoom(φ, f0, dφ0, αprev, α, φprev, f, dφprev, dφ)
+		}
+		if math.Abs(dφ) <= -o.C2*dφ0 {
+			return
+		}
+		if dφ >= 0 {
+			return o.zoom(φ, f0, dφ0, α, αprev, f, φprev, dφ, dφprev)
+		}
+		αprev, φprev, dφprev = α, f, dφ
+		α *= 2
+	}
+	return α, f, chk.Err("line search failed to bracket a step length after %d iterations", o.LsMaxIt)
+}
+
+// zoom finds a step length satisfying the strong Wolfe conditions within [αlo, αhi], where αlo
+// gives the lowest function value found so far and satisfies the sufficient decrease condition
+func (o *Minimizer) zoom(φ func(a float64) (float64, float64, error), f0, dφ0, αlo, αhi, φlo, φhi, dφlo, dφhi float64) (α, f float64, err error) {
+	for it := 0; it < o.LsMaxIt; it++ {
+
+		// trial step by cubic interpolation (bisection if unsafe)
+		α = cubicMin(αlo, φlo, dφlo, αhi, φhi, dφhi)
+		a, b := min(αlo, αhi), max(αlo, αhi)
+		if math.IsNaN(α) || α < a+0.1*(b-a) || α > b-0.1*(b-a) {
+			α = 0.5 * (αlo + αhi)
+		}
+		if math.Abs(αhi-αlo) < machEps*max(1, math.Abs(αlo)) {
+			break
+		}
+
+		// evaluate
+		var dφ float64
+		f, dφ, err = φ(α)
+		if err != nil {
+			return
+		}
+		if f > f0+o.C1*α*dφ0 || f >= φlo || math.IsNaN(f) {
+			αhi, φhi, dφhi = α, f, dφ
+			continue
+		}
+		if math.Abs(dφ) <= -o.C2*dφ0 {
+			return
+		}
+		if dφ*(αhi-αlo) >= 0 {
+			αhi, φhi, dφhi = αlo, φlo, dφlo
+		}
+		αlo, φlo, dφlo = α, f, dφ
+	}
+	if αlo > 0 { // accept step with sufficient decrease
+		f, _, err = φ(αlo)
+		return αlo, f, err
+	}
+	return α, f, chk.Err("line search failed to find a step length satisfying the strong Wolfe conditions after %d iterations", o.LsMaxIt)
+}
+
+// cubicMin returns the minimiser of the cubic interpolating φ and φ' at a and b (NaN if none)
+func cubicMin(a, φa, dφa, b, φb, dφb float64) float64 {
+	d1 := dφa + dφb - 3*(φa-φb)/(a-b)
+	s := d1*d1 - dφa*dφb
+	if s < 0 {
+		return math.NaN()
+	}
+	d2 := math.Copysign(math.Sqrt(s), b-a)
+	return b - (b-a)*(dφb+d2-d1)/(dφb-dφa+2*d2)
+}
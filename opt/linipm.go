@@ -6,12 +6,13 @@
 package opt
 
 import (
+	"context"
 	"math"
 
-	"github.com/cpmech/gosl/chk"
 	"github.com/cpmech/gosl/fun/dbf"
 	"github.com/cpmech/gosl/io"
 	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
 )
 
 // LinIpm implements the interior-point methods for linear programming problems
@@ -58,6 +59,19 @@ type LinIpm struct {
 
 	// linear solver
 	Lis la.SparseSolver // linear solver
+
+	// cancellation and progress report
+	Monitor utl.Monitor // residual = duality gap |cᵀx-bᵀλ|/(1+|cᵀx|), step = primal step length α
+
+	// general problem (see InitGeneral); computed after Solve
+	Prob *LinProb  // general problem; nil if Init was called instead of InitGeneral
+	Xg   la.Vector // [n] solution of general problem
+	Lg   la.Vector // [m] multipliers of general constraints (∂f/∂bᵢ)
+	Zg   la.Vector // [n] reduced costs c - Aᵀλ (multipliers of the bounds on variables)
+	Fg   float64   // objective value cᵀx + c0 of general problem
+
+	// internal
+	std *linStd // standard form of general problem
 }
 
 // Free frees allocated memory
@@ -70,6 +84,7 @@ func (o *LinIpm) Init(A *la.CCMatrix, b, c la.Vector, prms dbf.Params) {
 
 	// problem
 	o.A, o.B, o.C = A, b, c
+	o.Prob, o.std = nil, nil
 
 	// constants
 	o.NmaxIt = 50
@@ -112,8 +127,30 @@ func (o *LinIpm) Init(A *la.CCMatrix, b, c la.Vector, prms dbf.Params) {
 	o.Lis = la.NewSparseSolver("umfpack")
 }
 
+// InitGeneral initialises LinIpm with a problem in general form, which is converted to
+// standard form by means of slack, surplus and split variables. The solution of the general
+// problem is available in Xg, Lg, Zg and Fg after Solve. In addition to the duality gap, the
+// normalised primal and dual residuals must be smaller than Tol to stop the iterations
+func (o *LinIpm) InitGeneral(prob *LinProb, prms dbf.Params) {
+	std := newLinStd(prob)
+	o.Init(std.A, std.B, std.C, prms)
+	o.Prob, o.std = prob, std
+	o.Xg = la.NewVector(len(prob.C))
+	o.Lg = la.NewVector(len(prob.B))
+	o.Zg = la.NewVector(len(prob.C))
+}
+
 // Solve solves linear programming problem
 func (o *LinIpm) Solve(verbose bool) (err error) {
+	err = o.solve(verbose)
+	if err == nil && o.std != nil {
+		o.Fg = o.std.solution(o.Xg, o.Lg, o.Zg, o.X, o.L)
+	}
+	return
+}
+
+// solve solves the linear programming problem in standard form
+func (o *LinIpm) solve(verbose bool) (err error) {
 
 	// starting point
 	AAt := la.NewMatrix(o.Nl, o.Nl)               // A*Aᵀ
@@ -153,6 +190,9 @@ func (o *LinIpm) Solve(verbose bool) (err error) {
 
 	// auxiliary
 	I := o.Nx + o.Nl
+	bden := 1 + o.B.Largest(1) // to normalise the primal residual
+	cden := 1 + o.C.Largest(1) // to normalise the dual residual
+	feasible := true           // small primal and dual residuals (general problems only)
 
 	// control variables
 	var μ, σ float64     // μ and σ
@@ -194,9 +234,21 @@ func (o *LinIpm) Solve(verbose bool) (err error) {
 			fx := la.VecDot(o.C, o.X)
 			io.Pf("%3d%16.8e%16.8e\n", it, fx, lerr)
 		}
-		if lerr < o.Tol {
+		if o.std != nil {
+			feasible = o.Rl.Largest(bden) < o.Tol && o.Rx.Largest(cden) < o.Tol
+		}
+		if lerr < o.Tol && feasible { // small gap and feasible
 			break
 		}
+		if math.IsNaN(lerr) || math.IsInf(lerr, 0) {
+			return utl.NewIterError(utl.StopDiverged, "LinIpm", it, "duality gap is %v", lerr)
+		}
+
+		// cancellation and progress
+		err = o.Monitor.Check("LinIpm", it, lerr, αpa)
+		if err != nil {
+			return
+		}
 
 		// assemble Jacobian
 		o.J.Start()
@@ -262,11 +314,18 @@ func (o *LinIpm) Solve(verbose bool) (err error) {
 
 	// check convergence
 	if it == o.NmaxIt {
-		err = chk.Err("iterations did not converge")
+		err = utl.NewIterError(utl.StopMaxIt, "LinIpm", it, "iterations did not converge")
 	}
 	return
 }
 
+// SolveCtx solves linear programming problem and stops with an error if ctx is cancelled
+func (o *LinIpm) SolveCtx(ctx context.Context, verbose bool) (err error) {
+	o.Monitor.Ctx = ctx
+	defer func() { o.Monitor.Ctx = nil }()
+	return o.Solve(verbose)
+}
+
 func (o *LinIpm) calcMinRatios() (xrmin, srmin float64) {
 	firstxrmin, firstsrmin := true, true
 	for i := 0; i < o.Nx; i++ {
@@ -0,0 +1,339 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+// kinds of constraints in general linear programming problems
+const (
+	LinEq = iota // aᵢᵀx = bᵢ
+	LinLe        // aᵢᵀx ≤ bᵢ
+	LinGe        // aᵢᵀx ≥ bᵢ
+)
+
+// LinInf is the magnitude from which bounds on variables are regarded as infinite
+// (e.g. 1e20 as in the files read by ReadLPfortran)
+const LinInf = 1e20
+
+// LinProb holds a linear programming problem in general form
+//
+//          min cᵀx + c0   s.t.   aᵢᵀx {=,≤,≥} bᵢ,   l ≤ x ≤ u
+//           x
+//
+//  NOTE: bounds with |value| ≥ LinInf (e.g. ±Inf) are regarded as infinite.
+//        free variables have l = -∞ and u = +∞
+//
+//  Ranged constraints are defined by rᵢ ≠ 0 in R (as in MPS files), such that:
+//
+//          kind     rᵢ       lower      upper
+//          LinLe    any      bᵢ - |rᵢ|  bᵢ
+//          LinGe    any      bᵢ         bᵢ + |rᵢ|
+//          LinEq    > 0      bᵢ         bᵢ + |rᵢ|
+//          LinEq    < 0      bᵢ - |rᵢ|  bᵢ
+type LinProb struct {
+	A    *la.CCMatrix // [m][n] matrix of constraints
+	B    la.Vector    // [m] right-hand side
+	C    la.Vector    // [n] objective coefficients
+	Kind []int        // [m] kind of constraints: LinEq, LinLe or LinGe; nil means all LinEq
+	L    la.Vector    // [n] lower bounds; nil means all zero
+	U    la.Vector    // [n] upper bounds; nil means all +∞
+
+	// extra data (e.g. from MPS or CPLEX-LP files)
+	R        la.Vector // [m] ranges of constraints; nil means no ranges
+	C0       float64   // constant term of objective function
+	Maximise bool      // maximise cᵀx + c0 instead
+	Integer  []bool    // [n] integrality markers; nil means all continuous (ignored by LinIpm)
+	Name     string    // name of problem
+	ObjName  string    // name of objective function
+	RowNames []string  // [m] names of constraints; nil means r0, r1, ...
+	ColNames []string  // [n] names of variables; nil means x0, x1, ...
+}
+
+// Lower returns the lower bound of variable j (-∞ if unbounded)
+func (o *LinProb) Lower(j int) float64 {
+	if o.L == nil {
+		return 0
+	}
+	if o.L[j] <= -LinInf {
+		return math.Inf(-1)
+	}
+	return o.L[j]
+}
+
+// Upper returns the upper bound of variable j (+∞ if unbounded)
+func (o *LinProb) Upper(j int) float64 {
+	if o.U == nil || o.U[j] >= LinInf {
+		return math.Inf(1)
+	}
+	return o.U[j]
+}
+
+// RowKind returns the kind of constraint i
+func (o *LinProb) RowKind(i int) int {
+	if o.Kind == nil {
+		return LinEq
+	}
+	return o.Kind[i]
+}
+
+// RowRange returns the range of constraint i (zero if not ranged)
+func (o *LinProb) RowRange(i int) float64 {
+	if o.R == nil {
+		return 0
+	}
+	return o.R[i]
+}
+
+// RowBounds returns the lower and upper bounds of aᵢᵀx (±∞ if unbounded)
+func (o *LinProb) RowBounds(i int) (lo, hi float64) {
+	r := o.RowRange(i)
+	lo, hi = o.B[i], o.B[i]
+	switch o.RowKind(i) {
+	case LinLe:
+		lo = math.Inf(-1)
+		if r != 0 {
+			lo = o.B[i] - math.Abs(r)
+		}
+	case LinGe:
+		hi = math.Inf(1)
+		if r != 0 {
+			hi = o.B[i] + math.Abs(r)
+		}
+	default:
+		if r > 0 {
+			hi = o.B[i] + r
+		} else if r < 0 {
+			lo = o.B[i] + r
+		}
+	}
+	return
+}
+
+// IsInteger tells whether variable j must be integer or not
+func (o *LinProb) IsInteger(j int) bool {
+	return o.Integer != nil && o.Integer[j]
+}
+
+// RowName returns the name of constraint i
+func (o *LinProb) RowName(i int) string {
+	if o.RowNames == nil {
+		return io.Sf("r%d", i)
+	}
+	return o.RowNames[i]
+}
+
+// ColName returns the name of variable j
+func (o *LinProb) ColName(j int) string {
+	if o.ColNames == nil {
+		return io.Sf("x%d", j)
+	}
+	return o.ColNames[j]
+}
+
+// GetObjName returns the name of the objective function
+func (o *LinProb) GetObjName() string {
+	if o.ObjName == "" {
+		return "obj"
+	}
+	return o.ObjName
+}
+
+// linStd holds the standard form of a general problem and the data needed to map solutions back
+//  The general variables are obtained from the standard ones (y) by means of:
+//   x[j] = off[j] + sgn[j] * y[col[j]] - y[neg[j]]
+//  where col[j] < 0 indicates a fixed variable and neg[j] < 0 indicates that there is no
+//  negative part (only free variables are split)
+type linStd struct {
+	prob *LinProb     // general problem
+	A    *la.CCMatrix // [ms][ns] standard matrix
+	B    la.Vector    // [ms] standard right-hand side
+	C    la.Vector    // [ns] standard objective coefficients
+	col  []int        // [n] index of standard (positive part) variable
+	neg  []int        // [n] index of negative part of free variable
+	sgn  []float64    // [n] sign of standard variable
+	off  []float64    // [n] offset (fixed value or finite bound)
+}
+
+// newLinStd converts a general linear problem to standard form:
+//  1) finite lower bound:   x = l + y
+//  2) finite upper bound:   x = u - y   (if l = -∞)
+//  3) both bounds finite:   x = l + y   and new row y + w = u - l
+//  4) fixed variable:       x = l       (removed)
+//  5) free variable:        x = y⁺ - y⁻
+//  6) inequality rows:      aᵢᵀx ± sᵢ = bᵢ
+//  7) ranged rows:          aᵢᵀx ± sᵢ = bᵢ   and new row sᵢ + w = |rᵢ|
+//  8) maximisation:         c := -c
+func newLinStd(prob *LinProb) (o *linStd) {
+
+	// check
+	m, n, Ap, Ai, Ax := prob.A.Get()
+	if len(prob.B) != m || len(prob.C) != n {
+		chk.Panic("sizes of b and c must be equal to the number of rows (%d) and columns (%d) of A. %d, %d are incorrect", m, n, len(prob.B), len(prob.C))
+	}
+	if (prob.Kind != nil && len(prob.Kind) != m) || (prob.R != nil && len(prob.R) != m) || (prob.L != nil && len(prob.L) != n) || (prob.U != nil && len(prob.U) != n) {
+		chk.Panic("sizes of kind, r, l and u must be equal to %d, %d, %d and %d, respectively", m, m, n, n)
+	}
+
+	// variables
+	o = new(linStd)
+	o.prob = prob
+	o.col = make([]int, n)
+	o.neg = make([]int, n)
+	o.sgn = make([]float64, n)
+	o.off = make([]float64, n)
+	ns, nub := 0, 0 // number of standard variables and of extra (upper bound) rows
+	for j := 0; j < n; j++ {
+		l, u := prob.Lower(j), prob.Upper(j)
+		if l > u {
+			chk.Panic("lower bound of variable %d is greater than its upper bound: %g > %g", j, l, u)
+		}
+		o.col[j], o.neg[j], o.sgn[j] = ns, -1, 1
+		switch {
+		case l == u:
+			o.col[j], o.off[j] = -1, l
+			continue
+		case !math.IsInf(l, 0):
+			o.off[j] = l
+			if !math.IsInf(u, 0) {
+				nub++
+			}
+		case !math.IsInf(u, 0):
+			o.sgn[j], o.off[j] = -1, u
+		default:
+			ns++
+			o.neg[j] = ns
+		}
+		ns++
+	}
+	nslk, nrg := 0, 0 // number of slacks and of ranged constraints
+	for i := 0; i < m; i++ {
+		if o.slackSign(i) != 0 {
+			nslk++
+		}
+		if prob.RowRange(i) != 0 {
+			nrg++
+		}
+	}
+
+	// right-hand side and objective
+	ms := m + nub + nrg
+	nt := ns + nslk + nub + nrg
+	o.B = la.NewVector(ms)
+	o.C = la.NewVector(nt)
+	copy(o.B, prob.B)
+	σ := 1.0 // sign of objective function
+	if prob.Maximise {
+		σ = -1
+	}
+	for j := 0; j < n; j++ {
+		for k := Ap[j]; k < Ap[j+1]; k++ {
+			o.B[Ai[k]] -= Ax[k] * o.off[j]
+		}
+		if o.col[j] < 0 {
+			continue
+		}
+		o.C[o.col[j]] = σ * o.sgn[j] * prob.C[j]
+		if o.neg[j] >= 0 {
+			o.C[o.neg[j]] = -σ * prob.C[j]
+		}
+	}
+
+	// matrix
+	var T la.Triplet
+	nnz := len(Ax)
+	T.Init(ms, nt, 2*nnz+nslk+2*nub+2*nrg)
+	r, s := m, ns // indices of next extra row and next slack
+	for j := 0; j < n; j++ {
+		if o.col[j] < 0 {
+			continue
+		}
+		for k := Ap[j]; k < Ap[j+1]; k++ {
+			T.Put(Ai[k], o.col[j], o.sgn[j]*Ax[k])
+			if o.neg[j] >= 0 {
+				T.Put(Ai[k], o.neg[j], -Ax[k])
+			}
+		}
+		if o.sgn[j] > 0 && o.neg[j] < 0 && !math.IsInf(prob.Upper(j), 0) {
+			T.Put(r, o.col[j], 1)
+			T.Put(r, ns+nslk+r-m, 1)
+			o.B[r] = prob.Upper(j) - o.off[j]
+			r++
+		}
+	}
+	for i := 0; i < m; i++ {
+		sign := o.slackSign(i)
+		if sign == 0 {
+			continue
+		}
+		T.Put(i, s, sign)
+		if rng := prob.RowRange(i); rng != 0 {
+			T.Put(r, s, 1)
+			T.Put(r, ns+nslk+r-m, 1)
+			o.B[r] = math.Abs(rng)
+			r++
+		}
+		s++
+	}
+	o.A = T.ToMatrix(nil)
+	return
+}
+
+// solution maps the solution of the standard problem back to the general problem
+//  Input:
+//   y -- [ns] solution of standard problem
+//   λ -- [ms] multipliers of standard constraints
+//  Output:
+//   x -- [n] solution of general problem
+//   l -- [m] multipliers of general constraints (∂f/∂bᵢ)
+//   z -- [n] reduced costs z = c - Aᵀλ (multipliers of the bounds on variables)
+//   f -- value of objective function cᵀx + c0
+func (o *linStd) solution(x, l, z, y, λ la.Vector) (f float64) {
+	for j := 0; j < len(x); j++ {
+		x[j] = o.off[j]
+		if o.col[j] >= 0 {
+			x[j] += o.sgn[j] * y[o.col[j]]
+		}
+		if o.neg[j] >= 0 {
+			x[j] -= y[o.neg[j]]
+		}
+	}
+	σ := 1.0
+	if o.prob.Maximise {
+		σ = -1
+	}
+	for i := 0; i < len(l); i++ {
+		l[i] = σ * λ[i]
+	}
+	z.Apply(1, o.prob.C)
+	la.SpMatTrVecMulAdd(z, -1, o.prob.A, l)
+	return la.VecDot(o.prob.C, x) + o.prob.C0
+}
+
+// slackSign returns the coefficient of the slack variable of constraint i (zero if not needed)
+func (o *linStd) slackSign(i int) float64 {
+	r := o.prob.RowRange(i)
+	switch o.prob.RowKind(i) {
+	case LinEq:
+		if r > 0 {
+			return -1
+		}
+		if r < 0 {
+			return 1
+		}
+		return 0
+	case LinLe:
+		return 1
+	case LinGe:
+		return -1
+	}
+	chk.Panic("kind of constraint %d is invalid: %d", i, o.prob.RowKind(i))
+	return 0
+}
@@ -0,0 +1,844 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+// pricing rules for LinSimplex
+const (
+	SimplexSteepest = iota // dual steepest-edge: largest infeasibility²/weight (default)
+	SimplexDantzig         // largest primal infeasibility
+	SimplexBland           // smallest index (prevents cycling; slow and for small problems only)
+)
+
+// status of solution computed by LinSimplex
+const (
+	SimplexUnsolved   = iota // not solved yet or stopped because of max iterations, cancellation, etc.
+	SimplexOptimal           // optimal solution found
+	SimplexInfeasible        // problem is (primal) infeasible
+	SimplexUnbounded         // problem is unbounded
+)
+
+// status of variables in a basis
+const (
+	BasisBasic = iota // basic variable
+	BasisLower        // non-basic at lower bound
+	BasisUpper        // non-basic at upper bound
+	BasisZero         // non-basic free variable at zero
+)
+
+// SimplexBasis holds the status of variables in a basis (e.g. to warm-start LinSimplex)
+type SimplexBasis struct {
+	Cols []int // [n] status of variables x: BasisBasic, BasisLower, BasisUpper or BasisZero
+	Rows []int // [m] status of row activities aᵢᵀx: BasisBasic, BasisLower, BasisUpper or BasisZero
+}
+
+// LinSimplex implements the bounded revised dual simplex method for linear programs in general form
+//  Solve:
+//          min cᵀx + c0   s.t.   lr ≤ A x ≤ ur,   l ≤ x ≤ u
+//           x
+//
+//  where lr and ur are the bounds of constraints defined by the kinds and ranges of LinProb.
+//  The computational form [A -I] [x; r] = 0 is used, where r = A x are the row activities
+//  (logical variables), such that the initial basis (slack basis) is B = -I.
+//
+//  NOTE: (1) the basis matrix is factorised by sparse LU (Markowitz) with product-form updates
+//        (2) non-basic variables with infinite bounds that are dual infeasible are placed at
+//            artificial bounds ±BigBound which are removed after the dual simplex converges
+//        (3) warm starts are available by means of SetBasis; e.g. to solve again after adding
+//            constraints or changing bounds
+type LinSimplex struct {
+
+	// problem
+	Prob *LinProb // general problem
+
+	// constants
+	Pricing  int     // pricing rule: SimplexSteepest, SimplexDantzig or SimplexBland
+	NmaxIt   int     // max number of iterations
+	TolP     float64 // primal feasibility tolerance
+	TolD     float64 // dual feasibility tolerance
+	TolPiv   float64 // pivot tolerance
+	Nrefact  int     // number of basis updates before refactorisation
+	BigBound float64 // artificial bound for dual infeasible variables with infinite bounds
+	Verbose  bool    // show messages
+
+	// results
+	Status int       // SimplexOptimal, SimplexInfeasible, SimplexUnbounded or SimplexUnsolved
+	X      la.Vector // [n] solution
+	R      la.Vector // [m] row activities A x
+	L      la.Vector // [m] multipliers of constraints (∂f/∂bᵢ)
+	Z      la.Vector // [n] reduced costs c - Aᵀλ
+	F      float64   // objective value cᵀx + c0
+	Nit    int       // number of iterations of last Solve
+	Nfact  int       // number of factorisations of last Solve
+
+	// cancellation and progress report
+	Monitor utl.Monitor // residual = sum of primal infeasibilities
+
+	// internal
+	m, n   int        // number of constraints and variables
+	ap, ai []int      // A in compressed-column format
+	ax     []float64  // A in compressed-column format
+	σ      float64    // sign of objective function (-1 if maximising)
+	lb, ub []float64  // [n+m] bounds
+	cost   []float64  // [n+m] costs
+	x      []float64  // [n+m] values of variables
+	d      []float64  // [n+m] reduced costs
+	state  []int      // [n+m] status of variables
+	art    []bool     // [n+m] variable is at artificial bound
+	head   []int      // [m] variable at each position of basis
+	pos    []int      // [n+m] position of variable in basis or -1
+	w      []float64  // [m] dual steepest-edge weights
+	bmat   la.Triplet // basis matrix; i.e. the basic columns of [A -I]
+	lu     basisLU    // factorisation of basis
+}
+
+// NewLinSimplex returns a new dual simplex solver with the slack basis
+func NewLinSimplex(prob *LinProb) (o *LinSimplex) {
+
+	// problem
+	o = new(LinSimplex)
+	o.Prob = prob
+	o.m, o.n, o.ap, o.ai, o.ax = prob.A.Get()
+	if len(prob.B) != o.m || len(prob.C) != o.n {
+		chk.Panic("sizes of b and c must be equal to the number of rows (%d) and columns (%d) of A. %d, %d are incorrect", o.m, o.n, len(prob.B), len(prob.C))
+	}
+
+	// constants
+	o.Pricing = SimplexSteepest
+	o.NmaxIt = 10000
+	o.TolP = 1e-9
+	o.TolD = 1e-9
+	o.TolPiv = 1e-9
+	o.Nrefact = 50
+	o.BigBound = 1e7
+
+	// bounds, costs and slack basis
+	m, n := o.m, o.n
+	o.lb = make([]float64, n+m)
+	o.ub = make([]float64, n+m)
+	o.cost = make([]float64, n+m)
+	o.x = make([]float64, n+m)
+	o.d = make([]float64, n+m)
+	o.state = make([]int, n+m)
+	o.art = make([]bool, n+m)
+	o.head = make([]int, m)
+	o.pos = make([]int, n+m)
+	o.w = make([]float64, m)
+	for j := 0; j < n; j++ {
+		o.state[j], o.pos[j] = BasisLower, -1
+	}
+	for i := 0; i < m; i++ {
+		o.state[n+i], o.pos[n+i], o.head[i] = BasisBasic, i, n+i
+	}
+
+	// results
+	o.X = la.NewVector(n)
+	o.R = la.NewVector(m)
+	o.L = la.NewVector(m)
+	o.Z = la.NewVector(n)
+	return
+}
+
+// load loads bounds and costs from Prob; thus, bounds, costs and right-hand sides may be
+// modified between calls to Solve (but not the matrix A)
+func (o *LinSimplex) load() (err error) {
+	m, n, prob := o.m, o.n, o.Prob
+	o.σ = 1
+	if prob.Maximise {
+		o.σ = -1
+	}
+	for j := 0; j < n; j++ {
+		o.lb[j], o.ub[j] = prob.Lower(j), prob.Upper(j)
+		o.cost[j] = o.σ * prob.C[j]
+		if o.lb[j] > o.ub[j] {
+			return chk.Err("lower bound of variable %d is greater than its upper bound: %g > %g", j, o.lb[j], o.ub[j])
+		}
+	}
+	for i := 0; i < m; i++ {
+		o.lb[n+i], o.ub[n+i] = prob.RowBounds(i)
+	}
+	return
+}
+
+// GetBasis returns the current basis
+func (o *LinSimplex) GetBasis() (b *SimplexBasis) {
+	b = &SimplexBasis{Cols: make([]int, o.n), Rows: make([]int, o.m)}
+	copy(b.Cols, o.state[:o.n])
+	copy(b.Rows, o.state[o.n:])
+	return
+}
+
+// SetBasis sets the initial basis for a warm start
+//  NOTE: the basis may come from a problem with fewer constraints or variables (e.g. in
+//        cutting-plane or branch-and-bound methods); then, the row activities of the new
+//        constraints are basic and the new variables are non-basic. A singular basis is
+//        repaired (see factor)
+func (o *LinSimplex) SetBasis(b *SimplexBasis) (err error) {
+	if len(b.Cols) > o.n || len(b.Rows) > o.m {
+		return chk.Err("basis is larger than the problem: (%d, %d) > (%d, %d)", len(b.Cols), len(b.Rows), o.n, o.m)
+	}
+	state := make([]int, o.n+o.m)
+	for j := 0; j < o.n; j++ {
+		state[j] = BasisLower
+		if j < len(b.Cols) {
+			state[j] = b.Cols[j]
+		}
+	}
+	for i := 0; i < o.m; i++ {
+		state[o.n+i] = BasisBasic
+		if i < len(b.Rows) {
+			state[o.n+i] = b.Rows[i]
+		}
+	}
+	nb := 0
+	for _, s := range state {
+		if s == BasisBasic {
+			nb++
+		}
+	}
+	if nb != o.m {
+		return chk.Err("number of basic variables (%d) must be equal to the number of constraints (%d)", nb, o.m)
+	}
+	copy(o.state, state)
+	p := 0
+	for k, s := range o.state {
+		o.pos[k] = -1
+		if s == BasisBasic {
+			o.head[p], o.pos[k] = k, p
+			p++
+		}
+	}
+	_, err = o.factor()
+	return
+}
+
+// Solve solves the linear program
+func (o *LinSimplex) Solve() (err error) {
+
+	// initialise
+	o.Status, o.Nit, o.Nfact = SimplexUnsolved, 0, 0
+	m, n := o.m, o.n
+	err = o.load()
+	if err != nil {
+		return
+	}
+	for k := range o.art {
+		o.art[k] = false
+	}
+	_, err = o.factor()
+	if err != nil {
+		return
+	}
+	o.duals()
+	o.place()
+	o.primals()
+	o.weights()
+
+	// auxiliary
+	rho := make([]float64, m)      // row r of B⁻¹
+	alphaR := make([]float64, n+m) // row r of B⁻¹ [A -I]
+	alphaQ := make([]float64, m)   // B⁻¹ a_q
+	tau := make([]float64, m)      // B⁻¹ rho
+
+	// message
+	if o.Verbose {
+		io.Pf("%6s%16s%16s\n", "it", "f(x)", "infeasibility")
+	}
+
+	// iterations
+	for {
+
+		// pricing
+		r, δ, sinf := o.price()
+		if o.Verbose {
+			io.Pf("%6d%16.8e%16.8e\n", o.Nit, o.objective(), sinf)
+		}
+		if r < 0 {
+			done, e := o.removeArtificial()
+			if e != nil {
+				o.Status = SimplexUnbounded
+				return e
+			}
+			if done {
+				break
+			}
+			continue
+		}
+		if o.Nit >= o.NmaxIt {
+			return utl.NewIterError(utl.StopMaxIt, "LinSimplex", o.Nit, "iterations did not converge")
+		}
+		err = o.Monitor.Check("LinSimplex", o.Nit, sinf, 0)
+		if err != nil {
+			return
+		}
+
+		// row r of B⁻¹ [A -I]
+		for i := 0; i < m; i++ {
+			rho[i] = 0
+		}
+		rho[r] = 1
+		o.lu.btran(rho)
+		o.rowAlpha(alphaR, rho)
+
+		// ratio test
+		q := o.ratioTest(alphaR, δ)
+		if q < 0 && len(o.lu.etas) > 0 { // check again with fresh factorisation
+			err = o.refactor()
+			if err != nil {
+				return
+			}
+			continue
+		}
+		if q < 0 && math.Abs(δ) < 1e3*o.TolP*(1+math.Abs(o.x[o.head[r]]-δ)) { // round-off: shift bound
+			if δ < 0 {
+				o.lb[o.head[r]] = o.x[o.head[r]]
+			} else {
+				o.ub[o.head[r]] = o.x[o.head[r]]
+			}
+			continue
+		}
+		if q < 0 {
+			o.Status = SimplexInfeasible
+			return chk.Err("LinSimplex: problem is infeasible (constraint or variable %d cannot be satisfied)", o.head[r])
+		}
+
+		// column q of B⁻¹ [A -I]
+		o.column(alphaQ, q)
+		o.lu.ftran(alphaQ)
+		if math.Abs(alphaQ[r]-alphaR[q]) > 1e-7*(1+math.Abs(alphaQ[r])) && len(o.lu.etas) > 0 {
+			err = o.refactor()
+			if err != nil {
+				return
+			}
+			continue
+		}
+
+		// dual update
+		θd := o.d[q] / alphaR[q]
+		for k := 0; k < n+m; k++ {
+			if o.state[k] != BasisBasic {
+				o.d[k] -= θd * alphaR[k]
+			}
+		}
+		p := o.head[r]
+		o.d[q], o.d[p] = 0, -θd
+
+		// primal update
+		bound := o.ub[p]
+		if δ < 0 {
+			bound = o.lb[p]
+		}
+		θp := (o.x[p] - bound) / alphaQ[r]
+		for i := 0; i < m; i++ {
+			o.x[o.head[i]] -= θp * alphaQ[i]
+		}
+		o.x[q] += θp
+		o.x[p] = bound
+
+		// steepest-edge weights
+		if o.Pricing == SimplexSteepest {
+			copy(tau, rho)
+			o.lu.ftran(tau)
+			wr, ar := o.w[r], alphaQ[r]
+			for i := 0; i < m; i++ {
+				if i == r || alphaQ[i] == 0 {
+					continue
+				}
+				κ := alphaQ[i] / ar
+				o.w[i] = max(o.w[i]-2*κ*tau[i]+κ*κ*wr, 1e-4)
+			}
+			o.w[r] = max(wr/(ar*ar), 1e-4)
+		}
+
+		// change basis
+		o.state[q], o.pos[q], o.art[q] = BasisBasic, r, false
+		o.state[p], o.pos[p] = BasisUpper, -1
+		if δ < 0 {
+			o.state[p] = BasisLower
+		}
+		o.head[r] = q
+		o.lu.update(r, alphaQ)
+		o.Nit++
+		if len(o.lu.etas) >= o.Nrefact {
+			err = o.refactor()
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	// results
+	o.Status = SimplexOptimal
+	o.duals()
+	for j := 0; j < n; j++ {
+		o.X[j] = o.x[j]
+		o.Z[j] = o.σ * o.d[j]
+	}
+	for i := 0; i < m; i++ {
+		o.R[i] = o.x[n+i]
+		o.L[i] = o.σ * o.d[n+i]
+	}
+	o.F = la.VecDot(o.Prob.C, o.X) + o.Prob.C0
+	return
+}
+
+// CostRanges computes the ranges of the objective coefficients for which the current
+// (optimal) basis remains optimal
+//  Output:
+//   clo, chi -- [n] lower and upper values of c (±∞ if unbounded)
+func (o *LinSimplex) CostRanges() (clo, chi la.Vector, err error) {
+	if o.Status != SimplexOptimal {
+		return nil, nil, chk.Err("LinSimplex: problem must be solved to optimality first")
+	}
+	m, n := o.m, o.n
+	clo, chi = la.NewVector(n), la.NewVector(n)
+	rho := make([]float64, m)
+	alpha := make([]float64, n+m)
+	for j := 0; j < n; j++ {
+		δmin, δmax := math.Inf(-1), math.Inf(1)
+		dj := o.d[j]
+		switch o.state[j] {
+		case BasisBasic:
+			for i := 0; i < m; i++ {
+				rho[i] = 0
+			}
+			rho[o.pos[j]] = 1
+			o.lu.btran(rho)
+			o.rowAlpha(alpha, rho)
+			for k := 0; k < n+m; k++ {
+				a := alpha[k]
+				if o.state[k] == BasisBasic || o.lb[k] == o.ub[k] || math.Abs(a) < o.TolPiv {
+					continue
+				}
+				t := o.d[k] / a
+				switch {
+				case o.state[k] == BasisZero:
+					δmin, δmax = max(δmin, t), min(δmax, t)
+				case (o.state[k] == BasisLower) == (a > 0):
+					δmax = min(δmax, t)
+				default:
+					δmin = max(δmin, t)
+				}
+			}
+		case BasisLower:
+			if o.lb[j] != o.ub[j] {
+				δmin = -dj
+			}
+		case BasisUpper:
+			if o.lb[j] != o.ub[j] {
+				δmax = -dj
+			}
+		case BasisZero:
+			δmin, δmax = -dj, -dj
+		}
+		c := o.cost[j]
+		if o.σ > 0 {
+			clo[j], chi[j] = c+δmin, c+δmax
+		} else {
+			clo[j], chi[j] = -(c + δmax), -(c + δmin)
+		}
+	}
+	return
+}
+
+// RhsRanges computes the ranges of the right-hand side of constraints for which the current
+// (optimal) basis remains optimal. The bounds of ranged constraints are shifted together
+//  Output:
+//   blo, bhi -- [m] lower and upper values of b (±∞ if unbounded)
+func (o *LinSimplex) RhsRanges() (blo, bhi la.Vector, err error) {
+	if o.Status != SimplexOptimal {
+		return nil, nil, chk.Err("LinSimplex: problem must be solved to optimality first")
+	}
+	m, n := o.m, o.n
+	blo, bhi = la.NewVector(m), la.NewVector(m)
+	beta := make([]float64, m)
+	for i := 0; i < m; i++ {
+		k := n + i
+		δmin, δmax := math.Inf(-1), math.Inf(1)
+		if o.state[k] == BasisBasic {
+			δmin, δmax = o.x[k]-o.ub[k], o.x[k]-o.lb[k]
+		} else {
+			for l := 0; l < m; l++ {
+				beta[l] = 0
+			}
+			beta[i] = 1
+			o.lu.ftran(beta) // Δx_B = δ B⁻¹ eᵢ
+			for p := 0; p < m; p++ {
+				bp := beta[p]
+				if math.Abs(bp) < o.TolPiv {
+					continue
+				}
+				h := o.head[p]
+				t1, t2 := (o.lb[h]-o.x[h])/bp, (o.ub[h]-o.x[h])/bp
+				if bp < 0 {
+					t1, t2 = t2, t1
+				}
+				δmin, δmax = max(δmin, t1), min(δmax, t2)
+			}
+		}
+		blo[i], bhi[i] = o.Prob.B[i]+δmin, o.Prob.B[i]+δmax
+	}
+	return
+}
+
+// factor factorises the basis matrix. A singular basis is repaired by replacing dependent
+// columns by the logical variables of rows that have not been used as pivots; in this case,
+// the replaced variables become non-basic and repaired is true
+func (o *LinSimplex) factor() (repaired bool, err error) {
+	for it := 0; it <= o.m; it++ {
+		o.Nfact++
+		o.basisMatrix()
+		err = o.lu.factor(o.m, &o.bmat)
+		if err == nil {
+			return
+		}
+		p, k := o.lu.sing, -1
+		for _, i := range o.lu.prow[o.lu.rank:] {
+			if o.state[o.n+i] != BasisBasic {
+				k = o.n + i
+				break
+			}
+		}
+		if k < 0 {
+			return
+		}
+		if o.Verbose {
+			io.Pf("basis repair: variable %d replaced by logical variable of row %d\n", o.head[p], k-o.n)
+		}
+		o.state[o.head[p]], o.pos[o.head[p]] = BasisLower, -1
+		o.state[k], o.pos[k], o.head[p] = BasisBasic, p, k
+		repaired = true
+	}
+	return
+}
+
+// basisMatrix assembles the basis matrix with the basic columns of [A -I]
+func (o *LinSimplex) basisMatrix() {
+	nnz := 0
+	for _, k := range o.head {
+		if k >= o.n {
+			nnz++
+		} else {
+			nnz += o.ap[k+1] - o.ap[k]
+		}
+	}
+	if o.bmat.Max() < nnz {
+		o.bmat.Init(o.m, o.m, nnz)
+	}
+	o.bmat.Start()
+	for p, k := range o.head {
+		if k >= o.n {
+			o.bmat.Put(k-o.n, p, -1)
+			continue
+		}
+		for l := o.ap[k]; l < o.ap[k+1]; l++ {
+			o.bmat.Put(o.ai[l], p, o.ax[l])
+		}
+	}
+}
+
+// refactor factorises the basis matrix and recomputes primal and dual values
+func (o *LinSimplex) refactor() (err error) {
+	repaired, err := o.factor()
+	if err != nil {
+		return
+	}
+	o.duals()
+	if repaired {
+		o.place()
+		o.weights()
+	}
+	o.primals()
+	return
+}
+
+// column sets the dense column k of [A -I]
+func (o *LinSimplex) column(x []float64, k int) {
+	for i := range x {
+		x[i] = 0
+	}
+	if k >= o.n {
+		x[k-o.n] = -1
+		return
+	}
+	for l := o.ap[k]; l < o.ap[k+1]; l++ {
+		x[o.ai[l]] = o.ax[l]
+	}
+}
+
+// rowAlpha computes alpha = ρᵀ [A -I] for non-basic variables (zero for basic ones)
+func (o *LinSimplex) rowAlpha(alpha, rho []float64) {
+	n := o.n
+	for j := 0; j < n; j++ {
+		alpha[j] = 0
+		if o.state[j] == BasisBasic {
+			continue
+		}
+		for l := o.ap[j]; l < o.ap[j+1]; l++ {
+			alpha[j] += rho[o.ai[l]] * o.ax[l]
+		}
+	}
+	for i := 0; i < o.m; i++ {
+		alpha[n+i] = 0
+		if o.state[n+i] != BasisBasic {
+			alpha[n+i] = -rho[i]
+		}
+	}
+}
+
+// duals computes the reduced costs d = c - [A -I]ᵀ y with Bᵀ y = c_B
+func (o *LinSimplex) duals() {
+	y := make([]float64, o.m)
+	for p, k := range o.head {
+		y[p] = o.cost[k]
+	}
+	o.lu.btran(y)
+	for j := 0; j < o.n; j++ {
+		o.d[j] = o.cost[j]
+		for l := o.ap[j]; l < o.ap[j+1]; l++ {
+			o.d[j] -= y[o.ai[l]] * o.ax[l]
+		}
+	}
+	for i := 0; i < o.m; i++ {
+		o.d[o.n+i] = y[i]
+	}
+	for _, k := range o.head {
+		o.d[k] = 0
+	}
+}
+
+// place sets the non-basic variables at the bounds that make them dual feasible
+func (o *LinSimplex) place() {
+	for k := 0; k < o.n+o.m; k++ {
+		if o.state[k] == BasisBasic {
+			continue
+		}
+		l, u, dk := o.lb[k], o.ub[k], o.d[k]
+		hasL, hasU := !math.IsInf(l, 0), !math.IsInf(u, 0)
+		switch {
+		case l == u:
+			o.state[k] = BasisLower
+		case dk > o.TolD:
+			o.state[k] = BasisLower
+			o.art[k] = !hasL
+		case dk < -o.TolD:
+			o.state[k] = BasisUpper
+			o.art[k] = !hasU
+		case o.state[k] == BasisLower && hasL, o.state[k] == BasisUpper && hasU:
+		case hasL:
+			o.state[k] = BasisLower
+		case hasU:
+			o.state[k] = BasisUpper
+		default:
+			o.state[k] = BasisZero
+		}
+		o.x[k] = o.boundValue(k)
+	}
+}
+
+// boundValue returns the value of non-basic variable k
+func (o *LinSimplex) boundValue(k int) float64 {
+	switch o.state[k] {
+	case BasisLower:
+		if o.art[k] {
+			return -o.BigBound
+		}
+		return o.lb[k]
+	case BasisUpper:
+		if o.art[k] {
+			return o.BigBound
+		}
+		return o.ub[k]
+	}
+	return 0
+}
+
+// primals computes the basic variables from x_B = -B⁻¹ N x_N
+func (o *LinSimplex) primals() {
+	m, n := o.m, o.n
+	rhs := make([]float64, m)
+	for j := 0; j < n; j++ {
+		if o.state[j] == BasisBasic || o.x[j] == 0 {
+			continue
+		}
+		for l := o.ap[j]; l < o.ap[j+1]; l++ {
+			rhs[o.ai[l]] -= o.ax[l] * o.x[j]
+		}
+	}
+	for i := 0; i < m; i++ {
+		if o.state[n+i] != BasisBasic {
+			rhs[i] += o.x[n+i]
+		}
+	}
+	o.lu.ftran(rhs)
+	for p, k := range o.head {
+		o.x[k] = rhs[p]
+	}
+}
+
+// weights initialises the dual steepest-edge weights; i.e. wᵢ = ‖row i of B⁻¹‖²
+func (o *LinSimplex) weights() {
+	if o.Pricing != SimplexSteepest {
+		return
+	}
+	slack := true
+	for p, k := range o.head {
+		if k != o.n+p {
+			slack = false
+			break
+		}
+	}
+	rho := make([]float64, o.m)
+	for p := 0; p < o.m; p++ {
+		o.w[p] = 1
+		if slack {
+			continue
+		}
+		for i := range rho {
+			rho[i] = 0
+		}
+		rho[p] = 1
+		o.lu.btran(rho)
+		o.w[p] = la.VecDot(rho, rho)
+	}
+}
+
+// price selects the leaving variable
+//  Output:
+//   r    -- position in basis of leaving variable; -1 if primal feasible
+//   δ    -- infeasibility: x - l < 0 if below lower bound or x - u > 0 if above upper bound
+//   sinf -- sum of primal infeasibilities
+func (o *LinSimplex) price() (r int, δ, sinf float64) {
+	r = -1
+	best := 0.0
+	for p, k := range o.head {
+		v, dv := o.x[k], 0.0
+		if v < o.lb[k]-o.TolP*(1+math.Abs(o.lb[k])) {
+			dv = v - o.lb[k]
+		} else if v > o.ub[k]+o.TolP*(1+math.Abs(o.ub[k])) {
+			dv = v - o.ub[k]
+		} else {
+			continue
+		}
+		sinf += math.Abs(dv)
+		var score float64
+		switch o.Pricing {
+		case SimplexBland:
+			if r >= 0 && k > o.head[r] {
+				continue
+			}
+			score = 1
+		case SimplexDantzig:
+			score = math.Abs(dv)
+		default:
+			score = dv * dv / o.w[p]
+		}
+		if r < 0 || score > best || o.Pricing == SimplexBland {
+			r, δ, best = p, dv, score
+		}
+	}
+	return
+}
+
+// ratioTest selects the entering variable by means of Harris' two-pass ratio test (or the
+// smallest index with minimum ratio if Pricing == SimplexBland); returns -1 if no candidate
+func (o *LinSimplex) ratioTest(alphaR []float64, δ float64) (q int) {
+	s := 1.0
+	if δ < 0 {
+		s = -1
+	}
+	candidate := func(k int) (a float64, ok bool) {
+		if o.state[k] == BasisBasic || o.lb[k] == o.ub[k] {
+			return
+		}
+		a = s * alphaR[k]
+		if math.Abs(a) < o.TolPiv {
+			return
+		}
+		switch o.state[k] {
+		case BasisLower:
+			return a, a > 0
+		case BasisUpper:
+			return a, a < 0
+		}
+		return a, true
+	}
+	tmax := math.Inf(1)
+	for k := 0; k < o.n+o.m; k++ {
+		if a, ok := candidate(k); ok {
+			if o.Pricing == SimplexBland {
+				tmax = min(tmax, math.Abs(o.d[k])/math.Abs(a))
+			} else {
+				tmax = min(tmax, (math.Abs(o.d[k])+o.TolD)/math.Abs(a))
+			}
+		}
+	}
+	if o.Pricing == SimplexBland {
+		tmax += 1e-12
+	}
+	q = -1
+	best := 0.0
+	for k := 0; k < o.n+o.m; k++ {
+		a, ok := candidate(k)
+		if ok && math.Abs(o.d[k])/math.Abs(a) <= tmax && math.Abs(a) > best {
+			q, best = k, math.Abs(a)
+		}
+	}
+	if o.Pricing == SimplexBland && q >= 0 {
+		for k := 0; k < q; k++ { // smallest index with an acceptable pivot
+			a, ok := candidate(k)
+			if ok && math.Abs(o.d[k])/math.Abs(a) <= tmax && math.Abs(a) >= 1e-2*best {
+				return k
+			}
+		}
+	}
+	return
+}
+
+// removeArtificial moves the non-basic variables at artificial bounds back to their actual
+// bounds; returns done = true if there were no such variables
+func (o *LinSimplex) removeArtificial() (done bool, err error) {
+	done = true
+	for k := 0; k < o.n+o.m; k++ {
+		if !o.art[k] || o.state[k] == BasisBasic {
+			continue
+		}
+		if math.Abs(o.d[k]) > o.TolD {
+			if k < o.n {
+				return false, chk.Err("LinSimplex: problem is unbounded (variable %d)", k)
+			}
+			return false, chk.Err("LinSimplex: problem is unbounded (constraint %d)", k-o.n)
+		}
+		o.art[k] = false
+		o.state[k] = BasisZero
+		if !math.IsInf(o.lb[k], 0) {
+			o.state[k] = BasisLower
+		} else if !math.IsInf(o.ub[k], 0) {
+			o.state[k] = BasisUpper
+		}
+		o.x[k] = o.boundValue(k)
+		done = false
+	}
+	if !done {
+		o.primals()
+	}
+	return
+}
+
+// objective returns the current value of the objective function
+func (o *LinSimplex) objective() (f float64) {
+	for j := 0; j < o.n; j++ {
+		f += o.Prob.C[j] * o.x[j]
+	}
+	return f + o.Prob.C0
+}
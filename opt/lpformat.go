@@ -0,0 +1,762 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+// ReadLPcplex reads a linear programming problem from a file in CPLEX-LP format
+//  The following sections are recognised (case insensitive):
+//   Minimize|Maximize, Subject To, Bounds, Generals, Binaries and End
+//  Example:
+//   \ comment
+//   Maximize
+//    obj: 2 x + 3 y - z + 1
+//   Subject To
+//    c1: x + y + z <= 10
+//    c2: -2 <= x - y <= 4
+//   Bounds
+//    x <= 4
+//    -inf <= z <= 8
+//   Generals
+//    y
+//   End
+//  NOTE: (1) ranged constraints are given by lower <= expression <= upper
+//        (2) negative upper bounds with no lower bound given set the lower bound to -∞
+func ReadLPcplex(fn string) (prob *LinProb, err error) {
+	b, err := io.ReadFile(fn)
+	if err != nil {
+		return nil, chk.Err("cannot read LP file <%s>:\n%v", fn, err)
+	}
+	return parseLPcplex(string(b))
+}
+
+// WriteLPcplex writes the linear programming problem to a file in CPLEX-LP format
+//  NOTE: names cannot contain spaces or the characters +-<>=:\ or start with a digit or '.'
+func (o *LinProb) WriteLPcplex(fn string) (err error) {
+	buf, err := o.lpBuffer()
+	if err != nil {
+		return
+	}
+	io.WriteFile(fn, buf)
+	return
+}
+
+// kinds of tokens in CPLEX-LP files
+const (
+	lpNum   = iota // number
+	lpName         // name
+	lpOp           // <=, >= or =
+	lpSign         // + or -
+	lpColon        // :
+)
+
+// lpToken holds a token of CPLEX-LP files
+type lpToken struct {
+	kind int     // kind of token
+	str  string  // name or operator
+	val  float64 // number
+	line int     // line number
+}
+
+// lpParser holds data to parse CPLEX-LP files
+type lpParser struct {
+	prob     *LinProb       // problem
+	cols     map[string]int // name => index of variable
+	ai, aj   []int          // indices of non-zero entries in A
+	ax       []float64      // non-zero entries in A
+	lower    []float64      // lower bounds
+	upper    []float64      // upper bounds
+	lowerSet []bool         // lower bound was given
+	integer  []bool         // integrality markers
+	toks     []lpToken      // tokens of current section
+	pos      int            // current token
+}
+
+// parseLPcplex parses the contents of a CPLEX-LP file
+func parseLPcplex(data string) (prob *LinProb, err error) {
+
+	// split sections
+	o := &lpParser{prob: new(LinProb), cols: make(map[string]int)}
+	sections := make(map[string][]lpToken)
+	var order []string
+	section := ""
+	inBlock := false
+	for idx, line := range strings.Split(data, "\n") {
+		lnum := idx + 1
+
+		// comments
+		if inBlock {
+			k := strings.Index(line, "*\\")
+			if k < 0 {
+				continue
+			}
+			line, inBlock = line[k+2:], false
+		}
+		if k := strings.Index(line, "\\*"); k >= 0 && !strings.Contains(line[k:], "*\\") {
+			line, inBlock = line[:k], true
+		}
+		if k := strings.Index(line, "\\"); k >= 0 {
+			line = line[:k]
+		}
+		rest := strings.TrimSpace(line)
+		if rest == "" {
+			continue
+		}
+
+		// section keywords
+		words := strings.Fields(strings.ToLower(rest))
+		key, nw := lpKeyword(words)
+		if key != "" {
+			if key == "unsupported" {
+				return nil, chk.Err("LP: line %d: section %q is not supported", lnum, words[0])
+			}
+			if _, ok := sections[key]; ok && key != "end" {
+				return nil, chk.Err("LP: line %d: section %q is duplicated", lnum, words[0])
+			}
+			if key == "min" || key == "max" {
+				if _, ok := sections["obj"]; ok {
+					return nil, chk.Err("LP: line %d: objective function is duplicated", lnum)
+				}
+				o.prob.Maximise = key == "max"
+				key = "obj"
+			}
+			section = key
+			sections[section] = nil
+			order = append(order, section)
+			if section == "end" {
+				break
+			}
+			for k := 0; k < nw; k++ {
+				rest = strings.TrimSpace(rest[len(strings.Fields(rest)[0]):])
+			}
+		}
+		if rest == "" {
+			continue
+		}
+		if section == "" {
+			return nil, chk.Err("LP: line %d: objective sense (Minimize or Maximize) is required first", lnum)
+		}
+		toks, e := lpLex(rest, lnum)
+		if e != nil {
+			return nil, e
+		}
+		sections[section] = append(sections[section], toks...)
+	}
+	if _, ok := sections["obj"]; !ok {
+		return nil, chk.Err("LP: objective function is missing")
+	}
+
+	// parse sections
+	for _, key := range order {
+		o.toks, o.pos = sections[key], 0
+		switch key {
+		case "obj":
+			err = o.objective()
+		case "st":
+			err = o.constraints()
+		case "bounds":
+			err = o.bounds()
+		case "gen", "bin":
+			err = o.integers(key == "bin")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// results
+	prob = o.prob
+	m, n := len(prob.B), len(prob.C)
+	var T la.Triplet
+	T.Init(m, n, len(o.ax)+1)
+	for k := range o.ax {
+		T.Put(o.ai[k], o.aj[k], o.ax[k])
+	}
+	prob.A = T.ToMatrix(nil)
+	prob.L, prob.U = o.lower, o.upper
+	for _, isint := range o.integer {
+		if isint {
+			prob.Integer = o.integer
+			break
+		}
+	}
+	return
+}
+
+// lpKeyword returns the section corresponding to the first words of a line and the number of
+// words of the keyword; key is empty if the line does not start a section
+func lpKeyword(words []string) (key string, nw int) {
+	switch words[0] {
+	case "minimize", "minimise", "minimum", "min":
+		return "min", 1
+	case "maximize", "maximise", "maximum", "max":
+		return "max", 1
+	case "st", "s.t.", "st.", "subjectto", "suchthat":
+		return "st", 1
+	case "subject", "such":
+		if len(words) > 1 && (words[1] == "to" || words[1] == "that") {
+			return "st", 2
+		}
+	case "bounds", "bound":
+		return "bounds", 1
+	case "general", "generals", "gen", "integer", "integers":
+		return "gen", 1
+	case "binary", "binaries", "bin":
+		return "bin", 1
+	case "semi-continuous", "semis", "semi", "sos":
+		return "unsupported", 1
+	case "end":
+		return "end", 1
+	}
+	return "", 0
+}
+
+// lpLex splits a line into tokens
+func lpLex(s string, lnum int) (toks []lpToken, err error) {
+	isDelim := func(c byte) bool {
+		return strings.IndexByte(" \t\r<>=+-:", c) >= 0
+	}
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '<' || c == '>' || c == '=':
+			j := i + 1
+			for j < len(s) && j < i+2 && strings.IndexByte("<>=", s[j]) >= 0 {
+				j++
+			}
+			op := ""
+			switch s[i:j] {
+			case "<", "<=", "=<":
+				op = "<="
+			case ">", ">=", "=>":
+				op = ">="
+			case "=":
+				op = "="
+			default:
+				return nil, chk.Err("LP: line %d: operator %q is invalid", lnum, s[i:j])
+			}
+			toks = append(toks, lpToken{kind: lpOp, str: op, line: lnum})
+			i = j
+		case c == '+' || c == '-':
+			toks = append(toks, lpToken{kind: lpSign, str: s[i : i+1], line: lnum})
+			i++
+		case c == ':':
+			toks = append(toks, lpToken{kind: lpColon, str: ":", line: lnum})
+			i++
+		case (c >= '0' && c <= '9') || (c == '.' && i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9'):
+			j := i
+			for j < len(s) && ((s[j] >= '0' && s[j] <= '9') || s[j] == '.') {
+				j++
+			}
+			if j < len(s) && (s[j] == 'e' || s[j] == 'E') {
+				k := j + 1
+				if k < len(s) && (s[k] == '+' || s[k] == '-') {
+					k++
+				}
+				if k < len(s) && s[k] >= '0' && s[k] <= '9' {
+					for j = k; j < len(s) && s[j] >= '0' && s[j] <= '9'; j++ {
+					}
+				}
+			}
+			v, e := strconv.ParseFloat(s[i:j], 64)
+			if e != nil {
+				return nil, chk.Err("LP: line %d: cannot parse number %q", lnum, s[i:j])
+			}
+			toks = append(toks, lpToken{kind: lpNum, val: v, line: lnum})
+			i = j
+		default:
+			j := i
+			for j < len(s) && !isDelim(s[j]) {
+				j++
+			}
+			name := s[i:j]
+			switch strings.ToLower(name) {
+			case "inf", "infinity":
+				toks = append(toks, lpToken{kind: lpNum, val: math.Inf(1), line: lnum})
+			default:
+				toks = append(toks, lpToken{kind: lpName, str: name, line: lnum})
+			}
+			i = j
+		}
+	}
+	return
+}
+
+// column returns the index of variable, adding a new one if necessary
+func (o *lpParser) column(name string) int {
+	j, ok := o.cols[name]
+	if !ok {
+		j = len(o.prob.C)
+		o.cols[name] = j
+		o.prob.ColNames = append(o.prob.ColNames, name)
+		o.prob.C = append(o.prob.C, 0)
+		o.lower = append(o.lower, 0)
+		o.upper = append(o.upper, math.Inf(1))
+		o.lowerSet = append(o.lowerSet, false)
+		o.integer = append(o.integer, false)
+	}
+	return j
+}
+
+// errorf returns an error message with the line number of the current token
+func (o *lpParser) errorf(msg string, prm ...interface{}) error {
+	lnum := 0
+	if o.pos < len(o.toks) {
+		lnum = o.toks[o.pos].line
+	} else if len(o.toks) > 0 {
+		lnum = o.toks[len(o.toks)-1].line
+	}
+	return chk.Err("LP: line %d: "+msg, append([]interface{}{lnum}, prm...)...)
+}
+
+// is tells whether the token at position pos+k has the given kind
+func (o *lpParser) is(k, kind int) bool {
+	return o.pos+k < len(o.toks) && o.toks[o.pos+k].kind == kind
+}
+
+// label reads an optional "name:"
+func (o *lpParser) label() (name string) {
+	if o.is(0, lpName) && o.is(1, lpColon) {
+		name = o.toks[o.pos].str
+		o.pos += 2
+	}
+	return
+}
+
+// signedNumber reads a number preceded by optional signs
+func (o *lpParser) signedNumber() (v float64, ok bool) {
+	k, sign := 0, 1.0
+	for o.is(k, lpSign) {
+		if o.toks[o.pos+k].str == "-" {
+			sign = -sign
+		}
+		k++
+	}
+	if !o.is(k, lpNum) {
+		return
+	}
+	v, ok = sign*o.toks[o.pos+k].val, true
+	o.pos += k + 1
+	return
+}
+
+// expression reads a linear expression until an operator or the end of section
+//  Output:
+//   idx  -- indices of variables
+//   coef -- coefficients
+//   cte  -- constant term
+func (o *lpParser) expression() (idx []int, coef []float64, cte float64, err error) {
+	first := true
+	for o.pos < len(o.toks) && !o.is(0, lpOp) && !(o.is(0, lpName) && o.is(1, lpColon)) {
+		if !first && !o.is(0, lpSign) {
+			return nil, nil, 0, o.errorf("sign (+ or -) is required between terms")
+		}
+		sign := 1.0
+		for o.is(0, lpSign) {
+			if o.toks[o.pos].str == "-" {
+				sign = -sign
+			}
+			o.pos++
+		}
+		v, hasNum := 1.0, false
+		if o.is(0, lpNum) {
+			v, hasNum = o.toks[o.pos].val, true
+			o.pos++
+		}
+		if o.is(0, lpName) && !o.is(1, lpColon) {
+			idx = append(idx, o.column(o.toks[o.pos].str))
+			coef = append(coef, sign*v)
+			o.pos++
+		} else if hasNum {
+			cte += sign * v
+		} else {
+			return nil, nil, 0, o.errorf("term is invalid")
+		}
+		first = false
+	}
+	return
+}
+
+// objective parses the objective function
+func (o *lpParser) objective() (err error) {
+	o.prob.ObjName = o.label()
+	idx, coef, cte, err := o.expression()
+	if err != nil {
+		return
+	}
+	if o.pos < len(o.toks) {
+		return o.errorf("operators are not allowed in objective function")
+	}
+	for k, j := range idx {
+		o.prob.C[j] += coef[k]
+	}
+	o.prob.C0 = cte
+	return
+}
+
+// constraints parses the constraints
+func (o *lpParser) constraints() (err error) {
+	for o.pos < len(o.toks) {
+		name := o.label()
+		if name == "" {
+			name = io.Sf("r%d", len(o.prob.B))
+		}
+
+		// left-hand side number: range or reversed constraint
+		start := o.pos
+		lhs, hasLhs := o.signedNumber()
+		if hasLhs && !o.is(0, lpOp) {
+			hasLhs, o.pos = false, start
+		}
+		var op1 string
+		if hasLhs {
+			op1 = o.toks[o.pos].str
+			o.pos++
+		}
+
+		// expression
+		idx, coef, cte, e := o.expression()
+		if e != nil {
+			return e
+		}
+		if len(idx) == 0 {
+			return o.errorf("constraint %q has no variables", name)
+		}
+
+		// right-hand side
+		lo, hi := math.Inf(-1), math.Inf(1)
+		setBounds := func(op string, v float64, varLeft bool) {
+			if !varLeft {
+				switch op {
+				case "<=":
+					op = ">="
+				case ">=":
+					op = "<="
+				}
+			}
+			switch op {
+			case "<=":
+				hi = v - cte
+			case ">=":
+				lo = v - cte
+			default:
+				lo, hi = v-cte, v-cte
+			}
+		}
+		if hasLhs {
+			setBounds(op1, lhs, false)
+		}
+		if o.is(0, lpOp) {
+			op2 := o.toks[o.pos].str
+			o.pos++
+			rhs, ok := o.signedNumber()
+			if !ok {
+				return o.errorf("right-hand side of constraint %q must be a number", name)
+			}
+			if hasLhs && (op1 == "=" || op2 == "=" || op1 != op2) {
+				return o.errorf("ranged constraint %q must have the form lower <= expression <= upper", name)
+			}
+			setBounds(op2, rhs, true)
+		} else if !hasLhs {
+			return o.errorf("operator is missing in constraint %q", name)
+		}
+		if lo > hi {
+			return o.errorf("bounds of constraint %q are inconsistent", name)
+		}
+
+		// new row
+		i := len(o.prob.B)
+		for k, j := range idx {
+			o.ai, o.aj, o.ax = append(o.ai, i), append(o.aj, j), append(o.ax, coef[k])
+		}
+		o.prob.RowNames = append(o.prob.RowNames, name)
+		var kind int
+		var b, r float64
+		switch {
+		case lo == hi:
+			kind, b = LinEq, lo
+		case math.IsInf(lo, 0) && math.IsInf(hi, 0):
+			return o.errorf("bounds of constraint %q are infinite", name)
+		case math.IsInf(lo, 0):
+			kind, b = LinLe, hi
+		case math.IsInf(hi, 0):
+			kind, b = LinGe, lo
+		default:
+			kind, b, r = LinGe, lo, hi-lo
+			if o.prob.R == nil {
+				o.prob.R = make([]float64, i)
+			}
+		}
+		o.prob.B = append(o.prob.B, b)
+		o.prob.Kind = append(o.prob.Kind, kind)
+		if o.prob.R != nil {
+			o.prob.R = append(o.prob.R, r)
+		}
+	}
+	return
+}
+
+// bounds parses the bounds on variables
+func (o *lpParser) bounds() (err error) {
+	set := func(j int, op string, v float64, varLeft bool) {
+		if !varLeft {
+			switch op {
+			case "<=":
+				op = ">="
+			case ">=":
+				op = "<="
+			}
+		}
+		switch op {
+		case "<=":
+			o.upper[j] = v
+			if v < 0 && o.lower[j] == 0 && !o.lowerSet[j] {
+				o.lower[j] = math.Inf(-1)
+			}
+		case ">=":
+			o.lower[j], o.lowerSet[j] = v, true
+		default:
+			o.lower[j], o.upper[j], o.lowerSet[j] = v, v, true
+		}
+	}
+	for o.pos < len(o.toks) {
+
+		// x free
+		if o.is(0, lpName) && o.is(1, lpName) && strings.ToLower(o.toks[o.pos+1].str) == "free" {
+			j := o.column(o.toks[o.pos].str)
+			o.lower[j], o.upper[j], o.lowerSet[j] = math.Inf(-1), math.Inf(1), true
+			o.pos += 2
+			continue
+		}
+
+		// v op x [op v]
+		if v, ok := o.signedNumber(); ok {
+			if !o.is(0, lpOp) || !o.is(1, lpName) {
+				return o.errorf("bound must have the form value op name [op value]")
+			}
+			op := o.toks[o.pos].str
+			j := o.column(o.toks[o.pos+1].str)
+			o.pos += 2
+			set(j, op, v, false)
+			if o.is(0, lpOp) {
+				op2 := o.toks[o.pos].str
+				o.pos++
+				v2, ok := o.signedNumber()
+				if !ok || op2 == "=" || op2 != op {
+					return o.errorf("bound must have the form lower <= name <= upper")
+				}
+				set(j, op2, v2, true)
+			}
+			continue
+		}
+
+		// x op v
+		if !o.is(0, lpName) || !o.is(1, lpOp) {
+			return o.errorf("bound is invalid")
+		}
+		j := o.column(o.toks[o.pos].str)
+		op := o.toks[o.pos+1].str
+		o.pos += 2
+		v, ok := o.signedNumber()
+		if !ok {
+			return o.errorf("bound must have the form name op value")
+		}
+		set(j, op, v, true)
+	}
+	return
+}
+
+// integers parses the list of integer (or binary) variables
+func (o *lpParser) integers(binary bool) (err error) {
+	for ; o.pos < len(o.toks); o.pos++ {
+		if !o.is(0, lpName) {
+			return o.errorf("list of names is required for integer variables")
+		}
+		j := o.column(o.toks[o.pos].str)
+		o.integer[j] = true
+		if binary {
+			o.lower[j], o.upper[j], o.lowerSet[j] = 0, 1, true
+		}
+	}
+	return
+}
+
+// lpBuffer writes CPLEX-LP file into buffer
+func (o *LinProb) lpBuffer() (buf *bytes.Buffer, err error) {
+
+	// check names
+	m, n, Ap, Ai, Ax := o.A.Get()
+	check := func(name string) error {
+		if name == "" || strings.ContainsAny(name, " \t\\+-<>=:") || strings.IndexAny(name[:1], "0123456789.") == 0 {
+			return chk.Err("LP: name %q is invalid", name)
+		}
+		switch strings.ToLower(name) {
+		case "inf", "infinity", "free":
+			return chk.Err("LP: name %q is reserved", name)
+		}
+		return nil
+	}
+	if err = check(o.GetObjName()); err != nil {
+		return
+	}
+	for i := 0; i < m; i++ {
+		if err = check(o.RowName(i)); err != nil {
+			return
+		}
+	}
+	for j := 0; j < n; j++ {
+		if err = check(o.ColName(j)); err != nil {
+			return
+		}
+	}
+
+	// formatting
+	num := func(v float64) string {
+		switch {
+		case math.IsInf(v, 1):
+			return "inf"
+		case math.IsInf(v, -1):
+			return "-inf"
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	buf = new(bytes.Buffer)
+	terms := func(idx []int, coef []float64) {
+		if len(idx) == 0 && n > 0 {
+			idx, coef = []int{0}, []float64{0}
+		}
+		for k, j := range idx {
+			if k > 0 && k%8 == 0 {
+				io.Ff(buf, "\n   ")
+			}
+			v := coef[k]
+			if v < 0 {
+				io.Ff(buf, " -")
+				v = -v
+			} else if k > 0 {
+				io.Ff(buf, " +")
+			}
+			if v != 1 {
+				io.Ff(buf, " %s", num(v))
+			}
+			io.Ff(buf, " %s", o.ColName(j))
+		}
+	}
+
+	// objective function
+	if o.Name != "" {
+		io.Ff(buf, "\\ Problem name: %s\n", o.Name)
+	}
+	if o.Maximise {
+		io.Ff(buf, "Maximize\n")
+	} else {
+		io.Ff(buf, "Minimize\n")
+	}
+	io.Ff(buf, " %s:", o.GetObjName())
+	var idx []int
+	var coef []float64
+	for j := 0; j < n; j++ {
+		if o.C[j] != 0 {
+			idx, coef = append(idx, j), append(coef, o.C[j])
+		}
+	}
+	terms(idx, coef)
+	if o.C0 != 0 {
+		if o.C0 < 0 {
+			io.Ff(buf, " - %s", num(-o.C0))
+		} else {
+			io.Ff(buf, " + %s", num(o.C0))
+		}
+	}
+	io.Ff(buf, "\n")
+
+	// constraints
+	rowIdx := make([][]int, m)
+	rowCoef := make([][]float64, m)
+	for j := 0; j < n; j++ {
+		for k := Ap[j]; k < Ap[j+1]; k++ {
+			rowIdx[Ai[k]] = append(rowIdx[Ai[k]], j)
+			rowCoef[Ai[k]] = append(rowCoef[Ai[k]], Ax[k])
+		}
+	}
+	io.Ff(buf, "Subject To\n")
+	for i := 0; i < m; i++ {
+		lo, hi := o.RowBounds(i)
+		io.Ff(buf, " %s:", o.RowName(i))
+		if lo != hi && !math.IsInf(lo, 0) && !math.IsInf(hi, 0) {
+			io.Ff(buf, " %s <=", num(lo))
+		}
+		terms(rowIdx[i], rowCoef[i])
+		switch {
+		case lo == hi:
+			io.Ff(buf, " = %s\n", num(hi))
+		case math.IsInf(lo, 0):
+			io.Ff(buf, " <= %s\n", num(hi))
+		case math.IsInf(hi, 0):
+			io.Ff(buf, " >= %s\n", num(lo))
+		default:
+			io.Ff(buf, " <= %s\n", num(hi))
+		}
+	}
+
+	// bounds
+	io.Ff(buf, "Bounds\n")
+	var gen, bin []string
+	for j := 0; j < n; j++ {
+		l, u := o.Lower(j), o.Upper(j)
+		name := o.ColName(j)
+		if o.IsInteger(j) {
+			if l == 0 && u == 1 {
+				bin = append(bin, name)
+				continue
+			}
+			gen = append(gen, name)
+		}
+		switch {
+		case l == u:
+			io.Ff(buf, " %s = %s\n", name, num(l))
+		case math.IsInf(l, 0) && math.IsInf(u, 0):
+			io.Ff(buf, " %s free\n", name)
+		case math.IsInf(u, 0):
+			if l != 0 {
+				io.Ff(buf, " %s >= %s\n", name, num(l))
+			}
+		case l == 0 && u >= 0:
+			io.Ff(buf, " %s <= %s\n", name, num(u))
+		default:
+			io.Ff(buf, " %s <= %s <= %s\n", num(l), name, num(u))
+		}
+	}
+
+	// integers
+	list := func(title string, names []string) {
+		if len(names) == 0 {
+			return
+		}
+		io.Ff(buf, "%s\n", title)
+		for k, name := range names {
+			if k > 0 && k%8 == 0 {
+				io.Ff(buf, "\n")
+			}
+			io.Ff(buf, " %s", name)
+		}
+		io.Ff(buf, "\n")
+	}
+	list("Generals", gen)
+	list("Binaries", bin)
+	io.Ff(buf, "End\n")
+	return
+}
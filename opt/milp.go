@@ -0,0 +1,462 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+// node selection rules for Milp
+const (
+	MilpBestFirst  = iota // node with the best (lowest) bound (default)
+	MilpDepthFirst        // last created node
+)
+
+// branching rules for Milp
+const (
+	MilpPseudoCost     = iota // pseudo-costs (product score); most fractional while not initialised (default)
+	MilpMostFractional        // variable with fractional part closest to 0.5
+)
+
+// status of solution computed by Milp
+const (
+	MilpUnsolved   = iota // not solved yet or stopped without feasible solution
+	MilpOptimal           // optimal solution found (within the gap tolerances)
+	MilpFeasible          // stopped because of limits with a feasible solution
+	MilpInfeasible        // problem is infeasible
+	MilpUnbounded         // LP relaxation is unbounded
+)
+
+// MilpIncumbentCb defines a function that is called when a new incumbent (best integer
+// solution) is found. Returning stop = true stops the solver
+//  Input:
+//   x     -- solution
+//   f     -- objective value
+//   bound -- current best bound of the objective value
+type MilpIncumbentCb func(x la.Vector, f, bound float64) (stop bool)
+
+// Milp implements the branch-and-bound method for mixed-integer linear programs
+//  Solve:
+//          min cᵀx + c0   s.t.   lr ≤ A x ≤ ur,   l ≤ x ≤ u,   xⱼ integer if Integer[j]
+//           x
+//
+//  NOTE: (1) the LP relaxations are solved by LinSimplex, where each node starts from the
+//            optimal basis of its parent (warm start)
+//        (2) the gap is (f_incumbent - bound) / max(1, |f_incumbent|) for minimisation problems
+type Milp struct {
+
+	// problem
+	Prob *LinProb // general problem with integrality markers
+
+	// constants
+	NodeSel   int             // node selection: MilpBestFirst or MilpDepthFirst
+	Branching int             // branching rule: MilpPseudoCost or MilpMostFractional
+	Rounding  bool            // use rounding heuristic to find incumbents
+	TolInt    float64         // integrality tolerance
+	GapRel    float64         // relative gap tolerance
+	GapAbs    float64         // absolute gap tolerance
+	NmaxNodes int             // max number of nodes
+	TimeLimit time.Duration   // time limit (0 ⇒ no limit)
+	Verbose   bool            // show messages
+	Incumbent MilpIncumbentCb // callback for new incumbents [may be nil]
+
+	// results
+	Status int       // MilpOptimal, MilpFeasible, MilpInfeasible, MilpUnbounded or MilpUnsolved
+	X      la.Vector // [n] best integer solution
+	F      float64   // objective value of best integer solution
+	Bound  float64   // best bound of the objective value
+	Gap    float64   // relative gap
+	Nodes  int       // number of solved nodes
+	LpIt   int       // total number of simplex iterations
+
+	// cancellation and progress report
+	Monitor utl.Monitor // residual = relative gap
+
+	// internal
+	lp     LinProb      // LP relaxation with bounds of current node
+	lps    *LinSimplex  // LP solver
+	σ      float64      // sign of objective function (-1 if maximising)
+	fbest  float64      // objective value (minimisation) of incumbent
+	nodes  []*milpNode  // open nodes
+	ψ      [2][]float64 // pseudo-costs (down, up)
+	nψ     [2][]int     // number of observations of pseudo-costs (down, up)
+	t0     time.Time    // starting time
+	stop   bool         // stopped by callback
+	ints   []int        // indices of integer variables
+	xround []float64    // rounded solution
+}
+
+// milpNode holds a node of the branch-and-bound tree
+type milpNode struct {
+	l, u  []float64     // bounds of variables
+	basis *SimplexBasis // optimal basis of parent
+	bound float64       // objective value (minimisation) of parent
+	depth int           // depth in tree
+	bvar  int           // branching variable (-1 for root)
+	up    bool          // up branch
+	frac  float64       // change of branching variable
+}
+
+// NewMilp returns a new branch-and-bound solver
+func NewMilp(prob *LinProb) (o *Milp) {
+	o = new(Milp)
+	o.Prob = prob
+	o.NodeSel = MilpBestFirst
+	o.Branching = MilpPseudoCost
+	o.Rounding = true
+	o.TolInt = 1e-6
+	o.GapRel = 1e-6
+	o.GapAbs = 1e-9
+	o.NmaxNodes = 100000
+	n := len(prob.C)
+	for j := 0; j < n; j++ {
+		if prob.IsInteger(j) {
+			o.ints = append(o.ints, j)
+		}
+	}
+	o.X = la.NewVector(n)
+	o.xround = make([]float64, n)
+	for k := 0; k < 2; k++ {
+		o.ψ[k] = make([]float64, n)
+		o.nψ[k] = make([]int, n)
+	}
+	o.lp = *prob
+	o.lp.L = make([]float64, n)
+	o.lp.U = make([]float64, n)
+	o.lps = NewLinSimplex(&o.lp)
+	return
+}
+
+// Solve solves the mixed-integer linear program
+func (o *Milp) Solve() (err error) {
+
+	// initialise
+	o.Status, o.Nodes, o.LpIt, o.stop = MilpUnsolved, 0, 0, false
+	o.t0 = time.Now()
+	o.σ = 1
+	if o.Prob.Maximise {
+		o.σ = -1
+	}
+	o.fbest = math.Inf(1)
+	o.Bound = math.Inf(-1)
+
+	// root node
+	n := len(o.Prob.C)
+	root := &milpNode{l: make([]float64, n), u: make([]float64, n), bvar: -1, bound: math.Inf(-1)}
+	for j := 0; j < n; j++ {
+		root.l[j], root.u[j] = o.Prob.Lower(j), o.Prob.Upper(j)
+		if o.Prob.IsInteger(j) {
+			root.l[j], root.u[j] = math.Ceil(root.l[j]-o.TolInt), math.Floor(root.u[j]+o.TolInt)
+		}
+		if root.l[j] > root.u[j] {
+			o.Status = MilpInfeasible
+			return chk.Err("Milp: problem is infeasible (bounds of variable %d)", j)
+		}
+	}
+	o.nodes = []*milpNode{root}
+
+	// message
+	if o.Verbose {
+		io.Pf("%8s%8s%16s%16s%12s\n", "node", "open", "incumbent", "bound", "gap")
+	}
+
+	// branch-and-bound
+	for len(o.nodes) > 0 {
+
+		// check termination
+		o.updateBound()
+		if o.converged() {
+			break
+		}
+		if o.Verbose && (o.Nodes%100 == 0) {
+			io.Pf("%8d%8d%16.8e%16.8e%12.4e\n", o.Nodes, len(o.nodes), o.σ*o.fbest, o.Bound, o.Gap)
+		}
+		if o.Nodes >= o.NmaxNodes {
+			return o.limit(utl.NewIterError(utl.StopMaxIt, "Milp", o.Nodes, "max number of nodes reached"))
+		}
+		if o.TimeLimit > 0 && time.Since(o.t0) > o.TimeLimit {
+			e := utl.NewIterError(utl.StopCancelled, "Milp", o.Nodes, "time limit reached")
+			e.Err = context.DeadlineExceeded
+			return o.limit(e)
+		}
+		err = o.Monitor.Check("Milp", o.Nodes, o.Gap, 0)
+		if err != nil {
+			return o.limit(err)
+		}
+
+		// select and solve node
+		node := o.selectNode()
+		if node.bound >= o.cutoff() {
+			continue
+		}
+		err = o.solveNode(node)
+		if err != nil {
+			return
+		}
+		if o.stop {
+			o.updateBound()
+			o.Status = MilpFeasible
+			return
+		}
+	}
+
+	// results
+	o.updateBound()
+	if math.IsInf(o.fbest, 1) {
+		o.Status = MilpInfeasible
+		return chk.Err("Milp: problem is infeasible (%d nodes)", o.Nodes)
+	}
+	o.Status = MilpOptimal
+	return
+}
+
+// SolveCtx solves the mixed-integer linear program and stops with an error if ctx is cancelled
+func (o *Milp) SolveCtx(ctx context.Context) (err error) {
+	o.Monitor.Ctx = ctx
+	defer func() { o.Monitor.Ctx = nil }()
+	return o.Solve()
+}
+
+// solveNode solves the LP relaxation of a node and branches
+func (o *Milp) solveNode(node *milpNode) (err error) {
+
+	// solve LP relaxation
+	copy(o.lp.L, node.l)
+	copy(o.lp.U, node.u)
+	if node.basis != nil {
+		err = o.lps.SetBasis(node.basis)
+		if err != nil {
+			return
+		}
+	}
+	err = o.lps.Solve()
+	o.Nodes++
+	o.LpIt += o.lps.Nit
+	switch o.lps.Status {
+	case SimplexInfeasible:
+		return nil
+	case SimplexUnbounded:
+		if node.bvar < 0 {
+			o.Status = MilpUnbounded
+			return chk.Err("Milp: LP relaxation is unbounded")
+		}
+		return nil
+	}
+	if err != nil {
+		return
+	}
+	g := o.σ * o.lps.F
+
+	// pseudo-costs
+	if node.bvar >= 0 && node.frac > 0 {
+		k := 0
+		if node.up {
+			k = 1
+		}
+		j, gain := node.bvar, max(g-node.bound, 0)/node.frac
+		o.ψ[k][j] = (o.ψ[k][j]*float64(o.nψ[k][j]) + gain) / float64(o.nψ[k][j]+1)
+		o.nψ[k][j]++
+	}
+	if g >= o.cutoff() {
+		return
+	}
+
+	// integer solution
+	x := o.lps.X
+	jb := o.selectVar(x)
+	if jb < 0 {
+		copy(o.xround, x)
+		for _, j := range o.ints {
+			o.xround[j] = math.Round(x[j])
+		}
+		o.newIncumbent(o.xround)
+		return
+	}
+
+	// rounding heuristic
+	if o.Rounding {
+		o.roundingHeuristic(x)
+		if o.stop {
+			return
+		}
+	}
+
+	// branch
+	basis := o.lps.GetBasis()
+	fl := math.Floor(x[jb])
+	down := &milpNode{l: node.l, u: make([]float64, len(node.u)), basis: basis, bound: g, depth: node.depth + 1, bvar: jb, frac: x[jb] - fl}
+	copy(down.u, node.u)
+	down.u[jb] = fl
+	up := &milpNode{l: make([]float64, len(node.l)), u: node.u, basis: basis, bound: g, depth: node.depth + 1, bvar: jb, up: true, frac: fl + 1 - x[jb]}
+	copy(up.l, node.l)
+	up.l[jb] = fl + 1
+	if down.frac < 0.5 { // the last node is the first one selected in depth-first search
+		o.nodes = append(o.nodes, up, down)
+	} else {
+		o.nodes = append(o.nodes, down, up)
+	}
+	return
+}
+
+// selectNode removes the next node from the list of open nodes
+func (o *Milp) selectNode() (node *milpNode) {
+	k := len(o.nodes) - 1
+	if o.NodeSel == MilpBestFirst {
+		for i, nd := range o.nodes {
+			if nd.bound < o.nodes[k].bound || (nd.bound == o.nodes[k].bound && nd.depth > o.nodes[k].depth) {
+				k = i
+			}
+		}
+	}
+	node = o.nodes[k]
+	o.nodes[k] = o.nodes[len(o.nodes)-1]
+	o.nodes = o.nodes[:len(o.nodes)-1]
+	return
+}
+
+// selectVar selects the branching variable; returns -1 if all integer variables are integral
+func (o *Milp) selectVar(x []float64) (jb int) {
+
+	// average pseudo-costs used for variables without observations
+	var ave [2]float64
+	for k := 0; k < 2; k++ {
+		sum, cnt := 0.0, 0
+		for _, j := range o.ints {
+			if o.nψ[k][j] > 0 {
+				sum += o.ψ[k][j]
+				cnt++
+			}
+		}
+		ave[k] = 1
+		if cnt > 0 {
+			ave[k] = sum / float64(cnt)
+		}
+	}
+
+	// select
+	jb = -1
+	best := -1.0
+	for _, j := range o.ints {
+		f := x[j] - math.Floor(x[j])
+		if f < o.TolInt || f > 1-o.TolInt {
+			continue
+		}
+		var score float64
+		if o.Branching == MilpPseudoCost {
+			var ψ [2]float64
+			for k := 0; k < 2; k++ {
+				ψ[k] = ave[k]
+				if o.nψ[k][j] > 0 {
+					ψ[k] = o.ψ[k][j]
+				}
+			}
+			score = max(ψ[0]*f, 1e-6) * max(ψ[1]*(1-f), 1e-6)
+		} else {
+			score = min(f, 1-f)
+		}
+		if score > best {
+			jb, best = j, score
+		}
+	}
+	return
+}
+
+// roundingHeuristic rounds the integer variables of an LP solution to the nearest integers and
+// accepts the result if it is feasible
+func (o *Milp) roundingHeuristic(x []float64) {
+	copy(o.xround, x)
+	for _, j := range o.ints {
+		o.xround[j] = math.Round(x[j])
+		if o.xround[j] < o.lp.L[j] || o.xround[j] > o.lp.U[j] {
+			return
+		}
+	}
+	f := o.σ * o.objective(o.xround)
+	if f >= o.fbest {
+		return
+	}
+	r := make([]float64, len(o.Prob.B))
+	la.SpMatVecMul(r, 1, o.Prob.A, o.xround)
+	for i, ri := range r {
+		lo, hi := o.Prob.RowBounds(i)
+		tol := 1e-7 * (1 + math.Abs(ri))
+		if ri < lo-tol || ri > hi+tol {
+			return
+		}
+	}
+	o.newIncumbent(o.xround)
+}
+
+// newIncumbent sets a new best integer solution
+func (o *Milp) newIncumbent(x []float64) {
+	f := o.objective(x)
+	if o.σ*f >= o.fbest {
+		return
+	}
+	o.fbest = o.σ * f
+	copy(o.X, x)
+	o.F = f
+	o.updateBound()
+	if o.Verbose {
+		io.Pf("%8d%8d%16.8e%16.8e%12.4e *\n", o.Nodes, len(o.nodes), o.F, o.Bound, o.Gap)
+	}
+	if o.Incumbent != nil {
+		o.stop = o.Incumbent(o.X, o.F, o.Bound)
+	}
+}
+
+// objective computes cᵀx + c0
+func (o *Milp) objective(x []float64) float64 {
+	return la.VecDot(o.Prob.C, x) + o.Prob.C0
+}
+
+// cutoff returns the objective value (minimisation) above which nodes are pruned
+func (o *Milp) cutoff() float64 {
+	if math.IsInf(o.fbest, 1) {
+		return o.fbest
+	}
+	return o.fbest - max(o.GapAbs, o.GapRel*max(1, math.Abs(o.fbest)))
+}
+
+// updateBound computes the best bound (in the sense of the problem) and the gap
+func (o *Milp) updateBound() {
+	bound := o.fbest
+	for _, nd := range o.nodes {
+		bound = min(bound, nd.bound)
+	}
+	if len(o.nodes) == 0 && math.IsInf(o.fbest, 1) {
+		bound = math.Inf(1)
+	}
+	o.Bound = o.σ * bound
+	o.Gap = math.Inf(1)
+	if !math.IsInf(o.fbest, 1) {
+		o.Gap = max(o.fbest-bound, 0) / max(1, math.Abs(o.fbest))
+	}
+}
+
+// converged checks whether the gap is closed
+func (o *Milp) converged() bool {
+	if math.IsInf(o.fbest, 1) {
+		return false
+	}
+	bound := o.σ * o.Bound
+	return o.fbest-bound <= o.GapAbs || o.Gap <= o.GapRel
+}
+
+// limit sets the status after the solver has been stopped by limits or cancellation
+func (o *Milp) limit(e error) error {
+	o.updateBound()
+	if !math.IsInf(o.fbest, 1) {
+		o.Status = MilpFeasible
+	}
+	return e
+}
@@ -0,0 +1,471 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+// methods for unconstrained (or bound-constrained) minimisation
+const (
+	MinBFGS       = iota // quasi-Newton BFGS with dense inverse Hessian approximation
+	MinLBFGS             // limited-memory BFGS
+	MinCGFR              // nonlinear conjugate gradients with Fletcher–Reeves formula
+	MinCGPR              // nonlinear conjugate gradients with Polak–Ribière (PR+) formula
+	MinNewtonCG          // truncated (line-search) Newton method with conjugate gradients
+	MinNelderMead        // derivative-free: Nelder–Mead simplex with adaptive parameters
+	MinPowell            // derivative-free: Powell's conjugate directions
+	MinBobyqa            // derivative-free: trust region with quadratic interpolation models (BOBYQA-style)
+)
+
+// MinResult holds the results of a minimisation
+type MinResult struct {
+	X         la.Vector   // solution
+	F         float64     // f(x)
+	G         la.Vector   // gradient at x (if available)
+	Nit       int         // number of iterations
+	Nfeval    int         // number of calls to f(x) (including finite differences)
+	Ngeval    int         // number of gradient evaluations
+	NHeval    int         // number of Hessian evaluations or Hessian-vector products
+	Converged bool        // tolerances have been satisfied
+	HistF     []float64   // [Nit+1] f(x) at each iteration (including initial point)
+	HistG     []float64   // [Nit+1] |g(x)|∞ at each iteration (including initial point; if available)
+	HistX     []la.Vector // [Nit+1] x at each iteration (including initial point) if Minimizer.Hist
+}
+
+// Minimizer implements methods to find the minimum of unconstrained nonlinear functions
+//  Solve:
+//          min f(x)
+//           x
+//
+//  NOTE: (1) the gradient-based methods use a line search that satisfies the strong Wolfe
+//            conditions (see lineSearch)
+//        (2) if Gfcn is nil, the gradient is computed by central finite differences; if Hfcn
+//            is nil, MinNewtonCG computes Hessian-vector products by finite differences of
+//            the gradient
+//        (3) the derivative-free methods (MinNelderMead, MinPowell and MinBobyqa) do not use
+//            Gfcn; they accept the box bounds Lower ≤ x ≤ Upper, stop when the size of the
+//            simplex, the displacement in one cycle or the trust-region radius is smaller than
+//            Xtol max(1, |x|∞), and are deterministic (no random numbers)
+type Minimizer struct {
+
+	// constants
+	Method   int     // method; e.g. MinBFGS
+	MaxIt    int     // max number of iterations
+	Gtol     float64 // tolerance on |g(x)|∞ for convergence
+	Ftol     float64 // tolerance on the relative change of f for convergence
+	Nhist    int     // number of pairs (s, y) stored in L-BFGS
+	EtaCG    float64 // max forcing term η of Newton-CG: inner iterations stop if |r| ≤ min(η, √|g|) |g|
+	C1       float64 // sufficient decrease coefficient (Wolfe conditions)
+	C2       float64 // curvature coefficient (Wolfe conditions)
+	LsMaxIt  int     // max number of iterations in line search
+	Hfd      float64 // relative step for finite differences
+	Xtol     float64 // tolerance on x for convergence of derivative-free methods
+	Step0    float64 // initial step (simplex size or trust radius) of derivative-free methods [0 ⇒ 0.1 max(1, |x0|∞)]
+	Npts     int     // number of interpolation points in MinBobyqa, within [n+2, (n+1)(n+2)/2] [0 ⇒ 2n+1]
+	MaxFeval int     // max number of function evaluations of derivative-free methods [0 ⇒ unlimited]
+	Hist     bool    // record history of x in results
+	Verbose  bool    // show messages
+
+	// callbacks
+	Ffcn fun.Sv // f(x) function
+	Gfcn fun.Vv // g(x) = df/dx gradient [may be nil]
+	Hfcn fun.Mv // H(x) = d²f/dx² Hessian for MinNewtonCG [may be nil]
+
+	// bounds (derivative-free methods only)
+	Lower la.Vector // lower bounds [may be nil]
+	Upper la.Vector // upper bounds [may be nil]
+
+	// cancellation and progress report
+	Monitor utl.Monitor // residual = |g(x)|∞ or f(x) (derivative-free), step = step length or size
+
+	// internal
+	ndim   int        // dimension
+	res    *MinResult // current results
+	budget bool       // check MaxFeval in fcn (derivative-free methods)
+	x0     la.Vector  // point at start of line search
+	g0     la.Vector  // gradient at start of line search
+	d      la.Vector  // search direction
+	w      la.Vector  // workspace
+}
+
+// NewMinimizer returns a new minimizer
+//  Input:
+//   method -- method; e.g. MinBFGS
+//   ndim   -- dimension of x
+//   ffcn   -- f(x) function
+//   gfcn   -- g(x) gradient function [may be nil]
+func NewMinimizer(method, ndim int, ffcn fun.Sv, gfcn fun.Vv) (o *Minimizer) {
+	o = new(Minimizer)
+	o.Method = method
+	o.MaxIt = 1000
+	o.Gtol = 1e-6
+	o.Ftol = 1e-15
+	o.Nhist = 10
+	o.EtaCG = 0.01
+	o.C1 = 1e-4
+	o.C2 = 0.9
+	if method == MinCGFR || method == MinCGPR {
+		o.C2 = 0.1
+	}
+	o.LsMaxIt = 30
+	o.Hfd = 1e-6
+	o.Xtol = 1e-8
+	o.MaxFeval = 10000 * ndim
+	o.Ffcn = ffcn
+	o.Gfcn = gfcn
+	o.ndim = ndim
+	o.x0 = la.NewVector(ndim)
+	o.g0 = la.NewVector(ndim)
+	o.d = la.NewVector(ndim)
+	o.w = la.NewVector(ndim)
+	return
+}
+
+// Min finds the minimum of f(x) starting from x0 (which is not modified)
+func (o *Minimizer) Min(x0 la.Vector) (res *MinResult, err error) {
+	if len(x0) != o.ndim {
+		return nil, chk.Err("size of x0 must be equal to %d. %d is incorrect", o.ndim, len(x0))
+	}
+	o.res = &MinResult{X: x0.GetCopy(), G: la.NewVector(o.ndim)}
+	res = o.res
+	o.budget = false
+	switch o.Method {
+	case MinBFGS, MinLBFGS, MinCGFR, MinCGPR, MinNewtonCG:
+		if o.Lower != nil || o.Upper != nil {
+			return res, chk.Err("bounds can only be used with derivative-free methods")
+		}
+		err = o.gradientBased()
+	case MinNelderMead, MinPowell, MinBobyqa:
+		res.G = nil
+		o.budget = o.MaxFeval > 0
+		if (o.Lower != nil && len(o.Lower) != o.ndim) || (o.Upper != nil && len(o.Upper) != o.ndim) {
+			return res, chk.Err("size of bounds must be equal to %d", o.ndim)
+		}
+		if o.Verbose {
+			io.Pf("%6s%8s%23s%14s\n", "it", "nfeval", "f(x)", "size")
+		}
+		switch o.Method {
+		case MinNelderMead:
+			err = o.nelderMead()
+		case MinPowell:
+			err = o.powell()
+		default:
+			err = o.bobyqa()
+		}
+	default:
+		err = chk.Err("minimisation method %d is not available", o.Method)
+	}
+	return
+}
+
+// gradientBased implements the gradient-based methods
+func (o *Minimizer) gradientBased() (err error) {
+
+	// initial point
+	n, res := o.ndim, o.res
+	x, g := res.X, res.G
+	res.F, err = o.fcn(x)
+	if err != nil {
+		return
+	}
+	err = o.grad(g, x)
+	if err != nil {
+		return
+	}
+	o.record()
+
+	// data for methods
+	var H *la.Matrix              // BFGS: inverse Hessian approximation
+	var ss, ys []la.Vector        // L-BFGS: pairs (s, y)
+	var ρs []float64              // L-BFGS: 1/(yᵀs)
+	var dold la.Vector            // CG: previous direction
+	var gdold, αold float64       // CG: previous gᵀd and step length
+	var Hx *la.Matrix             // Newton-CG: Hessian
+	var r, p, Hp, z, gp la.Vector // Newton-CG: auxiliary vectors
+	switch o.Method {
+	case MinBFGS:
+		H = la.NewMatrix(n, n)
+		H.SetDiag(1)
+	case MinCGFR, MinCGPR:
+		dold = la.NewVector(n)
+	case MinNewtonCG:
+		if o.Hfcn != nil {
+			Hx = la.NewMatrix(n, n)
+		}
+		r, p, Hp, z, gp = la.NewVector(n), la.NewVector(n), la.NewVector(n), la.NewVector(n), la.NewVector(n)
+	}
+	reset := true // first iteration or direction has been reset to steepest descent
+
+	// message
+	if o.Verbose {
+		io.Pf("%6s%23s%14s%14s\n", "it", "f(x)", "|g|∞", "α")
+	}
+
+	// iterations
+	var α float64
+	for {
+
+		// check convergence
+		gnorm := g.Largest(1)
+		if o.Verbose {
+			io.Pf("%6d%23.15e%14.6e%14.6e\n", res.Nit, res.F, gnorm, α)
+		}
+		if gnorm <= o.Gtol {
+			res.Converged = true
+			return
+		}
+		if res.Nit >= o.MaxIt {
+			return utl.NewIterError(utl.StopMaxIt, "Minimizer", res.Nit, "iterations did not converge")
+		}
+		err = o.Monitor.Check("Minimizer", res.Nit, gnorm, α)
+		if err != nil {
+			return
+		}
+
+		// search direction
+		gnrm2 := g.Norm()
+		α0 := 1.0
+		switch o.Method {
+
+		case MinBFGS: // d = -H g
+			for i := 0; i < n; i++ {
+				o.d[i] = 0
+				for j := 0; j < n; j++ {
+					o.d[i] -= H.Get(i, j) * g[j]
+				}
+			}
+
+		case MinLBFGS: // two-loop recursion
+			m := len(ss)
+			a := make([]float64, m)
+			copy(o.d, g)
+			for k := m - 1; k >= 0; k-- {
+				a[k] = ρs[k] * la.VecDot(ss[k], o.d)
+				la.VecAdd(o.d, 1, o.d, -a[k], ys[k])
+			}
+			if m > 0 {
+				γ := la.VecDot(ss[m-1], ys[m-1]) / la.VecDot(ys[m-1], ys[m-1])
+				o.d.Apply(γ, o.d)
+			}
+			for k := 0; k < m; k++ {
+				b := ρs[k] * la.VecDot(ys[k], o.d)
+				la.VecAdd(o.d, 1, o.d, a[k]-b, ss[k])
+			}
+			o.d.Apply(-1, o.d)
+
+		case MinCGFR, MinCGPR:
+			β := 0.0
+			if !reset && res.Nit%n != 0 {
+				gg0 := la.VecDot(o.g0, o.g0)
+				if o.Method == MinCGFR {
+					β = la.VecDot(g, g) / gg0
+				} else {
+					β = max((la.VecDot(g, g)-la.VecDot(g, o.g0))/gg0, 0)
+				}
+			}
+			la.VecAdd(o.d, -1, g, β, dold)
+			if !reset {
+				α0 = min(1, 1.01*αold*gdold/la.VecDot(g, o.d))
+				if α0 <= 0 {
+					α0 = 1
+				}
+			}
+
+		case MinNewtonCG: // solve H d = -g approximately
+			if Hx != nil {
+				err = o.Hfcn(Hx, x)
+				res.NHeval++
+				if err != nil {
+					return
+				}
+			}
+			hv := func(v, hv la.Vector) error {
+				if Hx != nil {
+					for i := 0; i < n; i++ {
+						hv[i] = 0
+						for j := 0; j < n; j++ {
+							hv[i] += Hx.Get(i, j) * v[j]
+						}
+					}
+					return nil
+				}
+				res.NHeval++
+				ε := math.Sqrt(machEps) * (1 + x.Norm()) / max(v.Norm(), 1e-300)
+				la.VecAdd(o.w, 1, x, ε, v)
+				if e := o.grad(gp, o.w); e != nil {
+					return e
+				}
+				la.VecAdd(hv, 1/ε, gp, -1/ε, g)
+				return nil
+			}
+			tol := min(o.EtaCG, math.Sqrt(gnrm2)) * gnrm2
+			z.Fill(0)
+			copy(r, g)
+			p.Apply(-1, g)
+			rr := la.VecDot(r, r)
+			for j := 0; j < 2*n; j++ {
+				err = hv(p, Hp)
+				if err != nil {
+					return
+				}
+				pHp := la.VecDot(p, Hp)
+				if pHp <= machEps*la.VecDot(p, p) { // negative curvature
+					if j == 0 {
+						copy(z, p)
+					}
+					break
+				}
+				a := rr / pHp
+				la.VecAdd(z, 1, z, a, p)
+				la.VecAdd(r, 1, r, a, Hp)
+				rrNew := la.VecDot(r, r)
+				if math.Sqrt(rrNew) < tol {
+					break
+				}
+				la.VecAdd(p, -1, r, rrNew/rr, p)
+				rr = rrNew
+			}
+			copy(o.d, z)
+		}
+
+		// check descent direction
+		gd := la.VecDot(g, o.d)
+		if gd >= 0 {
+			o.d.Apply(-1, g)
+			gd = -gnrm2 * gnrm2
+			reset = true
+			ss, ys, ρs = nil, nil, nil
+		}
+		if reset && o.Method != MinNewtonCG {
+			α0 = min(1, 1/gnrm2)
+		}
+
+		// line search
+		fold := res.F
+		copy(o.x0, x)
+		copy(o.g0, g)
+		α, res.F, err = o.lineSearch(x, g, fold, gd, α0)
+		if err != nil {
+			copy(x, o.x0)
+			copy(g, o.g0)
+			res.F = fold
+			if !reset {
+				reset = true
+				ss, ys, ρs = nil, nil, nil
+				if H != nil {
+					H.SetDiag(1)
+				}
+				continue
+			}
+			return chk.Err("line search failed at iteration %d:\n%v", res.Nit, err)
+		}
+		res.Nit++
+		o.record()
+
+		// update
+		s := la.NewVector(n)
+		y := la.NewVector(n)
+		la.VecAdd(s, 1, x, -1, o.x0)
+		la.VecAdd(y, 1, g, -1, o.g0)
+		sy := la.VecDot(s, y)
+		switch o.Method {
+
+		case MinBFGS: // H = (I - ρ s yᵀ) H (I - ρ y sᵀ) + ρ s sᵀ
+			if sy > machEps*s.Norm()*y.Norm() {
+				if reset {
+					γ := sy / la.VecDot(y, y)
+					H.SetDiag(γ)
+				}
+				ρ := 1 / sy
+				Hy := o.w
+				for i := 0; i < n; i++ {
+					Hy[i] = 0
+					for j := 0; j < n; j++ {
+						Hy[i] += H.Get(i, j) * y[j]
+					}
+				}
+				yHy := la.VecDot(y, Hy)
+				for i := 0; i < n; i++ {
+					for j := 0; j < n; j++ {
+						H.Add(i, j, -ρ*(s[i]*Hy[j]+Hy[i]*s[j])+(ρ*ρ*yHy+ρ)*s[i]*s[j])
+					}
+				}
+			}
+
+		case MinLBFGS:
+			if sy > machEps*s.Norm()*y.Norm() {
+				if len(ss) == o.Nhist {
+					ss, ys, ρs = ss[1:], ys[1:], ρs[1:]
+				}
+				ss, ys, ρs = append(ss, s), append(ys, y), append(ρs, 1/sy)
+			}
+
+		case MinCGFR, MinCGPR:
+			copy(dold, o.d)
+			gdold, αold = gd, α
+		}
+		reset = false
+
+		// check relative change of f
+		if math.Abs(fold-res.F) <= o.Ftol*max(1, math.Abs(res.F)) {
+			res.Converged = true
+			return
+		}
+	}
+}
+
+// fcn computes f(x) and counts the number of evaluations (checking the budget of derivative-free methods)
+func (o *Minimizer) fcn(x la.Vector) (f float64, err error) {
+	if o.budget && o.res.Nfeval >= o.MaxFeval {
+		return 0, utl.NewIterError(utl.StopMaxIt, "Minimizer", o.res.Nit, "max number of function evaluations (%d) reached", o.MaxFeval)
+	}
+	o.res.Nfeval++
+	return o.Ffcn(x)
+}
+
+// grad computes the gradient by means of Gfcn or central finite differences
+func (o *Minimizer) grad(g, x la.Vector) (err error) {
+	o.res.Ngeval++
+	if o.Gfcn != nil {
+		return o.Gfcn(g, x)
+	}
+	var fp, fm float64
+	for i := 0; i < len(x); i++ {
+		xi := x[i]
+		h := o.Hfd * max(1, math.Abs(xi))
+		x[i] = xi + h
+		fp, err = o.fcn(x)
+		if err != nil {
+			x[i] = xi
+			return
+		}
+		x[i] = xi - h
+		fm, err = o.fcn(x)
+		x[i] = xi
+		if err != nil {
+			return
+		}
+		g[i] = (fp - fm) / (2 * h)
+	}
+	return
+}
+
+// record records the history
+func (o *Minimizer) record() {
+	o.res.HistF = append(o.res.HistF, o.res.F)
+	if o.res.G != nil {
+		o.res.HistG = append(o.res.HistG, o.res.G.Largest(1))
+	}
+	if o.Hist {
+		o.res.HistX = append(o.res.HistX, o.res.X.GetCopy())
+	}
+}
@@ -0,0 +1,534 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+// ReadMPS reads a linear programming problem from a file in MPS format
+//  Input:
+//   fn    -- filename
+//   fixed -- fixed MPS format; i.e. fields at columns 2-3, 5-12, 15-22, 25-36, 40-47 and 50-61.
+//            Otherwise, the free MPS format with fields separated by spaces is used
+//  Output:
+//   prob -- general problem with names, integrality markers, ranges and objective sense
+//  NOTE: (1) only the first RHS, RANGES and BOUNDS sets are considered
+//        (2) free rows other than the objective function are ignored
+//        (3) negative UP bounds with no lower bound given set the lower bound to -∞
+func ReadMPS(fn string, fixed bool) (prob *LinProb, err error) {
+	b, err := io.ReadFile(fn)
+	if err != nil {
+		return nil, chk.Err("cannot read MPS file <%s>:\n%v", fn, err)
+	}
+	return parseMPS(string(b), fixed)
+}
+
+// WriteMPS writes the linear programming problem to a file in MPS format
+//  Input:
+//   fn    -- filename
+//   fixed -- fixed MPS format; otherwise, free MPS. Names in fixed format must have at most
+//            8 characters; names in any format cannot contain spaces
+func (o *LinProb) WriteMPS(fn string, fixed bool) (err error) {
+	buf, err := o.mpsBuffer(fixed)
+	if err != nil {
+		return
+	}
+	io.WriteFile(fn, buf)
+	return
+}
+
+// parseMPS parses the contents of a MPS file
+func parseMPS(data string, fixed bool) (prob *LinProb, err error) {
+
+	// auxiliary
+	prob = new(LinProb)
+	rows := make(map[string]int) // name => index of constraint; -1 means objective; -2 means ignored free row
+	cols := make(map[string]int)
+	var ai, aj []int
+	var ax, c, lower, upper []float64
+	var lowerSet, integer []bool
+	firstSet := make(map[string]string) // section => name of first RHS, RANGES or BOUNDS set
+	section := ""
+	intMarker := false
+	sensePending := false
+	lastCol := ""
+
+	// fields of line
+	fields := func(line string) (f []string) {
+		if !fixed {
+			return strings.Fields(line)
+		}
+		for _, lim := range [][2]int{{1, 3}, {4, 12}, {14, 22}, {24, 36}, {39, 47}, {49, 61}} {
+			if lim[0] >= len(line) {
+				break
+			}
+			end := lim[1]
+			if end > len(line) {
+				end = len(line)
+			}
+			f = append(f, strings.TrimSpace(line[lim[0]:end]))
+		}
+		for len(f) > 0 && f[len(f)-1] == "" {
+			f = f[:len(f)-1]
+		}
+		if len(f) > 0 && f[0] == "" && section != "BOUNDS" && section != "ROWS" {
+			f = f[1:]
+		}
+		return
+	}
+
+	// number
+	atof := func(lnum int, s string) (v float64, e error) {
+		v, e = strconv.ParseFloat(s, 64)
+		if e != nil {
+			e = chk.Err("MPS: line %d: cannot parse number %q", lnum, s)
+		}
+		return
+	}
+
+	// column index
+	column := func(name string) int {
+		j, ok := cols[name]
+		if !ok {
+			j = len(c)
+			cols[name] = j
+			prob.ColNames = append(prob.ColNames, name)
+			c = append(c, 0)
+			lower = append(lower, 0)
+			upper = append(upper, math.Inf(1))
+			lowerSet = append(lowerSet, false)
+			integer = append(integer, intMarker)
+		}
+		return j
+	}
+
+	// process lines
+	lines := strings.Split(data, "\n")
+	for idx, line := range lines {
+		lnum := idx + 1
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" || line[0] == '*' {
+			continue
+		}
+
+		// section header
+		if line[0] != ' ' && line[0] != '\t' {
+			words := strings.Fields(line)
+			section = strings.ToUpper(words[0])
+			switch section {
+			case "NAME":
+				if fixed && len(line) > 14 {
+					prob.Name = strings.TrimSpace(line[14:])
+				} else if len(words) > 1 {
+					prob.Name = words[1]
+				}
+			case "OBJSENSE":
+				sensePending = true
+				if len(words) > 1 {
+					err = mpsSense(prob, words[1], lnum)
+					sensePending = false
+				}
+			case "OBJSENSE:":
+				sensePending = true
+			case "ROWS", "COLUMNS", "RHS", "RANGES", "BOUNDS":
+			case "ENDATA":
+			default:
+				err = chk.Err("MPS: line %d: section %q is not supported", lnum, words[0])
+			}
+			if err != nil {
+				return nil, err
+			}
+			if section == "ENDATA" {
+				break
+			}
+			continue
+		}
+
+		// data
+		f := fields(line)
+		if len(f) == 0 {
+			continue
+		}
+		switch section {
+
+		case "OBJSENSE", "OBJSENSE:":
+			if !sensePending {
+				return nil, chk.Err("MPS: line %d: objective sense has been given already", lnum)
+			}
+			err = mpsSense(prob, f[0], lnum)
+			sensePending = false
+
+		case "ROWS":
+			if len(f) < 2 {
+				return nil, chk.Err("MPS: line %d: row type and name are required", lnum)
+			}
+			kind := strings.ToUpper(f[0])
+			name := f[1]
+			if _, ok := rows[name]; ok {
+				return nil, chk.Err("MPS: line %d: row %q is duplicated", lnum, name)
+			}
+			switch kind {
+			case "N":
+				if prob.ObjName == "" {
+					prob.ObjName = name
+					rows[name] = -1
+				} else {
+					rows[name] = -2
+				}
+			case "E", "L", "G":
+				i := len(prob.B)
+				rows[name] = i
+				prob.RowNames = append(prob.RowNames, name)
+				prob.B = append(prob.B, 0)
+				switch kind {
+				case "E":
+					prob.Kind = append(prob.Kind, LinEq)
+				case "L":
+					prob.Kind = append(prob.Kind, LinLe)
+				default:
+					prob.Kind = append(prob.Kind, LinGe)
+				}
+			default:
+				return nil, chk.Err("MPS: line %d: row type %q is invalid", lnum, f[0])
+			}
+
+		case "COLUMNS":
+			if strings.Contains(line, "'MARKER'") {
+				switch {
+				case strings.Contains(line, "'INTORG'"):
+					intMarker = true
+				case strings.Contains(line, "'INTEND'"):
+					intMarker = false
+				default:
+					return nil, chk.Err("MPS: line %d: marker is invalid", lnum)
+				}
+				continue
+			}
+			if len(f) != 3 && len(f) != 5 {
+				return nil, chk.Err("MPS: line %d: column name followed by one or two pairs (row, value) is required", lnum)
+			}
+			if f[0] != lastCol {
+				if _, ok := cols[f[0]]; ok {
+					return nil, chk.Err("MPS: line %d: entries of column %q must be given contiguously", lnum, f[0])
+				}
+				lastCol = f[0]
+			}
+			j := column(f[0])
+			for k := 1; k < len(f); k += 2 {
+				row, ok := rows[f[k]]
+				if !ok {
+					return nil, chk.Err("MPS: line %d: row %q is not defined", lnum, f[k])
+				}
+				v, e := atof(lnum, f[k+1])
+				if e != nil {
+					return nil, e
+				}
+				switch row {
+				case -1:
+					c[j] += v
+				case -2:
+				default:
+					ai, aj, ax = append(ai, row), append(aj, j), append(ax, v)
+				}
+			}
+
+		case "RHS", "RANGES":
+			if len(f)%2 == 0 { // set name is missing
+				f = append([]string{""}, f...)
+			}
+			if len(f) != 3 && len(f) != 5 {
+				return nil, chk.Err("MPS: line %d: set name followed by one or two pairs (row, value) is required", lnum)
+			}
+			if set, ok := firstSet[section]; !ok {
+				firstSet[section] = f[0]
+			} else if set != f[0] {
+				continue
+			}
+			if section == "RANGES" && prob.R == nil {
+				prob.R = la.NewVector(len(prob.B))
+			}
+			for k := 1; k < len(f); k += 2 {
+				row, ok := rows[f[k]]
+				if !ok {
+					return nil, chk.Err("MPS: line %d: row %q is not defined", lnum, f[k])
+				}
+				v, e := atof(lnum, f[k+1])
+				if e != nil {
+					return nil, e
+				}
+				if section == "RHS" {
+					switch row {
+					case -1:
+						prob.C0 = -v
+					case -2:
+					default:
+						prob.B[row] = v
+					}
+					continue
+				}
+				if row < 0 {
+					return nil, chk.Err("MPS: line %d: range of free row %q is invalid", lnum, f[k])
+				}
+				prob.R[row] = v
+			}
+
+		case "BOUNDS":
+			typ := strings.ToUpper(f[0])
+			needValue := !(typ == "FR" || typ == "MI" || typ == "PL" || typ == "BV")
+			if fixed {
+				if len(f) < 3 || (needValue && len(f) < 4) {
+					return nil, chk.Err("MPS: line %d: bound type, set name, column name and value are required", lnum)
+				}
+			} else {
+				if (needValue && len(f) == 3) || (!needValue && len(f) == 2) { // set name is missing
+					f = append([]string{f[0], ""}, f[1:]...)
+				}
+				if len(f) < 3 || (needValue && len(f) != 4) {
+					return nil, chk.Err("MPS: line %d: bound type, set name, column name and value are required", lnum)
+				}
+			}
+			if set, ok := firstSet[section]; !ok {
+				firstSet[section] = f[1]
+			} else if set != f[1] {
+				continue
+			}
+			j, ok := cols[f[2]]
+			if !ok {
+				return nil, chk.Err("MPS: line %d: column %q is not defined", lnum, f[2])
+			}
+			var v float64
+			if needValue {
+				v, err = atof(lnum, f[3])
+				if err != nil {
+					return nil, err
+				}
+			}
+			switch typ {
+			case "UP", "UI":
+				upper[j] = v
+				if v < 0 && lower[j] == 0 && !lowerSet[j] {
+					lower[j] = math.Inf(-1)
+				}
+			case "LO", "LI":
+				lower[j], lowerSet[j] = v, true
+			case "FX":
+				lower[j], upper[j], lowerSet[j] = v, v, true
+			case "FR":
+				lower[j], upper[j], lowerSet[j] = math.Inf(-1), math.Inf(1), true
+			case "MI":
+				lower[j], lowerSet[j] = math.Inf(-1), true
+			case "PL":
+				upper[j] = math.Inf(1)
+			case "BV":
+				lower[j], upper[j], lowerSet[j] = 0, 1, true
+			default:
+				return nil, chk.Err("MPS: line %d: bound type %q is not supported", lnum, f[0])
+			}
+			if typ == "UI" || typ == "LI" || typ == "BV" {
+				integer[j] = true
+			}
+
+		default:
+			return nil, chk.Err("MPS: line %d: data found outside of sections", lnum)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if section != "ENDATA" {
+		return nil, chk.Err("MPS: ENDATA is missing")
+	}
+
+	// results
+	m, n := len(prob.B), len(c)
+	var T la.Triplet
+	T.Init(m, n, len(ax)+1)
+	for k := range ax {
+		T.Put(ai[k], aj[k], ax[k])
+	}
+	prob.A = T.ToMatrix(nil)
+	prob.C, prob.L, prob.U = c, lower, upper
+	for _, isint := range integer {
+		if isint {
+			prob.Integer = integer
+			break
+		}
+	}
+	return
+}
+
+// mpsSense sets the objective sense
+func mpsSense(prob *LinProb, sense string, lnum int) error {
+	switch strings.ToUpper(sense) {
+	case "MAX", "MAXIMIZE", "MAXIMISE":
+		prob.Maximise = true
+	case "MIN", "MINIMIZE", "MINIMISE":
+		prob.Maximise = false
+	default:
+		return chk.Err("MPS: line %d: objective sense %q is invalid", lnum, sense)
+	}
+	return nil
+}
+
+// mpsBuffer writes MPS file into buffer
+func (o *LinProb) mpsBuffer(fixed bool) (buf *bytes.Buffer, err error) {
+
+	// check names
+	m, n, Ap, Ai, Ax := o.A.Get()
+	check := func(name string) error {
+		if name == "" || strings.ContainsAny(name, " \t") || (fixed && len(name) > 8) {
+			return chk.Err("MPS: name %q is invalid (empty, with spaces, or too long for the fixed format)", name)
+		}
+		return nil
+	}
+	if err = check(o.GetObjName()); err != nil {
+		return
+	}
+	for i := 0; i < m; i++ {
+		if err = check(o.RowName(i)); err != nil {
+			return
+		}
+	}
+	for j := 0; j < n; j++ {
+		if err = check(o.ColName(j)); err != nil {
+			return
+		}
+	}
+
+	// formatting
+	num := func(v float64) string {
+		s := strconv.FormatFloat(v, 'g', -1, 64)
+		for prec := 11; fixed && len(s) > 12; prec-- {
+			s = strconv.FormatFloat(v, 'g', prec, 64)
+		}
+		return s
+	}
+	entry := func(a, b string, v float64) {
+		if fixed {
+			io.Ff(buf, "    %-8s  %-8s  %12s\n", a, b, num(v))
+		} else {
+			io.Ff(buf, " %s %s %s\n", a, b, num(v))
+		}
+	}
+	bound := func(typ, name string, v float64, withValue bool) {
+		if fixed {
+			io.Ff(buf, " %-2s %-8s  %s", typ, "BND", name)
+			if withValue {
+				io.Ff(buf, "%s  %12s", strings.Repeat(" ", 8-len(name)), num(v))
+			}
+		} else {
+			io.Ff(buf, " %s BND %s", typ, name)
+			if withValue {
+				io.Ff(buf, " %s", num(v))
+			}
+		}
+		io.Ff(buf, "\n")
+	}
+
+	// header and rows
+	buf = new(bytes.Buffer)
+	name := o.Name
+	if name == "" || strings.ContainsAny(name, " \t") {
+		name = "PROBLEM"
+	}
+	io.Ff(buf, "NAME          %s\n", name)
+	if o.Maximise {
+		io.Ff(buf, "OBJSENSE\n    MAX\n")
+	}
+	io.Ff(buf, "ROWS\n N  %s\n", o.GetObjName())
+	for i := 0; i < m; i++ {
+		kind := "E"
+		switch o.RowKind(i) {
+		case LinLe:
+			kind = "L"
+		case LinGe:
+			kind = "G"
+		}
+		io.Ff(buf, " %s  %s\n", kind, o.RowName(i))
+	}
+
+	// columns
+	io.Ff(buf, "COLUMNS\n")
+	intMarker := false
+	marker := func(start bool) {
+		tag := "'INTEND'"
+		if start {
+			tag = "'INTORG'"
+		}
+		if fixed {
+			io.Ff(buf, "    MARKER                 'MARKER'                 %s\n", tag)
+		} else {
+			io.Ff(buf, " MARKER 'MARKER' %s\n", tag)
+		}
+		intMarker = start
+	}
+	for j := 0; j < n; j++ {
+		if o.IsInteger(j) != intMarker {
+			marker(!intMarker)
+		}
+		if o.C[j] != 0 || Ap[j] == Ap[j+1] {
+			entry(o.ColName(j), o.GetObjName(), o.C[j])
+		}
+		for k := Ap[j]; k < Ap[j+1]; k++ {
+			entry(o.ColName(j), o.RowName(Ai[k]), Ax[k])
+		}
+	}
+	if intMarker {
+		marker(false)
+	}
+
+	// right-hand side and ranges
+	io.Ff(buf, "RHS\n")
+	if o.C0 != 0 {
+		entry("RHS", o.GetObjName(), -o.C0)
+	}
+	for i := 0; i < m; i++ {
+		if o.B[i] != 0 {
+			entry("RHS", o.RowName(i), o.B[i])
+		}
+	}
+	if o.R != nil {
+		io.Ff(buf, "RANGES\n")
+		for i := 0; i < m; i++ {
+			if o.R[i] != 0 {
+				entry("RNG", o.RowName(i), o.R[i])
+			}
+		}
+	}
+
+	// bounds
+	io.Ff(buf, "BOUNDS\n")
+	for j := 0; j < n; j++ {
+		l, u := o.Lower(j), o.Upper(j)
+		name := o.ColName(j)
+		switch {
+		case l == u:
+			bound("FX", name, l, true)
+		case math.IsInf(l, 0) && math.IsInf(u, 0):
+			bound("FR", name, 0, false)
+		case o.IsInteger(j) && l == 0 && u == 1:
+			bound("BV", name, 0, false)
+		default:
+			if math.IsInf(l, 0) {
+				bound("MI", name, 0, false)
+			} else if l != 0 || u < 0 {
+				bound("LO", name, l, true)
+			}
+			if !math.IsInf(u, 0) {
+				bound("UP", name, u, true)
+			}
+		}
+	}
+	io.Ff(buf, "ENDATA\n")
+	return
+}
@@ -0,0 +1,269 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/fun"
+	"github.com/cpmech/gosl/la"
+)
+
+// NlProb defines a nonlinear constrained problem:
+//
+//          min f(x)    subject to    g(x) ≤ 0    and    h(x) = 0
+//           x
+//
+//  NOTE: (1) the Jacobians of g and h are given in triplet form; the callbacks must call Start()
+//            before putting the entries
+//        (2) if Gfcn, IneqJac or EqJac are nil, central finite differences are used
+type NlProb struct {
+	Ndim    int     // dimension of x
+	Nineq   int     // number of inequality constraints g(x) ≤ 0
+	Neq     int     // number of equality constraints h(x) = 0
+	Ffcn    fun.Sv  // f(x) objective function
+	Gfcn    fun.Vv  // df/dx gradient of f [may be nil]
+	Ineq    fun.Vv  // g(x) inequality constraints [may be nil if Nineq = 0]
+	IneqJac fun.Tv  // dg/dx Jacobian of g [may be nil]
+	Eq      fun.Vv  // h(x) equality constraints [may be nil if Neq = 0]
+	EqJac   fun.Tv  // dh/dx Jacobian of h [may be nil]
+	NnzIneq int     // max number of entries in the Jacobian of g [0 ⇒ Nineq*Ndim]
+	NnzEq   int     // max number of entries in the Jacobian of h [0 ⇒ Neq*Ndim]
+	Hfd     float64 // relative step for finite differences [0 ⇒ 1e-6]
+}
+
+// KktResidual holds the residuals of the Karush–Kuhn–Tucker conditions
+type KktResidual struct {
+	Stat  float64 // stationarity: |∇f + Jgᵀλ + Jhᵀμ|∞
+	Feas  float64 // primal feasibility: max(g⁺, |h|)
+	Dual  float64 // dual feasibility: max(-λ)⁺
+	Compl float64 // complementarity: max|λᵢ gᵢ|
+}
+
+// Max returns the largest residual
+func (o KktResidual) Max() float64 {
+	return max(max(o.Stat, o.Feas), max(o.Dual, o.Compl))
+}
+
+// NlResult holds the results of a nonlinear constrained optimisation
+type NlResult struct {
+	X         la.Vector   // solution
+	F         float64     // f(x)
+	G         la.Vector   // [Nineq] g(x)
+	H         la.Vector   // [Neq] h(x)
+	Lam       la.Vector   // [Nineq] multipliers of inequality constraints (λ ≥ 0)
+	Mu        la.Vector   // [Neq] multipliers of equality constraints
+	Kkt       KktResidual // KKT residuals at solution
+	Nit       int         // number of (outer) iterations
+	Nfeval    int         // number of calls to f(x) (including finite differences)
+	Ngeval    int         // number of gradient evaluations
+	Ncval     int         // number of evaluations of constraints (including finite differences)
+	Njeval    int         // number of evaluations of Jacobians of constraints
+	Converged bool        // tolerances have been satisfied
+	HistF     []float64   // [Nit+1] f(x) at each iteration (including initial point)
+	HistKkt   []float64   // [Nit+1] largest KKT residual at each iteration (including initial point)
+}
+
+// nlEval evaluates functions and derivatives of a nonlinear problem and counts evaluations
+type nlEval struct {
+	prob   *NlProb    // problem
+	res    *NlResult  // results with counters
+	hfd    float64    // relative step for finite differences
+	ti, te la.Triplet // Jacobians of g and h
+	wi, we la.Vector  // workspace for finite differences
+}
+
+// newNlEval checks the problem and returns a new evaluator with the initial results
+func newNlEval(prob *NlProb, x0 la.Vector) (o *nlEval, err error) {
+	if prob == nil || prob.Ffcn == nil {
+		return nil, chk.Err("problem and objective function must be given")
+	}
+	n := prob.Ndim
+	if n < 1 || prob.Nineq < 0 || prob.Neq < 0 {
+		return nil, chk.Err("dimensions are invalid: Ndim = %d, Nineq = %d, Neq = %d", n, prob.Nineq, prob.Neq)
+	}
+	if (prob.Nineq > 0 && prob.Ineq == nil) || (prob.Neq > 0 && prob.Eq == nil) {
+		return nil, chk.Err("constraint functions must be given if Nineq > 0 or Neq > 0")
+	}
+	if len(x0) != n {
+		return nil, chk.Err("size of x0 must be equal to %d. %d is incorrect", n, len(x0))
+	}
+	o = &nlEval{prob: prob, hfd: prob.Hfd}
+	if o.hfd <= 0 {
+		o.hfd = 1e-6
+	}
+	o.res = &NlResult{
+		X:   x0.GetCopy(),
+		G:   la.NewVector(prob.Nineq),
+		H:   la.NewVector(prob.Neq),
+		Lam: la.NewVector(prob.Nineq),
+		Mu:  la.NewVector(prob.Neq),
+	}
+	if prob.Nineq > 0 {
+		nnz := prob.NnzIneq
+		if nnz == 0 || prob.IneqJac == nil {
+			nnz = prob.Nineq * n
+		}
+		o.ti.Init(prob.Nineq, n, nnz)
+		o.wi = la.NewVector(prob.Nineq)
+	}
+	if prob.Neq > 0 {
+		nnz := prob.NnzEq
+		if nnz == 0 || prob.EqJac == nil {
+			nnz = prob.Neq * n
+		}
+		o.te.Init(prob.Neq, n, nnz)
+		o.we = la.NewVector(prob.Neq)
+	}
+	return
+}
+
+// fcn computes f(x)
+func (o *nlEval) fcn(x la.Vector) (f float64, err error) {
+	o.res.Nfeval++
+	return o.prob.Ffcn(x)
+}
+
+// grad computes the gradient of f by means of Gfcn or central finite differences
+func (o *nlEval) grad(g, x la.Vector) (err error) {
+	o.res.Ngeval++
+	if o.prob.Gfcn != nil {
+		return o.prob.Gfcn(g, x)
+	}
+	var fp, fm float64
+	for j := 0; j < len(x); j++ {
+		xj := x[j]
+		h := o.hfd * max(1, math.Abs(xj))
+		x[j] = xj + h
+		fp, err = o.fcn(x)
+		if err == nil {
+			x[j] = xj - h
+			fm, err = o.fcn(x)
+		}
+		x[j] = xj
+		if err != nil {
+			return
+		}
+		g[j] = (fp - fm) / (2 * h)
+	}
+	return
+}
+
+// cons computes the constraints g(x) and h(x)
+func (o *nlEval) cons(gx, hx, x la.Vector) (err error) {
+	o.res.Ncval++
+	if o.prob.Nineq > 0 {
+		err = o.prob.Ineq(gx, x)
+		if err != nil {
+			return
+		}
+	}
+	if o.prob.Neq > 0 {
+		err = o.prob.Eq(hx, x)
+	}
+	return
+}
+
+// jacs computes the Jacobians of g and h (nil if there are no constraints of each kind)
+func (o *nlEval) jacs(x la.Vector) (Ji, Je *la.CCMatrix, err error) {
+	o.res.Njeval++
+	if o.prob.Nineq > 0 {
+		Ji, err = o.jac(&o.ti, o.prob.IneqJac, o.prob.Ineq, o.wi, x)
+		if err != nil {
+			return
+		}
+	}
+	if o.prob.Neq > 0 {
+		Je, err = o.jac(&o.te, o.prob.EqJac, o.prob.Eq, o.we, x)
+	}
+	return
+}
+
+// jac computes one Jacobian by means of jfcn or central finite differences of ffcn
+func (o *nlEval) jac(T *la.Triplet, jfcn fun.Tv, ffcn fun.Vv, w, x la.Vector) (J *la.CCMatrix, err error) {
+	if jfcn != nil {
+		err = jfcn(T, x)
+		if err != nil {
+			return
+		}
+	} else {
+		T.Start()
+		fp := la.NewVector(len(w))
+		for j := 0; j < len(x); j++ {
+			xj := x[j]
+			h := o.hfd * max(1, math.Abs(xj))
+			x[j] = xj + h
+			o.res.Ncval++
+			err = ffcn(fp, x)
+			if err == nil {
+				x[j] = xj - h
+				o.res.Ncval++
+				err = ffcn(w, x)
+			}
+			x[j] = xj
+			if err != nil {
+				return
+			}
+			for i := 0; i < len(w); i++ {
+				if d := (fp[i] - w[i]) / (2 * h); d != 0 {
+					T.Put(i, j, d)
+				}
+			}
+		}
+	}
+	if T.Len() == 0 { // conversion requires at least one entry
+		T.Put(0, 0, 0)
+	}
+	return T.ToMatrix(nil), nil
+}
+
+// lagGrad computes the gradient of the Lagrangian: gl = ∇f + Jgᵀλ + Jhᵀμ
+func lagGrad(gl, gf la.Vector, Ji, Je *la.CCMatrix, λ, μ la.Vector) {
+	copy(gl, gf)
+	if Ji != nil {
+		la.SpMatTrVecMulAdd(gl, 1, Ji, λ)
+	}
+	if Je != nil {
+		la.SpMatTrVecMulAdd(gl, 1, Je, μ)
+	}
+}
+
+// kkt computes the KKT residuals with the current results (X, G, H, Lam and Mu)
+//  Input:
+//   gf     -- gradient of f at x
+//   Ji, Je -- Jacobians of g and h at x
+//   gl     -- workspace
+//  Note: the stationarity residual is divided by max(1, |∇f|∞)
+func (o *nlEval) kkt(gf la.Vector, Ji, Je *la.CCMatrix, gl la.Vector) {
+	res := o.res
+	lagGrad(gl, gf, Ji, Je, res.Lam, res.Mu)
+	res.Kkt = KktResidual{Stat: gl.Largest(1) / max(1, gf.Largest(1))}
+	for i, gi := range res.G {
+		res.Kkt.Feas = max(res.Kkt.Feas, gi)
+		res.Kkt.Dual = max(res.Kkt.Dual, -res.Lam[i])
+		res.Kkt.Compl = max(res.Kkt.Compl, math.Abs(res.Lam[i]*gi))
+	}
+	for _, hi := range res.H {
+		res.Kkt.Feas = max(res.Kkt.Feas, math.Abs(hi))
+	}
+}
+
+// record records the history
+func (o *nlEval) record() {
+	o.res.HistF = append(o.res.HistF, o.res.F)
+	o.res.HistKkt = append(o.res.HistKkt, o.res.Kkt.Max())
+}
+
+// violation returns the l1 norm of the constraint violation: Σ gᵢ⁺ + Σ |hᵢ|
+func violation(gx, hx la.Vector) (v float64) {
+	for _, gi := range gx {
+		v += max(gi, 0)
+	}
+	for _, hi := range hx {
+		v += math.Abs(hi)
+	}
+	return
+}
@@ -0,0 +1,454 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/rnd"
+	"github.com/cpmech/gosl/utl"
+)
+
+// MultiObjFunc defines a function to compute the objective values f(x) and the values of the
+// inequality constraints g(x) ≤ 0 of multi-objective problems
+//  Output:
+//   f -- [nobj] objective values
+//   g -- [ncon] constraint values
+//  Input:
+//   x -- [ndim] variables
+type MultiObjFunc func(f, g, x la.Vector) error
+
+// Nsga2 implements the elitist non-dominated sorting genetic algorithm (NSGA-II) to approximate
+// the Pareto-optimal front of multi-objective problems within a box
+//  Solve:
+//          min {f₀(x), f₁(x), ..., fₘ₋₁(x)}    subject to    g(x) ≤ 0  and  Lower ≤ x ≤ Upper
+//           x
+//
+//  NOTE: (1) constraint-domination is employed: a feasible solution dominates any infeasible
+//            one; an infeasible solution dominates another one with larger total violation
+//            Σ max(0, gᵢ); and feasible solutions are compared by utl.ParetoMin
+//        (2) the offspring are generated by binary tournament selection (rank, then crowding
+//            distance), simulated binary crossover (SBX) and polynomial mutation
+//        (3) the initial population is generated by Latin hypercube sampling (rnd.LatinIHS)
+//        (4) if Seed > 0, the random numbers are generated by a generator of the solver that is
+//            initialised with Seed, such that the results are reproducible (also with
+//            Nworkers > 1) and the global generator (rnd.Init) is not affected. Otherwise, the
+//            global generator is used
+//        (5) f(x) and g(x) are evaluated by Nworkers goroutines concurrently; thus Ofcn must be
+//            safe for concurrent use if Nworkers > 1
+//  Reference:
+//   Deb K, Pratap A, Agarwal S and Meyarivan T (2002) A fast and elitist multiobjective genetic
+//   algorithm: NSGA-II. IEEE Transactions on Evolutionary Computation, 6(2):182-197
+type Nsga2 struct {
+
+	// constants
+	Npop     int     // population size (even)
+	MaxIt    int     // number of generations
+	Pc       float64 // crossover probability
+	EtaC     float64 // distribution index of the simulated binary crossover ηc
+	Pm       float64 // mutation probability of each variable [0 ⇒ 1 / ndim]
+	EtaM     float64 // distribution index of the polynomial mutation ηm
+	Phi      float64 // φ > 0 ⇒ tournaments between feasible solutions use utl.ParetoMinProb(φ)
+	Seed     int     // seed for random numbers [≤ 0 ⇒ the global generator is used]
+	Nworkers int     // number of goroutines to evaluate f(x) and g(x) [≤ 1 ⇒ sequential]
+	Verbose  bool    // show messages
+
+	// problem
+	Nobj  int          // number of objectives
+	Ncon  int          // number of inequality constraints
+	Ofcn  MultiObjFunc // f(x) and g(x) function
+	Lower la.Vector    // lower bounds (finite)
+	Upper la.Vector    // upper bounds (finite)
+
+	// cancellation and progress report
+	Monitor utl.Monitor // residual = smallest constraint violation, step = number of non-dominated solutions
+
+	// results
+	X      []la.Vector // [Npop][ndim] final population
+	Ovs    [][]float64 // [Npop][Nobj] objective values
+	Ocs    [][]float64 // [Npop][Ncon] constraint values
+	Viol   []float64   // [Npop] total constraint violations Σ max(0, gᵢ)
+	Rank   []int       // [Npop] index of non-dominated front (0 ⇒ non-dominated)
+	Front  []int       // indices of the feasible non-dominated solutions (Pareto front approximation)
+	Nit    int         // number of generations
+	Nfeval int         // number of evaluations of Ofcn
+
+	// internal: pool with parents and offspring
+	ndim int         // dimension
+	xs   []la.Vector // [2 Npop][ndim] variables
+	ovs  [][]float64 // [2 Npop][Nobj] objective values
+	ocs  [][]float64 // [2 Npop][Ncon] constraint values
+	viol []float64   // [2 Npop] total constraint violations
+	rank []int       // [2 Npop] index of non-dominated front
+	dist []float64   // [2 Npop] crowding distances
+	sdom [][]int     // [2 Npop] solutions dominated by each solution
+	ndom []int       // [2 Npop] number of solutions dominating each solution
+	rng  *rand.Rand  // random numbers generator
+	tmp  []int       // sorted front
+}
+
+// NewNsga2 returns a new NSGA-II solver
+//  Input:
+//   nobj  -- number of objectives
+//   ncon  -- number of inequality constraints g(x) ≤ 0
+//   ofcn  -- f(x) and g(x) function
+//   lower -- lower bounds (finite)
+//   upper -- upper bounds (finite)
+func NewNsga2(nobj, ncon int, ofcn MultiObjFunc, lower, upper la.Vector) (o *Nsga2) {
+	o = new(Nsga2)
+	o.Npop = 100
+	o.MaxIt = 250
+	o.Pc = 0.9
+	o.EtaC = 15
+	o.EtaM = 20
+	o.Nobj = nobj
+	o.Ncon = ncon
+	o.Ofcn = ofcn
+	o.Lower = lower
+	o.Upper = upper
+	o.ndim = len(lower)
+	return
+}
+
+// Solve runs MaxIt generations and collects the final population in the results
+func (o *Nsga2) Solve() (err error) {
+
+	// check
+	n, npop := o.ndim, o.Npop
+	if o.Ofcn == nil || o.Nobj < 1 || o.Ncon < 0 || n < 1 || len(o.Upper) != n {
+		return chk.Err("objective function, number of objectives ≥ 1 and bounds (with the same size ≥ 1) must be given")
+	}
+	for j := 0; j < n; j++ {
+		if !(o.Lower[j] < o.Upper[j]) || math.IsInf(o.Lower[j], 0) || math.IsInf(o.Upper[j], 0) {
+			return chk.Err("bounds must be finite with Lower < Upper. [%g, %g] of x[%d] is invalid", o.Lower[j], o.Upper[j], j)
+		}
+	}
+	if npop < 4 || npop%2 != 0 {
+		return chk.Err("population size must be even and at least 4. %d is incorrect", npop)
+	}
+
+	// initialise
+	o.rng = rnd.NewRand(o.Seed)
+	o.Nit, o.Nfeval = 0, 0
+	o.xs = append(latinRand(o.rng, npop, o.Lower, o.Upper), make([]la.Vector, npop)...)
+	o.ovs = utl.Alloc(2*npop, o.Nobj)
+	o.ocs = utl.Alloc(2*npop, o.Ncon)
+	o.viol = make([]float64, 2*npop)
+	o.rank = make([]int, 2*npop)
+	o.dist = make([]float64, 2*npop)
+	o.sdom = make([][]int, 2*npop)
+	o.ndom = make([]int, 2*npop)
+	pop := utl.IntRange(npop)          // parents
+	off := utl.IntRange2(npop, 2*npop) // offspring
+	for _, k := range off {
+		o.xs[k] = la.NewVector(n)
+	}
+	all := utl.IntRange(2 * npop)
+	inpop := make([]bool, 2*npop)
+
+	// initial population
+	err = o.evaluate(pop)
+	if err != nil {
+		return
+	}
+	for _, front := range o.sortFronts(pop) {
+		o.crowding(front)
+	}
+	if o.Verbose {
+		io.Pf("%6s%8s%8s%23s\n", "it", "nfeval", "nfront", "min(violation)")
+	}
+
+	// generations
+	for {
+
+		// progress
+		nfront, vmin := 0, math.Inf(1)
+		for _, p := range pop {
+			if o.rank[p] == 0 {
+				nfront++
+			}
+			vmin = min(vmin, o.viol[p])
+		}
+		if o.Verbose {
+			io.Pf("%6d%8d%8d%23.15e\n", o.Nit, o.Nfeval, nfront, vmin)
+		}
+		if o.Nit >= o.MaxIt {
+			break
+		}
+		err = o.Monitor.Check("Nsga2", o.Nit, vmin, float64(nfront))
+		if err != nil {
+			return
+		}
+		o.Nit++
+
+		// offspring
+		for k := 0; k < npop; k += 2 {
+			a, b := o.xs[off[k]], o.xs[off[k+1]]
+			o.crossover(a, b, o.xs[o.tournament(pop)], o.xs[o.tournament(pop)])
+			o.mutation(a)
+			o.mutation(b)
+		}
+		err = o.evaluate(off)
+		if err != nil {
+			return
+		}
+
+		// survivors: best fronts of parents and offspring; the last one is truncated by crowding distance
+		pop = pop[:0]
+		for _, front := range o.sortFronts(all) {
+			o.crowding(front)
+			if len(pop)+len(front) > npop {
+				sort.SliceStable(front, func(a, b int) bool { return o.dist[front[a]] > o.dist[front[b]] })
+				front = front[:npop-len(pop)]
+			}
+			pop = append(pop, front...)
+			if len(pop) == npop {
+				break
+			}
+		}
+		for k := range inpop {
+			inpop[k] = false
+		}
+		for _, p := range pop {
+			inpop[p] = true
+		}
+		off = off[:0]
+		for k := range inpop {
+			if !inpop[k] {
+				off = append(off, k)
+			}
+		}
+	}
+
+	// results
+	o.X = make([]la.Vector, npop)
+	o.Ovs = make([][]float64, npop)
+	o.Ocs = make([][]float64, npop)
+	o.Viol = make([]float64, npop)
+	o.Rank = make([]int, npop)
+	var feasible []int
+	for i, p := range pop {
+		o.X[i], o.Ovs[i], o.Ocs[i] = o.xs[p], o.ovs[p], o.ocs[p]
+		o.Viol[i], o.Rank[i] = o.viol[p], o.rank[p]
+		if o.viol[p] == 0 {
+			feasible = append(feasible, i)
+		}
+	}
+	fovs := make([][]float64, len(feasible))
+	for k, i := range feasible {
+		fovs[k] = o.Ovs[i]
+	}
+	o.Front = utl.ParetoFront(fovs)
+	for k, i := range o.Front {
+		o.Front[k] = feasible[i]
+	}
+	return
+}
+
+// evaluate computes f(x), g(x) and the total constraint violation of a set of solutions in the
+// pool (concurrently if Nworkers > 1). NaN values are replaced by +∞
+func (o *Nsga2) evaluate(idx []int) (err error) {
+	err = runConcurrently(o.Nworkers, len(idx), func(i int) error {
+		k := idx[i]
+		return o.Ofcn(o.ovs[k], o.ocs[k], o.xs[k])
+	})
+	o.Nfeval += len(idx)
+	if err != nil {
+		return
+	}
+	for _, k := range idx {
+		for m, f := range o.ovs[k] {
+			if math.IsNaN(f) {
+				o.ovs[k][m] = math.Inf(1)
+			}
+		}
+		o.viol[k] = 0
+		for _, g := range o.ocs[k] {
+			if math.IsNaN(g) {
+				g = math.Inf(1)
+			}
+			o.viol[k] += max(0, g)
+		}
+	}
+	return
+}
+
+// dominates compares two solutions in the pool by constraint-domination (see NOTE (1) of Nsga2)
+func (o *Nsga2) dominates(p, q int) (pDominates, qDominates bool) {
+	vp, vq := o.viol[p], o.viol[q]
+	if vp == 0 && vq == 0 {
+		return utl.ParetoMin(o.ovs[p], o.ovs[q])
+	}
+	return vp < vq, vq < vp
+}
+
+// sortFronts performs the fast non-dominated sorting of a set of solutions in the pool and sets
+// their ranks
+//  Output:
+//   fronts -- indices of solutions in each front; fronts[0] has the non-dominated solutions
+func (o *Nsga2) sortFronts(set []int) (fronts [][]int) {
+	for _, p := range set {
+		o.sdom[p] = o.sdom[p][:0]
+		o.ndom[p] = 0
+	}
+	for a, p := range set {
+		for _, q := range set[a+1:] {
+			pDominates, qDominates := o.dominates(p, q)
+			if pDominates {
+				o.sdom[p] = append(o.sdom[p], q)
+				o.ndom[q]++
+			}
+			if qDominates {
+				o.sdom[q] = append(o.sdom[q], p)
+				o.ndom[p]++
+			}
+		}
+	}
+	var front []int
+	for _, p := range set {
+		if o.ndom[p] == 0 {
+			o.rank[p] = 0
+			front = append(front, p)
+		}
+	}
+	for r := 1; len(front) > 0; r++ {
+		fronts = append(fronts, front)
+		var next []int
+		for _, p := range front {
+			for _, q := range o.sdom[p] {
+				o.ndom[q]--
+				if o.ndom[q] == 0 {
+					o.rank[q] = r
+					next = append(next, q)
+				}
+			}
+		}
+		front = next
+	}
+	return
+}
+
+// crowding computes the crowding distances of solutions in a front; i.e. the sum over the
+// objectives of the normalised distance between the neighbours. The extreme solutions get +∞
+func (o *Nsga2) crowding(front []int) {
+	l := len(front)
+	for _, p := range front {
+		o.dist[p] = 0
+	}
+	if l < 3 {
+		for _, p := range front {
+			o.dist[p] = math.Inf(1)
+		}
+		return
+	}
+	o.tmp = append(o.tmp[:0], front...)
+	s := o.tmp
+	for m := 0; m < o.Nobj; m++ {
+		sort.SliceStable(s, func(a, b int) bool { return o.ovs[s[a]][m] < o.ovs[s[b]][m] })
+		o.dist[s[0]], o.dist[s[l-1]] = math.Inf(1), math.Inf(1)
+		Δf := o.ovs[s[l-1]][m] - o.ovs[s[0]][m]
+		if !(Δf > 0) || math.IsInf(Δf, 0) {
+			continue
+		}
+		for i := 1; i < l-1; i++ {
+			o.dist[s[i]] += (o.ovs[s[i+1]][m] - o.ovs[s[i-1]][m]) / Δf
+		}
+	}
+}
+
+// tournament selects a parent by binary tournament: the solution with smaller rank wins and
+// then the one with larger crowding distance (or utl.ParetoMinProb is used if Phi > 0)
+func (o *Nsga2) tournament(pop []int) int {
+	p, q := pop[randInt(o.rng, 0, len(pop)-1)], pop[randInt(o.rng, 0, len(pop)-1)]
+	if o.Phi > 0 && o.viol[p] == 0 && o.viol[q] == 0 {
+		if paretoMinProb(o.rng, o.ovs[p], o.ovs[q], o.Phi) {
+			return p
+		}
+		return q
+	}
+	if o.rank[q] < o.rank[p] || (o.rank[q] == o.rank[p] && o.dist[q] > o.dist[p]) {
+		return q
+	}
+	return p
+}
+
+// crossover generates two children a and b from the parents u and v by simulated binary
+// crossover (bounded version) with probability Pc; otherwise the parents are copied
+//  Reference:
+//   Deb K and Agrawal RB (1995) Simulated binary crossover for continuous search space.
+//   Complex Systems, 9:115-148
+func (o *Nsga2) crossover(a, b, u, v la.Vector) {
+	copy(a, u)
+	copy(b, v)
+	if randFloat64(o.rng, 0, 1) > o.Pc {
+		return
+	}
+	η := o.EtaC + 1
+	βq := func(β float64) float64 {
+		α := 2 - math.Pow(β, -η)
+		r := randFloat64(o.rng, 0, 1)
+		if r <= 1/α {
+			return math.Pow(r*α, 1/η)
+		}
+		return math.Pow(1/(2-r*α), 1/η)
+	}
+	for j := 0; j < o.ndim; j++ {
+		if randFloat64(o.rng, 0, 1) > 0.5 || math.Abs(u[j]-v[j]) < 1e-14 {
+			continue
+		}
+		xl, xu := o.Lower[j], o.Upper[j]
+		y1, y2 := min(u[j], v[j]), max(u[j], v[j])
+		c1 := 0.5 * (y1 + y2 - βq(1+2*(y1-xl)/(y2-y1))*(y2-y1))
+		c2 := 0.5 * (y1 + y2 + βq(1+2*(xu-y2)/(y2-y1))*(y2-y1))
+		c1, c2 = max(xl, min(c1, xu)), max(xl, min(c2, xu))
+		if randFloat64(o.rng, 0, 1) <= 0.5 {
+			c1, c2 = c2, c1
+		}
+		a[j], b[j] = c1, c2
+	}
+}
+
+// mutation perturbs x by polynomial mutation (bounded version); each variable is mutated with
+// probability Pm
+//  Reference:
+//   Deb K and Goyal M (1996) A combined genetic adaptive search (GeneAS) for engineering design.
+//   Computer Science and Informatics, 26(4):30-45
+func (o *Nsga2) mutation(x la.Vector) {
+	pm := o.Pm
+	if pm <= 0 {
+		pm = 1 / float64(o.ndim)
+	}
+	η := o.EtaM + 1
+	for j := 0; j < o.ndim; j++ {
+		if randFloat64(o.rng, 0, 1) > pm {
+			continue
+		}
+		xl, xu := o.Lower[j], o.Upper[j]
+		δ1, δ2 := (x[j]-xl)/(xu-xl), (xu-x[j])/(xu-xl)
+		r := randFloat64(o.rng, 0, 1)
+		var δq float64
+		if r < 0.5 {
+			δq = math.Pow(2*r+(1-2*r)*math.Pow(1-δ1, η), 1/η) - 1
+		} else {
+			δq = 1 - math.Pow(2*(1-r)+2*(r-0.5)*math.Pow(1-δ2, η), 1/η)
+		}
+		x[j] = max(xl, min(x[j]+δq*(xu-xl), xu))
+	}
+}
+
+// paretoMinProb implements utl.ParetoMinProb with the random numbers generator rng
+func paretoMinProb(rng *rand.Rand, u, v []float64, φ float64) (uDominates bool) {
+	var pu float64
+	for i := 0; i < len(u); i++ {
+		pu += utl.ProbContestSmall(u[i], v[i], φ)
+	}
+	pu /= float64(len(u))
+	return pu == 1 || (pu > 0 && rng.Float64() <= pu)
+}
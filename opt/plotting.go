@@ -5,7 +5,10 @@
 package opt
 
 import (
+	"sort"
+
 	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/la"
 	"github.com/cpmech/gosl/plt"
 	"github.com/cpmech/gosl/utl"
 )
@@ -72,3 +75,76 @@ func PlotTwoVarsContour(x []float64, np int, extra func(), axequal bool,
 		plt.Equal()
 	}
 }
+
+// PlotTwoVarsPath plots the path of the iterations of a minimiser; e.g. over the contour drawn
+// by PlotTwoVarsContour (call it within "extra")
+//  Input
+//   hist -- history of x; e.g. MinResult.HistX with Minimizer.Hist = true. len(hist[i]) ≥ 2
+//   args -- plot arguments. can be nil
+func PlotTwoVarsPath(hist []la.Vector, args *plt.A) {
+	if len(hist) == 0 {
+		return
+	}
+	if args == nil {
+		args = &plt.A{C: "k", Ls: "-", M: ".", L: "path", Z: 9}
+	}
+	X, Y := make([]float64, len(hist)), make([]float64, len(hist))
+	for i, x := range hist {
+		X[i], Y[i] = x[0], x[1]
+	}
+	plt.Plot(X, Y, args)
+	plt.PlotOne(X[0], Y[0], &plt.A{C: args.C, Ls: "none", M: "o", Z: 9})
+}
+
+// PlotParetoFront plots the objective values of a two-objective problem; the non-dominated ones
+// (utl.ParetoFront) are highlighted and joined by a line
+//  Input
+//   ovs      -- [npoints][2] objective values; e.g. Nsga2.Ovs or its Front subset
+//   refFront -- [nref][2] reference front (e.g. the true Pareto front) plotted as a line. can be nil
+//   args     -- plot arguments for the non-dominated points. can be nil
+//   argsRef  -- plot arguments for the reference front. can be nil
+func PlotParetoFront(ovs, refFront [][]float64, args, argsRef *plt.A) {
+	if len(refFront) > 0 {
+		if argsRef == nil {
+			argsRef = &plt.A{C: "grey", Ls: "-", L: "reference", Z: 8}
+		}
+		X, Y := paretoCoords(refFront, utl.IntRange(len(refFront)))
+		plt.Plot(X, Y, argsRef)
+	}
+	if len(ovs) == 0 {
+		return
+	}
+	if args == nil {
+		args = &plt.A{C: "r", Ls: "-", M: "o", L: "non-dominated", Z: 10}
+	}
+	front := utl.ParetoFront(ovs)
+	nondom := make([]bool, len(ovs))
+	for _, i := range front {
+		nondom[i] = true
+	}
+	var dominated []int
+	for i := range ovs {
+		if !nondom[i] {
+			dominated = append(dominated, i)
+		}
+	}
+	if len(dominated) > 0 {
+		X, Y := paretoCoords(ovs, dominated)
+		plt.Plot(X, Y, &plt.A{C: "k", Ls: "none", M: ".", L: "dominated", Z: 9})
+	}
+	X, Y := paretoCoords(ovs, front)
+	plt.Plot(X, Y, args)
+}
+
+// paretoCoords returns the coordinates of a subset of two-objective values sorted by the first objective
+func paretoCoords(ovs [][]float64, idx []int) (X, Y []float64) {
+	s := make([]int, len(idx))
+	copy(s, idx)
+	sort.SliceStable(s, func(a, b int) bool { return ovs[s[a]][0] < ovs[s[b]][0] })
+	X, Y = make([]float64, len(s)), make([]float64, len(s))
+	for k, i := range s {
+		chk.IntAssert(len(ovs[i]), 2)
+		X[k], Y[k] = ovs[i][0], ovs[i][1]
+	}
+	return
+}
@@ -0,0 +1,497 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"context"
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+// status of solution computed by QpIpm
+const (
+	QpUnsolved   = iota // not solved yet or stopped because of max iterations, cancellation, etc.
+	QpOptimal           // optimal solution found
+	QpInfeasible        // problem is (primal) infeasible
+	QpUnbounded         // problem is unbounded (dual infeasible)
+)
+
+// QpProb holds a convex quadratic programming problem
+//
+//          min ½xᵀQx + cᵀx   s.t.   A x = b,   G x ≤ h,   l ≤ x ≤ u
+//           x
+//
+//  NOTE: (1) Q must be symmetric positive semi-definite and all its entries (upper and lower
+//            parts) must be given
+//        (2) bounds with |value| ≥ LinInf (e.g. ±Inf) are regarded as infinite
+type QpProb struct {
+	Q *la.CCMatrix // [n][n] Hessian; nil means zero (linear program)
+	C la.Vector    // [n] linear coefficients
+	A *la.CCMatrix // [me][n] matrix of equality constraints; nil means none
+	B la.Vector    // [me] right-hand side of equality constraints
+	G *la.CCMatrix // [mi][n] matrix of inequality constraints; nil means none
+	H la.Vector    // [mi] right-hand side of inequality constraints
+	L la.Vector    // [n] lower bounds; nil means all -∞
+	U la.Vector    // [n] upper bounds; nil means all +∞
+}
+
+// QpIpm implements a primal-dual interior-point method with Mehrotra's predictor-corrector for
+// convex quadratic programming problems (see QpProb). The bounds are handled as inequality
+// constraints; i.e. all inequalities are written as C x + s = d with slacks s ≥ 0 where the
+// rows of C are G, -I (finite lower bounds) and I (finite upper bounds).
+//
+//  The optimality conditions are:
+//
+//    Q x + c + Aᵀy + Gᵀz - zl + zu = 0,   A x = b,   C x + s = d,   s∘[z,zl,zu] = 0
+//
+//  and each Newton step solves the symmetric augmented (KKT) system
+//
+//    [ Q + δI    Aᵀ      Cᵀ   ] [Δx]   [    -rd       ]
+//    [   A      -δI      0    ] [Δy] = [    -re       ]
+//    [   C       0    -Z⁻¹S   ] [Δz]   [ -ri + Z⁻¹ rc ]
+//
+//  which is assembled into a la.Triplet and solved by a la.SparseSolver (e.g. "umfpack" or
+//  "mumps"). The regularisation δ allows the solution of problems with linearly dependent
+//  equality constraints.
+//
+//  Infeasibility is detected by means of the certificates computed with the (normalised)
+//  iterates; the problem is infeasible if
+//
+//    |Aᵀy + Cᵀz|∞ ≤ ϵ |y,z|∞   and   bᵀy + dᵀz < -√ϵ |y,z|∞
+//
+//  and unbounded if
+//
+//    |Q x|∞ ≤ ϵ |x|∞,   |A x|∞ ≤ ϵ |x|∞,   max(C x) ≤ ϵ |x|∞   and   cᵀx < -√ϵ |x|∞
+//
+//  where ϵ = InfTol and the right-hand sides are scaled by the largest entries of the
+//  corresponding matrices and vectors.
+//  Reference:
+//   Nocedal J and Wright SJ (2006) Numerical Optimization. 2nd Edition. Springer. Chapter 16
+type QpIpm struct {
+
+	// problem
+	Prob *QpProb // problem
+
+	// constants
+	NmaxIt  int     // max number of iterations
+	Tol     float64 // tolerance for relative residuals and complementarity
+	InfTol  float64 // tolerance ϵ for the infeasibility certificates
+	Reg     float64 // regularisation δ of the KKT system
+	Solver  string  // name of sparse solver; e.g. "umfpack" or "mumps"
+	Verbose bool    // show messages
+
+	// results
+	Status int       // QpOptimal, QpInfeasible, QpUnbounded or QpUnsolved
+	X      la.Vector // [n] solution
+	Y      la.Vector // [me] multipliers of equality constraints
+	Z      la.Vector // [mi] multipliers of inequality constraints G x ≤ h (z ≥ 0)
+	Zl     la.Vector // [n] multipliers of lower bounds (zero if infinite)
+	Zu     la.Vector // [n] multipliers of upper bounds (zero if infinite)
+	F      float64   // objective value ½xᵀQx + cᵀx
+	Nit    int       // number of iterations of last Solve
+
+	// cancellation and progress report
+	Monitor utl.Monitor // residual = largest relative residual, step = step length α
+
+	// internal
+	n, me, mg  int             // number of variables, equalities and inequalities G x ≤ h
+	mi         int             // number of inequalities C x ≤ d including bounds
+	lo, up     []int           // indices of variables with finite lower and upper bounds
+	d          la.Vector       // [mi] right-hand side of C x ≤ d
+	kkt        *la.Triplet     // KKT matrix
+	rhs        la.Vector       // right-hand side of KKT system
+	lis        la.SparseSolver // linear solver
+	qmax, amax float64         // largest entries of Q and A
+	cmax       float64         // largest entry of C
+}
+
+// NewQpIpm returns a new interior-point solver for convex quadratic programming problems
+func NewQpIpm(prob *QpProb) (o *QpIpm) {
+
+	// problem
+	o = new(QpIpm)
+	o.Prob = prob
+	o.n = len(prob.C)
+	n := o.n
+	if prob.Q != nil {
+		if m, nq, _, _, _ := prob.Q.Get(); m != n || nq != n {
+			chk.Panic("Q must be a square matrix with size equal to len(c) = %d. (%d,%d) is incorrect", n, m, nq)
+		}
+	}
+	if prob.A != nil {
+		m, na, _, _, _ := prob.A.Get()
+		if m != len(prob.B) || na != n {
+			chk.Panic("A must have len(b) = %d rows and len(c) = %d columns. (%d,%d) is incorrect", len(prob.B), n, m, na)
+		}
+		o.me = m
+	}
+	if prob.G != nil {
+		m, ng, _, _, _ := prob.G.Get()
+		if m != len(prob.H) || ng != n {
+			chk.Panic("G must have len(h) = %d rows and len(c) = %d columns. (%d,%d) is incorrect", len(prob.H), n, m, ng)
+		}
+		o.mg = m
+	}
+	if (prob.L != nil && len(prob.L) != n) || (prob.U != nil && len(prob.U) != n) {
+		chk.Panic("sizes of l and u must be equal to len(c) = %d", n)
+	}
+
+	// constants
+	o.NmaxIt = 100
+	o.Tol = 1e-8
+	o.InfTol = 1e-8
+	o.Reg = 1e-10
+	o.Solver = "umfpack"
+
+	// bounds
+	for j := 0; j < n; j++ {
+		if prob.L != nil && prob.L[j] > -LinInf {
+			o.lo = append(o.lo, j)
+		}
+	}
+	for j := 0; j < n; j++ {
+		if prob.U != nil && prob.U[j] < LinInf {
+			o.up = append(o.up, j)
+		}
+	}
+	o.mi = o.mg + len(o.lo) + len(o.up)
+	o.d = la.NewVector(o.mi)
+	copy(o.d, prob.H)
+	for k, j := range o.lo {
+		o.d[o.mg+k] = -prob.L[j]
+	}
+	for k, j := range o.up {
+		o.d[o.mg+len(o.lo)+k] = prob.U[j]
+	}
+
+	// scaling of certificates
+	nnz := n + o.me + 2*(len(o.lo)+len(o.up)) + o.mi
+	o.qmax, o.amax, o.cmax = 1, 1, 1
+	if prob.Q != nil {
+		_, _, _, _, ax := prob.Q.Get()
+		o.qmax = max(1, normInf(ax))
+		nnz += len(ax)
+	}
+	if prob.A != nil {
+		_, _, _, _, ax := prob.A.Get()
+		o.amax = max(1, normInf(ax))
+		nnz += 2 * len(ax)
+	}
+	if prob.G != nil {
+		_, _, _, _, ax := prob.G.Get()
+		o.cmax = max(1, normInf(ax))
+		nnz += 2 * len(ax)
+	}
+
+	// KKT matrix and results
+	ny := n + o.me + o.mi
+	o.kkt = new(la.Triplet)
+	o.kkt.Init(ny, ny, nnz)
+	o.rhs = la.NewVector(ny)
+	o.X = la.NewVector(n)
+	o.Y = la.NewVector(o.me)
+	o.Z = la.NewVector(o.mg)
+	o.Zl = la.NewVector(n)
+	o.Zu = la.NewVector(n)
+	return
+}
+
+// Free frees allocated memory
+func (o *QpIpm) Free() {
+	if o.lis != nil {
+		o.lis.Free()
+		o.lis = nil
+	}
+}
+
+// Solve solves the quadratic programming problem
+//  NOTE: an error is returned if the problem is infeasible or unbounded; the Status is set
+//        accordingly and the last iterates (certificates) are available in X, Y, Z, Zl and Zu
+func (o *QpIpm) Solve() (err error) {
+
+	// variables u := [x, y, z] and slacks s
+	o.Status, o.Nit = QpUnsolved, 0
+	n, me, mi := o.n, o.me, o.mi
+	ny := n + me + mi
+	u := la.NewVector(ny)
+	x, y, z := u[:n], u[n:n+me], u[n+me:]
+	s := la.NewVector(mi)
+	z.Fill(1)
+	s.Fill(1)
+	defer o.results(u)
+
+	// residuals and directions
+	r, Δ := la.NewVector(ny), la.NewVector(ny)
+	rd, re, ri := r[:n], r[n:n+me], r[n+me:]
+	Δz := Δ[n+me:]
+	rc, Δs, Δsa, Δza := la.NewVector(mi), la.NewVector(mi), la.NewVector(mi), la.NewVector(mi)
+	qx, ax, cx, t := la.NewVector(n), la.NewVector(me), la.NewVector(mi), la.NewVector(n)
+
+	// normalisation of residuals
+	c, b := o.Prob.C, o.Prob.B
+	bden := 1 + max(normInf(b), normInf(o.d))
+	cden := 1 + normInf(c)
+	sϵ := math.Sqrt(o.InfTol)
+
+	// message
+	if o.Verbose {
+		io.Pf("%4s%23s%14s%14s%14s%14s\n", "it", "f(x)", "primal", "dual", "μ", "α")
+	}
+
+	// iterations
+	var μ, σ, α float64
+	for it := 0; ; it++ {
+		o.Nit = it
+
+		// residuals
+		o.matvec(qx, ax, cx, t, x, y, z)
+		for j := 0; j < n; j++ {
+			rd[j] = qx[j] + c[j] + t[j]
+		}
+		for i := 0; i < me; i++ {
+			re[i] = ax[i] - b[i]
+		}
+		for k := 0; k < mi; k++ {
+			ri[k] = cx[k] + s[k] - o.d[k]
+		}
+		μ = 0
+		if mi > 0 {
+			μ = la.VecDot(s, z) / float64(mi)
+		}
+		o.F = 0.5*la.VecDot(x, qx) + la.VecDot(c, x)
+
+		// check convergence
+		ep := max(normInf(re), normInf(ri)) / bden
+		ed := normInf(rd) / cden
+		if o.Verbose {
+			io.Pf("%4d%23.15e%14.6e%14.6e%14.6e%14.6e\n", it, o.F, ep, ed, μ, α)
+		}
+		if math.IsNaN(ep+ed+μ) || math.IsInf(ep+ed+μ, 0) {
+			return utl.NewIterError(utl.StopDiverged, "QpIpm", it, "residuals are %v and %v with μ = %v", ep, ed, μ)
+		}
+		if ep <= o.Tol && ed <= o.Tol && μ <= o.Tol*(1+math.Abs(o.F)) {
+			o.Status = QpOptimal
+			return
+		}
+
+		// check infeasibility: Farkas certificate (y, z) with Aᵀy + Cᵀz ≈ 0 and bᵀy + dᵀz < 0
+		w := max(normInf(y), normInf(z))
+		if w > 0 && normInf(t) <= o.InfTol*w*max(o.amax, o.cmax) && la.VecDot(b, y)+la.VecDot(o.d, z) < -sϵ*w*bden {
+			o.Status = QpInfeasible
+			return chk.Err("QpIpm: problem is infeasible (|Aᵀy + Cᵀz| = %g with |y,z| = %g)", normInf(t), w)
+		}
+
+		// check unboundedness: direction of recession x with Q x ≈ 0, A x ≈ 0, C x ≤ 0 and cᵀx < 0
+		v, cxmax := normInf(x), 0.0
+		for k := 0; k < mi; k++ {
+			cxmax = max(cxmax, cx[k])
+		}
+		if v > 0 && la.VecDot(c, x) < -sϵ*v*cden && normInf(qx) <= o.InfTol*v*o.qmax &&
+			normInf(ax) <= o.InfTol*v*o.amax && cxmax <= o.InfTol*v*o.cmax {
+			o.Status = QpUnbounded
+			return chk.Err("QpIpm: problem is unbounded (cᵀx = %g with |x| = %g)", la.VecDot(c, x), v)
+		}
+
+		// check iterations, cancellation and progress
+		if it == o.NmaxIt {
+			return utl.NewIterError(utl.StopMaxIt, "QpIpm", it, "iterations did not converge")
+		}
+		err = o.Monitor.Check("QpIpm", it, max(ep, ed), α)
+		if err != nil {
+			return
+		}
+
+		// factorise KKT matrix
+		o.assemble(z, s)
+		if it == 0 {
+			o.Free()
+			o.lis = la.NewSparseSolver(o.Solver)
+			err = o.lis.Init(o.kkt, false, false, "", "", nil)
+			if err != nil {
+				return
+			}
+		}
+		err = o.lis.Fact()
+		if err != nil {
+			return
+		}
+
+		// predictor (affine) step
+		for k := 0; k < mi; k++ {
+			rc[k] = s[k] * z[k]
+		}
+		err = o.direction(Δ, Δs, r, rc, z, s)
+		if err != nil {
+			return
+		}
+
+		// starting point: shift slacks and multipliers away from the boundary
+		if it == 0 {
+			la.VecAdd(u, 1, u, 1, Δ)
+			for k := 0; k < mi; k++ {
+				s[k] = max(1, math.Abs(s[k]+Δs[k]))
+				z[k] = max(1, math.Abs(z[k]))
+			}
+			continue
+		}
+
+		// corrector step
+		if mi > 0 {
+			α = qpStep(s, z, Δs, Δz, 1)
+			μa := 0.0
+			for k := 0; k < mi; k++ {
+				μa += (s[k] + α*Δs[k]) * (z[k] + α*Δz[k])
+			}
+			σ = math.Pow(μa/float64(mi)/μ, 3)
+			copy(Δsa, Δs)
+			copy(Δza, Δz)
+			for k := 0; k < mi; k++ {
+				rc[k] = s[k]*z[k] + Δsa[k]*Δza[k] - σ*μ
+			}
+			err = o.direction(Δ, Δs, r, rc, z, s)
+			if err != nil {
+				return
+			}
+		}
+
+		// update
+		α = qpStep(s, z, Δs, Δz, 0.995)
+		la.VecAdd(u, 1, u, α, Δ)
+		la.VecAdd(s, 1, s, α, Δs)
+	}
+}
+
+// SolveCtx solves the quadratic programming problem and stops with an error if ctx is cancelled
+func (o *QpIpm) SolveCtx(ctx context.Context) (err error) {
+	o.Monitor.Ctx = ctx
+	defer func() { o.Monitor.Ctx = nil }()
+	return o.Solve()
+}
+
+// matvec computes the products Q x, A x, C x and Aᵀy + Cᵀz
+func (o *QpIpm) matvec(qx, ax, cx, t, x, y, z la.Vector) {
+	prob := o.Prob
+	qx.Fill(0)
+	t.Fill(0)
+	if prob.Q != nil {
+		la.SpMatVecMul(qx, 1, prob.Q, x)
+	}
+	if prob.A != nil {
+		la.SpMatVecMul(ax, 1, prob.A, x)
+		la.SpMatTrVecMulAdd(t, 1, prob.A, y)
+	}
+	if prob.G != nil {
+		la.SpMatVecMul(cx[:o.mg], 1, prob.G, x)
+		la.SpMatTrVecMulAdd(t, 1, prob.G, z[:o.mg])
+	}
+	k := o.mg
+	for _, j := range o.lo {
+		cx[k] = -x[j]
+		t[j] -= z[k]
+		k++
+	}
+	for _, j := range o.up {
+		cx[k] = x[j]
+		t[j] += z[k]
+		k++
+	}
+}
+
+// assemble assembles the KKT matrix
+func (o *QpIpm) assemble(z, s la.Vector) {
+	prob := o.Prob
+	n, me := o.n, o.me
+	o.kkt.Start()
+	if prob.Q != nil {
+		_, _, p, i, x := prob.Q.Get()
+		for j := 0; j < n; j++ {
+			for k := p[j]; k < p[j+1]; k++ {
+				o.kkt.Put(i[k], j, x[k])
+			}
+		}
+	}
+	for j := 0; j < n; j++ {
+		o.kkt.Put(j, j, o.Reg)
+	}
+	if prob.A != nil {
+		o.kkt.PutCCMatAndMatT(prob.A)
+	}
+	for i := 0; i < me; i++ {
+		o.kkt.Put(n+i, n+i, -o.Reg)
+	}
+	I := n + me
+	if prob.G != nil {
+		_, _, p, i, x := prob.G.Get()
+		for j := 0; j < n; j++ {
+			for k := p[j]; k < p[j+1]; k++ {
+				o.kkt.Put(I+i[k], j, x[k])
+				o.kkt.Put(j, I+i[k], x[k])
+			}
+		}
+	}
+	k := I + o.mg
+	for _, j := range o.lo {
+		o.kkt.Put(k, j, -1)
+		o.kkt.Put(j, k, -1)
+		k++
+	}
+	for _, j := range o.up {
+		o.kkt.Put(k, j, 1)
+		o.kkt.Put(j, k, 1)
+		k++
+	}
+	for k := 0; k < o.mi; k++ {
+		o.kkt.Put(I+k, I+k, -s[k]/z[k])
+	}
+}
+
+// direction solves the KKT system for Δ := [Δx, Δy, Δz] and computes Δs
+//  Input:
+//   r  -- residuals [rd, re, ri]
+//   rc -- complementarity residual
+//   z  -- multipliers of inequalities
+//   s  -- slacks
+func (o *QpIpm) direction(Δ, Δs, r, rc, z, s la.Vector) (err error) {
+	I := o.n + o.me
+	rhs := o.rhs
+	for k := 0; k < I; k++ {
+		rhs[k] = -r[k]
+	}
+	for k := 0; k < o.mi; k++ {
+		rhs[I+k] = -r[I+k] + rc[k]/z[k]
+	}
+	err = o.lis.Solve(Δ, rhs, false)
+	if err != nil {
+		return
+	}
+	Δz := Δ[I:]
+	for k := 0; k < o.mi; k++ {
+		Δs[k] = -(rc[k] + s[k]*Δz[k]) / z[k]
+	}
+	return
+}
+
+// results sets the results with the iterates u = [x, y, z]
+func (o *QpIpm) results(u la.Vector) {
+	n, I := o.n, o.n+o.me
+	copy(o.X, u[:n])
+	copy(o.Y, u[n:I])
+	copy(o.Z, u[I:I+o.mg])
+	o.Zl.Fill(0)
+	o.Zu.Fill(0)
+	k := I + o.mg
+	for _, j := range o.lo {
+		o.Zl[j] = u[k]
+		k++
+	}
+	for _, j := range o.up {
+		o.Zu[j] = u[k]
+		k++
+	}
+}
@@ -0,0 +1,354 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"math"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+// Sqp implements the sequential quadratic programming method for nonlinear constrained problems
+//  The search direction d is the solution of the quadratic programming (QP) subproblem
+//
+//          min ½ dᵀB d + ∇fᵀd    subject to    g + Jg d ≤ 0    and    h + Jh d = 0
+//           d
+//
+//  where B is a damped BFGS approximation of the Hessian of the Lagrangian; the step length is
+//  computed by a backtracking line search on the l1 merit function f + ν (Σ gᵢ⁺ + Σ |hᵢ|).
+//  If the linearised constraints are inconsistent, an elastic QP subproblem with one relaxation
+//  variable is solved instead.
+//  NOTE: the QP subproblems are solved by QpIpm; i.e. the KKT systems are assembled with the
+//        sparse Jacobians and solved by a sparse solver. Nonetheless, B is a dense matrix; thus,
+//        this solver is suitable for problems with a moderate number of variables
+//  Reference:
+//   Nocedal J and Wright SJ (2006) Numerical Optimization. 2nd Edition. Springer. Chapter 18
+type Sqp struct {
+
+	// constants
+	Prob    *NlProb // problem
+	MaxIt   int     // max number of iterations
+	Tol     float64 // tolerance on the largest KKT residual for convergence
+	LsMaxIt int     // max number of iterations in line search
+	Eta     float64 // sufficient decrease coefficient of line search (Armijo)
+	Verbose bool    // show messages
+
+	// cancellation and progress report
+	Monitor utl.Monitor // residual = largest KKT residual, step = step length α
+}
+
+// NewSqp returns a new SQP solver
+func NewSqp(prob *NlProb) (o *Sqp) {
+	o = new(Sqp)
+	o.Prob = prob
+	o.MaxIt = 200
+	o.Tol = 1e-6
+	o.LsMaxIt = 30
+	o.Eta = 1e-4
+	return
+}
+
+// Solve solves the problem starting from x0 (which is not modified)
+func (o *Sqp) Solve(x0 la.Vector) (res *NlResult, err error) {
+
+	// initial point
+	ev, err := newNlEval(o.Prob, x0)
+	if err != nil {
+		return
+	}
+	res = ev.res
+	n, mi, me := o.Prob.Ndim, o.Prob.Nineq, o.Prob.Neq
+	x := res.X
+	gf, gl, glOld := la.NewVector(n), la.NewVector(n), la.NewVector(n)
+	res.F, err = ev.fcn(x)
+	if err != nil {
+		return
+	}
+	err = ev.cons(res.G, res.H, x)
+	if err != nil {
+		return
+	}
+	err = ev.grad(gf, x)
+	if err != nil {
+		return
+	}
+	Ji, Je, err := ev.jacs(x)
+	if err != nil {
+		return
+	}
+
+	// auxiliary
+	B := la.NewMatrix(n, n)
+	B.SetDiag(1)
+	reset := true // B is the identity matrix
+	ν := 0.0      // penalty parameter of merit function
+	xnew, gnew, hnew := la.NewVector(n), la.NewVector(mi), la.NewVector(me)
+	bi, be := la.NewVector(mi), la.NewVector(me)
+	s, y, Bs := la.NewVector(n), la.NewVector(n), la.NewVector(n)
+
+	// message
+	if o.Verbose {
+		io.Pf("%6s%23s%14s%14s%14s%14s\n", "it", "f(x)", "kkt", "viol", "ν", "α")
+	}
+
+	// iterations
+	var α float64
+	for {
+
+		// check convergence
+		ev.kkt(gf, Ji, Je, gl)
+		ev.record()
+		kkt := res.Kkt.Max()
+		if o.Verbose {
+			io.Pf("%6d%23.15e%14.6e%14.6e%14.6e%14.6e\n", res.Nit, res.F, kkt, violation(res.G, res.H), ν, α)
+		}
+		if kkt <= o.Tol {
+			res.Converged = true
+			return
+		}
+		if res.Nit >= o.MaxIt {
+			return res, utl.NewIterError(utl.StopMaxIt, "Sqp", res.Nit, "iterations did not converge")
+		}
+		err = o.Monitor.Check("Sqp", res.Nit, kkt, α)
+		if err != nil {
+			return
+		}
+
+		// QP subproblem
+		bi.Apply(-1, res.G)
+		be.Apply(-1, res.H)
+		d, μqp, λqp, e := qpSolve(B, gf, Je, be, Ji, bi)
+		if e != nil {
+			d, μqp, λqp, e = qpElastic(B, gf, Je, be, Ji, bi, 1e3*max(1, ν))
+			if e != nil {
+				return res, chk.Err("QP subproblem failed at iteration %d:\n%v", res.Nit, e)
+			}
+		}
+
+		// penalty parameter and merit function
+		mult := max(normInf(λqp), normInf(μqp))
+		if ν < 1.1*mult {
+			ν = 1.5*mult + 1e-8
+		}
+		viol := violation(res.G, res.H)
+		φ0 := res.F + ν*viol
+		dφ := la.VecDot(gf, d) - ν*viol
+
+		// line search
+		α = 1.0
+		var fnew float64
+		failed := false
+		for it := 0; ; it++ {
+			la.VecAdd(xnew, 1, x, α, d)
+			fnew, err = ev.fcn(xnew)
+			if err != nil {
+				return
+			}
+			err = ev.cons(gnew, hnew, xnew)
+			if err != nil {
+				return
+			}
+			φ := fnew + ν*violation(gnew, hnew)
+			if φ <= φ0+o.Eta*α*dφ || math.Abs(φ-φ0) <= 10*machEps*max(1, math.Abs(φ0)) {
+				break
+			}
+			if it == o.LsMaxIt {
+				failed = true
+				break
+			}
+			αq := -dφ * α * α / (2 * (φ - φ0 - dφ*α)) // minimiser of quadratic interpolation
+			if math.IsNaN(αq) || math.IsInf(αq, 0) {
+				αq = 0.5 * α
+			}
+			α = max(0.1*α, min(0.5*α, αq))
+		}
+		if failed {
+			if !reset {
+				B.SetDiag(1)
+				reset = true
+				res.Nit++
+				continue
+			}
+			return res, chk.Err("line search failed at iteration %d", res.Nit)
+		}
+
+		// update point and multipliers
+		la.VecAdd(s, 1, xnew, -1, x)
+		lagGrad(glOld, gf, Ji, Je, λqp, μqp)
+		copy(x, xnew)
+		copy(res.G, gnew)
+		copy(res.H, hnew)
+		copy(res.Lam, λqp)
+		copy(res.Mu, μqp)
+		res.F = fnew
+		err = ev.grad(gf, x)
+		if err != nil {
+			return
+		}
+		Ji, Je, err = ev.jacs(x)
+		if err != nil {
+			return
+		}
+		res.Nit++
+
+		// damped BFGS update: B = B - B s sᵀB / sᵀB s + y yᵀ / sᵀy
+		lagGrad(gl, gf, Ji, Je, λqp, μqp)
+		la.VecAdd(y, 1, gl, -1, glOld)
+		for i := 0; i < n; i++ {
+			Bs[i] = 0
+			for j := 0; j < n; j++ {
+				Bs[i] += B.Get(i, j) * s[j]
+			}
+		}
+		sBs, sy := la.VecDot(s, Bs), la.VecDot(s, y)
+		if sBs <= machEps*la.VecDot(s, s) {
+			continue
+		}
+		if sy < 0.2*sBs { // Powell's damping
+			θ := 0.8 * sBs / (sBs - sy)
+			la.VecAdd(y, θ, y, 1-θ, Bs)
+			sy = la.VecDot(s, y)
+		}
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				B.Add(i, j, -Bs[i]*Bs[j]/sBs+y[i]*y[j]/sy)
+			}
+		}
+		reset = false
+	}
+}
+
+// qpSolve solves the convex quadratic program
+//
+//          min ½ dᵀB d + cᵀd    subject to    Ae d = be    and    Ai d ≤ bi
+//           d
+//
+//  by means of QpIpm; i.e. the KKT system is assembled with the sparse matrices Ae and Ai and
+//  solved by a sparse solver. B must be positive definite on the null space of Ae
+//  Input:
+//   Ae, Ai -- matrices of constraints [may be nil if there are no constraints of that kind]
+//  Output:
+//   d -- solution
+//   y -- multipliers of equality constraints: B d + c + Aeᵀy + Aiᵀz = 0
+//   z -- multipliers of inequality constraints (z ≥ 0)
+func qpSolve(B *la.Matrix, c la.Vector, Ae *la.CCMatrix, be la.Vector, Ai *la.CCMatrix, bi la.Vector) (d, y, z la.Vector, err error) {
+	n := len(c)
+	var Q la.Triplet
+	Q.Init(n, n, n*n)
+	qpPutDense(&Q, B)
+	return qpRun(&QpProb{Q: Q.ToMatrix(nil), C: c, A: Ae, B: be, G: Ai, H: bi})
+}
+
+// qpRun solves the QP subproblem with QpIpm
+func qpRun(prob *QpProb) (x, y, z la.Vector, err error) {
+	qp := NewQpIpm(prob)
+	defer qp.Free()
+	qp.Tol = 1e-11
+	err = qp.Solve()
+	return qp.X, qp.Y, qp.Z, err
+}
+
+// qpPutDense puts the non-zero entries of the dense matrix B into T
+func qpPutDense(T *la.Triplet, B *la.Matrix) {
+	for j := 0; j < B.N; j++ {
+		for i := 0; i < B.M; i++ {
+			if v := B.Get(i, j); v != 0 {
+				T.Put(i, j, v)
+			}
+		}
+	}
+}
+
+// qpPutSparse puts α A into T starting at row i0
+func qpPutSparse(T *la.Triplet, i0 int, α float64, A *la.CCMatrix) {
+	if A == nil {
+		return
+	}
+	_, n, ap, ai, ax := A.Get()
+	for j := 0; j < n; j++ {
+		for l := ap[j]; l < ap[j+1]; l++ {
+			T.Put(i0+ai[l], j, α*ax[l])
+		}
+	}
+}
+
+// qpStep returns the largest step α ≤ 1 such that s + α Δs ≥ (1-τ) s and z + α Δz ≥ (1-τ) z
+func qpStep(s, z, Δs, Δz la.Vector, τ float64) (α float64) {
+	α = 1.0
+	for k := range s {
+		if Δs[k] < 0 {
+			α = min(α, -τ*s[k]/Δs[k])
+		}
+		if Δz[k] < 0 {
+			α = min(α, -τ*z[k]/Δz[k])
+		}
+	}
+	return
+}
+
+// qpElastic solves the elastic QP with the relaxation variable t ≥ 0:
+//
+//          min ½ dᵀB d + cᵀd + M t    subject to    Ai d - t ≤ bi    and    -t ≤ Ae d - be ≤ t
+//          d,t
+//
+//  and returns the multipliers corresponding to the original constraints
+func qpElastic(B *la.Matrix, c la.Vector, Ae *la.CCMatrix, be la.Vector, Ai *la.CCMatrix, bi la.Vector, M float64) (d, y, z la.Vector, err error) {
+
+	// objective
+	n, me, mi := len(c), len(be), len(bi)
+	var Q la.Triplet
+	Q.Init(n+1, n+1, n*n+1)
+	qpPutDense(&Q, B)
+	Q.Put(n, n, 1e-8*M)
+	ct := la.NewVector(n + 1)
+	copy(ct, c)
+	ct[n] = M
+
+	// constraints: t ≥ 0 is a lower bound
+	m := mi + 2*me
+	nnz := m
+	if Ai != nil {
+		_, _, ap, _, _ := Ai.Get()
+		nnz += ap[n]
+	}
+	if Ae != nil {
+		_, _, ap, _, _ := Ae.Get()
+		nnz += 2 * ap[n]
+	}
+	var G la.Triplet
+	G.Init(m, n+1, nnz)
+	qpPutSparse(&G, 0, 1, Ai)
+	qpPutSparse(&G, mi, 1, Ae)
+	qpPutSparse(&G, mi+me, -1, Ae)
+	h := la.NewVector(m)
+	for k := 0; k < m; k++ {
+		G.Put(k, n, -1)
+	}
+	copy(h, bi)
+	for k := 0; k < me; k++ {
+		h[mi+k] = be[k]
+		h[mi+me+k] = -be[k]
+	}
+	L := la.NewVector(n + 1)
+	for j := 0; j < n; j++ {
+		L[j] = -LinInf
+	}
+	prob := &QpProb{Q: Q.ToMatrix(nil), C: ct, L: L}
+	if m > 0 {
+		prob.G, prob.H = G.ToMatrix(nil), h
+	}
+	dt, _, zt, err := qpRun(prob)
+	if err != nil {
+		return
+	}
+	d, y, z = dt[:n], la.NewVector(me), zt[:mi]
+	for k := 0; k < me; k++ {
+		y[k] = zt[mi+k] - zt[mi+me+k]
+	}
+	return
+}
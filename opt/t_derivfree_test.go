@@ -0,0 +1,211 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/plt"
+	"github.com/cpmech/gosl/utl"
+)
+
+var derivfreeNames = map[int]string{MinNelderMead: "Nelder-Mead", MinPowell: "Powell", MinBobyqa: "BOBYQA"}
+
+func Test_derivfree01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("derivfree01. derivative-free methods: Rosenbrock function")
+
+	for _, method := range []int{MinNelderMead, MinPowell, MinBobyqa} {
+
+		// solve
+		name := derivfreeNames[method]
+		sol := NewMinimizer(method, 2, rosenbrock, nil)
+		sol.Hist = true
+		sol.MaxIt = 5000
+		sol.Xtol = 1e-10
+		res, err := sol.Min([]float64{-1.2, 1})
+		if err != nil {
+			tst.Errorf("%s failed:\n%v", name, err)
+			return
+		}
+
+		// check
+		io.Pforan("%-12s: x = %v  nit = %4d  nfeval = %4d\n", name, res.X, res.Nit, res.Nfeval)
+		if !res.Converged {
+			tst.Errorf("%s did not converge\n", name)
+		}
+		chk.Array(tst, "x", 1e-6, res.X, []float64{1, 1})
+		chk.Float64(tst, "f", 1e-11, res.F, 0)
+		chk.Int(tst, "ngeval", res.Ngeval, 0)
+		chk.Int(tst, "len(HistF)", len(res.HistF), res.Nit+1)
+		chk.Int(tst, "len(HistX)", len(res.HistX), res.Nit+1)
+		chk.Int(tst, "len(HistG)", len(res.HistG), 0)
+		for i := 1; i < len(res.HistF); i++ {
+			if res.HistF[i] > res.HistF[i-1] {
+				tst.Errorf("%s: f(x) must not increase\n", name)
+				break
+			}
+		}
+
+		// plot
+		if chk.Verbose {
+			f := func(x []float64) float64 { v, _ := rosenbrock(x); return v }
+			plt.Reset(false, nil)
+			PlotTwoVarsContour(res.X, 41, func() { PlotTwoVarsPath(res.HistX, nil) }, false,
+				[]float64{-2, -1}, []float64{2, 3}, &plt.A{Levels: utl.LinSpace(0, 100, 11)}, nil, f)
+			plt.Save("/tmp/gosl/opt", "t_derivfree01-"+name)
+		}
+	}
+}
+
+func Test_derivfree02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("derivfree02. derivative-free methods: box bounds")
+
+	// f(x) = Σ (x[i] - i - 1)²  with  0 ≤ x ≤ 2.5
+	// solution: x = {1, 2, 2.5, 2.5}  f = 0.25 + 2.25 = 2.5
+	ffcn := func(x la.Vector) (f float64, err error) {
+		for i := range x {
+			d := x[i] - float64(i) - 1
+			f += d * d
+		}
+		return
+	}
+	for _, method := range []int{MinNelderMead, MinPowell, MinBobyqa} {
+		name := derivfreeNames[method]
+		sol := NewMinimizer(method, 4, ffcn, nil)
+		sol.Lower = utl.Vals(4, 0)
+		sol.Upper = utl.Vals(4, 2.5)
+		res, err := sol.Min([]float64{0.5, 0.5, 0.5, 0.5})
+		if err != nil {
+			tst.Errorf("%s failed:\n%v", name, err)
+			return
+		}
+		io.Pforan("%-12s: x = %v  nit = %4d  nfeval = %4d\n", name, res.X, res.Nit, res.Nfeval)
+		chk.Array(tst, "x", 1e-6, res.X, []float64{1, 2, 2.5, 2.5})
+		chk.Float64(tst, "f", 1e-10, res.F, 2.5)
+	}
+
+	// 10-dimensional Rosenbrock with solution on a bound: x[0] ≥ 1.2
+	ndim := 10
+	lower := utl.Vals(ndim, -5)
+	lower[0] = 1.2
+	var fs []float64
+	for _, method := range []int{MinPowell, MinBobyqa} {
+		name := derivfreeNames[method]
+		sol := NewMinimizer(method, ndim, rosenbrock, nil)
+		sol.Lower = lower
+		sol.Upper = utl.Vals(ndim, 5)
+		sol.MaxIt = 10000
+		res, err := sol.Min(utl.Vals(ndim, 2))
+		if err != nil {
+			tst.Errorf("%s failed:\n%v", name, err)
+			return
+		}
+		io.Pforan("%-12s: nit = %4d  nfeval = %5d  f = %v\n", name, res.Nit, res.Nfeval, res.F)
+		chk.Float64(tst, "x0", 1e-15, res.X[0], 1.2)
+		for i := 0; i < ndim; i++ {
+			if res.X[i] < sol.Lower[i] || res.X[i] > sol.Upper[i] {
+				tst.Errorf("%s: x is out of bounds: %v\n", name, res.X)
+			}
+		}
+		fs = append(fs, res.F)
+	}
+	chk.Float64(tst, "f(Powell) == f(BOBYQA)", 1e-8, fs[0], fs[1])
+}
+
+func Test_derivfree03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("derivfree03. derivative-free methods: budget, reproducibility and errors")
+
+	ndim := 6
+	x0 := utl.Vals(ndim, -1)
+	for _, method := range []int{MinNelderMead, MinPowell, MinBobyqa} {
+		name := derivfreeNames[method]
+
+		// budget
+		sol := NewMinimizer(method, ndim, rosenbrock, nil)
+		sol.MaxFeval = 50
+		res, err := sol.Min(x0)
+		if !utl.IsMaxIt(err) {
+			tst.Errorf("%s: budget should have caused a max iterations error. err = %v\n", name, err)
+		}
+		io.Pforan("%-12s: %v\n", name, err)
+		chk.Int(tst, "nfeval", res.Nfeval, 50)
+
+		// reproducibility
+		sol.MaxFeval = 2000
+		res1, err1 := sol.Min(x0)
+		res2, err2 := sol.Min(x0)
+		if (err1 == nil) != (err2 == nil) {
+			tst.Errorf("%s: runs must give the same errors\n", name)
+		}
+		chk.Array(tst, "x(run1) == x(run2)", 1e-15, res1.X, res2.X)
+		chk.Int(tst, "nfeval(run1) == nfeval(run2)", res1.Nfeval, res2.Nfeval)
+		chk.Array(tst, "x0 must not be modified", 1e-15, x0, utl.Vals(ndim, -1))
+	}
+
+	// bounds with gradient-based method
+	sol := NewMinimizer(MinBFGS, ndim, rosenbrock, nil)
+	sol.Lower = utl.Vals(ndim, 0)
+	_, err := sol.Min(x0)
+	if err == nil {
+		tst.Errorf("bounds with BFGS should have caused an error\n")
+	}
+
+	// wrong number of interpolation points
+	sol = NewMinimizer(MinBobyqa, ndim, rosenbrock, nil)
+	sol.Npts = ndim + 1
+	_, err = sol.Min(x0)
+	if err == nil {
+		tst.Errorf("wrong Npts should have caused an error\n")
+	}
+
+	// other numbers of interpolation points (starting away from the local minimum near x[0] = -1)
+	for _, npts := range []int{ndim + 2, (ndim + 1) * (ndim + 2) / 2} {
+		sol.Npts = npts
+		sol.MaxIt = 10000
+		res, err := sol.Min(utl.Vals(ndim, 0.5))
+		if err != nil {
+			tst.Errorf("BOBYQA with Npts = %d failed:\n%v", npts, err)
+			return
+		}
+		io.Pforan("BOBYQA(npts=%2d): nfeval = %d\n", npts, res.Nfeval)
+		chk.Array(tst, "x", 1e-5, res.X, utl.Vals(ndim, 1))
+	}
+}
+
+func Test_derivfree04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("derivfree04. Nelder-Mead in 1D with shrink")
+
+	// the bump around x = 0.375 makes the reflection (x = -1) and the contraction (x = 0.25 or
+	// x = 0.5) of the initial simplex {0, 1} fail, thus the simplex must shrink towards x = 0
+	fcn := func(x la.Vector) (float64, error) {
+		return (x[0]-0.1)*(x[0]-0.1) + 1000*math.Exp(-400*(x[0]-0.375)*(x[0]-0.375)), nil
+	}
+	sol := NewMinimizer(MinNelderMead, 1, fcn, nil)
+	sol.Step0 = 1
+	sol.Xtol = 1e-10
+	res, err := sol.Min([]float64{0})
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	io.Pforan("x = %v  nit = %d  nfeval = %d\n", res.X, res.Nit, res.Nfeval)
+	if !res.Converged {
+		tst.Errorf("Nelder-Mead did not converge\n")
+	}
+	chk.Array(tst, "x", 1e-6, res.X, []float64{0.1})
+	chk.Float64(tst, "f", 1e-9, res.F, 0)
+}
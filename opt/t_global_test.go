@@ -0,0 +1,186 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/rnd"
+	"github.com/cpmech/gosl/utl"
+)
+
+var globalNames = map[int]string{GlobalDE: "DE", GlobalPSO: "PSO", GlobalCMAES: "CMA-ES"}
+
+// rastrigin implements the Rastrigin function (multimodal) with minimum f = 0 at x = 0
+func rastrigin(x la.Vector) (f float64, err error) {
+	f = 10 * float64(len(x))
+	for _, xi := range x {
+		f += xi*xi - 10*math.Cos(2*math.Pi*xi)
+	}
+	return
+}
+
+func Test_global01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("global01. population-based methods: Rastrigin function")
+
+	for _, method := range []int{GlobalDE, GlobalPSO, GlobalCMAES} {
+
+		// solve
+		name := globalNames[method]
+		sol := NewGlobalMinimizer(method, rastrigin, utl.Vals(2, -5.12), utl.Vals(2, 5.12))
+		sol.Seed = 1234
+		sol.Hist = true
+		if method == GlobalCMAES {
+			sol.Npop = 40
+		}
+		res, err := sol.Min()
+		if err != nil {
+			tst.Errorf("%s failed:\n%v", name, err)
+			return
+		}
+
+		// check
+		io.Pforan("%-7s: x = %v  f = %v  nit = %4d  nfeval = %5d\n", name, res.X, res.F, res.Nit, res.Nfeval)
+		if !res.Converged {
+			tst.Errorf("%s did not converge\n", name)
+		}
+		chk.Array(tst, "x", 1e-7, res.X, nil)
+		chk.Float64(tst, "f", 1e-12, res.F, 0)
+		chk.Int(tst, "len(HistF)", len(res.HistF), res.Nit+1)
+		chk.Int(tst, "len(HistX)", len(res.HistX), res.Nit+1)
+		for i := 1; i < len(res.HistF); i++ {
+			if res.HistF[i] > res.HistF[i-1] {
+				tst.Errorf("%s: best f(x) must not increase\n", name)
+				break
+			}
+		}
+	}
+}
+
+func Test_global02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("global02. strategies of differential evolution and box bounds")
+
+	// strategies: Rosenbrock function
+	names := []string{"rand/1/bin", "best/1/bin", "current-to-best/1/bin", "rand/2/bin", "best/2/bin"}
+	for strategy := DeRand1Bin; strategy <= DeBest2Bin; strategy++ {
+		sol := NewGlobalMinimizer(GlobalDE, rosenbrock, utl.Vals(3, -5), utl.Vals(3, 5))
+		sol.Seed = 1234
+		sol.DeStrategy = strategy
+		res, err := sol.Min()
+		if err != nil {
+			tst.Errorf("DE %s failed:\n%v", names[strategy], err)
+			return
+		}
+		io.Pforan("DE %-22s: nit = %4d  nfeval = %5d  f = %v\n", names[strategy], res.Nit, res.Nfeval, res.F)
+		chk.Array(tst, "x", 1e-6, res.X, []float64{1, 1, 1})
+	}
+
+	// solution on bounds: f(x) = Σ (xᵢ - 3)²  with  -2 ≤ x ≤ 2
+	ffcn := func(x la.Vector) (f float64, err error) {
+		for _, xi := range x {
+			f += (xi - 3) * (xi - 3)
+		}
+		return
+	}
+	for _, method := range []int{GlobalDE, GlobalPSO, GlobalCMAES} {
+		name := globalNames[method]
+		sol := NewGlobalMinimizer(method, ffcn, utl.Vals(4, -2), utl.Vals(4, 2))
+		sol.Seed = 1234
+		res, err := sol.Min()
+		if err != nil {
+			tst.Errorf("%s failed:\n%v", name, err)
+			return
+		}
+		io.Pforan("%-7s: x = %v  nit = %4d  nfeval = %5d\n", name, res.X, res.Nit, res.Nfeval)
+		chk.Array(tst, "x", 1e-10, res.X, utl.Vals(4, 2))
+	}
+}
+
+func Test_global03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("global03. reproducibility, parallel evaluation, budget and errors")
+
+	lower, upper := utl.Vals(5, -5.12), utl.Vals(5, 5.12)
+	for _, method := range []int{GlobalDE, GlobalPSO, GlobalCMAES} {
+		name := globalNames[method]
+
+		// reproducibility with sequential and concurrent evaluations; the global generator must
+		// neither affect the results nor be affected
+		var xs []la.Vector
+		var nfevals []int
+		for k, nworkers := range []int{1, 1, 4} {
+			rnd.Init(100 + k)
+			a := rnd.Int(0, 1000000)
+			rnd.Init(100 + k)
+			sol := NewGlobalMinimizer(method, rastrigin, lower, upper)
+			sol.Seed = 4321
+			sol.MaxIt = 50
+			sol.Nworkers = nworkers
+			res, err := sol.Min()
+			if !utl.IsMaxIt(err) && err != nil {
+				tst.Errorf("%s failed:\n%v", name, err)
+				return
+			}
+			xs = append(xs, res.X)
+			nfevals = append(nfevals, res.Nfeval)
+			chk.Int(tst, "global generator", rnd.Int(0, 1000000), a)
+		}
+		io.Pforan("%-7s: x = %v\n", name, xs[0])
+		chk.Array(tst, "x(run1) == x(run2)", 1e-17, xs[0], xs[1])
+		chk.Array(tst, "x(sequential) == x(parallel)", 1e-17, xs[0], xs[2])
+		chk.Ints(tst, "nfeval", nfevals, []int{nfevals[0], nfevals[0], nfevals[0]})
+
+		// budget
+		sol := NewGlobalMinimizer(method, rastrigin, lower, upper)
+		sol.MaxFeval = 500
+		res, err := sol.Min()
+		if !utl.IsMaxIt(err) {
+			tst.Errorf("%s: budget should have caused a max iterations error. err = %v\n", name, err)
+		}
+		if res.Nfeval > 500 {
+			tst.Errorf("%s: number of evaluations (%d) must not exceed the budget\n", name, res.Nfeval)
+		}
+
+		// error in f(x)
+		sol = NewGlobalMinimizer(method, func(x la.Vector) (float64, error) {
+			return 0, chk.Err("f(x) failed")
+		}, lower, upper)
+		sol.Nworkers = 3
+		_, err = sol.Min()
+		if err == nil {
+			tst.Errorf("%s: error in f(x) should have been returned\n", name)
+		}
+	}
+
+	// wrong input
+	_, err := NewGlobalMinimizer(GlobalDE, rastrigin, []float64{0, 1}, []float64{1, 1}).Min()
+	if err == nil {
+		tst.Errorf("Lower = Upper should have caused an error\n")
+	}
+	_, err = NewGlobalMinimizer(GlobalPSO, rastrigin, []float64{0, math.Inf(-1)}, []float64{1, 1}).Min()
+	if err == nil {
+		tst.Errorf("infinite bounds should have caused an error\n")
+	}
+	_, err = NewGlobalMinimizer(-1, rastrigin, lower, upper).Min()
+	if err == nil {
+		tst.Errorf("wrong method should have caused an error\n")
+	}
+	sol := NewGlobalMinimizer(GlobalDE, rastrigin, lower, upper)
+	sol.DeStrategy = DeRand2Bin
+	sol.Npop = 5
+	_, err = sol.Min()
+	if err == nil {
+		tst.Errorf("small population should have caused an error\n")
+	}
+}
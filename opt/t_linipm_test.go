@@ -5,6 +5,7 @@
 package opt
 
 import (
+	"context"
 	"math"
 	"testing"
 	"time"
@@ -13,6 +14,7 @@ import (
 	"github.com/cpmech/gosl/io"
 	"github.com/cpmech/gosl/la"
 	"github.com/cpmech/gosl/plt"
+	"github.com/cpmech/gosl/utl"
 )
 
 func Test_linipm01(tst *testing.T) {
@@ -198,3 +200,202 @@ func Test_linipm03(tst *testing.T) {
 	la.MatVecMul(bres, 1, A.ToDense(), ipm.X)
 	chk.Array(tst, "A*x=b", 1e-13, bres, b)
 }
+
+func Test_linipm04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("linipm04. progress and cancellation")
+
+	// problem of linipm01
+	var T la.Triplet
+	T.Init(2, 4, 6)
+	T.Put(0, 0, 2.0)
+	T.Put(0, 1, 1.0)
+	T.Put(0, 2, 1.0)
+	T.Put(1, 0, 1.0)
+	T.Put(1, 1, 2.0)
+	T.Put(1, 3, 1.0)
+	Am := T.ToMatrix(nil)
+	c := []float64{-4, -5, 0, 0}
+	b := []float64{3, 3}
+
+	// progress
+	var ipm LinIpm
+	defer ipm.Free()
+	ipm.Init(Am, b, c, nil)
+	var gaps []float64
+	ipm.Monitor.Callback = func(p utl.Progress) {
+		gaps = append(gaps, p.Residual)
+	}
+	err := ipm.SolveCtx(context.Background(), chk.Verbose)
+	if err != nil {
+		tst.Errorf("ipm failed:\n%v", err)
+		return
+	}
+	io.Pforan("gaps = %v\n", gaps)
+	chk.Array(tst, "x", 1e-8, ipm.X[:2], []float64{1, 1})
+	if len(gaps) < 2 {
+		tst.Errorf("progress callback must be called at each iteration\n")
+		return
+	}
+
+	// cancellation
+	ctx, cancel := context.WithCancel(context.Background())
+	ipm.Free()
+	ipm.Init(Am, b, c, nil)
+	ipm.Monitor.Callback = func(p utl.Progress) {
+		if p.It == 1 {
+			cancel()
+		}
+	}
+	err = ipm.SolveCtx(ctx, chk.Verbose)
+	io.Pforan("err = %v\n", err)
+	if !utl.IsCancelled(err) {
+		tst.Errorf("LinIpm should have been cancelled\n")
+		return
+	}
+
+	// max iterations
+	ipm.Free()
+	ipm.Init(Am, b, c, nil)
+	ipm.Monitor.Callback = nil
+	ipm.NmaxIt = 2
+	err = ipm.Solve(chk.Verbose)
+	io.Pforan("err = %v\n", err)
+	if !utl.IsMaxIt(err) {
+		tst.Errorf("LinIpm should have failed with max iterations error\n")
+	}
+}
+
+func Test_linipm05(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("linipm05. general form: free variable and inequalities")
+
+	// problem of linipm02 in general form
+	//   min   2*x0 +   x1
+	//   s.t.   -x0 +   x1 ≤ 1
+	//           x0 +   x1 ≥ 2
+	//           x0 - 2*x1 ≤ 4
+	//         x0 free, x1 ≥ 0
+	var T la.Triplet
+	T.Init(3, 2, 6)
+	T.Put(0, 0, -1)
+	T.Put(0, 1, 1)
+	T.Put(1, 0, 1)
+	T.Put(1, 1, 1)
+	T.Put(2, 0, 1)
+	T.Put(2, 1, -2)
+	prob := &LinProb{
+		A:    T.ToMatrix(nil),
+		B:    []float64{1, 2, 4},
+		C:    []float64{2, 1},
+		Kind: []int{LinLe, LinGe, LinLe},
+		L:    []float64{math.Inf(-1), 0},
+	}
+
+	// solve LP
+	var ipm LinIpm
+	defer ipm.Free()
+	ipm.InitGeneral(prob, nil)
+	err := ipm.Solve(chk.Verbose)
+	if err != nil {
+		tst.Errorf("ipm failed:\n%v", err)
+		return
+	}
+
+	// check
+	io.Pforan("x = %v\n", ipm.Xg)
+	io.Pfcyan("λ = %v\n", ipm.Lg)
+	io.Pforan("z = %v\n", ipm.Zg)
+	chk.Int(tst, "nx(standard)", ipm.Nx, 6)
+	chk.Array(tst, "x", 1e-8, ipm.Xg, []float64{0.5, 1.5})
+	chk.Array(tst, "λ", 1e-7, ipm.Lg, []float64{-0.5, 1.5, 0})
+	chk.Array(tst, "z", 1e-7, ipm.Zg, []float64{0, 0})
+	chk.Float64(tst, "f", 1e-8, ipm.Fg, 2.5)
+}
+
+func Test_linipm06(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("linipm06. general form: bounded and fixed variables")
+
+	// linear program
+	//   min  -2*x0 - x1 + x2 + x3
+	//   s.t.    x0 + x1           ≤ 5
+	//                x1 - x2      ≥ 0
+	//           x0      + x2 + x3 = 1.5
+	//         1 ≤ x0 ≤ 2,  x1 ≤ 4,  x2 ≥ -5,  x3 = 0.5
+	// solution
+	//   x = {2, 3, -1, 0.5}  with  x0 at its upper bound
+	var T la.Triplet
+	T.Init(3, 4, 7)
+	T.Put(0, 0, 1)
+	T.Put(0, 1, 1)
+	T.Put(1, 1, 1)
+	T.Put(1, 2, -1)
+	T.Put(2, 0, 1)
+	T.Put(2, 2, 1)
+	T.Put(2, 3, 1)
+	prob := &LinProb{
+		A:    T.ToMatrix(nil),
+		B:    []float64{5, 0, 1.5},
+		C:    []float64{-2, -1, 1, 1},
+		Kind: []int{LinLe, LinGe, LinEq},
+		L:    []float64{1, math.Inf(-1), -5, 0.5},
+		U:    []float64{2, 4, LinInf, 0.5},
+	}
+
+	// solve LP
+	var ipm LinIpm
+	defer ipm.Free()
+	ipm.InitGeneral(prob, nil)
+	err := ipm.Solve(chk.Verbose)
+	if err != nil {
+		tst.Errorf("ipm failed:\n%v", err)
+		return
+	}
+
+	// check
+	io.Pforan("x = %v\n", ipm.Xg)
+	io.Pfcyan("λ = %v\n", ipm.Lg)
+	io.Pforan("z = %v\n", ipm.Zg)
+	chk.Int(tst, "nl(standard)", ipm.Nl, 4)
+	chk.Array(tst, "x", 1e-8, ipm.Xg, []float64{2, 3, -1, 0.5})
+	chk.Array(tst, "λ", 1e-7, ipm.Lg, []float64{-1, 0, 1})
+	chk.Array(tst, "z", 1e-7, ipm.Zg, []float64{-2, 0, 0, 0})
+	chk.Float64(tst, "f", 1e-8, ipm.Fg, -7.5)
+}
+
+func Test_linipm07(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("linipm07. general form: bounds from file")
+
+	// read LP with upper bounds on some variables
+	A, b, c, l, u := ReadLPfortran("data/kb2.dat")
+	prob := &LinProb{A: A, B: b, C: c, L: l, U: u}
+
+	// solve LP
+	var ipm LinIpm
+	defer ipm.Free()
+	ipm.InitGeneral(prob, nil)
+	err := ipm.Solve(chk.Verbose)
+	if err != nil {
+		tst.Errorf("ipm failed:\n%v", err)
+		return
+	}
+
+	// check
+	io.Pf("\n")
+	bres := make([]float64, len(b))
+	la.SpMatVecMul(bres, 1, A, ipm.Xg)
+	chk.Array(tst, "A*x=b", 1e-9, bres, b)
+	for j, x := range ipm.Xg {
+		if x < l[j]-1e-9 || x > u[j]+1e-9 {
+			tst.Errorf("x[%d]=%g is outside bounds [%g, %g]\n", j, x, l[j], u[j])
+			return
+		}
+	}
+	chk.Float64(tst, "f", 1e-5, ipm.Fg, -1.74990012991e+03) // from Netlib
+}
@@ -0,0 +1,411 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+)
+
+// linsimplexProb01 returns the problem of linipm01 in general form
+//   min  -4*x0 - 5*x1
+//   s.t.  2*x0 +   x1 ≤ 3
+//           x0 + 2*x1 ≤ 3
+//         x0,x1 ≥ 0
+func linsimplexProb01() *LinProb {
+	var T la.Triplet
+	T.Init(2, 2, 4)
+	T.Put(0, 0, 2)
+	T.Put(0, 1, 1)
+	T.Put(1, 0, 1)
+	T.Put(1, 1, 2)
+	return &LinProb{
+		A:    T.ToMatrix(nil),
+		B:    []float64{3, 3},
+		C:    []float64{-4, -5},
+		Kind: []int{LinLe, LinLe},
+	}
+}
+
+func Test_linsimplex01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("linsimplex01. pricing rules and sensitivity ranges")
+
+	inf := math.Inf(1)
+	for _, pricing := range []int{SimplexSteepest, SimplexDantzig, SimplexBland} {
+
+		// solve
+		prob := linsimplexProb01()
+		lps := NewLinSimplex(prob)
+		lps.Pricing = pricing
+		lps.Verbose = chk.Verbose
+		err := lps.Solve()
+		if err != nil {
+			tst.Errorf("simplex failed:\n%v", err)
+			return
+		}
+
+		// check
+		io.Pforan("pricing = %d: x = %v  nit = %d\n", pricing, lps.X, lps.Nit)
+		chk.Int(tst, "status", lps.Status, SimplexOptimal)
+		chk.Array(tst, "x", 1e-12, lps.X, []float64{1, 1})
+		chk.Array(tst, "r", 1e-12, lps.R, []float64{3, 3})
+		chk.Array(tst, "λ", 1e-12, lps.L, []float64{-1, -2})
+		chk.Array(tst, "z", 1e-12, lps.Z, []float64{0, 0})
+		chk.Float64(tst, "f", 1e-12, lps.F, -9)
+
+		// sensitivity
+		clo, chi, err := lps.CostRanges()
+		if err != nil {
+			tst.Errorf("%v", err)
+			return
+		}
+		chk.Array(tst, "clo", 1e-12, clo, []float64{-10, -8})
+		chk.Array(tst, "chi", 1e-12, chi, []float64{-2.5, -2})
+		blo, bhi, err := lps.RhsRanges()
+		if err != nil {
+			tst.Errorf("%v", err)
+			return
+		}
+		chk.Array(tst, "blo", 1e-12, blo, []float64{1.5, 1.5})
+		chk.Array(tst, "bhi", 1e-12, bhi, []float64{6, 6})
+
+		// maximisation of -f
+		prob.Maximise = true
+		prob.C = []float64{4, 5}
+		err = lps.Solve()
+		if err != nil {
+			tst.Errorf("simplex failed:\n%v", err)
+			return
+		}
+		chk.Array(tst, "x (max)", 1e-12, lps.X, []float64{1, 1})
+		chk.Array(tst, "λ (max)", 1e-12, lps.L, []float64{1, 2})
+		chk.Float64(tst, "f (max)", 1e-12, lps.F, 9)
+		clo, chi, _ = lps.CostRanges()
+		chk.Array(tst, "clo (max)", 1e-12, clo, []float64{2.5, 2})
+		chk.Array(tst, "chi (max)", 1e-12, chi, []float64{10, 8})
+	}
+
+	// non-basic variable
+	//   min  x0 + x1  s.t.  x0 + 2*x1 ≥ 2,  x ≥ 0   ⇒   x = {0, 1}
+	var T la.Triplet
+	T.Init(1, 2, 2)
+	T.Put(0, 0, 1)
+	T.Put(0, 1, 2)
+	lps := NewLinSimplex(&LinProb{A: T.ToMatrix(nil), B: []float64{2}, C: []float64{1, 1}, Kind: []int{LinGe}})
+	err := lps.Solve()
+	if err != nil {
+		tst.Errorf("simplex failed:\n%v", err)
+		return
+	}
+	chk.Array(tst, "x", 1e-12, lps.X, []float64{0, 1})
+	chk.Array(tst, "z", 1e-12, lps.Z, []float64{0.5, 0})
+	clo, chi, _ := lps.CostRanges()
+	checkInfArray(tst, "clo", clo, []float64{0.5, 0})
+	checkInfArray(tst, "chi", chi, []float64{inf, 2})
+	blo, bhi, _ := lps.RhsRanges()
+	checkInfArray(tst, "blo", blo, []float64{0})
+	checkInfArray(tst, "bhi", bhi, []float64{inf})
+}
+
+func Test_linsimplex02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("linsimplex02. general form: bounds, free variables and ranges")
+
+	// problem of linipm05: free variable
+	var T la.Triplet
+	T.Init(3, 2, 6)
+	T.Put(0, 0, -1)
+	T.Put(0, 1, 1)
+	T.Put(1, 0, 1)
+	T.Put(1, 1, 1)
+	T.Put(2, 0, 1)
+	T.Put(2, 1, -2)
+	lps := NewLinSimplex(&LinProb{
+		A:    T.ToMatrix(nil),
+		B:    []float64{1, 2, 4},
+		C:    []float64{2, 1},
+		Kind: []int{LinLe, LinGe, LinLe},
+		L:    []float64{math.Inf(-1), 0},
+	})
+	err := lps.Solve()
+	if err != nil {
+		tst.Errorf("simplex failed:\n%v", err)
+		return
+	}
+	chk.Array(tst, "x", 1e-12, lps.X, []float64{0.5, 1.5})
+	chk.Array(tst, "λ", 1e-12, lps.L, []float64{-0.5, 1.5, 0})
+	chk.Float64(tst, "f", 1e-12, lps.F, 2.5)
+
+	// problem of linipm06: bounded and fixed variables
+	T.Init(3, 4, 7)
+	T.Put(0, 0, 1)
+	T.Put(0, 1, 1)
+	T.Put(1, 1, 1)
+	T.Put(1, 2, -1)
+	T.Put(2, 0, 1)
+	T.Put(2, 2, 1)
+	T.Put(2, 3, 1)
+	lps = NewLinSimplex(&LinProb{
+		A:    T.ToMatrix(nil),
+		B:    []float64{5, 0, 1.5},
+		C:    []float64{-2, -1, 1, 1},
+		Kind: []int{LinLe, LinGe, LinEq},
+		L:    []float64{1, math.Inf(-1), -5, 0.5},
+		U:    []float64{2, 4, LinInf, 0.5},
+	})
+	err = lps.Solve()
+	if err != nil {
+		tst.Errorf("simplex failed:\n%v", err)
+		return
+	}
+	chk.Array(tst, "x", 1e-12, lps.X, []float64{2, 3, -1, 0.5})
+	chk.Array(tst, "λ", 1e-12, lps.L, []float64{-1, 0, 1})
+	chk.Array(tst, "z", 1e-12, lps.Z, []float64{-2, 0, 0, 0})
+	chk.Float64(tst, "f", 1e-12, lps.F, -7.5)
+
+	// problem of lpfiles01: maximisation with ranges
+	prob, err := parseMPS(lpfilesMPS, false)
+	if err != nil {
+		tst.Errorf("%v", err)
+		return
+	}
+	lps = NewLinSimplex(prob)
+	err = lps.Solve()
+	if err != nil {
+		tst.Errorf("simplex failed:\n%v", err)
+		return
+	}
+	chk.Array(tst, "x", 1e-12, lps.X, []float64{4, 1, -2, 0})
+	chk.Float64(tst, "f", 1e-12, lps.F, 18)
+
+	// Netlib problems
+	for _, p := range []struct {
+		name string
+		fopt float64
+	}{
+		{"afiro", -4.6475314286e+02},
+		{"kb2", -1.74990012991e+03},
+	} {
+		prob, err := ReadMPS("data/"+p.name+".mps", true)
+		if err != nil {
+			tst.Errorf("%v", err)
+			return
+		}
+		lps = NewLinSimplex(prob)
+		err = lps.Solve()
+		if err != nil {
+			tst.Errorf("simplex failed:\n%v", err)
+			return
+		}
+		io.Pforan("%s: f = %v  nit = %d\n", p.name, lps.F, lps.Nit)
+		chk.Float64(tst, p.name, 1e-7, lps.F, p.fopt)
+	}
+}
+
+func Test_linsimplex03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("linsimplex03. warm starts")
+
+	// solve
+	prob := linsimplexProb01()
+	lps := NewLinSimplex(prob)
+	err := lps.Solve()
+	if err != nil {
+		tst.Errorf("simplex failed:\n%v", err)
+		return
+	}
+	basis := lps.GetBasis()
+	chk.Ints(tst, "cols", basis.Cols, []int{BasisBasic, BasisBasic})
+	chk.Ints(tst, "rows", basis.Rows, []int{BasisUpper, BasisUpper})
+
+	// change bound and solve again: x0 ≤ 0.5
+	prob.U = []float64{0.5, LinInf}
+	err = lps.Solve()
+	if err != nil {
+		tst.Errorf("simplex failed:\n%v", err)
+		return
+	}
+	io.Pforan("bound: x = %v  nit = %d\n", lps.X, lps.Nit)
+	chk.Array(tst, "x", 1e-12, lps.X, []float64{0.5, 1.25})
+	chk.Float64(tst, "f", 1e-12, lps.F, -8.25)
+	chk.Int(tst, "nit", lps.Nit, 1)
+
+	// add cut x0 + x1 ≤ 1.5 and solve new problem starting from previous basis
+	var T la.Triplet
+	T.Init(3, 2, 6)
+	T.Put(0, 0, 2)
+	T.Put(0, 1, 1)
+	T.Put(1, 0, 1)
+	T.Put(1, 1, 2)
+	T.Put(2, 0, 1)
+	T.Put(2, 1, 1)
+	cut := NewLinSimplex(&LinProb{
+		A:    T.ToMatrix(nil),
+		B:    []float64{3, 3, 1.5},
+		C:    []float64{-4, -5},
+		Kind: []int{LinLe, LinLe, LinLe},
+	})
+	err = cut.SetBasis(basis)
+	if err != nil {
+		tst.Errorf("%v", err)
+		return
+	}
+	err = cut.Solve()
+	if err != nil {
+		tst.Errorf("simplex failed:\n%v", err)
+		return
+	}
+	io.Pforan("cut: x = %v  nit = %d\n", cut.X, cut.Nit)
+	chk.Array(tst, "x", 1e-12, cut.X, []float64{0, 1.5})
+	chk.Float64(tst, "f", 1e-12, cut.F, -7.5)
+	if cut.Nit > 2 {
+		tst.Errorf("warm start should take at most 2 iterations. nit = %d\n", cut.Nit)
+	}
+
+	// invalid basis
+	err = cut.SetBasis(&SimplexBasis{Cols: []int{BasisBasic, BasisBasic}, Rows: []int{BasisBasic}})
+	if err == nil {
+		tst.Errorf("wrong number of basic variables should have caused an error\n")
+	}
+	io.Pforan("%v\n", err)
+}
+
+func Test_linsimplex04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("linsimplex04. unbounded and infeasible problems")
+
+	// unbounded: min -x0  s.t.  x0 - x1 ≤ 1,  x ≥ 0
+	var T la.Triplet
+	T.Init(1, 2, 2)
+	T.Put(0, 0, 1)
+	T.Put(0, 1, -1)
+	prob := &LinProb{A: T.ToMatrix(nil), B: []float64{1}, C: []float64{-1, 0}, Kind: []int{LinLe}}
+	lps := NewLinSimplex(prob)
+	err := lps.Solve()
+	if err == nil {
+		tst.Errorf("unbounded problem should have caused an error\n")
+	}
+	io.Pforan("%v\n", err)
+	chk.Int(tst, "status", lps.Status, SimplexUnbounded)
+
+	// infeasible: min x0 + x1  s.t.  x0 + x1 ≤ -1,  x ≥ 0
+	T.Init(1, 2, 2)
+	T.Put(0, 0, 1)
+	T.Put(0, 1, 1)
+	prob = &LinProb{A: T.ToMatrix(nil), B: []float64{-1}, C: []float64{1, 1}, Kind: []int{LinLe}}
+	lps = NewLinSimplex(prob)
+	err = lps.Solve()
+	if err == nil {
+		tst.Errorf("infeasible problem should have caused an error\n")
+	}
+	io.Pforan("%v\n", err)
+	chk.Int(tst, "status", lps.Status, SimplexInfeasible)
+	_, _, err = lps.CostRanges()
+	if err == nil {
+		tst.Errorf("sensitivity of infeasible problem should have caused an error\n")
+	}
+}
+
+func Test_linsimplex05(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("linsimplex05. sparse LU of basis with product-form updates")
+
+	// arrowhead matrix: Bᵢᵢ = 4, B₀ⱼ = Bⱼ₀ = 1; dense LU in the natural order would fill it in
+	m := 200
+	var B la.Triplet
+	B.Init(m, m, 3*m)
+	D := la.NewMatrix(m, m)
+	put := func(i, j int, v float64) {
+		B.Put(i, j, v)
+		D.Set(i, j, v)
+	}
+	for i := 0; i < m; i++ {
+		put(i, i, 4)
+		if i > 0 {
+			put(0, i, 1)
+			put(i, 0, 1)
+		}
+	}
+	var lu basisLU
+	err := lu.factor(m, &B)
+	if err != nil {
+		tst.Errorf("%v\n", err)
+		return
+	}
+	nnz := m
+	for k := 0; k < m; k++ {
+		nnz += len(lu.lidx[k]) + len(lu.uidx[k])
+	}
+	io.Pforan("nnz(L+U) = %d  nnz(B) = %d\n", nnz, 3*m-2)
+	chk.Int(tst, "nnz(L+U)", nnz, 3*m-2)
+
+	// residuals of ftran and btran
+	check := func(D *la.Matrix) {
+		b, c := make([]float64, m), make([]float64, m)
+		for i := 0; i < m; i++ {
+			b[i], c[i] = math.Sin(float64(i)), math.Cos(float64(i))
+		}
+		x, y := append([]float64{}, b...), append([]float64{}, c...)
+		lu.ftran(x)
+		lu.btran(y)
+		rx, ry := 0.0, 0.0
+		for i := 0; i < m; i++ {
+			sx, sy := -b[i], -c[i]
+			for j := 0; j < m; j++ {
+				sx += D.Get(i, j) * x[j]
+				sy += D.Get(j, i) * y[j]
+			}
+			rx, ry = max(rx, math.Abs(sx)), max(ry, math.Abs(sy))
+		}
+		io.Pforan("‖B x - b‖ = %.2e  ‖Bᵀ y - c‖ = %.2e\n", rx, ry)
+		if rx > 1e-13 || ry > 1e-13 {
+			tst.Errorf("residuals are too large: %g, %g\n", rx, ry)
+		}
+	}
+	check(D)
+
+	// replace columns 5 and 100 by a_q = e₀ + 2 e_q + e_{q+1} (product-form updates)
+	for _, p := range []int{5, 100} {
+		alpha := make([]float64, m)
+		alpha[0], alpha[p], alpha[p+1] = 1, 2, 1
+		for i := 0; i < m; i++ {
+			D.Set(i, p, alpha[i])
+		}
+		lu.ftran(alpha)
+		lu.update(p, alpha)
+	}
+	check(D)
+
+	// singular: column 7 = column 3 = 4 e₃; row 7 cannot be used as pivot
+	B.Init(m, m, m)
+	for i := 0; i < m; i++ {
+		j := i
+		if i == 7 {
+			j = 3
+		}
+		B.Put(j, i, 4)
+	}
+	err = lu.factor(m, &B)
+	if err == nil {
+		tst.Errorf("singular matrix should have caused an error\n")
+		return
+	}
+	io.Pforan("%v\n", err)
+	chk.Int(tst, "rank", lu.rank, m-1)
+	if lu.sing != 3 && lu.sing != 7 {
+		tst.Errorf("dependent column should be 3 or 7. sing = %d\n", lu.sing)
+	}
+	chk.Ints(tst, "unused rows", lu.prow[lu.rank:], []int{7})
+}
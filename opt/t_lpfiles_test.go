@@ -0,0 +1,273 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"math"
+	"os"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+// test problem:
+//   max  x1 + 2*x2 - x3 + 10
+//   s.t.  1.5 ≤ x1 + x4 ≤ 4
+//               x1 + x2 ≥ 1
+//             -x2 + x3  = -3
+//          -3 ≤ x3 + x4 ≤ 0
+//         x1 ≤ 4,  -1 ≤ x2 ≤ 1,  x3 ≤ 3,  x4 ∈ {0, 1}
+//         x2 integer
+// solution (LP relaxation):
+//   x = {4, 1, -2, 0}  f = 18
+
+const lpfilesMPS = `* test problem
+NAME TESTLP
+OBJSENSE
+    MAX
+ROWS
+ N  profit
+ L  lim1
+ G  lim2
+ E  myeqn
+ E  rng
+ N  other
+COLUMNS
+ x1 profit 1 lim1 1
+ x1 lim2 1
+ MARKER 'MARKER' 'INTORG'
+ x2 profit 2 myeqn -1
+ x2 lim2 1
+ MARKER 'MARKER' 'INTEND'
+ x3 profit -1 myeqn 1
+ x3 rng 1 other 3
+ x4 lim1 1 rng 1
+RHS
+ RHS profit -10 lim1 4
+ RHS lim2 1 myeqn -3
+ RHS rng -3
+ RHS2 lim2 123
+RANGES
+ RNG lim1 2.5 rng 3
+BOUNDS
+ UP BND x1 4
+ LO BND x2 -1
+ UP BND x2 1
+ MI BND x3
+ UP BND x3 3
+ BV BND x4
+ENDATA
+`
+
+const lpfilesLP = `\ test problem
+Maximize
+ profit: x1 + 2 x2 - x3 + 10
+Subject To
+ lim1: 1.5 <= x1 + x4 <= 4
+ lim2: x1 + x2 >= 1
+ myeqn: - x2 + x3 = -3
+ rng: -3 <= x3 + x4
+   <= 0
+Bounds
+ x1 <= 4
+ -1 <= x2 <= 1
+ -inf <= x3 <= 3 \ upper bound
+Generals
+ x2
+Binaries
+ x4
+End
+`
+
+func checkInfArray(tst *testing.T, msg string, res, correct []float64) {
+	for i := range correct {
+		if res[i] != correct[i] && math.Abs(res[i]-correct[i]) > 1e-15 {
+			tst.Errorf("%s failed: %v != %v\n", msg, res, correct)
+			return
+		}
+	}
+	io.Pf("%s: %v == %v OK\n", msg, res, correct)
+}
+
+func checkLinProb(tst *testing.T, a, b *LinProb) {
+	chk.Deep2(tst, "A", 1e-15, a.A.ToDense().GetDeep2(), b.A.ToDense().GetDeep2())
+	chk.Array(tst, "c", 1e-15, a.C, b.C)
+	chk.Float64(tst, "c0", 1e-15, a.C0, b.C0)
+	if a.Maximise != b.Maximise {
+		tst.Errorf("objective senses are different\n")
+	}
+	chk.String(tst, a.GetObjName(), b.GetObjName())
+	for i := 0; i < len(a.B); i++ {
+		alo, ahi := a.RowBounds(i)
+		blo, bhi := b.RowBounds(i)
+		checkInfArray(tst, "row bounds "+a.RowName(i), []float64{alo, ahi}, []float64{blo, bhi})
+		chk.String(tst, a.RowName(i), b.RowName(i))
+	}
+	for j := 0; j < len(a.C); j++ {
+		checkInfArray(tst, "bounds "+a.ColName(j), []float64{a.Lower(j), a.Upper(j)}, []float64{b.Lower(j), b.Upper(j)})
+		chk.String(tst, a.ColName(j), b.ColName(j))
+		if a.IsInteger(j) != b.IsInteger(j) {
+			tst.Errorf("integrality of %s is different\n", a.ColName(j))
+		}
+	}
+}
+
+func Test_lpfiles01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("lpfiles01. read MPS and CPLEX-LP files")
+
+	// MPS
+	io.WriteStringToFileD("/tmp/gosl/opt", "lpfiles01.mps", lpfilesMPS)
+	prob, err := ReadMPS("/tmp/gosl/opt/lpfiles01.mps", false)
+	if err != nil {
+		tst.Errorf("%v", err)
+		return
+	}
+	inf := math.Inf(1)
+	chk.String(tst, prob.Name, "TESTLP")
+	chk.String(tst, prob.ObjName, "profit")
+	chk.Strings(tst, "rows", prob.RowNames, []string{"lim1", "lim2", "myeqn", "rng"})
+	chk.Strings(tst, "cols", prob.ColNames, []string{"x1", "x2", "x3", "x4"})
+	chk.Ints(tst, "kind", prob.Kind, []int{LinLe, LinGe, LinEq, LinEq})
+	chk.Array(tst, "b", 1e-15, prob.B, []float64{4, 1, -3, -3})
+	chk.Array(tst, "r", 1e-15, prob.R, []float64{2.5, 0, 0, 3})
+	chk.Array(tst, "c", 1e-15, prob.C, []float64{1, 2, -1, 0})
+	checkInfArray(tst, "l", prob.L, []float64{0, -1, -inf, 0})
+	chk.Array(tst, "u", 1e-15, prob.U, []float64{4, 1, 3, 1})
+	chk.Float64(tst, "c0", 1e-15, prob.C0, 10)
+	chk.Bools(tst, "integer", prob.Integer, []bool{false, true, false, true})
+	chk.Deep2(tst, "A", 1e-15, prob.A.ToDense().GetDeep2(), [][]float64{
+		{1, 0, 0, 1},
+		{1, 1, 0, 0},
+		{0, -1, 1, 0},
+		{0, 0, 1, 1},
+	})
+	if !prob.Maximise {
+		tst.Errorf("objective sense should be MAX\n")
+		return
+	}
+
+	// CPLEX-LP
+	io.WriteStringToFileD("/tmp/gosl/opt", "lpfiles01.lp", lpfilesLP)
+	lp, err := ReadLPcplex("/tmp/gosl/opt/lpfiles01.lp")
+	if err != nil {
+		tst.Errorf("%v", err)
+		return
+	}
+	checkLinProb(tst, lp, prob)
+
+	// solve LP relaxation
+	var ipm LinIpm
+	defer ipm.Free()
+	ipm.InitGeneral(prob, nil)
+	err = ipm.Solve(chk.Verbose)
+	if err != nil {
+		tst.Errorf("ipm failed:\n%v", err)
+		return
+	}
+	io.Pforan("x = %v\n", ipm.Xg)
+	chk.Array(tst, "x", 1e-7, ipm.Xg, []float64{4, 1, -2, 0})
+	chk.Float64(tst, "f", 1e-7, ipm.Fg, 18)
+
+	// errors
+	_, err = parseMPS("NAME X\nROWS\n N obj\nCOLUMNS\n x obj 1 r1 2\nENDATA\n", false)
+	if err == nil {
+		tst.Errorf("undefined row should have caused an error\n")
+	}
+	io.Pforan("%v\n", err)
+	_, err = parseLPcplex("Minimize\n obj: x + y\nSubject To\n c1: x + y\nEnd\n")
+	if err == nil {
+		tst.Errorf("missing operator should have caused an error\n")
+	}
+	io.Pforan("%v\n", err)
+}
+
+func Test_lpfiles02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("lpfiles02. write MPS and CPLEX-LP files")
+
+	// problem
+	prob, err := parseMPS(lpfilesMPS, false)
+	if err != nil {
+		tst.Errorf("%v", err)
+		return
+	}
+
+	// write and read again
+	os.MkdirAll("/tmp/gosl/opt", 0777)
+	for _, fixed := range []bool{true, false} {
+		fn := io.Sf("/tmp/gosl/opt/lpfiles02-fixed%v.mps", fixed)
+		err = prob.WriteMPS(fn, fixed)
+		if err != nil {
+			tst.Errorf("%v", err)
+			return
+		}
+		res, err := ReadMPS(fn, fixed)
+		if err != nil {
+			tst.Errorf("%v", err)
+			return
+		}
+		checkLinProb(tst, res, prob)
+	}
+	err = prob.WriteLPcplex("/tmp/gosl/opt/lpfiles02.lp")
+	if err != nil {
+		tst.Errorf("%v", err)
+		return
+	}
+	res, err := ReadLPcplex("/tmp/gosl/opt/lpfiles02.lp")
+	if err != nil {
+		tst.Errorf("%v", err)
+		return
+	}
+	checkLinProb(tst, res, prob)
+
+	// invalid names
+	prob.ColNames[0] = "very_long_name"
+	err = prob.WriteMPS("/tmp/gosl/opt/lpfiles02.mps", true)
+	if err == nil {
+		tst.Errorf("long name in fixed MPS should have caused an error\n")
+	}
+	io.Pforan("%v\n", err)
+	prob.ColNames[0] = "x+1"
+	err = prob.WriteLPcplex("/tmp/gosl/opt/lpfiles02.lp")
+	if err == nil {
+		tst.Errorf("name with + in LP file should have caused an error\n")
+	}
+	io.Pforan("%v\n", err)
+}
+
+func Test_lpfiles03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("lpfiles03. Netlib problems in fixed MPS format")
+
+	// optimal values from Netlib
+	for _, p := range []struct {
+		name string
+		fopt float64
+	}{
+		{"afiro", -4.6475314286e+02},
+		{"kb2", -1.74990012991e+03},
+	} {
+		prob, err := ReadMPS("data/"+p.name+".mps", true)
+		if err != nil {
+			tst.Errorf("%v", err)
+			return
+		}
+		var ipm LinIpm
+		ipm.InitGeneral(prob, nil)
+		err = ipm.Solve(chk.Verbose)
+		ipm.Free()
+		if err != nil {
+			tst.Errorf("ipm failed:\n%v", err)
+			return
+		}
+		io.Pforan("%s: f = %v\n", p.name, ipm.Fg)
+		chk.Float64(tst, p.name, 1e-5, ipm.Fg, p.fopt)
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+// milpKnapsack returns the knapsack problem
+//   max  8*x0 + 11*x1 + 6*x2 + 4*x3
+//   s.t. 5*x0 +  7*x1 + 4*x2 + 3*x3 ≤ 14
+//        x binary
+// solution:
+//   x = {0, 1, 1, 1}  f = 21  (LP relaxation: f = 22)
+func milpKnapsack() *LinProb {
+	var T la.Triplet
+	T.Init(1, 4, 4)
+	T.Put(0, 0, 5)
+	T.Put(0, 1, 7)
+	T.Put(0, 2, 4)
+	T.Put(0, 3, 3)
+	return &LinProb{
+		A:        T.ToMatrix(nil),
+		B:        []float64{14},
+		C:        []float64{8, 11, 6, 4},
+		Kind:     []int{LinLe},
+		U:        []float64{1, 1, 1, 1},
+		Integer:  []bool{true, true, true, true},
+		Maximise: true,
+	}
+}
+
+func Test_milp01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("milp01. knapsack with all node selection and branching rules")
+
+	for _, nodesel := range []int{MilpBestFirst, MilpDepthFirst} {
+		for _, branching := range []int{MilpPseudoCost, MilpMostFractional} {
+			for _, rounding := range []bool{true, false} {
+				milp := NewMilp(milpKnapsack())
+				milp.NodeSel = nodesel
+				milp.Branching = branching
+				milp.Rounding = rounding
+				milp.Verbose = chk.Verbose
+				err := milp.Solve()
+				if err != nil {
+					tst.Errorf("milp failed:\n%v", err)
+					return
+				}
+				io.Pforan("nodesel=%d branching=%d rounding=%v: x = %v  nodes = %d\n", nodesel, branching, rounding, milp.X, milp.Nodes)
+				chk.Int(tst, "status", milp.Status, MilpOptimal)
+				chk.Array(tst, "x", 1e-15, milp.X, []float64{0, 1, 1, 1})
+				chk.Float64(tst, "f", 1e-12, milp.F, 21)
+				chk.Float64(tst, "bound", 1e-12, milp.Bound, 21)
+				chk.Float64(tst, "gap", 1e-15, milp.Gap, 0)
+			}
+		}
+	}
+}
+
+func Test_milp02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("milp02. general integers, mixed problem and infeasibility")
+
+	// general integers
+	//   max  x1
+	//   s.t. -x0 +   x1 ≤ 1
+	//       3*x0 + 2*x1 ≤ 12
+	//       2*x0 + 3*x1 ≤ 12
+	//        x ≥ 0 integer
+	// solution: f = 2 (LP relaxation: x = {1.8, 2.8})
+	var T la.Triplet
+	T.Init(3, 2, 6)
+	T.Put(0, 0, -1)
+	T.Put(0, 1, 1)
+	T.Put(1, 0, 3)
+	T.Put(1, 1, 2)
+	T.Put(2, 0, 2)
+	T.Put(2, 1, 3)
+	milp := NewMilp(&LinProb{
+		A:        T.ToMatrix(nil),
+		B:        []float64{1, 12, 12},
+		C:        []float64{0, 1},
+		Kind:     []int{LinLe, LinLe, LinLe},
+		Integer:  []bool{true, true},
+		Maximise: true,
+	})
+	err := milp.Solve()
+	if err != nil {
+		tst.Errorf("milp failed:\n%v", err)
+		return
+	}
+	io.Pforan("x = %v  nodes = %d\n", milp.X, milp.Nodes)
+	chk.Float64(tst, "f", 1e-12, milp.F, 2)
+	chk.Float64(tst, "x1", 1e-15, milp.X[1], 2)
+
+	// mixed problem
+	//   min  -x0 - 2*x1 + x2
+	//   s.t.  x0 +   x1 + x2 ≤ 3.5
+	//         x0 -   x1      ≤ 0.5
+	//               2*x1 - x2 ≤ 3.5
+	//         x0, x1 ≥ 0 integer,  x2 ≥ 0
+	// solution:
+	//   x = {1, 2, 0.5}  f = -4.5
+	T.Init(3, 3, 7)
+	T.Put(0, 0, 1)
+	T.Put(0, 1, 1)
+	T.Put(0, 2, 1)
+	T.Put(1, 0, 1)
+	T.Put(1, 1, -1)
+	T.Put(2, 1, 2)
+	T.Put(2, 2, -1)
+	milp = NewMilp(&LinProb{
+		A:       T.ToMatrix(nil),
+		B:       []float64{3.5, 0.5, 3.5},
+		C:       []float64{-1, -2, 1},
+		Kind:    []int{LinLe, LinLe, LinLe},
+		Integer: []bool{true, true, false},
+	})
+	err = milp.Solve()
+	if err != nil {
+		tst.Errorf("milp failed:\n%v", err)
+		return
+	}
+	io.Pforan("x = %v  nodes = %d\n", milp.X, milp.Nodes)
+	chk.Array(tst, "x", 1e-12, milp.X, []float64{1, 2, 0.5})
+	chk.Float64(tst, "f", 1e-12, milp.F, -4.5)
+
+	// problem of lpfiles01 (LP relaxation has integer solution)
+	prob, err := parseMPS(lpfilesMPS, false)
+	if err != nil {
+		tst.Errorf("%v", err)
+		return
+	}
+	milp = NewMilp(prob)
+	err = milp.Solve()
+	if err != nil {
+		tst.Errorf("milp failed:\n%v", err)
+		return
+	}
+	chk.Array(tst, "x", 1e-12, milp.X, []float64{4, 1, -2, 0})
+	chk.Float64(tst, "f", 1e-12, milp.F, 18)
+	chk.Int(tst, "nodes", milp.Nodes, 1)
+
+	// infeasible: 2*x0 = 1,  0 ≤ x0 ≤ 10 integer
+	T.Init(1, 1, 1)
+	T.Put(0, 0, 2)
+	milp = NewMilp(&LinProb{
+		A:       T.ToMatrix(nil),
+		B:       []float64{1},
+		C:       []float64{1},
+		Kind:    []int{LinEq},
+		U:       []float64{10},
+		Integer: []bool{true},
+	})
+	err = milp.Solve()
+	if err == nil {
+		tst.Errorf("infeasible problem should have caused an error\n")
+	}
+	io.Pforan("%v\n", err)
+	chk.Int(tst, "status", milp.Status, MilpInfeasible)
+}
+
+func Test_milp03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("milp03. incumbent callback and limits")
+
+	// callback
+	var fs []float64
+	milp := NewMilp(milpKnapsack())
+	milp.NodeSel = MilpDepthFirst
+	milp.Rounding = false
+	milp.Incumbent = func(x la.Vector, f, bound float64) bool {
+		io.Pforan("incumbent: x = %v  f = %v  bound = %v\n", x, f, bound)
+		if f > bound+1e-12 {
+			tst.Errorf("incumbent value cannot be greater than bound in maximisation: %v > %v\n", f, bound)
+		}
+		fs = append(fs, f)
+		return false
+	}
+	err := milp.Solve()
+	if err != nil {
+		tst.Errorf("milp failed:\n%v", err)
+		return
+	}
+	if len(fs) < 1 {
+		tst.Errorf("callback should have been called\n")
+		return
+	}
+	for i := 1; i < len(fs); i++ {
+		if fs[i] <= fs[i-1] {
+			tst.Errorf("incumbent values should increase: %v\n", fs)
+		}
+	}
+	chk.Float64(tst, "last incumbent", 1e-12, fs[len(fs)-1], 21)
+
+	// stop with first incumbent
+	milp.Incumbent = func(x la.Vector, f, bound float64) bool { return true }
+	err = milp.Solve()
+	if err != nil {
+		tst.Errorf("milp failed:\n%v", err)
+		return
+	}
+	chk.Int(tst, "status", milp.Status, MilpFeasible)
+	chk.Float64(tst, "f", 1e-12, milp.F, fs[0])
+
+	// node limit
+	milp.Incumbent = nil
+	milp.NmaxNodes = 1
+	err = milp.Solve()
+	if err == nil {
+		tst.Errorf("node limit should have caused an error\n")
+	}
+	io.Pforan("%v\n", err)
+	if !utl.IsMaxIt(err) {
+		tst.Errorf("error should be of max iterations kind\n")
+	}
+	chk.Int(tst, "nodes", milp.Nodes, 1)
+	chk.Float64(tst, "bound", 1e-12, milp.Bound, 22)
+
+	// time limit
+	milp.NmaxNodes = 100
+	milp.TimeLimit = time.Nanosecond
+	err = milp.Solve()
+	if !utl.IsCancelled(err) {
+		tst.Errorf("time limit should have caused a cancellation error. err = %v\n", err)
+	}
+	io.Pforan("%v\n", err)
+	chk.Int(tst, "status", milp.Status, MilpUnsolved)
+}
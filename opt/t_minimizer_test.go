@@ -0,0 +1,206 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/plt"
+	"github.com/cpmech/gosl/utl"
+)
+
+// rosenbrock computes the n-dimensional Rosenbrock function
+//   f(x) = Σ 100 (x[i+1] - x[i]²)² + (1 - x[i])²
+func rosenbrock(x la.Vector) (f float64, err error) {
+	for i := 0; i < len(x)-1; i++ {
+		a, b := x[i+1]-x[i]*x[i], 1-x[i]
+		f += 100*a*a + b*b
+	}
+	return
+}
+
+// rosenbrockGrad computes the gradient of the Rosenbrock function
+func rosenbrockGrad(g, x la.Vector) (err error) {
+	g.Fill(0)
+	for i := 0; i < len(x)-1; i++ {
+		a := x[i+1] - x[i]*x[i]
+		g[i] += -400*a*x[i] - 2*(1-x[i])
+		g[i+1] += 200 * a
+	}
+	return
+}
+
+// rosenbrockHess computes the Hessian of the Rosenbrock function
+func rosenbrockHess(H *la.Matrix, x la.Vector) (err error) {
+	H.Fill(0)
+	for i := 0; i < len(x)-1; i++ {
+		H.Add(i, i, 1200*x[i]*x[i]-400*x[i+1]+2)
+		H.Add(i, i+1, -400*x[i])
+		H.Add(i+1, i, -400*x[i])
+		H.Add(i+1, i+1, 200)
+	}
+	return
+}
+
+func Test_minimizer01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("minimizer01. gradient-based methods: Rosenbrock function")
+
+	names := []string{"BFGS", "L-BFGS", "CG-FR", "CG-PR", "Newton-CG"}
+	for _, method := range []int{MinBFGS, MinLBFGS, MinCGFR, MinCGPR, MinNewtonCG} {
+
+		// solve
+		sol := NewMinimizer(method, 2, rosenbrock, rosenbrockGrad)
+		sol.Hfcn = rosenbrockHess
+		sol.Hist = true
+		sol.MaxIt = 5000
+		res, err := sol.Min([]float64{-1.2, 1})
+		if err != nil {
+			tst.Errorf("%s failed:\n%v", names[method], err)
+			return
+		}
+
+		// check
+		io.Pforan("%-10s: x = %v  nit = %4d  nfeval = %4d  ngeval = %4d  nheval = %3d\n", names[method], res.X, res.Nit, res.Nfeval, res.Ngeval, res.NHeval)
+		if !res.Converged {
+			tst.Errorf("%s did not converge\n", names[method])
+		}
+		chk.Array(tst, "x", 1e-5, res.X, []float64{1, 1})
+		chk.Float64(tst, "f", 1e-10, res.F, 0)
+		chk.Int(tst, "len(HistF)", len(res.HistF), res.Nit+1)
+		chk.Int(tst, "len(HistG)", len(res.HistG), res.Nit+1)
+		chk.Int(tst, "len(HistX)", len(res.HistX), res.Nit+1)
+		chk.Array(tst, "x(0)", 1e-15, res.HistX[0], []float64{-1.2, 1})
+		chk.Float64(tst, "f(0)", 1e-13, res.HistF[0], 24.2)
+		if res.Nfeval < res.Nit || res.Ngeval < res.Nit {
+			tst.Errorf("numbers of evaluations are incorrect\n")
+		}
+		if method == MinNewtonCG && res.NHeval != res.Nit {
+			tst.Errorf("Hessian must be evaluated once per iteration\n")
+		}
+
+		// plot
+		if chk.Verbose {
+			f := func(x []float64) float64 { v, _ := rosenbrock(x); return v }
+			plt.Reset(false, nil)
+			PlotTwoVarsContour(res.X, 41, func() { PlotTwoVarsPath(res.HistX, nil) }, false,
+				[]float64{-2, -1}, []float64{2, 3}, &plt.A{Levels: utl.LinSpace(0, 100, 11)}, nil, f)
+			plt.Save("/tmp/gosl/opt", "t_minimizer01-"+names[method])
+		}
+	}
+}
+
+func Test_minimizer02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("minimizer02. gradient-based methods: quadratic function")
+
+	// f(x) = ½ xᵀA x - bᵀx  ⇒  x* = A⁻¹ b
+	A := [][]float64{
+		{4, 1, 0, 0, 0},
+		{1, 4, 1, 0, 0},
+		{0, 1, 4, 1, 0},
+		{0, 0, 1, 4, 1},
+		{0, 0, 0, 1, 4},
+	}
+	b := []float64{5, 6, 6, 6, 5}
+	ffcn := func(x la.Vector) (f float64, err error) {
+		for i := range x {
+			for j := range x {
+				f += 0.5 * x[i] * A[i][j] * x[j]
+			}
+			f -= b[i] * x[i]
+		}
+		return
+	}
+	gfcn := func(g, x la.Vector) (err error) {
+		for i := range x {
+			g[i] = -b[i]
+			for j := range x {
+				g[i] += A[i][j] * x[j]
+			}
+		}
+		return
+	}
+	hfcn := func(H *la.Matrix, x la.Vector) (err error) {
+		H.SetFromDeep2(A)
+		return
+	}
+
+	for _, method := range []int{MinBFGS, MinLBFGS, MinCGFR, MinCGPR, MinNewtonCG} {
+		sol := NewMinimizer(method, 5, ffcn, gfcn)
+		sol.Hfcn = hfcn
+		sol.Gtol = 1e-10
+		sol.EtaCG = 1e-14 // exact Newton step
+		res, err := sol.Min(la.NewVector(5))
+		if err != nil {
+			tst.Errorf("method %d failed:\n%v", method, err)
+			return
+		}
+		io.Pforan("method %d: nit = %d\n", method, res.Nit)
+		chk.Array(tst, "x", 1e-9, res.X, []float64{1, 1, 1, 1, 1})
+		chk.Float64(tst, "f", 1e-14, res.F, -14)
+		if method == MinNewtonCG {
+			chk.Int(tst, "Newton-CG: nit", res.Nit, 1)
+		}
+	}
+}
+
+func Test_minimizer03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("minimizer03. finite differences, L-BFGS memory and errors")
+
+	// 10-dimensional Rosenbrock function without gradient
+	ndim := 10
+	x0 := la.NewVector(ndim)
+	x0.Fill(-1)
+	ones := la.NewVector(ndim)
+	ones.Fill(1)
+	for _, nhist := range []int{3, 20} {
+		sol := NewMinimizer(MinLBFGS, ndim, rosenbrock, nil)
+		sol.Nhist = nhist
+		res, err := sol.Min(x0)
+		if err != nil {
+			tst.Errorf("L-BFGS failed:\n%v", err)
+			return
+		}
+		io.Pforan("L-BFGS(%d): nit = %d  nfeval = %d\n", nhist, res.Nit, res.Nfeval)
+		chk.Array(tst, "x", 1e-5, res.X, ones)
+		if res.Nfeval < res.Ngeval*2*ndim {
+			tst.Errorf("finite differences must call f(x) 2*ndim times per gradient\n")
+		}
+	}
+	chk.Array(tst, "x0 must not be modified", 1e-15, x0, utl.Vals(ndim, -1))
+
+	// Newton-CG with Hessian-vector products by finite differences
+	sol := NewMinimizer(MinNewtonCG, ndim, rosenbrock, rosenbrockGrad)
+	res, err := sol.Min(x0)
+	if err != nil {
+		tst.Errorf("Newton-CG failed:\n%v", err)
+		return
+	}
+	io.Pforan("Newton-CG: nit = %d  nheval = %d\n", res.Nit, res.NHeval)
+	chk.Array(tst, "x", 1e-6, res.X, ones)
+
+	// max iterations
+	sol = NewMinimizer(MinBFGS, ndim, rosenbrock, rosenbrockGrad)
+	sol.MaxIt = 2
+	res, err = sol.Min(x0)
+	if !utl.IsMaxIt(err) {
+		tst.Errorf("max iterations should have caused an error. err = %v\n", err)
+	}
+	chk.Int(tst, "nit", res.Nit, 2)
+
+	// wrong size
+	_, err = sol.Min([]float64{0})
+	if err == nil {
+		tst.Errorf("wrong size of x0 should have caused an error\n")
+	}
+}
@@ -0,0 +1,399 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+// nlSimple returns the problem
+//   min (x0-2)² + (x1-1)²   s.t.  x0² - x1 ≤ 0,  x0 + x1 ≤ 2
+// solution:
+//   x = {1, 1}  f = 1  λ = {2/3, 2/3}
+func nlSimple(withDerivs bool) (prob *NlProb) {
+	prob = &NlProb{
+		Ndim:  2,
+		Nineq: 2,
+		Ffcn: func(x la.Vector) (float64, error) {
+			a, b := x[0]-2, x[1]-1
+			return a*a + b*b, nil
+		},
+		Ineq: func(g, x la.Vector) error {
+			g[0] = x[0]*x[0] - x[1]
+			g[1] = x[0] + x[1] - 2
+			return nil
+		},
+	}
+	if withDerivs {
+		prob.Gfcn = func(g, x la.Vector) error {
+			g[0], g[1] = 2*(x[0]-2), 2*(x[1]-1)
+			return nil
+		}
+		prob.NnzIneq = 4
+		prob.IneqJac = func(J *la.Triplet, x la.Vector) error {
+			J.Start()
+			J.Put(0, 0, 2*x[0])
+			J.Put(0, 1, -1)
+			J.Put(1, 0, 1)
+			J.Put(1, 1, 1)
+			return nil
+		}
+	}
+	return
+}
+
+// nlHs071 returns the problem 71 of Hock and Schittkowski
+//   min  x0 x3 (x0 + x1 + x2) + x2
+//   s.t. x0 x1 x2 x3 ≥ 25,  Σ xᵢ² = 40,  1 ≤ xᵢ ≤ 5
+// solution:
+//   x = {1, 4.74299963, 3.82114998, 1.37940829}  f = 17.0140173
+func nlHs071() (prob *NlProb) {
+	return &NlProb{
+		Ndim:  4,
+		Nineq: 9,
+		Neq:   1,
+		Ffcn: func(x la.Vector) (float64, error) {
+			return x[0]*x[3]*(x[0]+x[1]+x[2]) + x[2], nil
+		},
+		Gfcn: func(g, x la.Vector) error {
+			g[0] = x[3]*(x[0]+x[1]+x[2]) + x[0]*x[3]
+			g[1] = x[0] * x[3]
+			g[2] = x[0]*x[3] + 1
+			g[3] = x[0] * (x[0] + x[1] + x[2])
+			return nil
+		},
+		Ineq: func(g, x la.Vector) error {
+			g[0] = 25 - x[0]*x[1]*x[2]*x[3]
+			for i := 0; i < 4; i++ {
+				g[1+i] = 1 - x[i]
+				g[5+i] = x[i] - 5
+			}
+			return nil
+		},
+		NnzIneq: 12,
+		IneqJac: func(J *la.Triplet, x la.Vector) error {
+			J.Start()
+			J.Put(0, 0, -x[1]*x[2]*x[3])
+			J.Put(0, 1, -x[0]*x[2]*x[3])
+			J.Put(0, 2, -x[0]*x[1]*x[3])
+			J.Put(0, 3, -x[0]*x[1]*x[2])
+			for i := 0; i < 4; i++ {
+				J.Put(1+i, i, -1)
+				J.Put(5+i, i, 1)
+			}
+			return nil
+		},
+		Eq: func(h, x la.Vector) error {
+			h[0] = la.VecDot(x, x) - 40
+			return nil
+		},
+		NnzEq: 4,
+		EqJac: func(J *la.Triplet, x la.Vector) error {
+			J.Start()
+			for i := 0; i < 4; i++ {
+				J.Put(0, i, 2*x[i])
+			}
+			return nil
+		},
+	}
+}
+
+// nlChain returns the problem with sparse (banded) Jacobian
+//   min  Σ (xᵢ - i)²   s.t.  xᵢ + xᵢ₊₁ = 1  (i = 0...n-2),  x₀ ≥ 1
+// solution (n even):
+//   x = {1, 0, 1, 0, ...}
+func nlChain(n int) (prob *NlProb) {
+	return &NlProb{
+		Ndim:  n,
+		Nineq: 1,
+		Neq:   n - 1,
+		Ffcn: func(x la.Vector) (f float64, err error) {
+			for i := range x {
+				d := x[i] - float64(i)
+				f += d * d
+			}
+			return
+		},
+		Gfcn: func(g, x la.Vector) error {
+			for i := range x {
+				g[i] = 2 * (x[i] - float64(i))
+			}
+			return nil
+		},
+		Ineq: func(g, x la.Vector) error {
+			g[0] = 1 - x[0]
+			return nil
+		},
+		NnzIneq: 1,
+		IneqJac: func(J *la.Triplet, x la.Vector) error {
+			J.Start()
+			J.Put(0, 0, -1)
+			return nil
+		},
+		Eq: func(h, x la.Vector) error {
+			for i := 0; i < n-1; i++ {
+				h[i] = x[i] + x[i+1] - 1
+			}
+			return nil
+		},
+		NnzEq: 2 * (n - 1),
+		EqJac: func(J *la.Triplet, x la.Vector) error {
+			J.Start()
+			for i := 0; i < n-1; i++ {
+				J.Put(i, i, 1)
+				J.Put(i, i+1, 1)
+			}
+			return nil
+		},
+	}
+}
+
+func Test_nlprob01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("nlprob01. SQP and augmented Lagrangian: simple problem")
+
+	for _, withDerivs := range []bool{true, false} {
+
+		// SQP
+		sqp := NewSqp(nlSimple(withDerivs))
+		sqp.Tol = 1e-10
+		sqp.Verbose = chk.Verbose
+		res, err := sqp.Solve([]float64{0, 0})
+		if err != nil {
+			tst.Errorf("SQP failed:\n%v", err)
+			return
+		}
+		io.Pforan("SQP: x = %v  λ = %v  nit = %d  nfeval = %d  njeval = %d\n", res.X, res.Lam, res.Nit, res.Nfeval, res.Njeval)
+		chk.Array(tst, "x", 1e-8, res.X, []float64{1, 1})
+		chk.Float64(tst, "f", 1e-8, res.F, 1)
+		chk.Array(tst, "λ", 1e-7, res.Lam, []float64{2.0 / 3.0, 2.0 / 3.0})
+		chk.Int(tst, "len(HistKkt)", len(res.HistKkt), res.Nit+1)
+		if !res.Converged || res.Kkt.Max() > 1e-10 {
+			tst.Errorf("SQP must converge with KKT residual ≤ 1e-10. kkt = %+v\n", res.Kkt)
+		}
+
+		// augmented Lagrangian
+		for _, method := range []int{MinLBFGS, MinBFGS} {
+			alg := NewAugLag(nlSimple(withDerivs), method)
+			alg.Verbose = chk.Verbose
+			res, err = alg.Solve([]float64{0, 0})
+			if err != nil {
+				tst.Errorf("AugLag failed:\n%v", err)
+				return
+			}
+			io.Pforan("AugLag: x = %v  λ = %v  nit = %d  nfeval = %d\n", res.X, res.Lam, res.Nit, res.Nfeval)
+			chk.Array(tst, "x", 1e-6, res.X, []float64{1, 1})
+			chk.Array(tst, "λ", 1e-5, res.Lam, []float64{2.0 / 3.0, 2.0 / 3.0})
+		}
+	}
+}
+
+func Test_nlprob02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("nlprob02. SQP and augmented Lagrangian: HS071")
+
+	xref := []float64{1, 4.74299963, 3.82114998, 1.37940829}
+	x0 := []float64{1, 5, 5, 1}
+
+	// SQP
+	sqp := NewSqp(nlHs071())
+	sqp.Tol = 1e-10
+	sqp.Verbose = chk.Verbose
+	res, err := sqp.Solve(x0)
+	if err != nil {
+		tst.Errorf("SQP failed:\n%v", err)
+		return
+	}
+	io.Pforan("SQP: x = %v  f = %v  nit = %d\n", res.X, res.F, res.Nit)
+	chk.Array(tst, "x", 1e-7, res.X, xref)
+	chk.Float64(tst, "f", 1e-7, res.F, 17.0140173)
+	if res.Lam[1] < 1e-3 {
+		tst.Errorf("constraint x0 ≥ 1 must be active: λ = %v\n", res.Lam)
+	}
+	lamSqp, muSqp := res.Lam.GetCopy(), res.Mu.GetCopy()
+
+	// augmented Lagrangian
+	alg := NewAugLag(nlHs071(), MinLBFGS)
+	alg.Verbose = chk.Verbose
+	res, err = alg.Solve(x0)
+	if err != nil {
+		tst.Errorf("AugLag failed:\n%v", err)
+		return
+	}
+	io.Pforan("AugLag: x = %v  f = %v  nit = %d\n", res.X, res.F, res.Nit)
+	chk.Array(tst, "x", 1e-6, res.X, xref)
+	chk.Array(tst, "λ(AugLag) == λ(SQP)", 1e-5, res.Lam, lamSqp)
+	chk.Array(tst, "μ(AugLag) == μ(SQP)", 1e-5, res.Mu, muSqp)
+	if res.Kkt.Max() > alg.Tol {
+		tst.Errorf("KKT residual is too large: %+v\n", res.Kkt)
+	}
+}
+
+func Test_nlprob03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("nlprob03. sparse Jacobians, inconsistent linearisation and errors")
+
+	// chain
+	n := 20
+	xref := la.NewVector(n)
+	for i := 0; i < n; i += 2 {
+		xref[i] = 1
+	}
+	for _, useSqp := range []bool{true, false} {
+		var res *NlResult
+		var err error
+		if useSqp {
+			sqp := NewSqp(nlChain(n))
+			sqp.Tol = 1e-10
+			res, err = sqp.Solve(la.NewVector(n))
+		} else {
+			alg := NewAugLag(nlChain(n), MinLBFGS)
+			res, err = alg.Solve(la.NewVector(n))
+		}
+		if err != nil {
+			tst.Errorf("solver failed:\n%v", err)
+			return
+		}
+		io.Pforan("x = %.6f  nit = %d\n", res.X, res.Nit)
+		chk.Array(tst, "x", 1e-6, res.X, xref)
+		chk.Float64(tst, "λ", 1e-5, res.Lam[0], 40) // with x = {a, 1-a, a, ...}: df/da = 40 a
+	}
+
+	// inconsistent linearisation at x0: x0² - 1 = 0 with x0 = 0
+	//   min (x0 - 3)²  s.t.  x0² = 1  ⇒  x0 = 1
+	prob := &NlProb{
+		Ndim: 1,
+		Neq:  1,
+		Ffcn: func(x la.Vector) (float64, error) { return (x[0] - 3) * (x[0] - 3), nil },
+		Eq: func(h, x la.Vector) error {
+			h[0] = x[0]*x[0] - 1
+			return nil
+		},
+	}
+	sqp := NewSqp(prob)
+	res, err := sqp.Solve([]float64{0})
+	if err != nil {
+		tst.Errorf("SQP failed:\n%v", err)
+		return
+	}
+	chk.Float64(tst, "x", 1e-6, res.X[0], 1)
+	chk.Float64(tst, "μ", 1e-5, res.Mu[0], 2)
+
+	// max iterations
+	sqp = NewSqp(nlHs071())
+	sqp.MaxIt = 2
+	_, err = sqp.Solve([]float64{1, 5, 5, 1})
+	if !utl.IsMaxIt(err) {
+		tst.Errorf("max iterations should have caused an error. err = %v\n", err)
+	}
+
+	// wrong input
+	_, err = sqp.Solve([]float64{1})
+	if err == nil {
+		tst.Errorf("wrong size of x0 should have caused an error\n")
+	}
+	_, err = NewAugLag(nlHs071(), MinNelderMead).Solve([]float64{1, 5, 5, 1})
+	if err == nil {
+		tst.Errorf("derivative-free method in AugLag should have caused an error\n")
+	}
+	_, err = NewSqp(&NlProb{Ndim: 1, Neq: 1, Ffcn: prob.Ffcn}).Solve([]float64{0})
+	if err == nil {
+		tst.Errorf("missing constraint function should have caused an error\n")
+	}
+}
+
+// nlIsotonic returns the isotonic regression problem:
+//
+//   min Σ (xᵢ - wᵢ)²  s.t.  xᵢ - xᵢ₊₁ ≤ 0   with   wᵢ = i + 3 (-1)ⁱ
+//
+//  whose solution pools the pairs {w₂ₖ, w₂ₖ₊₁}; i.e. x₂ₖ = x₂ₖ₊₁ = 2k + ½
+func nlIsotonic(n int) (prob *NlProb) {
+	w := la.NewVector(n)
+	for i := 0; i < n; i++ {
+		w[i] = float64(i) + 3*float64(1-2*(i%2))
+	}
+	return &NlProb{
+		Ndim:  n,
+		Nineq: n - 1,
+		Ffcn: func(x la.Vector) (f float64, err error) {
+			for i := range x {
+				d := x[i] - w[i]
+				f += d * d
+			}
+			return
+		},
+		Gfcn: func(g, x la.Vector) error {
+			for i := range x {
+				g[i] = 2 * (x[i] - w[i])
+			}
+			return nil
+		},
+		Ineq: func(g, x la.Vector) error {
+			for i := 0; i < n-1; i++ {
+				g[i] = x[i] - x[i+1]
+			}
+			return nil
+		},
+		NnzIneq: 2 * (n - 1),
+		IneqJac: func(J *la.Triplet, x la.Vector) error {
+			J.Start()
+			for i := 0; i < n-1; i++ {
+				J.Put(i, i, 1)
+				J.Put(i, i+1, -1)
+			}
+			return nil
+		},
+	}
+}
+
+func Test_nlprob04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("nlprob04. SQP with large sparse Jacobians")
+
+	// chain: λ = 2n (see nlprob03)
+	n := 200
+	sqp := NewSqp(nlChain(n))
+	sqp.Tol = 1e-10
+	res, err := sqp.Solve(la.NewVector(n))
+	if err != nil {
+		tst.Errorf("SQP failed:\n%v", err)
+		return
+	}
+	io.Pforan("chain: nit = %d\n", res.Nit)
+	xref := la.NewVector(n)
+	for i := 0; i < n; i += 2 {
+		xref[i] = 1
+	}
+	chk.Array(tst, "x", 1e-6, res.X, xref)
+	chk.Float64(tst, "λ", 1e-4, res.Lam[0], float64(2*n))
+
+	// isotonic regression: λ₂ₖ = 2 (w₂ₖ - x₂ₖ) = 5 and λ₂ₖ₊₁ = 0
+	sqp = NewSqp(nlIsotonic(n))
+	sqp.Tol = 1e-10
+	res, err = sqp.Solve(la.NewVector(n))
+	if err != nil {
+		tst.Errorf("SQP failed:\n%v", err)
+		return
+	}
+	io.Pforan("isotonic: nit = %d\n", res.Nit)
+	lref := la.NewVector(n - 1)
+	for i := 0; i < n; i++ {
+		xref[i] = float64(i-i%2) + 0.5
+		if i%2 == 0 {
+			lref[i] = 5
+		}
+	}
+	chk.Array(tst, "x", 1e-6, res.X, xref)
+	chk.Array(tst, "λ", 1e-5, res.Lam, lref)
+}
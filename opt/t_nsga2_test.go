@@ -0,0 +1,232 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/plt"
+	"github.com/cpmech/gosl/rnd"
+	"github.com/cpmech/gosl/utl"
+)
+
+// zdt1 implements the ZDT1 problem with Pareto-optimal front f₁ = 1 - √f₀ (x₁ = ... = xₙ₋₁ = 0)
+func zdt1(f, g, x la.Vector) (err error) {
+	n := len(x)
+	s := 0.0
+	for j := 1; j < n; j++ {
+		s += x[j]
+	}
+	h := 1 + 9*s/float64(n-1)
+	f[0] = x[0]
+	f[1] = h * (1 - math.Sqrt(x[0]/h))
+	return
+}
+
+// frontOvs returns the objective values of the Pareto front approximation
+func frontOvs(sol *Nsga2) (ovs [][]float64) {
+	ovs = make([][]float64, len(sol.Front))
+	for k, i := range sol.Front {
+		ovs[k] = sol.Ovs[i]
+	}
+	return
+}
+
+func Test_nsga201(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("nsga201. NSGA-II: ZDT1 problem")
+
+	// solve
+	sol := NewNsga2(2, 0, zdt1, utl.Vals(30, 0), utl.Vals(30, 1))
+	sol.Seed = 1234
+	err := sol.Solve()
+	if err != nil {
+		tst.Errorf("Solve failed:\n%v", err)
+		return
+	}
+
+	// reference front
+	nref := 101
+	ref := make([][]float64, nref)
+	for i := 0; i < nref; i++ {
+		f0 := float64(i) / float64(nref-1)
+		ref[i] = []float64{f0, 1 - math.Sqrt(f0)}
+	}
+
+	// check
+	ovs := frontOvs(sol)
+	hv := Hypervolume(ovs, []float64{1.1, 1.1})
+	igd := Igd(ovs, ref)
+	io.Pforan("nit = %d  nfeval = %d  nfront = %d  hv = %v  igd = %v\n", sol.Nit, sol.Nfeval, len(sol.Front), hv, igd)
+	chk.Int(tst, "nit", sol.Nit, 250)
+	chk.Int(tst, "nfeval", sol.Nfeval, 251*100)
+	chk.Int(tst, "nfront", len(sol.Front), 100)
+	for _, i := range sol.Front {
+		if sol.Rank[i] != 0 {
+			tst.Errorf("front has solution with rank %d\n", sol.Rank[i])
+			return
+		}
+	}
+	hvRef := Hypervolume(ref, []float64{1.1, 1.1}) // ≈ 1.21 - 1/3
+	chk.Float64(tst, "hv", 0.01, hv, hvRef)
+	if igd > 0.01 {
+		tst.Errorf("igd is too large: %g\n", igd)
+	}
+
+	// plot
+	if chk.Verbose {
+		plt.Reset(false, nil)
+		PlotParetoFront(sol.Ovs, ref, nil, nil)
+		plt.Gll("$f_0$", "$f_1$", nil)
+		plt.Save("/tmp/gosl", "nsga201")
+	}
+}
+
+func Test_nsga202(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("nsga202. NSGA-II: constrained CONSTR problem")
+
+	// problem
+	ofcn := func(f, g, x la.Vector) (err error) {
+		f[0] = x[0]
+		f[1] = (1 + x[1]) / x[0]
+		g[0] = 6 - x[1] - 9*x[0]
+		g[1] = 1 + x[1] - 9*x[0]
+		return
+	}
+
+	// reference front: x₁ = 6 - 9 x₀ for x₀ ∈ [7/18, 2/3] and x₁ = 0 for x₀ ∈ [2/3, 1]
+	nref := 101
+	ref := make([][]float64, nref)
+	for i := 0; i < nref; i++ {
+		f0 := 7.0/18.0 + float64(i)*(1-7.0/18.0)/float64(nref-1)
+		if f0 < 2.0/3.0 {
+			ref[i] = []float64{f0, (7 - 9*f0) / f0}
+		} else {
+			ref[i] = []float64{f0, 1 / f0}
+		}
+	}
+
+	// solve with tournaments by rank and crowding distance and by utl.ParetoMinProb
+	for _, φ := range []float64{0, 0.5} {
+		sol := NewNsga2(2, 2, ofcn, []float64{0.1, 0}, []float64{1, 5})
+		sol.Seed = 1234
+		sol.MaxIt = 100
+		sol.Phi = φ
+		err := sol.Solve()
+		if err != nil {
+			tst.Errorf("Solve failed:\n%v", err)
+			return
+		}
+
+		// check
+		ovs := frontOvs(sol)
+		igd := Igd(ovs, ref)
+		io.Pforan("φ = %g: nfront = %d  igd = %v\n", φ, len(sol.Front), igd)
+		if len(sol.Front) < 50 {
+			tst.Errorf("front is too small: %d\n", len(sol.Front))
+		}
+		for _, i := range sol.Front {
+			if sol.Viol[i] > 0 || sol.Ocs[i][0] > 0 || sol.Ocs[i][1] > 0 {
+				tst.Errorf("front has infeasible solution: g = %v\n", sol.Ocs[i])
+				return
+			}
+		}
+		if igd > 0.05 {
+			tst.Errorf("igd is too large: %g\n", igd)
+		}
+	}
+}
+
+func Test_nsga203(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("nsga203. hypervolume and inverted generational distance")
+
+	// hypervolume: 2D
+	ovs := [][]float64{{3, 1}, {1, 3}, {2, 2}, {2.5, 2.5}, {5, 0}}
+	chk.Float64(tst, "hv 2D", 1e-15, Hypervolume(ovs, []float64{4, 4}), 6)
+	chk.Float64(tst, "hv 2D: empty", 1e-15, Hypervolume(nil, []float64{4, 4}), 0)
+
+	// hypervolume: 3D
+	chk.Float64(tst, "hv 3D: one point", 1e-15, Hypervolume([][]float64{{0, 0, 0}}, []float64{1, 2, 3}), 6)
+	ovs = [][]float64{{0, 0, 1}, {1, 1, 0}, {1.5, 1.5, 1.5}}
+	chk.Float64(tst, "hv 3D: two boxes", 1e-15, Hypervolume(ovs, []float64{2, 2, 2}), 5)
+
+	// hypervolume: 4D with intersecting boxes (inclusion-exclusion)
+	ovs = [][]float64{{0, 1, 1, 1}, {1, 0, 1, 1}, {1, 1, 0, 1}, {1, 1, 1, 0}}
+	chk.Float64(tst, "hv 4D", 1e-15, Hypervolume(ovs, []float64{2, 2, 2, 2}), 4*2-6+4-1)
+
+	// inverted generational distance
+	ref := [][]float64{{0, 1}, {0.5, 0.5}, {1, 0}}
+	chk.Float64(tst, "igd: same", 1e-15, Igd(ref, ref), 0)
+	chk.Float64(tst, "igd: shifted", 1e-15, Igd([][]float64{{0, 1.5}, {0.5, 1}, {1, 0.5}}, ref), 0.5)
+	chk.Float64(tst, "igd: one point", 1e-15, Igd([][]float64{{0, 0}}, ref), (2+math.Sqrt(0.5))/3)
+	if !math.IsInf(Igd(nil, ref), 1) {
+		tst.Errorf("igd of empty set must be +∞\n")
+	}
+}
+
+func Test_nsga204(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("nsga204. NSGA-II: reproducibility and errors")
+
+	// sequential and concurrent evaluations; the global generator must neither affect the
+	// results nor be affected
+	var ovs [][][]float64
+	for k, nworkers := range []int{1, 4} {
+		rnd.Init(100 + k)
+		a := rnd.Int(0, 1000000)
+		rnd.Init(100 + k)
+		sol := NewNsga2(2, 0, zdt1, utl.Vals(5, 0), utl.Vals(5, 1))
+		sol.Seed = 4321
+		sol.Npop = 20
+		sol.MaxIt = 30
+		sol.Nworkers = nworkers
+		err := sol.Solve()
+		if err != nil {
+			tst.Errorf("Solve failed:\n%v", err)
+			return
+		}
+		ovs = append(ovs, sol.Ovs)
+		chk.Int(tst, "global generator", rnd.Int(0, 1000000), a)
+	}
+	chk.Deep2(tst, "ovs", 1e-15, ovs[0], ovs[1])
+
+	// error in objective function
+	ofcn := func(f, g, x la.Vector) (err error) {
+		if x[0] > 0.5 {
+			return chk.Err("x[0] = %g is too large", x[0])
+		}
+		return zdt1(f, g, x)
+	}
+	sol := NewNsga2(2, 0, ofcn, utl.Vals(3, 0), utl.Vals(3, 1))
+	sol.Nworkers = 2
+	if sol.Solve() == nil {
+		tst.Errorf("error in objective function should have been returned\n")
+	}
+
+	// wrong inputs
+	sol = NewNsga2(2, 0, zdt1, utl.Vals(3, 0), utl.Vals(3, 1))
+	sol.Npop = 5
+	if sol.Solve() == nil {
+		tst.Errorf("odd population size should have failed\n")
+	}
+	sol = NewNsga2(2, 0, zdt1, utl.Vals(3, 0), []float64{1, 0, 1})
+	if sol.Solve() == nil {
+		tst.Errorf("wrong bounds should have failed\n")
+	}
+	sol = NewNsga2(0, 0, zdt1, utl.Vals(3, 0), utl.Vals(3, 1))
+	if sol.Solve() == nil {
+		tst.Errorf("zero objectives should have failed\n")
+	}
+}
@@ -0,0 +1,263 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package opt
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+	"github.com/cpmech/gosl/la"
+	"github.com/cpmech/gosl/utl"
+)
+
+// qpSparse converts a dense matrix (given by rows) to compressed-column format
+func qpSparse(a [][]float64) *la.CCMatrix {
+	var T la.Triplet
+	T.Init(len(a), len(a[0]), len(a)*len(a[0]))
+	for i := range a {
+		for j := range a[i] {
+			if a[i][j] != 0 {
+				T.Put(i, j, a[i][j])
+			}
+		}
+	}
+	return T.ToMatrix(nil)
+}
+
+func Test_qpipm01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("qpipm01. QP with inequalities and bounds")
+
+	// Nocedal and Wright (2006) Example 16.4
+	//   min (x0 - 1)² + (x1 - 2.5)²
+	//   s.t. -x0 + 2x1 ≤ 2,  x0 + 2x1 ≤ 6,  x0 - 2x1 ≤ 2,  x ≥ 0
+	prob := &QpProb{
+		Q: qpSparse([][]float64{{2, 0}, {0, 2}}),
+		C: []float64{-2, -5},
+		G: qpSparse([][]float64{{-1, 2}, {1, 2}, {1, -2}}),
+		H: []float64{2, 6, 2},
+		L: []float64{0, 0},
+	}
+	sol := NewQpIpm(prob)
+	defer sol.Free()
+	sol.Verbose = chk.Verbose
+	err := sol.Solve()
+	if err != nil {
+		tst.Errorf("QpIpm failed:\n%v", err)
+		return
+	}
+	io.Pforan("x = %v  z = %v  nit = %d\n", sol.X, sol.Z, sol.Nit)
+	chk.Int(tst, "status", sol.Status, QpOptimal)
+	chk.Array(tst, "x", 1e-8, sol.X, []float64{1.4, 1.7})
+	chk.Float64(tst, "f", 1e-8, sol.F, -6.45)
+	chk.Array(tst, "z", 1e-8, sol.Z, []float64{0.8, 0, 0})
+	chk.Array(tst, "zl", 1e-8, sol.Zl, []float64{0, 0})
+	chk.Int(tst, "len(zu)", len(sol.Zu), 2)
+}
+
+func Test_qpipm02(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("qpipm02. portfolio optimisation (compared with dense QP solver)")
+
+	// Markowitz portfolio: min ½xᵀΣx  s.t.  Σ xᵢ = 1,  μᵀx ≥ r,  0 ≤ x ≤ 0.4
+	n := 5
+	M := [][]float64{
+		{0.20, 0.02, 0.01, 0.00, 0.03},
+		{0.01, 0.15, 0.02, 0.04, 0.00},
+		{0.03, 0.00, 0.25, 0.01, 0.02},
+		{0.00, 0.05, 0.01, 0.10, 0.01},
+		{0.02, 0.01, 0.00, 0.03, 0.30},
+	}
+	Σ := la.NewMatrix(n, n) // Σ = MᵀM
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			for k := 0; k < n; k++ {
+				Σ.Add(i, j, M[k][i]*M[k][j])
+			}
+		}
+	}
+	μ := []float64{0.08, 0.10, 0.12, 0.05, 0.15}
+	r := 0.11
+	prob := &QpProb{
+		Q: qpSparse(Σ.GetDeep2()),
+		C: la.NewVector(n),
+		A: qpSparse([][]float64{utl.Vals(n, 1)}),
+		B: []float64{1},
+		G: qpSparse([][]float64{{-μ[0], -μ[1], -μ[2], -μ[3], -μ[4]}}),
+		H: []float64{-r},
+		L: la.NewVector(n),
+		U: utl.Vals(n, 0.4),
+	}
+
+	// solve
+	sol := NewQpIpm(prob)
+	defer sol.Free()
+	sol.Tol = 1e-10
+	sol.Verbose = chk.Verbose
+	err := sol.Solve()
+	if err != nil {
+		tst.Errorf("QpIpm failed:\n%v", err)
+		return
+	}
+	io.Pforan("x = %.6f  nit = %d\n", sol.X, sol.Nit)
+	chk.Float64(tst, "Σx", 1e-10, la.VecDot(sol.X, utl.Vals(n, 1)), 1)
+	chk.Float64(tst, "μᵀx", 1e-10, la.VecDot(sol.X, μ), r)
+
+	// solver of SQP subproblems with bounds as inequalities
+	var Ai, Ae la.Triplet
+	Ai.Init(1+2*n, n, 3*n)
+	Ae.Init(1, n, n)
+	bi := la.NewVector(1 + 2*n)
+	for j := 0; j < n; j++ {
+		Ai.Put(0, j, -μ[j])
+		Ai.Put(1+j, j, -1)
+		Ai.Put(1+n+j, j, 1)
+		Ae.Put(0, j, 1)
+		bi[1+n+j] = 0.4
+	}
+	bi[0] = -r
+	x, y, z, err := qpSolve(Σ, la.NewVector(n), Ae.ToMatrix(nil), []float64{1}, Ai.ToMatrix(nil), bi)
+	if err != nil {
+		tst.Errorf("qpSolve failed:\n%v", err)
+		return
+	}
+	chk.Array(tst, "x", 1e-8, sol.X, x)
+	chk.Array(tst, "y", 1e-8, sol.Y, y)
+	chk.Array(tst, "z", 1e-8, sol.Z, z[:1])
+	chk.Array(tst, "zl", 1e-8, sol.Zl, z[1:1+n])
+	chk.Array(tst, "zu", 1e-8, sol.Zu, z[1+n:])
+
+	// stationarity: Σx + Aᵀy + Gᵀz - zl + zu = 0
+	rd := la.NewVector(n)
+	for j := 0; j < n; j++ {
+		rd[j] = la.VecDot(Σ.GetRow(j), sol.X) + sol.Y[0] - μ[j]*sol.Z[0] - sol.Zl[j] + sol.Zu[j]
+	}
+	chk.Array(tst, "stationarity", 1e-9, rd, nil)
+}
+
+func Test_qpipm03(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("qpipm03. linear program, dependent equalities and free variables")
+
+	// linear program: min -3x0 - 5x1  s.t.  2x1 ≤ 12,  3x0 + 2x1 ≤ 18,  0 ≤ x0 ≤ 4,  x1 ≥ 0
+	prob := &QpProb{
+		C: []float64{-3, -5},
+		G: qpSparse([][]float64{{0, 2}, {3, 2}}),
+		H: []float64{12, 18},
+		L: []float64{0, 0},
+		U: []float64{4, math.Inf(1)},
+	}
+	sol := NewQpIpm(prob)
+	defer sol.Free()
+	sol.Verbose = chk.Verbose
+	err := sol.Solve()
+	if err != nil {
+		tst.Errorf("QpIpm failed:\n%v", err)
+		return
+	}
+	io.Pforan("x = %v  z = %v  nit = %d\n", sol.X, sol.Z, sol.Nit)
+	chk.Array(tst, "x", 1e-7, sol.X, []float64{2, 6})
+	chk.Float64(tst, "f", 1e-7, sol.F, -36)
+	chk.Array(tst, "z", 1e-7, sol.Z, []float64{1.5, 1})
+	chk.Array(tst, "zu", 1e-7, sol.Zu, nil)
+
+	// repeated equality and free variables
+	//   min ½(x0² + x1² + x2²)  s.t.  x0 + x1 + x2 = 3 (twice)
+	prob = &QpProb{
+		Q: qpSparse([][]float64{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}),
+		C: la.NewVector(3),
+		A: qpSparse([][]float64{{1, 1, 1}, {1, 1, 1}}),
+		B: []float64{3, 3},
+	}
+	sol = NewQpIpm(prob)
+	defer sol.Free()
+	err = sol.Solve()
+	if err != nil {
+		tst.Errorf("QpIpm failed:\n%v", err)
+		return
+	}
+	io.Pforan("x = %v  y = %v  nit = %d\n", sol.X, sol.Y, sol.Nit)
+	chk.Array(tst, "x", 1e-8, sol.X, []float64{1, 1, 1})
+	chk.Float64(tst, "y0 + y1", 1e-8, sol.Y[0]+sol.Y[1], -1)
+}
+
+func Test_qpipm04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("qpipm04. infeasible and unbounded problems")
+
+	// infeasible: x0 + x1 = 1 and x0 + x1 ≤ 0.5
+	prob := &QpProb{
+		Q: qpSparse([][]float64{{1, 0}, {0, 1}}),
+		C: []float64{1, 1},
+		A: qpSparse([][]float64{{1, 1}}),
+		B: []float64{1},
+		G: qpSparse([][]float64{{1, 1}}),
+		H: []float64{0.5},
+	}
+	sol := NewQpIpm(prob)
+	defer sol.Free()
+	err := sol.Solve()
+	io.Pforan("%v\n", err)
+	if err == nil {
+		tst.Errorf("infeasible problem should have caused an error\n")
+	}
+	chk.Int(tst, "status", sol.Status, QpInfeasible)
+	if sol.Z[0] <= 0 || math.Abs(sol.Y[0]+sol.Z[0]) > 1e-6*sol.Z[0] { // certificate: y = -z
+		tst.Errorf("certificate is incorrect: y = %v, z = %v\n", sol.Y, sol.Z)
+	}
+
+	// infeasible bounds
+	prob = &QpProb{C: []float64{1, 1}, L: []float64{0, 2}, U: []float64{1, 1}}
+	sol = NewQpIpm(prob)
+	defer sol.Free()
+	err = sol.Solve()
+	io.Pforan("%v\n", err)
+	chk.Int(tst, "status", sol.Status, QpInfeasible)
+
+	// unbounded: min -x0 + x1²  s.t.  x ≥ 0
+	prob = &QpProb{
+		Q: qpSparse([][]float64{{0, 0}, {0, 2}}),
+		C: []float64{-1, 0},
+		L: []float64{0, 0},
+	}
+	sol = NewQpIpm(prob)
+	defer sol.Free()
+	err = sol.Solve()
+	io.Pforan("%v\n", err)
+	chk.Int(tst, "status", sol.Status, QpUnbounded)
+
+	// unbounded linear program: min -x0 - x1  s.t.  x0 - x1 + x2 = 3,  x ≥ 0
+	prob = &QpProb{
+		C: []float64{-1, -1, 0},
+		A: qpSparse([][]float64{{1, -1, 1}}),
+		B: []float64{3},
+		L: []float64{0, 0, 0},
+	}
+	sol = NewQpIpm(prob)
+	defer sol.Free()
+	err = sol.Solve()
+	io.Pforan("%v\n", err)
+	chk.Int(tst, "status", sol.Status, QpUnbounded)
+	if sol.X[1] < 1e3 {
+		tst.Errorf("x1 must be large (direction of recession): x = %v\n", sol.X)
+	}
+
+	// max iterations
+	prob = &QpProb{C: []float64{-3, -5}, G: qpSparse([][]float64{{0, 2}, {3, 2}}), H: []float64{12, 18}, L: []float64{0, 0}}
+	sol = NewQpIpm(prob)
+	defer sol.Free()
+	sol.NmaxIt = 2
+	err = sol.Solve()
+	if !utl.IsMaxIt(err) {
+		tst.Errorf("max iterations should have caused an error. err = %v\n", err)
+	}
+	chk.Int(tst, "status", sol.Status, QpUnsolved)
+}
@@ -6,6 +6,7 @@ package rnd
 
 import (
 	"math"
+	"math/rand"
 
 	"github.com/cpmech/gosl/utl"
 )
@@ -20,6 +21,11 @@ import (
 //  Output:
 //   x   -- [dim][n] points
 func LatinIHS(dim, n, d int) (x [][]int) {
+	return LatinIHSrand(NewRand(0), dim, n, d)
+}
+
+// LatinIHSrand implements LatinIHS with the random numbers generator r; e.g. from NewRand
+func LatinIHSrand(r *rand.Rand, dim, n, d int) (x [][]int) {
 
 	//  Discussion:
 	//
@@ -72,7 +78,7 @@ func LatinIHS(dim, n, d int) (x [][]int) {
 
 	// pick the first point
 	for i = 0; i < dim; i++ {
-		x[i][n-1] = Int(1, n)
+		x[i][n-1] = r.Int()%n + 1
 	}
 
 	// initialize avail and set an entry in a random row of each column of avail to n
@@ -97,7 +103,7 @@ func LatinIHS(dim, n, d int) (x [][]int) {
 			}
 
 			for k = count*d - 1; 0 <= k; k-- {
-				pointIndex = Int(0, k)
+				pointIndex = r.Int() % (k + 1)
 				point[i+k*dim] = list[pointIndex]
 				list[pointIndex] = list[k]
 			}
@@ -23,6 +23,23 @@ func Init(seed int) {
 	rand.Seed(int64(seed))
 }
 
+// NewRand returns a random numbers generator that does not change the state of the global
+// generator (see Init) if seed > 0
+//  Input:
+//   seed -- seed value; use seed <= 0 to draw numbers from the global generator instead
+func NewRand(seed int) *rand.Rand {
+	if seed <= 0 {
+		return rand.New(globalSource{})
+	}
+	return rand.New(rand.NewSource(int64(seed)))
+}
+
+// globalSource implements rand.Source by means of the global generator
+type globalSource struct{}
+
+func (globalSource) Int63() int64    { return rand.Int63() }
+func (globalSource) Seed(seed int64) { rand.Seed(seed) }
+
 // Int generates pseudo random integer between low and high.
 //  Input:
 //   low  -- lower limit
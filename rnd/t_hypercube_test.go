@@ -96,3 +96,18 @@ func Test_hc03(tst *testing.T) {
 		}
 	}
 }
+
+func Test_hc04(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("hc04. hypercube with own generator")
+
+	// same points as LatinIHS after Init(111) (see hc01)
+	Init(222)
+	x := LatinIHSrand(NewRand(111), 2, 10, 5)
+	xchk := [][]int{
+		{2, 9, 5, 8, 1, 4, 3, 10, 7, 6},
+		{3, 10, 1, 2, 7, 4, 9, 6, 5, 8},
+	}
+	chk.IntDeep2(tst, "x", x, xchk)
+}
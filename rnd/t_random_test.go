@@ -208,3 +208,31 @@ func checkRepeated(v []int) {
 		}
 	}
 }
+
+func Test_newrand01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("newrand01. generators with own seed")
+
+	// same sequence as the global generator with the same seed
+	Init(1234)
+	vals := make([]float64, Nsamples)
+	Float64s(vals, 0, 1)
+	r := NewRand(1234)
+	for i := 0; i < Nsamples; i++ {
+		chk.Float64(tst, io.Sf("x%d", i), 1e-17, r.Float64(), vals[i])
+	}
+
+	// the global generator is not affected
+	Init(4321)
+	a := Int(0, 1000000)
+	Init(4321)
+	r = NewRand(1234)
+	r.Int()
+	chk.Int(tst, "global", Int(0, 1000000), a)
+
+	// seed ≤ 0 ⇒ the global generator is used
+	Init(4321)
+	r = NewRand(0)
+	chk.Int(tst, "global", r.Int()%1000001, a)
+}
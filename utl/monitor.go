@@ -0,0 +1,118 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package utl
+
+import (
+	"context"
+	"fmt"
+)
+
+// StopKind defines the reason why an iterative solver stopped before converging
+type StopKind int
+
+const (
+	// StopCancelled indicates that the context was cancelled or its deadline was exceeded
+	StopCancelled StopKind = iota + 1
+
+	// StopDiverged indicates that the iterations are diverging
+	StopDiverged
+
+	// StopMaxIt indicates that the maximum number of iterations has been reached
+	StopMaxIt
+)
+
+// String returns the name of the stop kind
+func (o StopKind) String() string {
+	switch o {
+	case StopCancelled:
+		return "cancelled"
+	case StopDiverged:
+		return "diverged"
+	case StopMaxIt:
+		return "max iterations"
+	}
+	return "unknown"
+}
+
+// IterError is the error returned by iterative solvers that stop before converging
+type IterError struct {
+	Kind   StopKind // reason for stopping
+	Solver string   // name of the solver; e.g. "NlSolver"
+	It     int      // iteration number when the solver stopped
+	Msg    string   // message
+	Err    error    // cause, if any; e.g. context.Canceled or context.DeadlineExceeded
+}
+
+// NewIterError returns a new IterError
+func NewIterError(kind StopKind, solver string, it int, msg string, prm ...interface{}) *IterError {
+	return &IterError{Kind: kind, Solver: solver, It: it, Msg: fmt.Sprintf(msg, prm...)}
+}
+
+// Error returns the error message
+func (o *IterError) Error() string {
+	l := fmt.Sprintf("%s: %s (it = %d)", o.Solver, o.Kind, o.It)
+	if o.Msg != "" {
+		l += ": " + o.Msg
+	}
+	if o.Err != nil {
+		l += ": " + o.Err.Error()
+	}
+	return l
+}
+
+// Unwrap returns the cause of the error
+func (o *IterError) Unwrap() error {
+	return o.Err
+}
+
+// IsCancelled tells whether err is an IterError caused by cancellation
+func IsCancelled(err error) bool {
+	e, ok := err.(*IterError)
+	return ok && e.Kind == StopCancelled
+}
+
+// IsDiverged tells whether err is an IterError caused by divergence
+func IsDiverged(err error) bool {
+	e, ok := err.(*IterError)
+	return ok && e.Kind == StopDiverged
+}
+
+// IsMaxIt tells whether err is an IterError caused by reaching the maximum number of iterations
+func IsMaxIt(err error) bool {
+	e, ok := err.(*IterError)
+	return ok && e.Kind == StopMaxIt
+}
+
+// Progress holds information about the current iteration of an iterative solver
+type Progress struct {
+	Solver   string  // name of the solver
+	It       int     // iteration number
+	Residual float64 // residual or error measure
+	Step     float64 // step size; e.g. norm of correction or Δx
+}
+
+// Monitor checks for cancellation and reports the progress of iterative solvers
+//  Note: the zero value is ready to use and does nothing
+type Monitor struct {
+	Ctx      context.Context  // context for cancellation [may be nil]
+	Callback func(p Progress) // progress callback [may be nil]
+}
+
+// Check reports the progress and checks whether the context has been cancelled
+//  Output:
+//   err -- an IterError with Kind == StopCancelled if the context is done; nil otherwise
+func (o *Monitor) Check(solver string, it int, residual, step float64) (err error) {
+	if o.Callback != nil {
+		o.Callback(Progress{solver, it, residual, step})
+	}
+	if o.Ctx != nil {
+		select {
+		case <-o.Ctx.Done():
+			return &IterError{Kind: StopCancelled, Solver: solver, It: it, Err: o.Ctx.Err()}
+		default:
+		}
+	}
+	return
+}
@@ -0,0 +1,77 @@
+// Copyright 2016 The Gosl Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package utl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cpmech/gosl/chk"
+	"github.com/cpmech/gosl/io"
+)
+
+func Test_monitor01(tst *testing.T) {
+
+	//verbose()
+	chk.PrintTitle("monitor01. progress and cancellation")
+
+	// zero value does nothing
+	var mon Monitor
+	err := mon.Check("solver", 0, 1, 1)
+	if err != nil {
+		tst.Errorf("zero monitor should not fail: %v\n", err)
+		return
+	}
+
+	// progress
+	var its []int
+	var res []float64
+	mon.Callback = func(p Progress) {
+		its = append(its, p.It)
+		res = append(res, p.Residual)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mon.Ctx = ctx
+	for it := 0; it < 3; it++ {
+		err = mon.Check("solver", it, 1.0/float64(it+1), 0)
+		if err != nil {
+			tst.Errorf("%v\n", err)
+			return
+		}
+	}
+	chk.Ints(tst, "its", its, []int{0, 1, 2})
+	chk.Array(tst, "res", 1e-15, res, []float64{1, 0.5, 1.0 / 3.0})
+
+	// cancellation
+	cancel()
+	err = mon.Check("solver", 3, 0.25, 0)
+	io.Pforan("err = %v\n", err)
+	if !IsCancelled(err) {
+		tst.Errorf("error should indicate cancellation\n")
+		return
+	}
+	if IsDiverged(err) || IsMaxIt(err) {
+		tst.Errorf("error kind is wrong\n")
+		return
+	}
+	e := err.(*IterError)
+	chk.IntAssert(e.It, 3)
+	if e.Unwrap() != context.Canceled {
+		tst.Errorf("cause should be context.Canceled\n")
+		return
+	}
+
+	// other kinds
+	err = NewIterError(StopMaxIt, "solver", 10, "did not converge after %d iterations", 10)
+	io.Pforan("err = %v\n", err)
+	chk.String(tst, err.Error(), "solver: max iterations (it = 10): did not converge after 10 iterations")
+	if !IsMaxIt(err) || IsCancelled(err) {
+		tst.Errorf("error kind is wrong\n")
+	}
+	if IsDiverged(nil) {
+		tst.Errorf("nil is not an IterError\n")
+	}
+}